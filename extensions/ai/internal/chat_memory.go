@@ -0,0 +1,488 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+)
+
+// MemoryStrategy is the strategy used to keep the chat history within the context window of the model.
+type MemoryStrategy string
+
+const (
+	// WindowMemory keeps the most recent turns that fit within the token budget.
+	WindowMemory MemoryStrategy = "window"
+	// SummaryMemory keeps the most recent turns verbatim and folds older turns into a rolling summary.
+	SummaryMemory MemoryStrategy = "summary"
+	// VectorMemory keeps the most recent turns verbatim and recalls the earlier turns most similar to the new message.
+	VectorMemory MemoryStrategy = "vector"
+)
+
+const (
+	// DefaultRecentTurns is the number of turns kept verbatim by the summary and vector strategies.
+	DefaultRecentTurns = 4
+	// DefaultRecallTurns is the number of earlier turns recalled by the vector strategy.
+	DefaultRecallTurns = 3
+	// defaultContextWindow is used for models with an unknown context window.
+	defaultContextWindow = 4096
+	// EstimatedImageTokens is the assumed token cost of an image attachment.
+	EstimatedImageTokens = 765
+	// messageOverheadTokens are the tokens added by the chat format for each message.
+	messageOverheadTokens = 4
+	// maxSummaryTokens is the size limit of the rolling summary.
+	maxSummaryTokens = 500
+)
+
+var ErrUnsupportedMemoryStrategy = errors.New("unsupported memory strategy")
+
+// contextWindows are the context window sizes of model families used when the deployment doesn't report them.
+// Entries are matched by exact model name first and then by the longest model name prefix.
+var contextWindows = map[string]int{
+	"gpt-35-turbo":     4096,
+	"gpt-35-turbo-16k": 16384,
+	"gpt-4":            8192,
+	"gpt-4-32k":        32768,
+	"gpt-4-turbo":      128000,
+	"gpt-4o":           128000,
+	"gpt-4.1":          1047576,
+	"gpt-4.5":          128000,
+	"gpt-5":            400000,
+	"o1":               200000,
+	"o1-mini":          128000,
+	"o1-preview":       128000,
+	"o3":               200000,
+	"o4-mini":          200000,
+}
+
+// ParseMemoryStrategy parses the name of a chat memory strategy.
+func ParseMemoryStrategy(value string) (MemoryStrategy, error) {
+	strategy := MemoryStrategy(strings.ToLower(value))
+	if !slices.Contains([]MemoryStrategy{WindowMemory, SummaryMemory, VectorMemory}, strategy) {
+		return "", fmt.Errorf(
+			"%w '%s', supported strategies are %s, %s, %s",
+			ErrUnsupportedMemoryStrategy,
+			value,
+			WindowMemory,
+			SummaryMemory,
+			VectorMemory,
+		)
+	}
+
+	return strategy, nil
+}
+
+// ContextWindow returns the context window of the model deployment in tokens.
+// The window reported by the deployment capabilities is used when available, otherwise it is looked up by model.
+// Returns false when the context window is unknown and the default was used.
+func ContextWindow(deployment *armcognitiveservices.Deployment) (int, bool) {
+	if deployment == nil || deployment.Properties == nil {
+		return defaultContextWindow, false
+	}
+
+	if value, has := deployment.Properties.Capabilities["maxContextToken"]; has && value != nil {
+		if contextWindow, err := strconv.Atoi(*value); err == nil && contextWindow > 0 {
+			return contextWindow, true
+		}
+	}
+
+	if deployment.Properties.Model == nil || deployment.Properties.Model.Name == nil {
+		return defaultContextWindow, false
+	}
+
+	modelName := strings.ToLower(*deployment.Properties.Model.Name)
+	modelVersion := ""
+	if deployment.Properties.Model.Version != nil {
+		modelVersion = strings.ToLower(*deployment.Properties.Model.Version)
+	}
+
+	// GPT-3.5 Turbo and GPT-4 Turbo were released as versions of the base models
+	switch {
+	case modelName == "gpt-35-turbo" && (modelVersion == "1106" || modelVersion == "0125"):
+		return 16385, true
+	case modelName == "gpt-4" && (strings.Contains(modelVersion, "preview") || strings.HasPrefix(modelVersion, "turbo")):
+		return 128000, true
+	}
+
+	return lookupContextWindow(modelName)
+}
+
+func lookupContextWindow(modelName string) (int, bool) {
+	if contextWindow, has := contextWindows[modelName]; has {
+		return contextWindow, true
+	}
+
+	bestPrefix := ""
+	for prefix := range contextWindows {
+		if strings.HasPrefix(modelName, prefix+"-") && len(prefix) > len(bestPrefix) {
+			bestPrefix = prefix
+		}
+	}
+
+	if bestPrefix != "" {
+		return contextWindows[bestPrefix], true
+	}
+
+	return defaultContextWindow, false
+}
+
+// HistoryBudget returns the tokens available for the chat history after reserving room for the system message,
+// the new user message and the response. 10% of the context window is kept as a margin since image tokens are estimated.
+func HistoryBudget(tokenizer *Tokenizer, contextWindow int, maxResponseTokens int, systemMessage string, userTokens int) int {
+	margin := contextWindow / 10
+	budget := contextWindow - margin - maxResponseTokens - userTokens - tokenizer.CountTokens(systemMessage) - messageOverheadTokens*2
+
+	return max(budget, 0)
+}
+
+// ChatTurn is a user message and the response of the model.
+type ChatTurn struct {
+	// UserContent is the content sent to the model including any image attachments.
+	UserContent *azopenai.ChatRequestUserMessageContent
+	// UserText is the text of the user message with images replaced by a placeholder.
+	UserText  string
+	Assistant string
+	Tokens    int
+}
+
+// NewChatTurn creates a turn and counts its size in tokens, images are estimated.
+func NewChatTurn(
+	tokenizer *Tokenizer,
+	userContent *azopenai.ChatRequestUserMessageContent,
+	userText string,
+	images int,
+	assistant string,
+) *ChatTurn {
+	return &ChatTurn{
+		UserContent: userContent,
+		UserText:    userText,
+		Assistant:   assistant,
+		Tokens: tokenizer.CountTokens(userText) + images*EstimatedImageTokens +
+			tokenizer.CountTokens(assistant) + messageOverheadTokens*2,
+	}
+}
+
+func (t *ChatTurn) messages() []azopenai.ChatRequestMessageClassification {
+	return []azopenai.ChatRequestMessageClassification{
+		&azopenai.ChatRequestUserMessage{Content: t.UserContent},
+		&azopenai.ChatRequestAssistantMessage{Content: azopenai.NewChatRequestAssistantMessageContent(t.Assistant)},
+	}
+}
+
+func (t *ChatTurn) text() string {
+	return fmt.Sprintf("User: %s\nAI: %s", t.UserText, t.Assistant)
+}
+
+// ChatMemory keeps the chat history sent to the model with each new user message.
+// The system message is not part of the memory and is always sent.
+type ChatMemory interface {
+	// Messages returns the history to send before the user message within the token budget.
+	Messages(ctx context.Context, userMessage string, budget int) ([]azopenai.ChatRequestMessageClassification, error)
+	// Add records a completed turn.
+	Add(ctx context.Context, turn *ChatTurn) error
+}
+
+// ChatMemoryOptions configures the chat memory strategies.
+type ChatMemoryOptions struct {
+	// RecentTurns is the number of turns kept verbatim by the summary and vector strategies.
+	RecentTurns int
+	// RecallTurns is the number of earlier turns recalled by the vector strategy.
+	RecallTurns int
+	// ChatCompletionModel is the deployment used to summarize the history.
+	ChatCompletionModel string
+	// SummaryTemplate is the prompt template used to summarize the history.
+	SummaryTemplate *PromptTemplate
+	// EmbeddingModel is the deployment used to embed the turns for the vector strategy.
+	EmbeddingModel string
+	// Tokenizer counts the tokens of the summary with the encoding of the chat model (default: cl100k_base).
+	Tokenizer *Tokenizer
+}
+
+// NewChatMemory creates the memory for the strategy.
+func NewChatMemory(strategy MemoryStrategy, openAiClient *azopenai.Client, options ChatMemoryOptions) (ChatMemory, error) {
+	if options.RecentTurns <= 0 {
+		options.RecentTurns = DefaultRecentTurns
+	}
+
+	if options.RecallTurns <= 0 {
+		options.RecallTurns = DefaultRecallTurns
+	}
+
+	if options.Tokenizer == nil {
+		options.Tokenizer = NewTokenizer("")
+	}
+
+	switch strategy {
+	case WindowMemory:
+		return &windowMemory{}, nil
+	case SummaryMemory:
+		if options.SummaryTemplate == nil {
+			return nil, errors.New("the summary memory strategy requires a summary prompt template")
+		}
+
+		return &summaryMemory{
+			openAiClient: openAiClient,
+			options:      options,
+		}, nil
+	case VectorMemory:
+		if options.EmbeddingModel == "" {
+			return nil, errors.New("the vector memory strategy requires an embedding model deployment")
+		}
+
+		return &vectorMemory{
+			openAiClient: openAiClient,
+			options:      options,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w '%s'", ErrUnsupportedMemoryStrategy, strategy)
+	}
+}
+
+// fitTurns returns the most recent turns that fit within the budget.
+func fitTurns(turns []*ChatTurn, budget int) []*ChatTurn {
+	used := 0
+	start := len(turns)
+
+	for i := len(turns) - 1; i >= 0; i-- {
+		if used+turns[i].Tokens > budget {
+			break
+		}
+
+		used += turns[i].Tokens
+		start = i
+	}
+
+	return turns[start:]
+}
+
+func turnMessages(turns []*ChatTurn) []azopenai.ChatRequestMessageClassification {
+	messages := []azopenai.ChatRequestMessageClassification{}
+	for _, turn := range turns {
+		messages = append(messages, turn.messages()...)
+	}
+
+	return messages
+}
+
+func sumTokens(turns []*ChatTurn) int {
+	tokens := 0
+	for _, turn := range turns {
+		tokens += turn.Tokens
+	}
+
+	return tokens
+}
+
+// windowMemory keeps the most recent turns that fit within the token budget.
+type windowMemory struct {
+	turns []*ChatTurn
+}
+
+func (m *windowMemory) Messages(ctx context.Context, userMessage string, budget int) ([]azopenai.ChatRequestMessageClassification, error) {
+	turns := fitTurns(m.turns, budget)
+	if dropped := len(m.turns) - len(turns); dropped > 0 {
+		log.Printf("Memory window dropped %d of %d turns\n", dropped, len(m.turns))
+	}
+
+	return turnMessages(turns), nil
+}
+
+func (m *windowMemory) Add(ctx context.Context, turn *ChatTurn) error {
+	m.turns = append(m.turns, turn)
+	return nil
+}
+
+// summaryMemory keeps the most recent turns verbatim and folds older turns into a rolling summary.
+type summaryMemory struct {
+	openAiClient *azopenai.Client
+	options      ChatMemoryOptions
+
+	summary string
+	turns   []*ChatTurn
+}
+
+func (m *summaryMemory) Messages(ctx context.Context, userMessage string, budget int) ([]azopenai.ChatRequestMessageClassification, error) {
+	// Fold the turns beyond the recent turns and any recent turns that no longer fit into the summary
+	keep := min(len(m.turns), m.options.RecentTurns)
+	for keep > 0 && sumTokens(m.turns[len(m.turns)-keep:])+m.options.Tokenizer.CountTokens(m.summary)+maxSummaryTokens > budget {
+		keep--
+	}
+
+	if evicted := m.turns[:len(m.turns)-keep]; len(evicted) > 0 {
+		if err := m.summarize(ctx, evicted); err != nil {
+			return nil, err
+		}
+
+		m.turns = slices.Clone(m.turns[len(m.turns)-keep:])
+	}
+
+	messages := []azopenai.ChatRequestMessageClassification{}
+	summaryTokens := 0
+
+	if m.summary != "" {
+		summaryTokens = m.options.Tokenizer.CountTokens(m.summary) + messageOverheadTokens
+		messages = append(messages, &azopenai.ChatRequestSystemMessage{
+			Content: azopenai.NewChatRequestSystemMessageContent(
+				fmt.Sprintf("Summary of the earlier conversation:\n%s", m.summary),
+			),
+		})
+	}
+
+	return append(messages, turnMessages(fitTurns(m.turns, budget-summaryTokens))...), nil
+}
+
+func (m *summaryMemory) Add(ctx context.Context, turn *ChatTurn) error {
+	m.turns = append(m.turns, turn)
+	return nil
+}
+
+// summarize updates the rolling summary with the evicted turns.
+func (m *summaryMemory) summarize(ctx context.Context, evicted []*ChatTurn) error {
+	content := []string{}
+	if m.summary != "" {
+		content = append(content, fmt.Sprintf("Summary of the conversation so far:\n%s\n", m.summary))
+	}
+
+	for _, turn := range evicted {
+		content = append(content, turn.text())
+	}
+
+	summaryMessages, err := m.options.SummaryTemplate.Render(map[string]string{
+		PromptVariableContent: strings.Join(content, "\n"),
+	})
+	if err != nil {
+		return err
+	}
+
+	response, err := m.openAiClient.GetChatCompletions(ctx, azopenai.ChatCompletionsOptions{
+		Messages:       ChatRequestMessages(summaryMessages),
+		DeploymentName: &m.options.ChatCompletionModel,
+		MaxTokens:      to.Ptr(int32(maxSummaryTokens)),
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(response.Choices) == 0 || response.Choices[0].Message.Content == nil {
+		return errors.New("no summary generated")
+	}
+
+	m.summary = *response.Choices[0].Message.Content
+	log.Printf("Summarized %d turns: %s\n", len(evicted), m.summary)
+
+	return nil
+}
+
+// vectorMemory keeps the most recent turns verbatim and recalls the earlier turns most similar to the new message.
+type vectorMemory struct {
+	openAiClient *azopenai.Client
+	options      ChatMemoryOptions
+
+	turns   []*ChatTurn
+	vectors [][]float32
+}
+
+func (m *vectorMemory) Messages(ctx context.Context, userMessage string, budget int) ([]azopenai.ChatRequestMessageClassification, error) {
+	recentStart := max(len(m.turns)-m.options.RecentTurns, 0)
+	recentTurns := fitTurns(m.turns[recentStart:], budget)
+	earlierTurns := m.turns[:len(m.turns)-len(recentTurns)]
+	budget -= sumTokens(recentTurns)
+
+	messages := []azopenai.ChatRequestMessageClassification{}
+
+	if len(earlierTurns) > 0 && budget > 0 {
+		queryVector, err := m.embed(ctx, userMessage)
+		if err != nil {
+			return nil, err
+		}
+
+		recalled := m.recall(queryVector, len(earlierTurns), budget)
+		if len(recalled) > 0 {
+			recalledText := make([]string, len(recalled))
+			for i, turn := range recalled {
+				recalledText[i] = turn.text()
+			}
+
+			messages = append(messages, &azopenai.ChatRequestSystemMessage{
+				Content: azopenai.NewChatRequestSystemMessageContent(
+					fmt.Sprintf("Relevant messages from earlier in the conversation:\n\n%s", strings.Join(recalledText, "\n\n")),
+				),
+			})
+		}
+	}
+
+	return append(messages, turnMessages(recentTurns)...), nil
+}
+
+// recall returns the earlier turns most similar to the query in chronological order.
+func (m *vectorMemory) recall(queryVector []float32, earlierCount int, budget int) []*ChatTurn {
+	indexes := make([]int, earlierCount)
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	scores := make([]float32, earlierCount)
+	for i := range indexes {
+		scores[i] = cosineSimilarity(queryVector, m.vectors[i])
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return scores[indexes[i]] > scores[indexes[j]]
+	})
+
+	selected := []int{}
+	for _, index := range indexes {
+		if len(selected) == m.options.RecallTurns {
+			break
+		}
+
+		if m.turns[index].Tokens > budget {
+			continue
+		}
+
+		budget -= m.turns[index].Tokens
+		selected = append(selected, index)
+		log.Printf("Recalled turn %d with similarity %f\n", index, scores[index])
+	}
+
+	slices.Sort(selected)
+
+	recalled := make([]*ChatTurn, len(selected))
+	for i, index := range selected {
+		recalled[i] = m.turns[index]
+	}
+
+	return recalled
+}
+
+func (m *vectorMemory) Add(ctx context.Context, turn *ChatTurn) error {
+	vector, err := m.embed(ctx, turn.text())
+	if err != nil {
+		return err
+	}
+
+	m.turns = append(m.turns, turn)
+	m.vectors = append(m.vectors, vector)
+
+	return nil
+}
+
+func (m *vectorMemory) embed(ctx context.Context, text string) ([]float32, error) {
+	response, err := m.openAiClient.GetEmbeddings(ctx, azopenai.EmbeddingsOptions{
+		Input:          []string{text},
+		DeploymentName: &m.options.EmbeddingModel,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Data[0].Embedding, nil
+}
@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ContextWindow(t *testing.T) {
+	newDeployment := func(name string, version string, capabilities map[string]*string) *armcognitiveservices.Deployment {
+		return &armcognitiveservices.Deployment{
+			Properties: &armcognitiveservices.DeploymentProperties{
+				Model:        &armcognitiveservices.DeploymentModel{Name: to.Ptr(name), Version: to.Ptr(version)},
+				Capabilities: capabilities,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		deployment *armcognitiveservices.Deployment
+		expected   int
+		known      bool
+	}{
+		{"Capability", newDeployment("gpt-4o", "2024-08-06", map[string]*string{"maxContextToken": to.Ptr("64000")}), 64000, true},
+		{"Exact", newDeployment("gpt-4", "0613", nil), 8192, true},
+		{"Prefix", newDeployment("gpt-4o-mini", "2024-07-18", nil), 128000, true},
+		{"LongestPrefix", newDeployment("gpt-4.1-mini", "2025-04-14", nil), 1047576, true},
+		{"Version", newDeployment("gpt-35-turbo", "0125", nil), 16385, true},
+		{"Unknown", newDeployment("phi-3", "1", nil), defaultContextWindow, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contextWindow, known := ContextWindow(test.deployment)
+			require.Equal(t, test.expected, contextWindow)
+			require.Equal(t, test.known, known)
+		})
+	}
+}
+
+func Test_ChatMemory(t *testing.T) {
+	tokenizer := NewTokenizer("gpt-4o")
+
+	newTurn := func(user string, assistant string) *ChatTurn {
+		return NewChatTurn(tokenizer, azopenai.NewChatRequestUserMessageContent(user), user, 0, assistant)
+	}
+
+	t.Run("Window", func(t *testing.T) {
+		memory, err := NewChatMemory(WindowMemory, nil, ChatMemoryOptions{})
+		require.NoError(t, err)
+
+		turns := []*ChatTurn{
+			newTurn("What is azd?", "The Azure Developer CLI."),
+			newTurn("How do I deploy?", "Run azd up."),
+			newTurn("And tear down?", "Run azd down."),
+		}
+
+		for _, turn := range turns {
+			require.NoError(t, memory.Add(context.Background(), turn))
+		}
+
+		messages, err := memory.Messages(context.Background(), "Thanks", turns[1].Tokens+turns[2].Tokens)
+		require.NoError(t, err)
+		require.Len(t, messages, 4)
+
+		messages, err = memory.Messages(context.Background(), "Thanks", 0)
+		require.NoError(t, err)
+		require.Empty(t, messages)
+	})
+
+	t.Run("VectorRecall", func(t *testing.T) {
+		memory := &vectorMemory{
+			options: ChatMemoryOptions{RecallTurns: 2},
+			turns: []*ChatTurn{
+				newTurn("What regions support gpt-4o?", "East US and Sweden Central."),
+				newTurn("How much does storage cost?", "It depends on the tier."),
+				newTurn("Which quota applies to gpt-4o?", "Tokens per minute."),
+			},
+			vectors: [][]float32{{1, 0}, {0, 1}, {0.9, 0.1}},
+		}
+
+		recalled := memory.recall([]float32{1, 0}, 3, 1000)
+		require.Len(t, recalled, 2)
+		require.Equal(t, memory.turns[0], recalled[0])
+		require.Equal(t, memory.turns[2], recalled[1])
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		require.Equal(t, 0, HistoryBudget(tokenizer, 1000, 800, "system", 500))
+		require.Greater(t, HistoryBudget(tokenizer, 128000, 800, "You are a helpful assistant.", 20), 100000)
+	})
+
+	t.Run("ParseStrategy", func(t *testing.T) {
+		strategy, err := ParseMemoryStrategy("Vector")
+		require.NoError(t, err)
+		require.Equal(t, VectorMemory, strategy)
+
+		_, err = ParseMemoryStrategy("forget")
+		require.ErrorIs(t, err, ErrUnsupportedMemoryStrategy)
+	})
+}
@@ -1,11 +1,10 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
@@ -20,22 +19,25 @@ import (
 	"github.com/wbreza/azd-extensions/sdk/ext"
 	"github.com/wbreza/azd-extensions/sdk/ext/output"
 	"github.com/wbreza/azd-extensions/sdk/ux"
-	"github.com/wbreza/azure-sdk-for-go/sdk/data/azsearchindex"
 )
 
 type chatUsageFlags struct {
 	message       string
 	systemMessage string
+	prompt        string
 	modelName     string
 	temperature   float32
 	maxTokens     int32
 	useSearch     bool
+	attach        []string
+	retrieval     retrievalFlags
+	memory        string
+	recentTurns   int
 }
 
 var (
-	defaultSystemMessage = "You are an AI assistant that helps people find information."
-	defaultTemperature   = float32(0.7)
-	defaultMaxTokens     = int32(800)
+	defaultTemperature = float32(0.7)
+	defaultMaxTokens   = int32(800)
 )
 
 func newChatCommand() *cobra.Command {
@@ -44,6 +46,9 @@ func newChatCommand() *cobra.Command {
 	chatCmd := &cobra.Command{
 		Use:   "chat",
 		Short: "Commands for managing chat",
+		Annotations: map[string]string{
+			noResultsAnnotation: "true",
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			header := output.CommandHeader{
 				Title:       "Chat with AI Model (azd ai chat)",
@@ -52,6 +57,24 @@ func newChatCommand() *cobra.Command {
 			header.Print()
 
 			ctx := cmd.Context()
+
+			if len(flags.attach) > 0 && flags.message == "" {
+				return &ext.ErrorWithSuggestion{
+					Err:        errors.New("attachments require a message"),
+					Suggestion: fmt.Sprintf("Provide a message with %s or use %s within the chat", color.CyanString("--message"), color.CyanString("/attach <file>")),
+				}
+			}
+
+			attachments := []*chatAttachment{}
+			for _, path := range flags.attach {
+				attachment, err := loadChatAttachment(ctx, path)
+				if err != nil {
+					return err
+				}
+
+				attachments = append(attachments, attachment)
+			}
+
 			azdContext, err := ext.CurrentContext(ctx)
 			if err != nil {
 				return err
@@ -96,7 +119,7 @@ func newChatCommand() *cobra.Command {
 			}
 
 			if extensionConfig.Ai.Models.ChatCompletion == "" {
-				color.Yellow("No chat completion model was found. Please select or create a chat completion model.")
+				fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("No chat completion model was found. Please select or create a chat completion model."))
 
 				chatDeployment, err := internal.PromptModelDeployment(ctx, azdContext, azureContext, &internal.PromptModelDeploymentOptions{
 					Capabilities: []string{
@@ -114,7 +137,7 @@ func newChatCommand() *cobra.Command {
 				}
 
 				extensionConfig.Ai.Models.ChatCompletion = *chatDeployment.Name
-				fmt.Println()
+				fmt.Fprintln(output.MessageWriter(ctx))
 			}
 
 			if flags.useSearch {
@@ -155,6 +178,29 @@ func newChatCommand() *cobra.Command {
 				return err
 			}
 
+			promptTemplates, err := internal.NewProjectPromptTemplateStore(azdContext)
+			if err != nil {
+				return err
+			}
+
+			chatTemplate, err := promptTemplates.Resolve(internal.ChatPromptTemplate, flags.prompt, extensionConfig.Prompts.Chat)
+			if err != nil {
+				return err
+			}
+
+			summaryTemplate, err := promptTemplates.Resolve(internal.ChatSummaryPromptTemplate, extensionConfig.Prompts.ChatSummary)
+			if err != nil {
+				return err
+			}
+
+			systemMessage := flags.systemMessage
+			if systemMessage == "" {
+				systemMessage, err = chatTemplate.SystemMessage()
+				if err != nil {
+					return err
+				}
+			}
+
 			loadingSpinner := ux.NewSpinner(&ux.SpinnerOptions{
 				Text:        "Starting chat...",
 				ClearOnStop: true,
@@ -177,34 +223,83 @@ func newChatCommand() *cobra.Command {
 				return err
 			}
 
+			if err := validateChatAttachments(&deployment.Deployment, attachments); err != nil {
+				loadingSpinner.Stop(ctx)
+				return err
+			}
+
 			hasVectorSearch := extensionConfig.Search.Service != "" && extensionConfig.Search.Index != "" && extensionConfig.Ai.Models.Embeddings != ""
 
+			var retriever *internal.Retriever
+			if hasVectorSearch {
+				retriever, err = newSearchRetriever(cmd, &flags.retrieval, azdContext, extensionConfig, openAiClient, credential, azClientOptions)
+				if err != nil {
+					return err
+				}
+			}
+
+			ragFlow := internal.NewRagFlow(openAiClient, chatTemplate, extensionConfig.Ai.Models.Embeddings, retriever)
+
+			memoryStrategy, err := internal.ParseMemoryStrategy(
+				cmp.Or(flags.memory, extensionConfig.Memory.Strategy, string(internal.SummaryMemory)),
+			)
+			if err != nil {
+				loadingSpinner.Stop(ctx)
+				return err
+			}
+
+			if memoryStrategy == internal.VectorMemory && extensionConfig.Ai.Models.Embeddings == "" {
+				loadingSpinner.Stop(ctx)
+				return &ext.ErrorWithSuggestion{
+					Err:        errors.New("the vector memory strategy requires an embedding model deployment"),
+					Suggestion: fmt.Sprintf("Run %s to select an embedding model", color.CyanString("azd ai chat --use-search")),
+				}
+			}
+
+			tokenizer := internal.NewTokenizer(*deployment.Properties.Model.Name)
+
+			memory, err := internal.NewChatMemory(memoryStrategy, openAiClient, internal.ChatMemoryOptions{
+				RecentTurns:         cmp.Or(flags.recentTurns, extensionConfig.Memory.RecentTurns),
+				RecallTurns:         extensionConfig.Memory.RecallTurns,
+				ChatCompletionModel: extensionConfig.Ai.Models.ChatCompletion,
+				SummaryTemplate:     summaryTemplate,
+				EmbeddingModel:      extensionConfig.Ai.Models.Embeddings,
+				Tokenizer:           tokenizer,
+			})
+			if err != nil {
+				loadingSpinner.Stop(ctx)
+				return err
+			}
+
+			contextWindow, knownContextWindow := internal.ContextWindow(&deployment.Deployment)
+
 			loadingSpinner.Stop(ctx)
 
-			fmt.Printf("AI Service: %s %s\n", color.CyanString(extensionConfig.Ai.Service), color.HiBlackString("(%s)", extensionConfig.ResourceGroup))
-			fmt.Printf("Chat Model: %s %s\n", color.CyanString(extensionConfig.Ai.Models.ChatCompletion), color.HiBlackString("(Model: %s, Version: %s)", *deployment.Properties.Model.Name, *deployment.Properties.Model.Version))
-			fmt.Println()
+			fmt.Fprintf(output.MessageWriter(ctx), "AI Service: %s %s\n", color.CyanString(extensionConfig.Ai.Service), color.HiBlackString("(%s)", extensionConfig.ResourceGroup))
+			fmt.Fprintf(output.MessageWriter(ctx), "Chat Model: %s %s\n", color.CyanString(extensionConfig.Ai.Models.ChatCompletion), color.HiBlackString("(Model: %s, Version: %s)", *deployment.Properties.Model.Name, *deployment.Properties.Model.Version))
+			fmt.Fprintln(output.MessageWriter(ctx))
 			if hasVectorSearch {
-				fmt.Printf("Search Service: %s %s\n", color.CyanString(extensionConfig.Search.Service), color.HiBlackString("(%s)", extensionConfig.Search.Index))
-				fmt.Printf("Search Index: %s\n", color.CyanString(extensionConfig.Search.Index))
-				fmt.Printf("Embeddings Model: %s\n", color.CyanString(extensionConfig.Ai.Models.Embeddings))
-				fmt.Println()
+				fmt.Fprintf(output.MessageWriter(ctx), "Search Service: %s %s\n", color.CyanString(extensionConfig.Search.Service), color.HiBlackString("(%s)", extensionConfig.Search.Index))
+				fmt.Fprintf(output.MessageWriter(ctx), "Search Index: %s\n", color.CyanString(extensionConfig.Search.Index))
+				fmt.Fprintf(output.MessageWriter(ctx), "Embeddings Model: %s\n", color.CyanString(extensionConfig.Ai.Models.Embeddings))
+				fmt.Fprintf(output.MessageWriter(ctx), "Retrieval: %s\n", color.CyanString(retriever.Options().String()))
+				fmt.Fprintln(output.MessageWriter(ctx))
 			}
-			fmt.Printf("System Message: %s\n", color.CyanString(flags.systemMessage))
-			fmt.Printf("Temperature: %s %s\n", color.CyanString(fmt.Sprint(flags.temperature)), color.HiBlackString("(Controls randomness)"))
-			fmt.Printf("Max Tokens: %s %s\n", color.CyanString(fmt.Sprint(flags.maxTokens)), color.HiBlackString("(Maximum number of tokens to generate)"))
-			fmt.Println()
-
-			messages := []azopenai.ChatRequestMessageClassification{}
-			messages = append(messages, &azopenai.ChatRequestSystemMessage{
-				Content: azopenai.NewChatRequestSystemMessageContent(flags.systemMessage),
-			})
+			fmt.Fprintf(output.MessageWriter(ctx), "Prompt Template: %s %s\n", color.CyanString(chatTemplate.Name), color.HiBlackString("(Version: %s)", chatTemplate.Version))
+			fmt.Fprintf(output.MessageWriter(ctx), "System Message: %s\n", color.CyanString(systemMessage))
+			fmt.Fprintf(output.MessageWriter(ctx), "Temperature: %s %s\n", color.CyanString(fmt.Sprint(flags.temperature)), color.HiBlackString("(Controls randomness)"))
+			fmt.Fprintf(output.MessageWriter(ctx), "Max Tokens: %s %s\n", color.CyanString(fmt.Sprint(flags.maxTokens)), color.HiBlackString("(Maximum number of tokens to generate)"))
+			if knownContextWindow {
+				fmt.Fprintf(output.MessageWriter(ctx), "Context Window: %s %s\n", color.CyanString(fmt.Sprint(contextWindow)), color.HiBlackString("(Memory: %s)", memoryStrategy))
+			} else {
+				fmt.Fprintf(output.MessageWriter(ctx), "Context Window: %s %s\n", color.CyanString(fmt.Sprint(contextWindow)), color.HiBlackString("(Unknown model, Memory: %s)", memoryStrategy))
+			}
+			fmt.Fprintln(output.MessageWriter(ctx))
 
 			thinkingSpinner := ux.NewSpinner(&ux.SpinnerOptions{
 				Text: "Thinking...",
 			})
 
-			totalTokenCount := 0
 			userMessage := flags.message
 
 			for {
@@ -213,7 +308,7 @@ func newChatCommand() *cobra.Command {
 				if userMessage == "" {
 					chatPrompt := ux.NewPrompt(&ux.PromptOptions{
 						Message:           "User",
-						PlaceHolder:       "Press `Ctrl+X` to cancel",
+						PlaceHolder:       "Type `/attach <file>` to attach a file, press `Ctrl+X` to cancel",
 						Required:          true,
 						RequiredMessage:   "Please enter a message",
 						ClearOnCompletion: true,
@@ -228,71 +323,75 @@ func newChatCommand() *cobra.Command {
 
 						return err
 					}
-				}
 
-				fmt.Printf("%s: %s\n", color.GreenString("User"), userMessage)
-				fmt.Println()
+					if userMessage == "/attach" || strings.HasPrefix(userMessage, "/attach ") {
+						attachPath := strings.Trim(strings.TrimSpace(strings.TrimPrefix(userMessage, "/attach")), `"'`)
+						userMessage = ""
 
-				if hasVectorSearch {
-					embeddingsResponse, err := openAiClient.GetEmbeddings(ctx, azopenai.EmbeddingsOptions{
-						Input:          []string{userMessage},
-						DeploymentName: &extensionConfig.Ai.Models.Embeddings,
-					}, nil)
-					if err != nil {
-						return err
-					}
+						if attachPath == "" {
+							fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("Usage: /attach <file>"))
+							fmt.Fprintln(output.MessageWriter(ctx))
+							continue
+						}
 
-					searchClient, err := azsearchindex.NewDocumentsClient(extensionConfig.Search.Endpoint, extensionConfig.Search.Index, credential, azClientOptions)
-					if err != nil {
-						return err
-					}
+						attachment, err := loadChatAttachment(ctx, attachPath)
+						if err == nil {
+							err = validateChatAttachments(&deployment.Deployment, []*chatAttachment{attachment})
+						}
 
-					searchResponse, err := searchClient.SearchPost(ctx, azsearchindex.SearchRequest{
-						Select:    to.Ptr("id, summary, content, path"),
-						QueryType: to.Ptr(azsearchindex.QueryTypeSimple),
-						VectorQueries: []azsearchindex.VectorQueryClassification{
-							&azsearchindex.VectorizedQuery{
-								Kind:       to.Ptr(azsearchindex.VectorQueryKindVector),
-								Fields:     to.Ptr("vector"),
-								Exhaustive: to.Ptr(true),
-								K:          to.Ptr(int32(3)),
-								Vector:     internal.ConvertToFloatPtrSlice(embeddingsResponse.Data[0].Embedding),
-							},
-						},
-					}, nil, nil)
-					if err != nil {
-						return nil
+						if err != nil {
+							fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("WARNING: %s", err.Error()))
+							fmt.Fprintln(output.MessageWriter(ctx))
+							continue
+						}
+
+						printChatAttachment(output.MessageWriter(ctx), attachment)
+						fmt.Fprintln(output.MessageWriter(ctx))
+
+						attachments = append(attachments, attachment)
+						continue
 					}
+				}
 
-					if len(searchResponse.Results) > 0 {
-						contextResults := make([]string, len(searchResponse.Results))
+				fmt.Fprintf(output.MessageWriter(ctx), "%s: %s\n", color.GreenString("User"), userMessage)
+				for _, attachment := range attachments {
+					printChatAttachment(output.MessageWriter(ctx), attachment)
+				}
+				fmt.Fprintln(output.MessageWriter(ctx))
 
-						for i, result := range searchResponse.Results {
-							contextResults[i] = fmt.Sprintf("- [%d] %s", i+1, fmt.Sprint(result.AdditionalProperties["summary"]))
-							log.Printf("Title: %s, Score: %f\n", result.AdditionalProperties["path"], *result.Score)
-						}
+				question := userMessage
 
-						userMessage = fmt.Sprintf("Question: \"%s\"\n\nContext:\n%s", userMessage, strings.Join(contextResults, "\n\n"))
-					}
+				userMessage, err = ragFlow.UserMessage(ctx, question)
+				if err != nil {
+					return err
 				}
 
-				if totalTokenCount > 1500 {
-					summarizedMessage, err := summarizeMessages(ctx, openAiClient, messages, extensionConfig)
-					if err != nil {
-						return err
-					}
+				userContent := newUserMessageContent(userMessage, attachments)
+				userText := userMessageText(userMessage, attachments)
+				imageCount := countImageAttachments(attachments)
 
-					messages = []azopenai.ChatRequestMessageClassification{&azopenai.ChatRequestUserMessage{
-						Content: azopenai.NewChatRequestUserMessageContent(summarizedMessage),
-					}}
+				budget := internal.HistoryBudget(
+					tokenizer,
+					contextWindow,
+					int(flags.maxTokens),
+					systemMessage,
+					tokenizer.CountTokens(userText)+imageCount*internal.EstimatedImageTokens,
+				)
 
-					log.Printf("Summarized message: %s\n", summarizedMessage)
+				history, err := memory.Messages(ctx, question, budget)
+				if err != nil {
+					return err
 				}
 
+				messages := []azopenai.ChatRequestMessageClassification{
+					&azopenai.ChatRequestSystemMessage{
+						Content: azopenai.NewChatRequestSystemMessageContent(systemMessage),
+					},
+				}
+				messages = append(messages, history...)
+				messages = append(messages, &azopenai.ChatRequestUserMessage{Content: userContent})
+
 				var chatResponse *azopenai.ChatCompletions
-				messages = append(messages, &azopenai.ChatRequestUserMessage{
-					Content: azopenai.NewChatRequestUserMessageContent(userMessage),
-				})
 
 				err = thinkingSpinner.Run(ctx, func(ctx context.Context) error {
 					response, err := openAiClient.GetChatCompletions(ctx, azopenai.ChatCompletionsOptions{
@@ -354,31 +453,44 @@ func newChatCommand() *cobra.Command {
 				for _, choice := range chatResponse.Choices {
 					if choice.Message != nil && choice.Message.Content != nil {
 						assistantMessage = *choice.Message.Content
-						fmt.Printf("%s: %s\n", color.CyanString("AI"), assistantMessage)
+						fmt.Fprintf(output.MessageWriter(ctx), "%s: %s\n", color.CyanString("AI"), assistantMessage)
 					}
 				}
 
-				messages = append(messages, &azopenai.ChatRequestAssistantMessage{
-					Content: azopenai.NewChatRequestAssistantMessageContent(assistantMessage),
-				})
+				// The retrieved search context isn't kept in memory, the response already reflects it
+				turn := internal.NewChatTurn(
+					tokenizer,
+					newUserMessageContent(question, attachments),
+					userMessageText(question, attachments),
+					imageCount,
+					assistantMessage,
+				)
+				if err := memory.Add(ctx, turn); err != nil {
+					return err
+				}
 
-				color.HiBlack("(Usage: Completion: %d, Prompt: %d, Total: %d)\n", *chatResponse.Usage.CompletionTokens, *chatResponse.Usage.PromptTokens, *chatResponse.Usage.TotalTokens)
-				fmt.Println()
+				fmt.Fprintln(output.MessageWriter(ctx), color.HiBlackString("(Usage: Completion: %d, Prompt: %d, Total: %d)\n", *chatResponse.Usage.CompletionTokens, *chatResponse.Usage.PromptTokens, *chatResponse.Usage.TotalTokens))
+				fmt.Fprintln(output.MessageWriter(ctx))
 
-				totalTokenCount += ((len(userMessage) / 4) + (len(assistantMessage) / 4))
 				userMessage = ""
+				attachments = []*chatAttachment{}
 			}
 
 			return nil
 		},
 	}
 
-	chatCmd.Flags().StringVar(&flags.systemMessage, "system-message", defaultSystemMessage, "System message to send to the AI model")
+	chatCmd.Flags().StringVar(&flags.systemMessage, "system-message", "", "System message to send to the AI model (default: system message of the prompt template)")
+	chatCmd.Flags().StringVar(&flags.prompt, "prompt", "", "Name of the prompt template in the prompts folder, optionally pinned with <name>@<version>")
 	chatCmd.Flags().Float32Var(&flags.temperature, "temperature", defaultTemperature, "Temperature for sampling")
 	chatCmd.Flags().Int32Var(&flags.maxTokens, "max-tokens", defaultMaxTokens, "Maximum number of tokens to generate")
 	chatCmd.Flags().StringVarP(&flags.message, "message", "m", "", "Message to send to the AI model")
 	chatCmd.Flags().StringVarP(&flags.modelName, "model deployment name", "d", "", "Name of the model to use")
+	chatCmd.Flags().StringArrayVar(&flags.attach, "attach", nil, "File to attach to the message, images require a vision capable model (can be repeated)")
 	chatCmd.Flags().BoolVar(&flags.useSearch, "use-search", false, "Use Azure Cognitive Search for search results")
+	addRetrievalFlags(chatCmd, &flags.retrieval)
+	chatCmd.Flags().StringVar(&flags.memory, "memory", "", "Strategy to keep the history within the context window: window, summary or vector (default: summary)")
+	chatCmd.Flags().IntVar(&flags.recentTurns, "recent-turns", 0, "Number of recent turns kept verbatim by the summary and vector memory strategies (default: 4)")
 
 	return chatCmd
 }
@@ -386,51 +498,3 @@ func newChatCommand() *cobra.Command {
 func getDateTime() string {
 	return time.Now().Format(time.RFC1123)
 }
-
-func summarizeMessages(ctx context.Context, openAiClient *azopenai.Client, messages []azopenai.ChatRequestMessageClassification, aiConfig *internal.ExtensionConfig) (string, error) {
-	// Concatenate message content to form a single text
-	var content []string
-	for _, msg := range messages {
-		switch m := msg.(type) {
-
-		case *azopenai.ChatRequestUserMessage:
-			contentBytes, err := json.Marshal(m.Content)
-			if err != nil {
-				continue
-			}
-
-			content = append(content, fmt.Sprintf("User: %s", string(contentBytes)))
-		case *azopenai.ChatRequestAssistantMessage:
-			contentBytes, err := json.Marshal(m.Content)
-			if err != nil {
-				continue
-			}
-
-			content = append(content, fmt.Sprintf("AI: %s", string(contentBytes)))
-		}
-	}
-
-	// Create a summarization prompt
-	summarizationPrompt := fmt.Sprintf("Summarize the following conversation:\n\n%s", strings.Join(content, "\n"))
-	log.Printf("Summarization prompt: %s\n", summarizationPrompt)
-
-	// Send to OpenAI for summarization
-	response, err := openAiClient.GetChatCompletions(ctx, azopenai.ChatCompletionsOptions{
-		Messages: []azopenai.ChatRequestMessageClassification{
-			&azopenai.ChatRequestUserMessage{
-				Content: azopenai.NewChatRequestUserMessageContent(summarizationPrompt),
-			},
-		},
-		DeploymentName: &aiConfig.Ai.Models.ChatCompletion,
-		MaxTokens:      to.Ptr(int32(500)), // Adjust token limit for summary response
-	}, nil)
-	if err != nil {
-		return "", err
-	}
-
-	// Extract summarized content
-	if len(response.Choices) > 0 {
-		return *response.Choices[0].Message.Content, nil
-	}
-	return "", errors.New("no summary generated")
-}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+	"github.com/fatih/color"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal/docprep"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+)
+
+const (
+	// maxTextAttachmentSize is the largest amount of text inlined into a chat message for a single attachment.
+	maxTextAttachmentSize = 100 * 1024
+	// maxImageAttachmentSize is the largest image accepted by the chat completions API.
+	maxImageAttachmentSize = 20 * 1024 * 1024
+	// maxPdfAttachmentSize is the largest PDF document converted to text.
+	maxPdfAttachmentSize = 20 * 1024 * 1024
+)
+
+var (
+	ErrVisionNotSupported = errors.New("model deployment does not support image inputs")
+
+	imageMediaTypes = map[string]string{
+		".png":  "image/png",
+		".jpg":  "image/jpeg",
+		".jpeg": "image/jpeg",
+		".gif":  "image/gif",
+		".webp": "image/webp",
+	}
+
+	// visionModels are the model families that accept image inputs.
+	visionModels = []string{"gpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "o1", "o3", "o4-mini"}
+	// textOnlyModels are the models within the vision model families that only accept text.
+	textOnlyModels = []string{"o1-mini", "o1-preview", "o3-mini"}
+)
+
+// chatAttachment is a file attached to a chat message.
+// Images are sent as image content parts while all other files are inlined as text.
+type chatAttachment struct {
+	Name     string
+	ImageUrl string
+	Text     string
+}
+
+func (a *chatAttachment) IsImage() bool {
+	return a.ImageUrl != ""
+}
+
+// loadChatAttachment reads the file at the path into an attachment.
+// PDF documents are converted to text with the docprep PDF parser.
+func loadChatAttachment(ctx context.Context, path string) (*chatAttachment, error) {
+	document, err := docprep.ParseDocument(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading attachment %s: %w", path, err)
+	}
+
+	extension := strings.ToLower(document.Type)
+
+	if mediaType, has := imageMediaTypes[extension]; has {
+		if document.Size > maxImageAttachmentSize {
+			return nil, fmt.Errorf(
+				"image %s is %d MB, images larger than %d MB are not supported",
+				document.Name,
+				document.Size/1024/1024,
+				maxImageAttachmentSize/1024/1024,
+			)
+		}
+
+		imageBytes, err := os.ReadFile(document.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return &chatAttachment{
+			Name:     document.Name,
+			ImageUrl: fmt.Sprintf("data:%s;base64,%s", mediaType, base64.StdEncoding.EncodeToString(imageBytes)),
+		}, nil
+	}
+
+	if docprep.IsAudioFile(document.Path) {
+		return nil, fmt.Errorf("audio file %s can't be attached to a chat message", document.Name)
+	}
+
+	var text string
+
+	if extension == ".pdf" {
+		if document.Size > maxPdfAttachmentSize {
+			return nil, fmt.Errorf("file %s is larger than the %d MB limit", document.Name, maxPdfAttachmentSize/1024/1024)
+		}
+
+		chunks, err := docprep.NewPdfParser(maxTextAttachmentSize).Parse(ctx, document)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing %s: %w", document.Name, err)
+		}
+
+		contents := []string{}
+		for _, chunk := range chunks {
+			contents = append(contents, chunk.Content)
+		}
+
+		text = strings.TrimSpace(strings.Join(contents, "\n"))
+
+		if text == "" {
+			return nil, fmt.Errorf("no text could be extracted from %s", document.Name)
+		}
+	} else {
+		if document.Size > maxTextAttachmentSize {
+			return nil, fmt.Errorf("file %s is larger than the %d KB limit", document.Name, maxTextAttachmentSize/1024)
+		}
+
+		textBytes, err := os.ReadFile(document.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !utf8.Valid(textBytes) {
+			return nil, fmt.Errorf("file %s is not a text file", document.Name)
+		}
+
+		text = string(textBytes)
+	}
+
+	if len(text) > maxTextAttachmentSize {
+		return nil, fmt.Errorf("text of %s is larger than the %d KB limit", document.Name, maxTextAttachmentSize/1024)
+	}
+
+	return &chatAttachment{
+		Name: document.Name,
+		Text: text,
+	}, nil
+}
+
+// isVisionCapable returns true when the model of the deployment accepts image inputs.
+func isVisionCapable(deployment *armcognitiveservices.Deployment) bool {
+	if deployment.Properties == nil {
+		return false
+	}
+
+	if value, has := deployment.Properties.Capabilities["vision"]; has {
+		return value != nil && strings.EqualFold(*value, "true")
+	}
+
+	if deployment.Properties.Model == nil || deployment.Properties.Model.Name == nil {
+		return false
+	}
+
+	modelName := strings.ToLower(*deployment.Properties.Model.Name)
+	modelVersion := ""
+	if deployment.Properties.Model.Version != nil {
+		modelVersion = strings.ToLower(*deployment.Properties.Model.Version)
+	}
+
+	// GPT-4 Turbo only supports images starting with the 2024-04-09 version & the vision preview
+	if modelName == "gpt-4" || modelName == "gpt-4-turbo" {
+		return modelVersion == "turbo-2024-04-09" || modelVersion == "2024-04-09" || modelVersion == "vision-preview"
+	}
+
+	if slices.Contains(textOnlyModels, modelName) {
+		return false
+	}
+
+	for _, visionModel := range visionModels {
+		if modelName == visionModel || strings.HasPrefix(modelName, visionModel+"-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateChatAttachments ensures the deployment supports all the attachments.
+func validateChatAttachments(deployment *armcognitiveservices.Deployment, attachments []*chatAttachment) error {
+	for _, attachment := range attachments {
+		if !attachment.IsImage() || isVisionCapable(deployment) {
+			continue
+		}
+
+		modelName := ""
+		if deployment.Properties != nil && deployment.Properties.Model != nil && deployment.Properties.Model.Name != nil {
+			modelName = *deployment.Properties.Model.Name
+		}
+
+		return &ext.ErrorWithSuggestion{
+			Err: fmt.Errorf("%w: %s uses model '%s' and can't accept %s", ErrVisionNotSupported, *deployment.Name, modelName, attachment.Name),
+			Suggestion: fmt.Sprintf(
+				"Use a vision capable model such as gpt-4o with %s or run %s",
+				color.CyanString("-d <deployment>"),
+				color.CyanString("azd ai model deployment select"),
+			),
+		}
+	}
+
+	return nil
+}
+
+// newUserMessageContent creates the content of a user message with the attachments.
+// Text attachments are appended to the message and images are added as image content parts.
+func newUserMessageContent(message string, attachments []*chatAttachment) *azopenai.ChatRequestUserMessageContent {
+	if len(attachments) == 0 {
+		return azopenai.NewChatRequestUserMessageContent(message)
+	}
+
+	text := message
+	imageParts := []azopenai.ChatCompletionRequestMessageContentPartClassification{}
+
+	for _, attachment := range attachments {
+		if attachment.IsImage() {
+			imageParts = append(imageParts, &azopenai.ChatCompletionRequestMessageContentPartImage{
+				ImageURL: &azopenai.ChatCompletionRequestMessageContentPartImageURL{
+					URL: &attachment.ImageUrl,
+				},
+			})
+			continue
+		}
+
+		text += fmt.Sprintf("\n\nAttached file: %s\n```\n%s\n```", attachment.Name, attachment.Text)
+	}
+
+	if len(imageParts) == 0 {
+		return azopenai.NewChatRequestUserMessageContent(text)
+	}
+
+	parts := []azopenai.ChatCompletionRequestMessageContentPartClassification{
+		&azopenai.ChatCompletionRequestMessageContentPartText{Text: &text},
+	}
+
+	return azopenai.NewChatRequestUserMessageContent(append(parts, imageParts...))
+}
+
+// userMessageText returns the text of the user message with the attachments and any images replaced by a placeholder.
+func userMessageText(message string, attachments []*chatAttachment) string {
+	text := message
+
+	for _, attachment := range attachments {
+		if attachment.IsImage() {
+			text += fmt.Sprintf(" [image: %s]", attachment.Name)
+			continue
+		}
+
+		text += fmt.Sprintf("\n\nAttached file: %s\n```\n%s\n```", attachment.Name, attachment.Text)
+	}
+
+	return text
+}
+
+// countImageAttachments returns the number of image attachments.
+func countImageAttachments(attachments []*chatAttachment) int {
+	count := 0
+	for _, attachment := range attachments {
+		if attachment.IsImage() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// printChatAttachment prints the name of the attachment.
+func printChatAttachment(writer io.Writer, attachment *chatAttachment) {
+	kind := "file"
+	if attachment.IsImage() {
+		kind = "image"
+	}
+
+	fmt.Fprintf(writer, "%s: %s %s\n", color.GreenString("Attached"), color.CyanString(attachment.Name), color.HiBlackString("(%s)", kind))
+}
@@ -10,9 +10,40 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/wbreza/azd-extensions/extensions/ai/internal"
 	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
 	"github.com/wbreza/azd-extensions/sdk/ux"
 )
 
+type deploymentResult struct {
+	Name    string `json:"name"`
+	Sku     string `json:"sku"`
+	Model   string `json:"model"`
+	Version string `json:"version"`
+}
+
+var deploymentResultTableOptions = &output.TableOptions{
+	Columns: []output.Column{
+		{Heading: "Name", ValueTemplate: "{{.Name}}"},
+		{Heading: "SKU", ValueTemplate: "{{.Sku}}"},
+		{Heading: "Model", ValueTemplate: "{{.Model}}"},
+		{Heading: "Version", ValueTemplate: "{{.Version}}"},
+	},
+}
+
+func newDeploymentResult(deployment *armcognitiveservices.Deployment) *deploymentResult {
+	return &deploymentResult{
+		Name:    *deployment.Name,
+		Sku:     *deployment.SKU.Name,
+		Model:   *deployment.Properties.Model.Name,
+		Version: *deployment.Properties.Model.Version,
+	}
+}
+
+type deploymentSelectResult struct {
+	Service    string `json:"service"`
+	Deployment string `json:"deployment"`
+}
+
 func newDeploymentCommand() *cobra.Command {
 	deploymentCmd := &cobra.Command{
 		Use:   "deployment",
@@ -82,15 +113,16 @@ func newDeploymentCommand() *cobra.Command {
 				deployments = append(deployments, pageResponse.Value...)
 			}
 
-			for _, deployment := range deployments {
-				fmt.Printf("Name: %s\n", *deployment.Name)
-				fmt.Printf("SKU: %s\n", *deployment.SKU.Name)
-				fmt.Printf("Model: %s\n", *deployment.Properties.Model.Name)
-				fmt.Printf("Version: %s\n", *deployment.Properties.Model.Version)
-				fmt.Println()
+			results := make([]*deploymentResult, len(deployments))
+			for i, deployment := range deployments {
+				results[i] = newDeploymentResult(deployment)
+			}
+
+			if output.IsStructured() {
+				return output.Print(ctx, results, deploymentResultTableOptions)
 			}
 
-			return nil
+			return output.WriteTable(output.ResultWriter(ctx), results, deploymentResultTableOptions)
 		},
 	}
 
@@ -139,9 +171,9 @@ func newDeploymentCommand() *cobra.Command {
 				return err
 			}
 
-			color.Green("Deployment '%s' created successfully", *modelDeployment.Name)
+			fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("Deployment '%s' created successfully", *modelDeployment.Name))
 
-			return nil
+			return output.Print(ctx, newDeploymentResult(modelDeployment), deploymentResultTableOptions)
 		},
 	}
 
@@ -218,7 +250,7 @@ func newDeploymentCommand() *cobra.Command {
 				deleteFlags.name = *selectedDeployment.Name
 			}
 
-			_, err = deploymentsClient.Get(ctx, extensionConfig.ResourceGroup, extensionConfig.Ai.Service, deleteFlags.name, nil)
+			deploymentResponse, err := deploymentsClient.Get(ctx, extensionConfig.ResourceGroup, extensionConfig.Ai.Service, deleteFlags.name, nil)
 			if err != nil {
 				return fmt.Errorf("deployment '%s' not found", deleteFlags.name)
 			}
@@ -265,9 +297,9 @@ func newDeploymentCommand() *cobra.Command {
 				return err
 			}
 
-			color.Green("Deployment '%s' deleted successfully", deleteFlags.name)
+			fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("Deployment '%s' deleted successfully", deleteFlags.name))
 
-			return nil
+			return output.Print(ctx, newDeploymentResult(&deploymentResponse.Deployment), deploymentResultTableOptions)
 		},
 	}
 
@@ -341,7 +373,15 @@ func newDeploymentCommand() *cobra.Command {
 				return err
 			}
 
-			return nil
+			return output.Print(ctx, &deploymentSelectResult{
+				Service:    extensionConfig.Ai.Service,
+				Deployment: extensionConfig.Ai.Models.ChatCompletion,
+			}, &output.TableOptions{
+				Columns: []output.Column{
+					{Heading: "AI Service", ValueTemplate: "{{.Service}}"},
+					{Heading: "Chat Model", ValueTemplate: "{{.Deployment}}"},
+				},
+			})
 		},
 	}
 
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
+	"github.com/wbreza/azd-extensions/sdk/ux"
+)
+
+func newDoctorCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnoses the configuration and permissions of the AI resources",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			header := output.CommandHeader{
+				Title:       "Diagnose AI configuration (azd ai doctor)",
+				Description: "Checks the configured AI resources exist, are reachable and that you have the required role assignments.",
+			}
+			header.Print()
+
+			azdContext, err := ext.CurrentContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			doctor, err := internal.NewDoctor(azdContext)
+			if err != nil {
+				return err
+			}
+
+			issues := runDoctorChecks(ctx, doctor.PrerequisiteChecks())
+			if resourceChecks := doctor.ResourceChecks(); len(resourceChecks) > 0 {
+				issues = append(issues, runDoctorChecks(ctx, resourceChecks)...)
+			}
+
+			if output.IsStructured() {
+				if err := printDoctorIssues(ctx, issues); err != nil {
+					return err
+				}
+			}
+
+			if len(issues) == 0 {
+				fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: All checks passed."))
+				return nil
+			}
+
+			fmt.Fprintln(output.MessageWriter(ctx), "Suggested fixes:")
+			fmt.Fprintln(output.MessageWriter(ctx))
+
+			failed := 0
+			for _, issue := range issues {
+				if !issue.Warning {
+					failed++
+				}
+
+				fmt.Fprintf(output.MessageWriter(ctx), "- %s: %s\n", issue.title, issue.Err.Error())
+				if issue.Suggestion == "" {
+					continue
+				}
+
+				for _, suggestion := range strings.Split(issue.Suggestion, "\n") {
+					fmt.Fprintf(output.MessageWriter(ctx), "    %s\n", color.CyanString(suggestion))
+				}
+			}
+
+			fmt.Fprintln(output.MessageWriter(ctx))
+
+			if failed > 0 {
+				return fmt.Errorf("%d of the diagnostic checks failed", failed)
+			}
+
+			return nil
+		},
+	}
+}
+
+type doctorIssue struct {
+	*internal.DoctorIssue
+	title string
+}
+
+type doctorIssueResult struct {
+	Check      string `json:"check"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// printDoctorIssues writes the issues found in the structured output format.
+func printDoctorIssues(ctx context.Context, issues []*doctorIssue) error {
+	results := make([]*doctorIssueResult, len(issues))
+	for i, issue := range issues {
+		severity := "error"
+		if issue.Warning {
+			severity = "warning"
+		}
+
+		results[i] = &doctorIssueResult{
+			Check:      issue.title,
+			Severity:   severity,
+			Message:    issue.Err.Error(),
+			Suggestion: issue.Suggestion,
+		}
+	}
+
+	return output.Print(ctx, results, &output.TableOptions{
+		Columns: []output.Column{
+			{Heading: "Check", ValueTemplate: "{{.Check}}"},
+			{Heading: "Severity", ValueTemplate: "{{.Severity}}"},
+			{Heading: "Message", ValueTemplate: "{{.Message}}"},
+		},
+	})
+}
+
+// runDoctorChecks runs the checks in order within a task list and returns the issues found.
+func runDoctorChecks(ctx context.Context, checks []*internal.DoctorCheck) []*doctorIssue {
+	issues := []*doctorIssue{}
+	taskList := ux.NewTaskList(nil)
+
+	for _, check := range checks {
+		taskList.AddTask(ux.TaskOptions{
+			Title: check.Title,
+			Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
+				err := check.Run(ctx)
+				if err == nil {
+					return ux.Success, nil
+				}
+
+				if errors.Is(err, internal.ErrDoctorSkipped) {
+					return ux.Skipped, err
+				}
+
+				var issue *internal.DoctorIssue
+				if !errors.As(err, &issue) {
+					issue = &internal.DoctorIssue{Err: err}
+				}
+
+				issues = append(issues, &doctorIssue{DoctorIssue: issue, title: check.Title})
+
+				if issue.Warning {
+					return ux.Warning, err
+				}
+
+				return ux.Error, err
+			},
+		})
+	}
+
+	// Failed checks are reported through the returned issues
+	_ = taskList.Run()
+
+	return issues
+}
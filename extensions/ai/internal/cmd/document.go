@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -134,13 +135,13 @@ func newUploadCommand() *cobra.Command {
 				return fmt.Errorf("no files found matching the pattern '%s'", flags.Pattern)
 			}
 
-			fmt.Printf("Source Data: %s\n", color.CyanString(absSourcePath))
-			fmt.Printf("Storage Account: %s\n", color.CyanString(extensionConfig.Storage.Account))
-			fmt.Printf("Storage Container: %s\n", color.CyanString(extensionConfig.Storage.Container))
+			fmt.Fprintf(output.MessageWriter(ctx), "Source Data: %s\n", color.CyanString(absSourcePath))
+			fmt.Fprintf(output.MessageWriter(ctx), "Storage Account: %s\n", color.CyanString(extensionConfig.Storage.Account))
+			fmt.Fprintf(output.MessageWriter(ctx), "Storage Container: %s\n", color.CyanString(extensionConfig.Storage.Container))
 
 			if !flags.Force {
-				fmt.Println()
-				fmt.Printf("Found %s matching files with pattern %s.\n", color.CyanString(fmt.Sprint(len(matchingFiles))), color.CyanString(flags.Pattern))
+				fmt.Fprintln(output.MessageWriter(ctx))
+				fmt.Fprintf(output.MessageWriter(ctx), "Found %s matching files with pattern %s.\n", color.CyanString(fmt.Sprint(len(matchingFiles))), color.CyanString(flags.Pattern))
 
 				continueConfirm := ux.NewConfirm(&ux.ConfirmOptions{
 					DefaultValue: ux.Ptr(true),
@@ -171,11 +172,23 @@ func newUploadCommand() *cobra.Command {
 					return err
 				}
 
+				progressBar := ux.NewProgressBar(&ux.ProgressBarOptions{
+					Unit:  ux.BytesUnit,
+					Width: 20,
+				})
+
 				taskList.AddTask(ux.TaskOptions{
-					Title: fmt.Sprintf("Uploading document %s", color.CyanString(relativePath)),
-					Async: true,
-					Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
-						if err := docPrepService.Upload(ctx, file, relativePath); err != nil {
+					Name:        relativePath,
+					Title:       fmt.Sprintf("Uploading document %s", color.CyanString(relativePath)),
+					Async:       true,
+					ProgressBar: progressBar,
+					ActionWithContext: func(ctx context.Context, setProgress ux.SetProgressFunc) (ux.TaskState, error) {
+						onProgress := func(completed int64, total int64) {
+							progressBar.SetTotal(total)
+							progressBar.SetCurrent(completed)
+						}
+
+						if err := docPrepService.Upload(ctx, file, relativePath, onProgress); err != nil {
 							return ux.Error, common.NewDetailedError("Failed to upload document", err)
 						}
 
@@ -184,11 +197,11 @@ func newUploadCommand() *cobra.Command {
 				})
 			}
 
-			if err := taskList.Run(); err != nil {
+			if err := taskList.RunWithContext(ctx); err != nil {
 				return err
 			}
 
-			return nil
+			return printTaskResults(ctx, taskList)
 		},
 	}
 
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/wbreza/azd-extensions/extensions/ai/internal"
@@ -24,9 +30,14 @@ type GenerateFlags struct {
 	ServiceName         string
 	ChatCompletionModel string
 	EmbeddingModel      string
-	Output              string
+	OutputDir           string
 	Pattern             string
 	Force               bool
+	MaxCost             float64
+	Redact              string
+	Detectors           []string
+	Dictionaries        []string
+	RedactionReport     string
 }
 
 type IngestFlags struct {
@@ -38,6 +49,22 @@ type IngestFlags struct {
 }
 
 // Command to initialize `azd ai embedding` command group
+// embeddingGenerateResult is the result of `azd ai embedding generate`.
+type embeddingGenerateResult struct {
+	OutputPath string                `json:"outputPath"`
+	Documents  []*taskResult         `json:"documents"`
+	Cost       *internal.CostMetrics `json:"cost"`
+}
+
+var embeddingGenerateTableOptions = &output.TableOptions{
+	Columns: []output.Column{
+		{Heading: "Output Path", ValueTemplate: "{{.OutputPath}}"},
+		{Heading: "Documents", ValueTemplate: "{{len .Documents}}"},
+		{Heading: "Estimated Cost", ValueTemplate: `{{printf "$%.4f" .Cost.EstimatedCost}}`},
+		{Heading: "Cost", ValueTemplate: `{{printf "$%.4f" .Cost.TotalCost}}`},
+	},
+}
+
 func newEmbeddingCommand() *cobra.Command {
 	// Main `embedding` command
 	embeddingCmd := &cobra.Command{
@@ -58,6 +85,9 @@ func newGenerateCommand() *cobra.Command {
 	generateCmd := &cobra.Command{
 		Use:   "generate",
 		Short: "Generate embeddings from documents in Azure",
+		Annotations: map[string]string{
+			outputPathAnnotation: "output-dir",
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			header := output.CommandHeader{
 				Title:       "Generate text embeddings for documents (azd ai embedding generate)",
@@ -83,7 +113,14 @@ func newGenerateCommand() *cobra.Command {
 					return err
 				}
 
+				parsedResource, err := arm.ParseResourceID(*aiAccount.ID)
+				if err != nil {
+					return err
+				}
+
 				extensionConfig = &internal.ExtensionConfig{
+					Subscription:  parsedResource.SubscriptionID,
+					ResourceGroup: parsedResource.ResourceGroupName,
 					Ai: internal.AiConfig{
 						Service:  *aiAccount.Name,
 						Endpoint: *aiAccount.Properties.Endpoint,
@@ -95,8 +132,8 @@ func newGenerateCommand() *cobra.Command {
 				extensionConfig.Ai.Models.Embeddings = flags.ChatCompletionModel
 			}
 
-			if flags.Output == "" {
-				flags.Output = "embeddings"
+			if flags.OutputDir == "" {
+				flags.OutputDir = "embeddings"
 			}
 
 			if flags.Pattern == "" {
@@ -116,7 +153,7 @@ func newGenerateCommand() *cobra.Command {
 			}
 
 			if extensionConfig.Ai.Models.ChatCompletion == "" {
-				color.Yellow("No chat completion model was found. Please select or create a chat completion model.")
+				fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("No chat completion model was found. Please select or create a chat completion model."))
 
 				selectedModelDeployment, err := internal.PromptModelDeployment(ctx, azdContext, azureContext, &internal.PromptModelDeploymentOptions{
 					Capabilities: []string{
@@ -131,7 +168,7 @@ func newGenerateCommand() *cobra.Command {
 			}
 
 			if extensionConfig.Ai.Models.Embeddings == "" {
-				color.Yellow("No text embedding model was found. Please select or create a text embedding model.")
+				fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("No text embedding model was found. Please select or create a text embedding model."))
 
 				selectedModelDeployment, err := internal.PromptModelDeployment(ctx, azdContext, azureContext, &internal.PromptModelDeploymentOptions{
 					Capabilities: []string{
@@ -161,14 +198,80 @@ func newGenerateCommand() *cobra.Command {
 				return err
 			}
 
-			absOutputPath := filepath.Join(cwd, flags.Output)
+			absOutputPath := filepath.Join(cwd, flags.OutputDir)
+
+			fmt.Fprintf(output.MessageWriter(ctx), "Source Data: %s\n", color.CyanString(absSourcePath))
+			fmt.Fprintf(output.MessageWriter(ctx), "Output Path: %s\n", color.CyanString(absOutputPath))
+
+			docPrepService, err := docprep.NewDocumentPrepService(ctx, azdContext, extensionConfig)
+			if err != nil {
+				return err
+			}
+
+			redactor, err := newRedactor(cmd, flags, &extensionConfig.Redaction)
+			if err != nil {
+				return err
+			}
+
+			if redactor != nil {
+				docPrepService.SetRedactor(redactor)
+
+				if flags.RedactionReport == "" {
+					timestamp := time.Now().Format("20060102_150405")
+					flags.RedactionReport = filepath.Join("redactions", fmt.Sprintf("redaction_report_%s.json", timestamp))
+				}
+
+				fmt.Fprintf(output.MessageWriter(ctx), "Redaction: %s\n", color.CyanString(string(redactor.Report().Action)))
+			}
+
+			prices, missingPrices, err := internal.LoadDeploymentPrices(
+				ctx,
+				azdContext,
+				extensionConfig,
+				extensionConfig.Ai.Models.ChatCompletion,
+				extensionConfig.Ai.Models.Embeddings,
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, deploymentName := range missingPrices {
+				fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("WARNING: No pricing found for model deployment %s, its usage is excluded from cost.", deploymentName))
+			}
+
+			tokenizers, err := internal.LoadDeploymentTokenizers(
+				ctx,
+				azdContext,
+				extensionConfig,
+				extensionConfig.Ai.Models.ChatCompletion,
+				extensionConfig.Ai.Models.Embeddings,
+			)
+			if err != nil {
+				return err
+			}
+
+			estimatedUsage := internal.UsageEstimate{}
+			for _, sourceDocumentPath := range matchingFiles {
+				documentUsage, err := docPrepService.EstimateEmbeddingUsage(ctx, sourceDocumentPath, tokenizers)
+				if err != nil {
+					return err
+				}
+
+				estimatedUsage.Merge(documentUsage)
+			}
+
+			costTracker := internal.NewCostTracker(prices, flags.MaxCost)
+			docPrepService.SetCostTracker(costTracker)
 
-			fmt.Printf("Source Data: %s\n", color.CyanString(absSourcePath))
-			fmt.Printf("Output Path: %s\n", color.CyanString(absOutputPath))
+			estimatedCost := costTracker.Estimate(estimatedUsage)
+			fmt.Fprintf(output.MessageWriter(ctx), "Estimated Cost: %s\n", color.CyanString("$%.4f", estimatedCost))
+			if flags.MaxCost > 0 {
+				fmt.Fprintf(output.MessageWriter(ctx), "Max Cost: %s\n", color.CyanString("$%.4f", flags.MaxCost))
+			}
 
 			if !flags.Force {
-				fmt.Println()
-				fmt.Printf("Found %s matching files with pattern %s.\n", color.CyanString(fmt.Sprint(len(matchingFiles))), color.CyanString(flags.Pattern))
+				fmt.Fprintln(output.MessageWriter(ctx))
+				fmt.Fprintf(output.MessageWriter(ctx), "Found %s matching files with pattern %s.\n", color.CyanString(fmt.Sprint(len(matchingFiles))), color.CyanString(flags.Pattern))
 
 				continueConfirm := ux.NewConfirm(&ux.ConfirmOptions{
 					DefaultValue: ux.Ptr(true),
@@ -189,11 +292,6 @@ func newGenerateCommand() *cobra.Command {
 				return err
 			}
 
-			docPrepService, err := docprep.NewDocumentPrepService(ctx, azdContext, extensionConfig)
-			if err != nil {
-				return err
-			}
-
 			taskList := ux.NewTaskList(nil)
 
 			for _, sourceDocumentPath := range matchingFiles {
@@ -204,11 +302,27 @@ func newGenerateCommand() *cobra.Command {
 
 				relativePath = strings.ReplaceAll(relativePath, "\\", "/")
 
+				progressBar := ux.NewProgressBar(&ux.ProgressBarOptions{
+					ItemName: "chunks",
+					Width:    20,
+				})
+
 				taskList.AddTask(ux.TaskOptions{
-					Title: fmt.Sprintf("Generating embeddings for document %s", relativePath),
-					Async: true,
+					Name:        relativePath,
+					Title:       fmt.Sprintf("Generating embeddings for document %s", relativePath),
+					Async:       true,
+					ProgressBar: progressBar,
 					Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
-						if _, err := docPrepService.GenerateEmbedding(ctx, sourceDocumentPath, absOutputPath); err != nil {
+						onProgress := func(completed int64, total int64) {
+							progressBar.SetTotal(total)
+							progressBar.SetCurrent(completed)
+						}
+
+						if _, err := docPrepService.GenerateEmbedding(ctx, sourceDocumentPath, absOutputPath, onProgress); err != nil {
+							if errors.Is(err, internal.ErrBudgetExceeded) {
+								return ux.Skipped, common.NewDetailedError("Budget exceeded", err)
+							}
+
 							return ux.Error, common.NewDetailedError("Failed to generate embeddings", err)
 						}
 
@@ -217,15 +331,34 @@ func newGenerateCommand() *cobra.Command {
 				})
 			}
 
-			if err := taskList.Run(); err != nil {
+			if err := taskList.Run(); err != nil && !errors.Is(err, internal.ErrBudgetExceeded) {
 				return err
 			}
 
-			if err != nil {
-				return err
+			printCostMetrics(output.MessageWriter(ctx), costTracker.Metrics())
+
+			if redactor != nil {
+				if err := saveRedactionReport(redactor.Report(), flags.RedactionReport); err != nil {
+					return fmt.Errorf("failed to save redaction report: %w", err)
+				}
+
+				printRedactionReport(output.MessageWriter(ctx), redactor.Report(), flags.RedactionReport)
 			}
 
-			return nil
+			if costTracker.CheckBudget() != nil {
+				fmt.Fprintln(output.MessageWriter(ctx))
+				fmt.Fprintln(output.MessageWriter(ctx), color.YellowString(
+					"WARNING: Max cost of $%.4f reached, remaining documents were skipped. Embeddings generated so far were saved to %s.",
+					flags.MaxCost,
+					absOutputPath,
+				))
+			}
+
+			return output.Print(ctx, &embeddingGenerateResult{
+				OutputPath: absOutputPath,
+				Documents:  newTaskResults(taskList),
+				Cost:       costTracker.Metrics(),
+			}, embeddingGenerateTableOptions)
 		},
 	}
 
@@ -234,9 +367,14 @@ func newGenerateCommand() *cobra.Command {
 	generateCmd.Flags().StringVar(&flags.ServiceName, "service", "", "Azure AI service name")
 	generateCmd.Flags().StringVar(&flags.EmbeddingModel, "embedding-model", "", "Model name to use for embedding (e.g., 'text-embedding-ada-002')")
 	generateCmd.Flags().StringVar(&flags.ChatCompletionModel, "chat-completion-model", "", "Model name to use for summary generation (e.g., 'gpt-4')")
-	generateCmd.Flags().StringVar(&flags.Output, "output", "", "Path or container to save generated embeddings")
+	generateCmd.Flags().StringVar(&flags.OutputDir, "output-dir", "", "Path or container to save generated embeddings")
 	generateCmd.Flags().StringVarP(&flags.Pattern, "pattern", "p", "", "Specify file types to process (e.g., '.pdf', '.txt')")
 	generateCmd.Flags().BoolVarP(&flags.Force, "force", "f", false, "Generate embeddings without confirmation")
+	generateCmd.Flags().Float64Var(&flags.MaxCost, "max-cost", 0, "Maximum cost in USD before remaining documents are skipped")
+	generateCmd.Flags().StringVar(&flags.Redact, "redact", "", "Action for chunks with sensitive content: redact, skip or flag (default: no redaction)")
+	generateCmd.Flags().StringSliceVar(&flags.Detectors, "detectors", nil, "Built-in detectors to run when redacting: pii, secrets (default: pii,secrets)")
+	generateCmd.Flags().StringArrayVar(&flags.Dictionaries, "dictionary", nil, "Path to a file of custom terms to detect, one per line (repeatable)")
+	generateCmd.Flags().StringVar(&flags.RedactionReport, "redaction-report", "", "Path to save the redaction report")
 
 	_ = generateCmd.MarkFlagRequired("source")
 
@@ -333,13 +471,13 @@ func newIngestCommand() *cobra.Command {
 				return err
 			}
 
-			fmt.Printf("Source Data: %s\n", color.CyanString(absSourcePath))
-			fmt.Printf("Search Service: %s\n", color.CyanString(extensionConfig.Search.Service))
-			fmt.Printf("Search Index: %s\n", color.CyanString(extensionConfig.Search.Index))
+			fmt.Fprintf(output.MessageWriter(ctx), "Source Data: %s\n", color.CyanString(absSourcePath))
+			fmt.Fprintf(output.MessageWriter(ctx), "Search Service: %s\n", color.CyanString(extensionConfig.Search.Service))
+			fmt.Fprintf(output.MessageWriter(ctx), "Search Index: %s\n", color.CyanString(extensionConfig.Search.Index))
 
 			if !flags.Force {
-				fmt.Println()
-				fmt.Printf("Found %s matching files with pattern %s.\n", color.CyanString(fmt.Sprint(len(matchingFiles))), color.CyanString(flags.Pattern))
+				fmt.Fprintln(output.MessageWriter(ctx))
+				fmt.Fprintf(output.MessageWriter(ctx), "Found %s matching files with pattern %s.\n", color.CyanString(fmt.Sprint(len(matchingFiles))), color.CyanString(flags.Pattern))
 
 				continueConfirm := ux.NewConfirm(&ux.ConfirmOptions{
 					DefaultValue: ux.Ptr(true),
@@ -372,6 +510,7 @@ func newIngestCommand() *cobra.Command {
 				relativePath = strings.ReplaceAll(relativePath, "\\", "/")
 
 				taskList.AddTask(ux.TaskOptions{
+					Name:  relativePath,
 					Title: fmt.Sprintf("Ingesting embeddings for document %s", relativePath),
 					Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
 						if err := docPrepService.IngestEmbedding(ctx, file); err != nil {
@@ -387,11 +526,7 @@ func newIngestCommand() *cobra.Command {
 				return err
 			}
 
-			if err != nil {
-				return err
-			}
-
-			return nil
+			return printTaskResults(ctx, taskList)
 		},
 	}
 
@@ -406,3 +541,97 @@ func newIngestCommand() *cobra.Command {
 
 	return ingestCmd
 }
+
+// newRedactor creates the redaction stage from the flags and the extension config.
+// Returns nil when redaction is disabled.
+func newRedactor(cmd *cobra.Command, flags *GenerateFlags, redactionConfig *internal.RedactionConfig) (*docprep.Redactor, error) {
+	actionValue := flags.Redact
+	if actionValue == "" {
+		actionValue = redactionConfig.Action
+	}
+
+	if actionValue == "" {
+		return nil, nil
+	}
+
+	action, err := docprep.ParseRedactionAction(actionValue)
+	if err != nil {
+		return nil, err
+	}
+
+	detectorNames := redactionConfig.Detectors
+	if cmd.Flags().Changed("detectors") {
+		detectorNames = flags.Detectors
+	}
+
+	if len(detectorNames) == 0 {
+		detectorNames = []string{docprep.PiiDetectors, docprep.SecretDetectors}
+	}
+
+	detectors, err := docprep.NewDetectors(detectorNames)
+	if err != nil {
+		return nil, err
+	}
+
+	dictionaries := slices.Concat(redactionConfig.Dictionaries, flags.Dictionaries)
+	for _, dictionaryPath := range dictionaries {
+		dictionary, err := docprep.LoadDictionaryDetector(dictionaryPath)
+		if err != nil {
+			return nil, err
+		}
+
+		detectors = append(detectors, dictionary)
+	}
+
+	if len(detectors) == 0 {
+		return nil, errors.New("redaction requires at least one detector or dictionary")
+	}
+
+	return docprep.NewRedactor(action, detectors), nil
+}
+
+func saveRedactionReport(report *docprep.RedactionReport, filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), permissions.PermissionDirectory); err != nil {
+		return err
+	}
+
+	findings := report.Sorted()
+
+	bytes, err := json.MarshalIndent(&docprep.RedactionReport{
+		Action:        report.Action,
+		Detections:    report.Detections,
+		SkippedChunks: report.SkippedChunks,
+		Findings:      findings,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, bytes, permissions.PermissionFile)
+}
+
+func printRedactionReport(writer io.Writer, report *docprep.RedactionReport, filename string) {
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, color.CyanString("Redaction"))
+
+	if len(report.Findings) == 0 {
+		fmt.Fprintln(writer, "No sensitive content found.")
+	}
+
+	detectorNames := []string{}
+	for name := range report.Detections {
+		detectorNames = append(detectorNames, name)
+	}
+
+	slices.Sort(detectorNames)
+
+	for _, name := range detectorNames {
+		fmt.Fprintf(writer, "%s: %d\n", name, report.Detections[name])
+	}
+
+	if report.SkippedChunks > 0 {
+		fmt.Fprintf(writer, "Skipped Chunks: %d\n", report.SkippedChunks)
+	}
+
+	fmt.Fprintf(writer, "Redaction report saved to: %s\n", color.CyanString(filename))
+}
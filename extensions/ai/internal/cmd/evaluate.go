@@ -8,8 +8,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/wbreza/azd-extensions/extensions/ai/internal"
@@ -22,6 +24,8 @@ import (
 
 var ErrTestCaseFailed = errors.New("evaluation failed")
 
+var defaultResponseCacheDir = filepath.Join("evaluations", ".cache")
+
 // Function to create the `evaluate` command group
 func newEvaluateCommand() *cobra.Command {
 	evaluateCmd := &cobra.Command{
@@ -32,6 +36,7 @@ func newEvaluateCommand() *cobra.Command {
 	// Add subcommands to the `evaluate` command group
 	evaluateCmd.AddCommand(newEvaluateFlowCommand())
 	evaluateCmd.AddCommand(newEvaluateModelCommand())
+	evaluateCmd.AddCommand(newEvaluateEndpointCommand())
 
 	return evaluateCmd
 }
@@ -42,6 +47,9 @@ func newEvaluateFlowCommand() *cobra.Command {
 	flowCmd := &cobra.Command{
 		Use:   "flow",
 		Short: "Evaluate model flow based on a test dataset",
+		Annotations: map[string]string{
+			outputPathAnnotation: "report",
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			header := output.CommandHeader{
 				Title:       "Evaluate an AI flow (azd ai evaluate flow)",
@@ -51,14 +59,14 @@ func newEvaluateFlowCommand() *cobra.Command {
 
 			ctx := cmd.Context()
 
-			if flags.Output == "" {
+			if flags.Report == "" {
 				currentTime := time.Now()
 				timestamp := currentTime.Format("20060102_150405")
 				filename := fmt.Sprintf("flow_report_%s.json", timestamp)
-				flags.Output = filepath.Join("evaluations", filename)
+				flags.Report = filepath.Join("evaluations", filename)
 			}
 
-			folderPath := filepath.Dir(flags.Output)
+			folderPath := filepath.Dir(flags.Report)
 			if err := os.MkdirAll(folderPath, permissions.PermissionDirectory); err != nil {
 				return err
 			}
@@ -76,26 +84,41 @@ func newEvaluateFlowCommand() *cobra.Command {
 				FuzzyMatchThreshold:      testData.FuzzyMatchThreshold,
 				SimilarityMatchThreshold: testData.SimilarityMatchThreshold,
 				BatchSize:                flags.BatchSize,
+				Replay:                   flags.Replay,
+				ModelVersion:             flags.ModelVersion,
 			}
 
-			fmt.Printf("Running evaluation against %s\n", color.CyanString(flags.TestData))
+			fmt.Fprintf(output.MessageWriter(ctx), "Running evaluation against %s\n", color.CyanString(flags.TestData))
 
-			evalReport, err := runEvaluation(ctx, testData, evalOptions)
+			evalReport, err := runEvaluation(ctx, testData, evalOptions, &evaluationRunConfig{
+				CacheDir: responseCacheDir(flags.CacheDir, flags.NoCache),
+				MaxCost:  flags.MaxCost,
+				Prompt:   flags.Prompt,
+				Retrieval: func(configured internal.RetrievalOptions) internal.RetrievalOptions {
+					return retrievalOptions(cmd, &flags.Retrieval, configured)
+				},
+			})
 			if err != nil {
 				return err
 			}
 
-			if err := saveEvaluationReport(evalReport, flags.Output); err != nil {
+			if err := saveEvaluationReport(evalReport, flags.Report); err != nil {
 				return fmt.Errorf("failed to save evaluation report: %w", err)
 			}
 
-			printEvaluationReportResults(evalReport)
+			if output.IsStructured() {
+				if err := output.Print(ctx, evalReport, evaluationReportTableOptions); err != nil {
+					return err
+				}
+			} else {
+				printEvaluationReportResults(output.MessageWriter(ctx), evalReport)
+			}
 
-			fmt.Println()
-			fmt.Printf("Evaluation report saved to: %s\n", color.CyanString(flags.Output))
-			fmt.Println()
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintf(output.MessageWriter(ctx), "Evaluation report saved to: %s\n", color.CyanString(flags.Report))
+			fmt.Fprintln(output.MessageWriter(ctx))
 
-			color.Green("SUCCESS: Flow evaluation completed.")
+			fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: Flow evaluation completed."))
 			return nil
 		},
 	}
@@ -105,8 +128,17 @@ func newEvaluateFlowCommand() *cobra.Command {
 	flowCmd.Flags().StringVar(&flags.EmbeddingDeploymentName, "embedding-deployment-name", "", "Name of the embedding model deployment to evaluate")
 	flowCmd.Flags().StringVar(&flags.IndexName, "index-name", "", "Name of the search index to evaluate")
 	flowCmd.Flags().StringVar(&flags.TestData, "test-data", "", "Path to JSON file with test questions and expected answers (required)")
-	flowCmd.Flags().StringVar(&flags.Output, "output", "", "Path to save the accuracy evaluation report")
+	flowCmd.Flags().StringVar(&flags.Report, "report", "", "Path to save the accuracy evaluation report")
 	flowCmd.Flags().IntVar(&flags.BatchSize, "batch-size", 1, "Number of test cases to evaluate in parallel")
+	flowCmd.Flags().BoolVar(&flags.Replay, "replay", false, "Re-score cached model responses without calling the model")
+	flowCmd.Flags().StringVar(&flags.ModelVersion, "model-version", "", "Model version of the cached responses to replay (default: the version of the last run)")
+	flowCmd.Flags().BoolVar(&flags.NoCache, "no-cache", false, "Disable the model response cache")
+	flowCmd.Flags().StringVar(&flags.CacheDir, "cache-dir", defaultResponseCacheDir, "Path to the model response cache")
+	flowCmd.Flags().Float64Var(&flags.MaxCost, "max-cost", 0, "Maximum cost in USD before remaining test cases are skipped")
+	flowCmd.Flags().StringVar(&flags.Prompt, "prompt", "", "Name of the prompt template in the prompts folder, optionally pinned with <name>@<version>")
+	addRetrievalFlags(flowCmd, &flags.Retrieval)
+
+	flowCmd.MarkFlagsMutuallyExclusive("replay", "no-cache")
 
 	_ = flowCmd.MarkFlagRequired("test-data")
 
@@ -119,6 +151,9 @@ func newEvaluateModelCommand() *cobra.Command {
 	modelCmd := &cobra.Command{
 		Use:   "model",
 		Short: "Evaluate model based on a test dataset",
+		Annotations: map[string]string{
+			outputPathAnnotation: "report",
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			header := output.CommandHeader{
 				Title:       "Evaluate an AI model (azd ai evaluate model)",
@@ -128,14 +163,14 @@ func newEvaluateModelCommand() *cobra.Command {
 
 			ctx := cmd.Context()
 
-			if flags.Output == "" {
+			if flags.Report == "" {
 				currentTime := time.Now()
 				timestamp := currentTime.Format("20060102_150405")
 				filename := fmt.Sprintf("model_report_%s.json", timestamp)
-				flags.Output = filepath.Join("evaluations", filename)
+				flags.Report = filepath.Join("evaluations", filename)
 			}
 
-			folderPath := filepath.Dir(flags.Output)
+			folderPath := filepath.Dir(flags.Report)
 			if err := os.MkdirAll(folderPath, permissions.PermissionDirectory); err != nil {
 				return err
 			}
@@ -151,26 +186,38 @@ func newEvaluateModelCommand() *cobra.Command {
 				FuzzyMatchThreshold:      testData.FuzzyMatchThreshold,
 				SimilarityMatchThreshold: testData.SimilarityMatchThreshold,
 				BatchSize:                flags.BatchSize,
+				Replay:                   flags.Replay,
+				ModelVersion:             flags.ModelVersion,
 			}
 
-			fmt.Printf("Running evaluation against %s\n", color.CyanString(flags.TestData))
+			fmt.Fprintf(output.MessageWriter(ctx), "Running evaluation against %s\n", color.CyanString(flags.TestData))
 
-			evalReport, err := runEvaluation(ctx, testData, evalOptions)
+			evalReport, err := runEvaluation(ctx, testData, evalOptions, &evaluationRunConfig{
+				CacheDir: responseCacheDir(flags.CacheDir, flags.NoCache),
+				MaxCost:  flags.MaxCost,
+				Prompt:   flags.Prompt,
+			})
 			if err != nil {
 				return err
 			}
 
-			if err := saveEvaluationReport(evalReport, flags.Output); err != nil {
+			if err := saveEvaluationReport(evalReport, flags.Report); err != nil {
 				return fmt.Errorf("failed to save evaluation report: %w", err)
 			}
 
-			printEvaluationReportResults(evalReport)
+			if output.IsStructured() {
+				if err := output.Print(ctx, evalReport, evaluationReportTableOptions); err != nil {
+					return err
+				}
+			} else {
+				printEvaluationReportResults(output.MessageWriter(ctx), evalReport)
+			}
 
-			fmt.Println()
-			fmt.Printf("Evaluation report saved to: %s\n", color.CyanString(flags.Output))
-			fmt.Println()
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintf(output.MessageWriter(ctx), "Evaluation report saved to: %s\n", color.CyanString(flags.Report))
+			fmt.Fprintln(output.MessageWriter(ctx))
 
-			color.Green("SUCCESS: Model evaluation completed.")
+			fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: Model evaluation completed."))
 			return nil
 		},
 	}
@@ -178,33 +225,215 @@ func newEvaluateModelCommand() *cobra.Command {
 	// Define flags for the `accuracy` command
 	modelCmd.Flags().StringVar(&flags.DeploymentName, "chat-deployment-name", "", "Name of the chat completion model deployment to evaluate")
 	modelCmd.Flags().StringVar(&flags.TestData, "test-data", "", "Path to JSON file with test questions and expected answers (required)")
-	modelCmd.Flags().StringVar(&flags.Output, "output", "", "Path to save the accuracy evaluation report")
+	modelCmd.Flags().StringVar(&flags.Report, "report", "", "Path to save the accuracy evaluation report")
 	modelCmd.Flags().IntVar(&flags.BatchSize, "batch-size", 1, "Number of test cases to evaluate in parallel")
+	modelCmd.Flags().BoolVar(&flags.Replay, "replay", false, "Re-score cached model responses without calling the model")
+	modelCmd.Flags().StringVar(&flags.ModelVersion, "model-version", "", "Model version of the cached responses to replay (default: the version of the last run)")
+	modelCmd.Flags().BoolVar(&flags.NoCache, "no-cache", false, "Disable the model response cache")
+	modelCmd.Flags().StringVar(&flags.CacheDir, "cache-dir", defaultResponseCacheDir, "Path to the model response cache")
+	modelCmd.Flags().Float64Var(&flags.MaxCost, "max-cost", 0, "Maximum cost in USD before remaining test cases are skipped")
+	modelCmd.Flags().StringVar(&flags.Prompt, "prompt", "", "Name of the prompt template in the prompts folder, optionally pinned with <name>@<version>")
+
+	modelCmd.MarkFlagsMutuallyExclusive("replay", "no-cache")
 
 	_ = modelCmd.MarkFlagRequired("test-data")
 
 	return modelCmd
 }
 
+func newEvaluateEndpointCommand() *cobra.Command {
+	flags := &EvaluateEndpointFlags{}
+
+	endpointCmd := &cobra.Command{
+		Use:   "endpoint",
+		Short: "Evaluate a deployed HTTP endpoint based on a test dataset",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			header := output.CommandHeader{
+				Title:       "Evaluate an HTTP endpoint (azd ai evaluate endpoint)",
+				Description: "Evaluates a deployed AI application for accuracy, latency, and token usage based on a test dataset.",
+			}
+			header.Print()
+
+			ctx := cmd.Context()
+
+			if flags.Report == "" {
+				currentTime := time.Now()
+				timestamp := currentTime.Format("20060102_150405")
+				filename := fmt.Sprintf("endpoint_report_%s.json", timestamp)
+				flags.Report = filepath.Join("evaluations", filename)
+			}
+
+			mapping := internal.DefaultEndpointMapping()
+			if flags.Mapping != "" {
+				var err error
+				mapping, err = internal.LoadEndpointMapping(flags.Mapping)
+				if err != nil {
+					return err
+				}
+			}
+
+			if mapping.Request.Headers == nil {
+				mapping.Request.Headers = map[string]string{}
+			}
+
+			for _, header := range flags.Headers {
+				name, value, has := strings.Cut(header, ":")
+				if !has || strings.TrimSpace(name) == "" {
+					return &ext.ErrorWithSuggestion{
+						Err:        fmt.Errorf("invalid header '%s'", header),
+						Suggestion: fmt.Sprintf("Specify headers as %s", color.CyanString("--header \"Name: value\"")),
+					}
+				}
+
+				mapping.Request.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+
+			endpointClient, err := internal.NewEndpointClient(flags.Url, mapping, flags.Timeout)
+			if err != nil {
+				return err
+			}
+
+			folderPath := filepath.Dir(flags.Report)
+			if err := os.MkdirAll(folderPath, permissions.PermissionDirectory); err != nil {
+				return err
+			}
+
+			testData, err := loadTestData(flags.TestData)
+			if err != nil {
+				return fmt.Errorf("failed to load test data: %w", err)
+			}
+
+			evalOptions := internal.EvaluationOptions{
+				EvaluationType:           internal.EvaluationTypeEndpoint,
+				EmbeddingModel:           flags.EmbeddingDeploymentName,
+				FuzzyMatchThreshold:      testData.FuzzyMatchThreshold,
+				SimilarityMatchThreshold: testData.SimilarityMatchThreshold,
+				BatchSize:                flags.BatchSize,
+				Endpoint:                 endpointClient,
+			}
+
+			fmt.Fprintf(output.MessageWriter(ctx), "Running evaluation against %s\n", color.CyanString(flags.TestData))
+
+			// Responses of live endpoints aren't cached since the deployed application can change between runs
+			evalReport, err := runEvaluation(ctx, testData, evalOptions, &evaluationRunConfig{
+				MaxCost: flags.MaxCost,
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := saveEvaluationReport(evalReport, flags.Report); err != nil {
+				return fmt.Errorf("failed to save evaluation report: %w", err)
+			}
+
+			if output.IsStructured() {
+				if err := output.Print(ctx, evalReport, evaluationReportTableOptions); err != nil {
+					return err
+				}
+			} else {
+				printEvaluationReportResults(output.MessageWriter(ctx), evalReport)
+			}
+
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintf(output.MessageWriter(ctx), "Evaluation report saved to: %s\n", color.CyanString(flags.Report))
+			fmt.Fprintln(output.MessageWriter(ctx))
+
+			fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: Endpoint evaluation completed."))
+			return nil
+		},
+	}
+
+	endpointCmd.Flags().StringVar(&flags.Url, "url", "", "URL of the endpoint to evaluate, may contain {{question}} and {{id}} variables (required)")
+	endpointCmd.Flags().StringVar(&flags.Mapping, "mapping", "", "Path to a YAML or JSON file mapping test cases to requests and responses to answers (default: OpenAI chat completions)")
+	endpointCmd.Flags().StringArrayVar(&flags.Headers, "header", []string{}, "Request header as \"Name: value\", environment variables are expanded (repeatable)")
+	endpointCmd.Flags().DurationVar(&flags.Timeout, "timeout", internal.DefaultEndpointTimeout, "Timeout of each request to the endpoint")
+	endpointCmd.Flags().StringVar(&flags.EmbeddingDeploymentName, "embedding-deployment-name", "", "Name of the embedding model deployment used for semantic matching")
+	endpointCmd.Flags().StringVar(&flags.TestData, "test-data", "", "Path to JSON file with test questions and expected answers (required)")
+	endpointCmd.Flags().StringVar(&flags.Report, "report", "", "Path to save the accuracy evaluation report")
+	endpointCmd.Flags().IntVar(&flags.BatchSize, "batch-size", 1, "Number of test cases to evaluate in parallel")
+	endpointCmd.Flags().Float64Var(&flags.MaxCost, "max-cost", 0, "Maximum cost in USD before remaining test cases are skipped")
+
+	_ = endpointCmd.MarkFlagRequired("url")
+	_ = endpointCmd.MarkFlagRequired("test-data")
+
+	return endpointCmd
+}
+
 // Flag structs for each evaluation command
 type EvaluateFlowFlags struct {
 	ChatDeploymentName      string
 	EmbeddingDeploymentName string
 	IndexName               string
 	TestData                string
-	Output                  string
+	Report                  string
 	BatchSize               int
+	Replay                  bool
+	ModelVersion            string
+	NoCache                 bool
+	CacheDir                string
+	MaxCost                 float64
+	Prompt                  string
+	Retrieval               retrievalFlags
 }
 
 // Flag structs for each evaluation command
 type EvaluateModelFlags struct {
 	DeploymentName string
 	TestData       string
-	Output         string
+	Report         string
 	BatchSize      int
+	Replay         bool
+	ModelVersion   string
+	NoCache        bool
+	CacheDir       string
+	MaxCost        float64
+	Prompt         string
+}
+
+// Flag structs for each evaluation command
+type EvaluateEndpointFlags struct {
+	Url                     string
+	Mapping                 string
+	Headers                 []string
+	Timeout                 time.Duration
+	EmbeddingDeploymentName string
+	TestData                string
+	Report                  string
+	BatchSize               int
+	MaxCost                 float64
+}
+
+// responseCacheDir returns the cache directory to use or an empty string when caching is disabled.
+func responseCacheDir(cacheDir string, noCache bool) string {
+	if noCache {
+		return ""
+	}
+
+	return cacheDir
+}
+
+// evaluationRunConfig contains the settings that control how an evaluation is executed
+type evaluationRunConfig struct {
+	// Path to the model response cache, caching is disabled when empty
+	CacheDir string
+	// Maximum cost in USD, no limit when zero
+	MaxCost float64
+	// Name of the prompt template, the configured or built-in template is used when empty
+	Prompt string
+	// Retrieval resolves the retrieval options of flow evaluations from the configured options
+	Retrieval func(configured internal.RetrievalOptions) internal.RetrievalOptions
 }
 
-func runEvaluation(ctx context.Context, testData *internal.EvaluationTestData, options internal.EvaluationOptions) (*internal.EvaluationReport, error) {
+func runEvaluation(
+	ctx context.Context,
+	testData *internal.EvaluationTestData,
+	options internal.EvaluationOptions,
+	runConfig *evaluationRunConfig,
+) (*internal.EvaluationReport, error) {
+	if options.ModelVersion != "" && !options.Replay {
+		return nil, errors.New("--model-version can only be used with --replay")
+	}
+
 	azdContext, err := ext.CurrentContext(ctx)
 	if err != nil {
 		return nil, err
@@ -222,7 +451,14 @@ func runEvaluation(ctx context.Context, testData *internal.EvaluationTestData, o
 			return nil, err
 		}
 
+		parsedResource, err := arm.ParseResourceID(*aiAccount.ID)
+		if err != nil {
+			return nil, err
+		}
+
 		extensionConfig = &internal.ExtensionConfig{
+			Subscription:  parsedResource.SubscriptionID,
+			ResourceGroup: parsedResource.ResourceGroupName,
 			Ai: internal.AiConfig{
 				Service:  *aiAccount.Name,
 				Endpoint: *aiAccount.Properties.Endpoint,
@@ -230,7 +466,9 @@ func runEvaluation(ctx context.Context, testData *internal.EvaluationTestData, o
 		}
 	}
 
-	if options.ChatCompletionModel == "" && extensionConfig.Ai.Models.ChatCompletion == "" {
+	isEndpoint := options.EvaluationType == internal.EvaluationTypeEndpoint
+
+	if !isEndpoint && options.ChatCompletionModel == "" && extensionConfig.Ai.Models.ChatCompletion == "" {
 		chatModel, err := internal.PromptModelDeployment(ctx, azdContext, azureContext, &internal.PromptModelDeploymentOptions{
 			Capabilities: []string{"chatCompletion"},
 		})
@@ -273,7 +511,19 @@ func runEvaluation(ctx context.Context, testData *internal.EvaluationTestData, o
 		}
 	}
 
-	if options.ChatCompletionModel == "" {
+	// Semantic matching of the endpoint answers requires an embedding model
+	if isEndpoint && options.EmbeddingModel == "" && extensionConfig.Ai.Models.Embeddings == "" {
+		embeddingModel, err := internal.PromptModelDeployment(ctx, azdContext, azureContext, &internal.PromptModelDeploymentOptions{
+			Capabilities: []string{"embeddings"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		extensionConfig.Ai.Models.Embeddings = *embeddingModel.Name
+	}
+
+	if options.ChatCompletionModel == "" && !isEndpoint {
 		options.ChatCompletionModel = extensionConfig.Ai.Models.ChatCompletion
 	}
 
@@ -285,9 +535,58 @@ func runEvaluation(ctx context.Context, testData *internal.EvaluationTestData, o
 		options.IndexName = extensionConfig.Search.Index
 	}
 
-	fmt.Printf("Chat Completion Model: %s\n", color.CyanString(options.ChatCompletionModel))
+	promptTemplates, err := internal.NewProjectPromptTemplateStore(azdContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Endpoints build their own prompts
+	if !isEndpoint {
+		promptTemplate, err := promptTemplates.Resolve(internal.EvaluatePromptTemplate, runConfig.Prompt, extensionConfig.Prompts.Evaluate)
+		if err != nil {
+			return nil, err
+		}
+
+		// Fail before any model calls when the template references unknown variables
+		if _, err := promptTemplate.UserMessage("", ""); err != nil {
+			return nil, err
+		}
+
+		options.PromptTemplate = promptTemplate
+	}
+
+	if options.EvaluationType == internal.EvaluationTypeFlow && runConfig.Retrieval != nil {
+		options.Retrieval = runConfig.Retrieval(extensionConfig.Retrieval.Evaluate)
+
+		if options.Retrieval.Rerank {
+			options.RerankTemplate, err = promptTemplates.Resolve(internal.RerankPromptTemplate, extensionConfig.Prompts.Rerank)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if isEndpoint {
+		mapping := options.Endpoint.Mapping()
+		fmt.Fprintf(output.MessageWriter(ctx), "Endpoint: %s %s\n", color.CyanString(options.Endpoint.Url()), color.HiBlackString("(%s)", mapping.Request.Method))
+		fmt.Fprintf(output.MessageWriter(ctx), "Answer Path: %s\n", color.CyanString(mapping.Response.Answer))
+		if mapping.Response.Context != "" {
+			fmt.Fprintf(output.MessageWriter(ctx), "Context Path: %s\n", color.CyanString(mapping.Response.Context))
+		}
+		fmt.Fprintf(output.MessageWriter(ctx), "Embedding Model: %s\n", color.CyanString(options.EmbeddingModel))
+	} else {
+		fmt.Fprintf(output.MessageWriter(ctx), "Chat Completion Model: %s\n", color.CyanString(options.ChatCompletionModel))
+	}
 	if options.EvaluationType == internal.EvaluationTypeFlow {
-		fmt.Printf("Embedding Model: %s\n", color.CyanString(options.EmbeddingModel))
+		fmt.Fprintf(output.MessageWriter(ctx), "Embedding Model: %s\n", color.CyanString(options.EmbeddingModel))
+		fmt.Fprintf(output.MessageWriter(ctx), "Retrieval: %s\n", color.CyanString(options.Retrieval.WithDefaults().String()))
+	}
+	if options.PromptTemplate != nil {
+		fmt.Fprintf(output.MessageWriter(ctx),
+			"Prompt Template: %s %s\n",
+			color.CyanString(options.PromptTemplate.Name),
+			color.HiBlackString("(Version: %s)", options.PromptTemplate.Version),
+		)
 	}
 
 	if err := internal.SaveExtensionConfig(ctx, azdContext, extensionConfig); err != nil {
@@ -299,6 +598,82 @@ func runEvaluation(ctx context.Context, testData *internal.EvaluationTestData, o
 		return nil, err
 	}
 
+	if runConfig.CacheDir != "" {
+		responseCache, err := internal.NewResponseCache(runConfig.CacheDir)
+		if err != nil {
+			return nil, err
+		}
+
+		evalService.SetResponseCache(responseCache)
+
+		if options.Replay {
+			// Responses are cached per model version, replays use the version of the last run unless specified
+			if options.ModelVersion == "" {
+				options.ModelVersion, err = responseCache.ModelVersion(options.ChatCompletionModel)
+				if errors.Is(err, internal.ErrCacheMiss) {
+					return nil, &ext.ErrorWithSuggestion{
+						Err:        fmt.Errorf("no cached responses found for model deployment %s in %s", options.ChatCompletionModel, runConfig.CacheDir),
+						Suggestion: "Run the evaluation without --replay to cache the model responses.",
+					}
+				}
+
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			fmt.Fprintf(
+				output.MessageWriter(ctx),
+				"Replaying cached responses of model version %s from %s\n",
+				color.CyanString(options.ModelVersion),
+				color.CyanString(runConfig.CacheDir),
+			)
+		}
+	}
+
+	// Prices and tokenizers are resolved from the model deployments in Azure, replays don't call Azure so the usage isn't priced
+	prices := map[string]internal.ModelPrice{}
+	tokenizers := internal.DeploymentTokenizers{}
+	if options.Replay {
+		fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("WARNING: Model usage is excluded from cost when replaying cached responses."))
+	} else {
+		var missingPrices []string
+		prices, missingPrices, err = internal.LoadDeploymentPrices(
+			ctx,
+			azdContext,
+			extensionConfig,
+			options.ChatCompletionModel,
+			options.EmbeddingModel,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, deploymentName := range missingPrices {
+			fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("WARNING: No pricing found for model deployment %s, its usage is excluded from cost.", deploymentName))
+		}
+
+		tokenizers, err = internal.LoadDeploymentTokenizers(
+			ctx,
+			azdContext,
+			extensionConfig,
+			options.ChatCompletionModel,
+			options.EmbeddingModel,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	costTracker := internal.NewCostTracker(prices, runConfig.MaxCost)
+	evalService.SetCostTracker(costTracker)
+
+	estimatedCost := costTracker.Estimate(internal.EstimateEvaluationUsage(testData, options, tokenizers))
+	fmt.Fprintf(output.MessageWriter(ctx), "Estimated Cost: %s\n", color.CyanString("$%.4f", estimatedCost))
+	if runConfig.MaxCost > 0 {
+		fmt.Fprintf(output.MessageWriter(ctx), "Max Cost: %s\n", color.CyanString("$%.4f", runConfig.MaxCost))
+	}
+
 	testCaseResults := []*internal.EvaluationTestCaseResult{}
 	taskList := ux.NewTaskList(&ux.TaskListConfig{
 		MaxConcurrentAsync: options.BatchSize,
@@ -311,6 +686,20 @@ func runEvaluation(ctx context.Context, testData *internal.EvaluationTestData, o
 			Action: func(spf ux.SetProgressFunc) (ux.TaskState, error) {
 				testCaseResult, err := evalService.EvaluateTestCase(ctx, testCase, options)
 				if err != nil {
+					if errors.Is(err, internal.ErrBudgetExceeded) {
+						return ux.Skipped, common.NewDetailedError("Budget exceeded", err)
+					}
+
+					if errors.Is(err, internal.ErrCacheMiss) {
+						return ux.Error, common.NewDetailedError(
+							"Evaluation Failed",
+							&ext.ErrorWithSuggestion{
+								Err:        err,
+								Suggestion: "Run the evaluation without --replay to populate the response cache.",
+							},
+						)
+					}
+
 					return ux.Error, common.NewDetailedError("Evaluation Failed", err)
 				}
 
@@ -325,38 +714,102 @@ func runEvaluation(ctx context.Context, testData *internal.EvaluationTestData, o
 		})
 	}
 
-	if err := taskList.Run(); err != nil && !errors.Is(err, ErrTestCaseFailed) {
+	if err := taskList.Run(); err != nil &&
+		!errors.Is(err, ErrTestCaseFailed) &&
+		!errors.Is(err, internal.ErrBudgetExceeded) {
 		return nil, err
 	}
 
 	evalReport := evalService.GenerateReport(testCaseResults)
+	if options.PromptTemplate != nil {
+		evalReport.Prompt = options.PromptTemplate.Info()
+	}
+
+	if costTracker.CheckBudget() != nil {
+		fmt.Fprintln(output.MessageWriter(ctx), color.YellowString(
+			"WARNING: Max cost of $%.4f reached, remaining test cases were skipped. The report contains partial results.",
+			runConfig.MaxCost,
+		))
+	}
 
 	return evalReport, nil
 }
 
-func printEvaluationReportResults(evalReport *internal.EvaluationReport) {
+// evaluationReportTableOptions renders the metrics of the evaluation report as a single row.
+var evaluationReportTableOptions = &output.TableOptions{
+	Columns: []output.Column{
+		{Heading: "Accuracy", ValueTemplate: `{{printf "%.2f" .Metrics.Accuracy}}`},
+		{Heading: "Precision", ValueTemplate: `{{printf "%.2f" .Metrics.Precision}}`},
+		{Heading: "Recall", ValueTemplate: `{{printf "%.2f" .Metrics.Recall}}`},
+		{Heading: "F1 Score", ValueTemplate: `{{printf "%.2f" .Metrics.F1}}`},
+		{Heading: "Avg Latency (ms)", ValueTemplate: `{{printf "%.2f" .Metrics.Latency.AvgDuration}}`},
+		{Heading: "P95 Latency (ms)", ValueTemplate: "{{.Metrics.Latency.P95Duration}}"},
+		{Heading: "Total Tokens", ValueTemplate: "{{.Metrics.TokenUsage.TotalTokens}}"},
+		{Heading: "Cost", ValueTemplate: `{{if .Metrics.Cost}}{{printf "$%.4f" .Metrics.Cost.TotalCost}}{{end}}`},
+	},
+}
+
+func printEvaluationReportResults(writer io.Writer, evalReport *internal.EvaluationReport) {
 	// Print out the eval result
-	color.Cyan("Accuracy")
-	fmt.Printf("Accuracy: %.2f\n", evalReport.Metrics.Accuracy)
-	fmt.Printf("Precision: %.2f\n", evalReport.Metrics.Precision)
-	fmt.Printf("Recall: %.2f\n", evalReport.Metrics.Recall)
-	fmt.Printf("F1 Score: %.2f\n", evalReport.Metrics.F1)
-	fmt.Println()
-
-	color.Cyan("Latency")
-	fmt.Printf("Total Duration: %d ms\n", evalReport.Metrics.Latency.TotalDuration)
-	fmt.Printf("Average Latency: %.2f ms\n", evalReport.Metrics.Latency.AvgDuration)
-	fmt.Printf("Median Duration: %d ms\n", evalReport.Metrics.Latency.MedianLatency)
-	fmt.Printf("Max Duration: %d ms\n", evalReport.Metrics.Latency.MaxDuration)
-	fmt.Printf("Min Duration: %d ms\n", evalReport.Metrics.Latency.MinDuration)
-	fmt.Println()
-
-	color.Cyan("Token Usage")
-	fmt.Printf("Total Tokens: %d\n", evalReport.Metrics.TokenUsage.TotalTokens)
-	fmt.Printf("Average Tokens: %.2f\n", evalReport.Metrics.TokenUsage.AvgTokens)
-	fmt.Printf("Median Tokens: %d\n", evalReport.Metrics.TokenUsage.MedianTokens)
-	fmt.Printf("Max Tokens: %d\n", evalReport.Metrics.TokenUsage.MaxTokens)
-	fmt.Printf("Min Tokens: %d\n", evalReport.Metrics.TokenUsage.MinTokens)
+	fmt.Fprintln(writer, color.CyanString("Accuracy"))
+	fmt.Fprintf(writer, "Accuracy: %.2f\n", evalReport.Metrics.Accuracy)
+	fmt.Fprintf(writer, "Precision: %.2f\n", evalReport.Metrics.Precision)
+	fmt.Fprintf(writer, "Recall: %.2f\n", evalReport.Metrics.Recall)
+	fmt.Fprintf(writer, "F1 Score: %.2f\n", evalReport.Metrics.F1)
+	fmt.Fprintln(writer)
+
+	fmt.Fprintln(writer, color.CyanString("Latency"))
+	fmt.Fprintf(writer, "Total Duration: %d ms\n", evalReport.Metrics.Latency.TotalDuration)
+	fmt.Fprintf(writer, "Average Latency: %.2f ms\n", evalReport.Metrics.Latency.AvgDuration)
+	fmt.Fprintf(writer, "Median Duration: %d ms\n", evalReport.Metrics.Latency.MedianLatency)
+	fmt.Fprintf(writer, "Max Duration: %d ms\n", evalReport.Metrics.Latency.MaxDuration)
+	fmt.Fprintf(writer, "Min Duration: %d ms\n", evalReport.Metrics.Latency.MinDuration)
+	fmt.Fprintf(writer,
+		"Percentiles: %s\n",
+		color.HiBlackString(
+			"p50 %d ms, p90 %d ms, p95 %d ms, p99 %d ms",
+			evalReport.Metrics.Latency.P50Duration,
+			evalReport.Metrics.Latency.P90Duration,
+			evalReport.Metrics.Latency.P95Duration,
+			evalReport.Metrics.Latency.P99Duration,
+		),
+	)
+	fmt.Fprintln(writer)
+
+	fmt.Fprintln(writer, color.CyanString("Token Usage"))
+	fmt.Fprintf(writer, "Total Tokens: %d\n", evalReport.Metrics.TokenUsage.TotalTokens)
+	fmt.Fprintf(writer, "Average Tokens: %.2f\n", evalReport.Metrics.TokenUsage.AvgTokens)
+	fmt.Fprintf(writer, "Median Tokens: %d\n", evalReport.Metrics.TokenUsage.MedianTokens)
+	fmt.Fprintf(writer, "Max Tokens: %d\n", evalReport.Metrics.TokenUsage.MaxTokens)
+	fmt.Fprintf(writer, "Min Tokens: %d\n", evalReport.Metrics.TokenUsage.MinTokens)
+
+	if evalReport.Metrics.Cost != nil {
+		fmt.Fprintln(writer)
+		printCostMetrics(writer, evalReport.Metrics.Cost)
+	}
+}
+
+func printCostMetrics(writer io.Writer, costMetrics *internal.CostMetrics) {
+	fmt.Fprintln(writer, color.CyanString("Cost"))
+	fmt.Fprintf(writer, "Estimated Cost: $%.4f\n", costMetrics.EstimatedCost)
+	fmt.Fprintf(writer, "Actual Cost: $%.4f\n", costMetrics.TotalCost)
+	if costMetrics.MaxCost > 0 {
+		fmt.Fprintf(writer, "Max Cost: $%.4f\n", costMetrics.MaxCost)
+	}
+
+	for _, deploymentCost := range costMetrics.Deployments {
+		costText := fmt.Sprintf("$%.4f", deploymentCost.Cost)
+		if !deploymentCost.Priced {
+			costText = "unknown"
+		}
+
+		fmt.Fprintf(writer,
+			"%s: %s %s\n",
+			deploymentCost.Deployment,
+			costText,
+			color.HiBlackString("(%d tokens)", deploymentCost.TokenUsage.TotalTokens),
+		)
+	}
 }
 
 // Function to load test data from JSON file
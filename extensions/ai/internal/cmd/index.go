@@ -20,6 +20,12 @@ type CreateIndexFlags struct {
 	PartitionCount     int
 }
 
+type indexResult struct {
+	Service  string `json:"service"`
+	Endpoint string `json:"endpoint"`
+	Index    string `json:"index"`
+}
+
 // Command to initialize `azd ai index` command group
 func newIndexCommand() *cobra.Command {
 	// Main `index` command
@@ -97,7 +103,17 @@ func newCreateIndexCommand() *cobra.Command {
 				return err
 			}
 
-			return nil
+			return output.Print(ctx, &indexResult{
+				Service:  extensionConfig.Search.Service,
+				Endpoint: extensionConfig.Search.Endpoint,
+				Index:    extensionConfig.Search.Index,
+			}, &output.TableOptions{
+				Columns: []output.Column{
+					{Heading: "Search Service", ValueTemplate: "{{.Service}}"},
+					{Heading: "Endpoint", ValueTemplate: "{{.Endpoint}}"},
+					{Heading: "Index", ValueTemplate: "{{.Index}}"},
+				},
+			})
 		},
 	}
 
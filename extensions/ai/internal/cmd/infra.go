@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal/infra"
+	"github.com/wbreza/azd-extensions/sdk/core/azd"
+	"github.com/wbreza/azd-extensions/sdk/core/environment"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
+	"github.com/wbreza/azd-extensions/sdk/ux"
+)
+
+type InfraGenerateFlags struct {
+	Force bool
+}
+
+func newInfraCommand() *cobra.Command {
+	infraCmd := &cobra.Command{
+		Use:   "infra",
+		Short: "Commands for managing infrastructure for AI resources",
+	}
+
+	infraCmd.AddCommand(newInfraGenerateCommand())
+
+	return infraCmd
+}
+
+func newInfraGenerateCommand() *cobra.Command {
+	flags := &InfraGenerateFlags{}
+
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generates Bicep infrastructure for the configured AI resources",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			header := output.CommandHeader{
+				Title:       "Generate infrastructure (azd ai infra generate)",
+				Description: "Generates Bicep modules for the configured AI resources so `azd provision` can recreate them in any environment.",
+			}
+			header.Print()
+
+			azdContext, err := ext.CurrentContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			azdProject, err := azdContext.Project(ctx)
+			if err != nil {
+				return &ext.ErrorWithSuggestion{
+					Err:        err,
+					Suggestion: fmt.Sprintf("Run %s to create an azd project.", color.CyanString("azd init")),
+				}
+			}
+
+			extensionConfig, err := internal.LoadExtensionConfig(ctx, azdContext)
+			if err != nil {
+				if errors.Is(err, internal.ErrNotFound) {
+					return &ext.ErrorWithSuggestion{
+						Err:        err,
+						Suggestion: fmt.Sprintf("Run %s to configure AI resources.", color.CyanString("azd ai setup")),
+					}
+				}
+
+				return err
+			}
+
+			azdCtx, err := azd.NewContext()
+			if err != nil {
+				return err
+			}
+
+			infraPath := filepath.Join(azdCtx.ProjectDirectory(), azdProject.Infra.Path)
+			aiModulePath := filepath.Join(infraPath, infra.AiModuleFile)
+
+			if _, err := os.Stat(aiModulePath); err == nil && !flags.Force {
+				overwriteConfirm := ux.NewConfirm(&ux.ConfirmOptions{
+					DefaultValue: ux.Ptr(true),
+					Message:      fmt.Sprintf("%s already exists, overwrite?", infra.AiModuleFile),
+				})
+
+				userConfirmed, err := overwriteConfirm.Ask()
+				if err != nil {
+					return err
+				}
+
+				if !*userConfirmed {
+					return ux.ErrCancelled
+				}
+			}
+
+			spinner := ux.NewSpinner(&ux.SpinnerOptions{
+				Text:        "Loading AI resources",
+				ClearOnStop: true,
+			})
+
+			if err := spinner.Start(ctx); err != nil {
+				return err
+			}
+
+			infraSpec, err := infra.LoadInfraSpec(ctx, azdContext, extensionConfig)
+			if err := spinner.Stop(ctx); err != nil {
+				return err
+			}
+
+			if err != nil {
+				return err
+			}
+
+			generatedFiles, err := infra.Generate(infraSpec, infraPath)
+			if err != nil {
+				return err
+			}
+
+			results := make([]*infra.GeneratedFile, len(generatedFiles))
+			for i, generatedFile := range generatedFiles {
+				relativePath, err := filepath.Rel(azdCtx.ProjectDirectory(), generatedFile.Path)
+				if err != nil {
+					relativePath = generatedFile.Path
+				}
+
+				results[i] = &infra.GeneratedFile{Path: relativePath, Status: generatedFile.Status}
+				fmt.Fprintf(output.MessageWriter(ctx), "%s: %s\n", generatedFile.Status, color.CyanString(relativePath))
+			}
+
+			hasAiModule, err := infra.ReferencesAiModule(infraPath)
+			if err != nil {
+				return err
+			}
+
+			if !hasAiModule {
+				fmt.Fprintln(output.MessageWriter(ctx))
+				fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("WARNING: %s does not reference %s.", infra.MainFile, infra.AiModuleFile))
+				fmt.Fprintf(output.MessageWriter(ctx), "Add the module to your existing %s and expose its outputs to provision the AI resources:\n\n", infra.MainFile)
+				fmt.Fprintln(output.MessageWriter(ctx), "  module ai 'ai.bicep' = {")
+				fmt.Fprintln(output.MessageWriter(ctx), "    name: 'ai'")
+				fmt.Fprintln(output.MessageWriter(ctx), "    scope: rg")
+				fmt.Fprintln(output.MessageWriter(ctx), "    params: {")
+				fmt.Fprintln(output.MessageWriter(ctx), "      resourceToken: resourceToken")
+				fmt.Fprintln(output.MessageWriter(ctx), "      principalId: principalId")
+				fmt.Fprintln(output.MessageWriter(ctx), "    }")
+				fmt.Fprintln(output.MessageWriter(ctx), "  }")
+			}
+
+			// Point the generated parameters at the existing resources so provisioning
+			// the current environment reuses them instead of creating new ones.
+			env, err := azdContext.Environment(ctx)
+			if err == nil {
+				for key, value := range infra.EnvironmentValues(infraSpec) {
+					if value != "" {
+						env.DotenvSet(key, value)
+					}
+				}
+
+				if env.GetSubscriptionId() == "" {
+					env.SetSubscriptionId(extensionConfig.Subscription)
+				}
+
+				if env.Getenv(environment.ResourceGroupEnvVarName) == "" {
+					env.DotenvSet(environment.ResourceGroupEnvVarName, extensionConfig.ResourceGroup)
+				}
+
+				if env.GetLocation() == "" {
+					env.SetLocation(infraSpec.Ai.Location)
+				}
+
+				if err := azdContext.SaveEnvironment(ctx, env); err != nil {
+					return err
+				}
+			} else {
+				fmt.Fprintln(output.MessageWriter(ctx))
+				fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("WARNING: No azd environment found, environment variables were not updated."))
+			}
+
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: Infrastructure generated for the AI resources."))
+			fmt.Fprintf(output.MessageWriter(ctx), "Run %s to provision the AI resources in any environment.\n", color.CyanString("azd provision"))
+
+			return output.Print(ctx, results, &output.TableOptions{
+				Columns: []output.Column{
+					{Heading: "Path", ValueTemplate: "{{.Path}}"},
+					{Heading: "Status", ValueTemplate: "{{.Status}}"},
+				},
+			})
+		},
+	}
+
+	generateCmd.Flags().BoolVarP(&flags.Force, "force", "f", false, "Overwrite existing files without confirmation")
+
+	return generateCmd
+}
@@ -13,6 +13,9 @@ func newModelCommand() *cobra.Command {
 	modelListCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all models",
+		Annotations: map[string]string{
+			noResultsAnnotation: "true",
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return nil
 		},
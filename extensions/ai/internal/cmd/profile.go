@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
+	"github.com/wbreza/azd-extensions/sdk/ux"
+)
+
+type profileResult struct {
+	Name   string                    `json:"name"`
+	Config *internal.ExtensionConfig `json:"config"`
+}
+
+var profileInfoTableOptions = &output.TableOptions{
+	Columns: []output.Column{
+		{Heading: "Name", ValueTemplate: "{{.Name}}"},
+		{Heading: "Active", ValueTemplate: "{{.Active}}"},
+	},
+}
+
+var profileResultTableOptions = &output.TableOptions{
+	Columns: []output.Column{
+		{Heading: "Profile", ValueTemplate: "{{.Name}}"},
+		{Heading: "AI Service", ValueTemplate: "{{.Config.Ai.Service}}"},
+		{Heading: "Chat Model", ValueTemplate: "{{.Config.Ai.Models.ChatCompletion}}"},
+		{Heading: "Embeddings Model", ValueTemplate: "{{.Config.Ai.Models.Embeddings}}"},
+		{Heading: "Search Index", ValueTemplate: "{{.Config.Search.Index}}"},
+	},
+}
+
+func newProfileCommand() *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Commands for managing named AI configuration profiles",
+	}
+
+	profileListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the AI configuration profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			azdContext, err := ext.CurrentContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			profiles, err := internal.ListProfiles(ctx, azdContext)
+			if err != nil {
+				return err
+			}
+
+			if output.IsStructured() {
+				return output.Print(ctx, profiles, profileInfoTableOptions)
+			}
+
+			for _, profile := range profiles {
+				if profile.Active {
+					fmt.Fprintf(output.MessageWriter(ctx), "* %s %s\n", color.CyanString(profile.Name), color.HiBlackString("(active)"))
+				} else {
+					fmt.Fprintf(output.MessageWriter(ctx), "  %s\n", profile.Name)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	profileUseCmd := &cobra.Command{
+		Use:   "use [profile-name]",
+		Short: "Set the active AI configuration profile",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			azdContext, err := ext.CurrentContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			var profileName string
+			if len(args) > 0 {
+				profileName = args[0]
+			} else {
+				profileName, err = promptProfile(ctx, azdContext, "Select the profile to use")
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := internal.UseProfile(ctx, azdContext, profileName); err != nil {
+				if errors.Is(err, internal.ErrProfileNotFound) {
+					return &ext.ErrorWithSuggestion{
+						Err:        err,
+						Suggestion: fmt.Sprintf("Run %s to create the profile", color.CyanString("azd ai profile create %s", profileName)),
+					}
+				}
+
+				return err
+			}
+
+			fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: Profile '%s' is now active.", profileName))
+
+			return output.Print(ctx, &internal.ProfileInfo{Name: profileName, Active: true}, profileInfoTableOptions)
+		},
+	}
+
+	type profileCreateFlags struct {
+		from string
+		use  bool
+	}
+
+	createFlags := &profileCreateFlags{}
+
+	profileCreateCmd := &cobra.Command{
+		Use:   "create <profile-name>",
+		Short: "Create a new AI configuration profile",
+		Long: "Create a new AI configuration profile from a copy of the active profile or the profile specified with --from. " +
+			"Run `azd ai setup --profile <profile-name>` to configure the resources of the new profile.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			profileName := args[0]
+
+			azdContext, err := ext.CurrentContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			sourceProfile := createFlags.from
+			if sourceProfile == "" {
+				sourceProfile, err = internal.ActiveProfile(ctx, azdContext)
+				if err != nil {
+					return err
+				}
+			}
+
+			extensionConfig, err := internal.LoadProfile(ctx, azdContext, sourceProfile)
+			if err != nil {
+				if !errors.Is(err, internal.ErrNotFound) {
+					return err
+				}
+
+				// An explicitly requested source profile must exist
+				if createFlags.from != "" {
+					return fmt.Errorf("%w: %s", internal.ErrProfileNotFound, createFlags.from)
+				}
+
+				extensionConfig = nil
+			}
+
+			if err := internal.CreateProfile(ctx, azdContext, profileName, extensionConfig); err != nil {
+				return err
+			}
+
+			if extensionConfig != nil {
+				fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: Profile '%s' created from profile '%s'.", profileName, sourceProfile))
+			} else {
+				fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: Profile '%s' created.", profileName))
+			}
+
+			if createFlags.use {
+				if err := internal.UseProfile(ctx, azdContext, profileName); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(output.MessageWriter(ctx), "Profile %s is now active.\n", color.CyanString(profileName))
+			}
+
+			fmt.Fprintf(output.MessageWriter(ctx), "Run %s to configure the profile.\n", color.CyanString("azd ai setup --profile %s", profileName))
+
+			return output.Print(ctx, &internal.ProfileInfo{Name: profileName, Active: createFlags.use}, profileInfoTableOptions)
+		},
+	}
+
+	profileCreateCmd.Flags().StringVar(&createFlags.from, "from", "", "Profile to copy the configuration from (default: active profile)")
+	profileCreateCmd.Flags().BoolVar(&createFlags.use, "use", false, "Set the new profile as the active profile")
+
+	type profileDeleteFlags struct {
+		force bool
+	}
+
+	deleteFlags := &profileDeleteFlags{}
+
+	profileDeleteCmd := &cobra.Command{
+		Use:   "delete <profile-name>",
+		Short: "Delete an AI configuration profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			profileName := args[0]
+
+			azdContext, err := ext.CurrentContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			if !deleteFlags.force {
+				confirmPrompt := ux.NewConfirm(&ux.ConfirmOptions{
+					Message:      fmt.Sprintf("Are you sure you want to delete the profile '%s'?", profileName),
+					DefaultValue: ux.Ptr(false),
+				})
+
+				confirmed, err := confirmPrompt.Ask()
+				if err != nil {
+					return err
+				}
+
+				if !*confirmed {
+					return ux.ErrCancelled
+				}
+			}
+
+			if err := internal.DeleteProfile(ctx, azdContext, profileName); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: Profile '%s' deleted.", profileName))
+
+			return output.Print(ctx, &internal.ProfileInfo{Name: profileName}, profileInfoTableOptions)
+		},
+	}
+
+	profileDeleteCmd.Flags().BoolVarP(&deleteFlags.force, "force", "f", false, "Force deletion without confirmation")
+
+	profileShowCmd := &cobra.Command{
+		Use:   "show [profile-name]",
+		Short: "Show the configuration of an AI configuration profile",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			azdContext, err := ext.CurrentContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			var profileName string
+			if len(args) > 0 {
+				profileName = args[0]
+			} else {
+				profileName, err = internal.ActiveProfile(ctx, azdContext)
+				if err != nil {
+					return err
+				}
+			}
+
+			extensionConfig, err := internal.LoadProfile(ctx, azdContext, profileName)
+			if err != nil {
+				if errors.Is(err, internal.ErrNotFound) {
+					return &ext.ErrorWithSuggestion{
+						Err:        fmt.Errorf("%w: %s", internal.ErrProfileNotFound, profileName),
+						Suggestion: fmt.Sprintf("Run %s to list the available profiles", color.CyanString("azd ai profile list")),
+					}
+				}
+
+				return err
+			}
+
+			if output.IsStructured() {
+				return output.Print(ctx, &profileResult{
+					Name:   profileName,
+					Config: extensionConfig,
+				}, profileResultTableOptions)
+			}
+
+			fmt.Fprintf(output.MessageWriter(ctx), "Profile: %s\n", color.CyanString(profileName))
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintf(output.MessageWriter(ctx), "Subscription ID: %s\n", color.CyanString(extensionConfig.Subscription))
+			fmt.Fprintf(output.MessageWriter(ctx), "Resource Group: %s\n", color.CyanString(extensionConfig.ResourceGroup))
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintf(output.MessageWriter(ctx), "AI Service: %s\n", color.CyanString(extensionConfig.Ai.Service))
+			fmt.Fprintf(output.MessageWriter(ctx), "AI Endpoint: %s\n", color.CyanString(extensionConfig.Ai.Endpoint))
+			fmt.Fprintf(output.MessageWriter(ctx), "Chat Model: %s\n", color.CyanString(extensionConfig.Ai.Models.ChatCompletion))
+			fmt.Fprintf(output.MessageWriter(ctx), "Embeddings Model: %s\n", color.CyanString(extensionConfig.Ai.Models.Embeddings))
+			fmt.Fprintf(output.MessageWriter(ctx), "Audio Model: %s\n", color.CyanString(extensionConfig.Ai.Models.Audio))
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintf(output.MessageWriter(ctx), "Storage Account: %s\n", color.CyanString(extensionConfig.Storage.Account))
+			fmt.Fprintf(output.MessageWriter(ctx), "Storage Container: %s\n", color.CyanString(extensionConfig.Storage.Container))
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintf(output.MessageWriter(ctx), "Search Service: %s\n", color.CyanString(extensionConfig.Search.Service))
+			fmt.Fprintf(output.MessageWriter(ctx), "Search Index: %s\n", color.CyanString(extensionConfig.Search.Index))
+
+			return nil
+		},
+	}
+
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+	profileCmd.AddCommand(profileShowCmd)
+
+	return profileCmd
+}
+
+// promptProfile prompts the user to select one of the existing profiles.
+func promptProfile(ctx context.Context, azdContext *ext.Context, message string) (string, error) {
+	profiles, err := internal.ListProfiles(ctx, azdContext)
+	if err != nil {
+		return "", err
+	}
+
+	choices := make([]string, len(profiles))
+	selectedIndex := 0
+
+	for i, profile := range profiles {
+		choices[i] = profile.Name
+		if profile.Active {
+			selectedIndex = i
+		}
+	}
+
+	profilePrompt := ux.NewSelect(&ux.SelectOptions{
+		Message:       message,
+		Allowed:       choices,
+		SelectedIndex: &selectedIndex,
+	})
+
+	choiceIndex, err := profilePrompt.Ask()
+	if err != nil {
+		return "", err
+	}
+
+	return choices[*choiceIndex], nil
+}
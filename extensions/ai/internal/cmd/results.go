@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
+	"github.com/wbreza/azd-extensions/sdk/ux"
+)
+
+// taskResult is the outcome of a task of a command, for example the upload of a single document.
+type taskResult struct {
+	Name       string `json:"name"`
+	State      string `json:"state"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+var taskResultsTableOptions = &output.TableOptions{
+	Columns: []output.Column{
+		{Heading: "Name", ValueTemplate: "{{.Name}}"},
+		{Heading: "State", ValueTemplate: "{{.State}}"},
+		{Heading: "Duration (ms)", ValueTemplate: "{{.DurationMs}}"},
+		{Heading: "Error", ValueTemplate: "{{.Error}}"},
+	},
+}
+
+// newTaskResults converts the results of a task list, tasks are identified by their name.
+func newTaskResults(taskList *ux.TaskList) []*taskResult {
+	taskResults := taskList.Results()
+	results := make([]*taskResult, len(taskResults))
+
+	for i, result := range taskResults {
+		results[i] = &taskResult{
+			Name:       result.Name,
+			State:      result.State.String(),
+			DurationMs: result.Duration.Milliseconds(),
+		}
+
+		if result.Error != nil {
+			results[i].Error = result.Error.Error()
+		}
+	}
+
+	return results
+}
+
+// printTaskResults writes the results of the task list in the structured output format.
+func printTaskResults(ctx context.Context, taskList *ux.TaskList) error {
+	return output.Print(ctx, newTaskResults(taskList), taskResultsTableOptions)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/spf13/cobra"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azure-sdk-for-go/sdk/data/azsearchindex"
+)
+
+// retrievalFlags are the flags shared by commands that include search results in the prompt.
+type retrievalFlags struct {
+	top        int
+	candidates int
+	dedupe     bool
+	mmr        bool
+	mmrLambda  float64
+	rerank     bool
+}
+
+func addRetrievalFlags(cmd *cobra.Command, flags *retrievalFlags) {
+	cmd.Flags().IntVar(&flags.top, "top", internal.DefaultRetrievalTop, "Number of search results included in the prompt")
+	cmd.Flags().IntVar(&flags.candidates, "candidates", 0, "Number of search results fetched before de-duplication, reranking and MMR (default: 4x --top when enabled)")
+	cmd.Flags().BoolVar(&flags.dedupe, "dedupe", false, "Keep only the best search result of each source document")
+	cmd.Flags().BoolVar(&flags.mmr, "mmr", false, "Select diverse search results with Maximal Marginal Relevance")
+	cmd.Flags().Float64Var(&flags.mmrLambda, "mmr-lambda", internal.DefaultMmrLambda, "Balance between relevance (1) and diversity (0) for MMR")
+	cmd.Flags().BoolVar(&flags.rerank, "rerank", false, "Rerank search results by scoring their relevance with the chat completion model")
+}
+
+// retrievalOptions applies the flags set on the command line on top of the configured retrieval options.
+func retrievalOptions(cmd *cobra.Command, flags *retrievalFlags, configured internal.RetrievalOptions) internal.RetrievalOptions {
+	options := configured
+
+	if cmd.Flags().Changed("top") {
+		options.Top = flags.top
+	}
+
+	if cmd.Flags().Changed("candidates") {
+		options.Candidates = flags.candidates
+	}
+
+	if cmd.Flags().Changed("dedupe") {
+		options.Dedupe = flags.dedupe
+	}
+
+	if cmd.Flags().Changed("mmr") {
+		options.Mmr = flags.mmr
+	}
+
+	if cmd.Flags().Changed("mmr-lambda") {
+		options.MmrLambda = flags.mmrLambda
+	}
+
+	if cmd.Flags().Changed("rerank") {
+		options.Rerank = flags.rerank
+	}
+
+	return options.WithDefaults()
+}
+
+// newSearchRetriever creates the retriever for the configured search index with the options of the command.
+func newSearchRetriever(
+	cmd *cobra.Command,
+	flags *retrievalFlags,
+	azdContext *ext.Context,
+	extensionConfig *internal.ExtensionConfig,
+	openAiClient *azopenai.Client,
+	credential azcore.TokenCredential,
+	azClientOptions *azcore.ClientOptions,
+) (*internal.Retriever, error) {
+	searchClient, err := azsearchindex.NewDocumentsClient(
+		extensionConfig.Search.Endpoint,
+		extensionConfig.Search.Index,
+		credential,
+		azClientOptions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	retriever := internal.NewRetriever(
+		searchClient,
+		openAiClient,
+		internal.DocumentSearchFields,
+		retrievalOptions(cmd, flags, extensionConfig.Retrieval.Chat),
+	)
+
+	if retriever.Options().Rerank {
+		promptTemplates, err := internal.NewProjectPromptTemplateStore(azdContext)
+		if err != nil {
+			return nil, err
+		}
+
+		rerankTemplate, err := promptTemplates.Resolve(internal.RerankPromptTemplate, extensionConfig.Prompts.Rerank)
+		if err != nil {
+			return nil, err
+		}
+
+		retriever.SetReranker(extensionConfig.Ai.Models.ChatCompletion, rerankTemplate)
+	}
+
+	return retriever, nil
+}
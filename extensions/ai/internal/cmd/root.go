@@ -1,16 +1,63 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal"
 	"github.com/wbreza/azd-extensions/sdk/ext/debug"
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
+	"github.com/wbreza/azd-extensions/sdk/ux"
 )
 
+// noResultsAnnotation marks the commands without results, they only support the none output format.
+const noResultsAnnotation = "azd.ai/no-results"
+
+// outputPathAnnotation is the flag that replaced the path accepted by `--output` before it became the output format.
+// `--output <path>` is still accepted for these commands so existing `up` workflows keep working.
+const outputPathAnnotation = "azd.ai/output-path"
+
 func NewRootCommand() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "azd ai <group> [options]",
 		Short: "A CLI for managing AI models and services",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			debug.WaitForDebugger()
+
+			if screenReader, _ := cmd.Flags().GetBool("screen-reader"); screenReader {
+				ux.SetRenderMode(ux.ScreenReaderRenderMode)
+			}
+
+			outputValue, _ := cmd.Flags().GetString("output")
+			format, err := output.ParseFormat(outputValue)
+			if err != nil {
+				pathFlag, has := cmd.Annotations[outputPathAnnotation]
+				if !has {
+					return err
+				}
+
+				if err := useDeprecatedOutputPath(cmd, pathFlag, outputValue); err != nil {
+					return err
+				}
+
+				format = output.NoneFormat
+			}
+
+			if format != output.NoneFormat && cmd.Annotations[noResultsAnnotation] == "true" {
+				commandName := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+				return fmt.Errorf("%w '%s', 'azd ai %s' has no results to output", output.ErrUnsupportedFormat, format, commandName)
+			}
+
+			output.SetFormat(format)
+			cmd.SetContext(output.WithWriters(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr()))
+
+			if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+				cmd.SetContext(internal.WithProfile(cmd.Context(), profile))
+			}
+
+			return nil
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
@@ -24,9 +71,34 @@ func NewRootCommand() *cobra.Command {
 	rootCmd.AddCommand(newEmbeddingCommand())
 	rootCmd.AddCommand(newIndexCommand())
 	rootCmd.AddCommand(newEvaluateCommand())
+	rootCmd.AddCommand(newServeCommand())
+	rootCmd.AddCommand(newInfraCommand())
+	rootCmd.AddCommand(newDoctorCommand())
+	rootCmd.AddCommand(newProfileCommand())
 	rootCmd.AddCommand(newVersionCommand())
 
 	rootCmd.PersistentFlags().Bool("debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().StringP("output", "o", string(output.NoneFormat), "The output format (none, json, table)")
+	rootCmd.PersistentFlags().String("profile", "", "AI configuration profile to use instead of the active profile")
+	rootCmd.PersistentFlags().Bool("screen-reader", false, "Write state changes as plain lines without animations for screen readers")
 
 	return rootCmd
 }
+
+// useDeprecatedOutputPath sets the path flag of the command to the path passed with `--output`.
+func useDeprecatedOutputPath(cmd *cobra.Command, pathFlag string, path string) error {
+	if cmd.Flags().Changed(pathFlag) {
+		return fmt.Errorf("--output and --%s can't be used together, use --%s for the path", pathFlag, pathFlag)
+	}
+
+	if err := cmd.Flags().Set(pathFlag, path); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.ErrOrStderr(), color.YellowString(
+		"WARNING: '--output <path>' is deprecated and will be removed in a future release, use '--%s <path>' instead.",
+		pathFlag,
+	))
+
+	return nil
+}
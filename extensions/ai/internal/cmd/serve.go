@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal/server"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
+)
+
+type serveFlags struct {
+	host          string
+	port          int
+	modelName     string
+	systemMessage string
+	prompt        string
+	noSearch      bool
+	quiet         bool
+	corsOrigins   []string
+	retrieval     retrievalFlags
+}
+
+func newServeCommand() *cobra.Command {
+	flags := &serveFlags{}
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start a local OpenAI compatible server for the configured AI services",
+		Annotations: map[string]string{
+			noResultsAnnotation: "true",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			header := output.CommandHeader{
+				Title:       "Start a local OpenAI compatible server (azd ai serve)",
+				Description: "Serve the chat completion and embeddings models with the same retrieval and prompt templates as `azd ai chat`.",
+			}
+			header.Print()
+
+			ctx := cmd.Context()
+
+			azdContext, err := ext.CurrentContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			azureContext, err := azdContext.AzureContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			var azClientOptions *azcore.ClientOptions
+
+			azdContext.Invoke(func(options *azcore.ClientOptions) error {
+				azClientOptions = options
+				return nil
+			})
+
+			credential, err := azdContext.Credential()
+			if err != nil {
+				return err
+			}
+
+			extensionConfig, err := internal.LoadExtensionConfig(ctx, azdContext)
+			if err != nil {
+				return &ext.ErrorWithSuggestion{
+					Err:        err,
+					Suggestion: fmt.Sprintf("Run %s to configure AI resources.", color.CyanString("azd ai setup")),
+				}
+			}
+
+			if flags.modelName != "" {
+				extensionConfig.Ai.Models.ChatCompletion = flags.modelName
+			}
+
+			if extensionConfig.Ai.Models.ChatCompletion == "" {
+				fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("No chat completion model was found. Please select or create a chat completion model."))
+
+				chatDeployment, err := internal.PromptModelDeployment(ctx, azdContext, azureContext, &internal.PromptModelDeploymentOptions{
+					Capabilities: []string{
+						"chatCompletion",
+					},
+				})
+				if err != nil {
+					if errors.Is(err, internal.ErrNoModelDeployments) {
+						return &ext.ErrorWithSuggestion{
+							Err:        err,
+							Suggestion: fmt.Sprintf("Run %s to create a model deployment", color.CyanString("azd ai model deployment create")),
+						}
+					}
+					return err
+				}
+
+				extensionConfig.Ai.Models.ChatCompletion = *chatDeployment.Name
+				fmt.Fprintln(output.MessageWriter(ctx))
+
+				if err := internal.SaveExtensionConfig(ctx, azdContext, extensionConfig); err != nil {
+					return err
+				}
+			}
+
+			promptTemplates, err := internal.NewProjectPromptTemplateStore(azdContext)
+			if err != nil {
+				return err
+			}
+
+			chatTemplate, err := promptTemplates.Resolve(internal.ChatPromptTemplate, flags.prompt, extensionConfig.Prompts.Chat)
+			if err != nil {
+				return err
+			}
+
+			systemMessage := flags.systemMessage
+			if systemMessage == "" {
+				systemMessage, err = chatTemplate.SystemMessage()
+				if err != nil {
+					return err
+				}
+			}
+
+			openAiClient, err := azopenai.NewClient(extensionConfig.Ai.Endpoint, credential, &azopenai.ClientOptions{ClientOptions: *azClientOptions})
+			if err != nil {
+				return err
+			}
+
+			hasVectorSearch := !flags.noSearch &&
+				extensionConfig.Search.Service != "" &&
+				extensionConfig.Search.Index != "" &&
+				extensionConfig.Ai.Models.Embeddings != ""
+
+			var retriever *internal.Retriever
+			if hasVectorSearch {
+				retriever, err = newSearchRetriever(cmd, &flags.retrieval, azdContext, extensionConfig, openAiClient, credential, azClientOptions)
+				if err != nil {
+					return err
+				}
+			}
+
+			ragFlow := internal.NewRagFlow(openAiClient, chatTemplate, extensionConfig.Ai.Models.Embeddings, retriever)
+
+			serverOptions := server.Options{
+				Host:                flags.host,
+				Port:                flags.port,
+				ChatCompletionModel: extensionConfig.Ai.Models.ChatCompletion,
+				EmbeddingModel:      extensionConfig.Ai.Models.Embeddings,
+				SystemMessage:       systemMessage,
+				CorsOrigins:         flags.corsOrigins,
+			}
+
+			if !flags.quiet {
+				serverOptions.LogWriter = output.MessageWriter(ctx)
+			}
+
+			openAiServer := server.New(openAiClient, ragFlow, serverOptions)
+			baseUrl := fmt.Sprintf("http://%s/v1", openAiServer.Address())
+
+			fmt.Fprintf(output.MessageWriter(ctx), "AI Service: %s %s\n", color.CyanString(extensionConfig.Ai.Service), color.HiBlackString("(%s)", extensionConfig.ResourceGroup))
+			fmt.Fprintf(output.MessageWriter(ctx), "Chat Model: %s\n", color.CyanString(extensionConfig.Ai.Models.ChatCompletion))
+			if extensionConfig.Ai.Models.Embeddings != "" {
+				fmt.Fprintf(output.MessageWriter(ctx), "Embeddings Model: %s\n", color.CyanString(extensionConfig.Ai.Models.Embeddings))
+			}
+			fmt.Fprintln(output.MessageWriter(ctx))
+			if hasVectorSearch {
+				fmt.Fprintf(output.MessageWriter(ctx), "Search Service: %s %s\n", color.CyanString(extensionConfig.Search.Service), color.HiBlackString("(%s)", extensionConfig.Search.Index))
+				fmt.Fprintf(output.MessageWriter(ctx), "Retrieval: %s\n", color.CyanString(retriever.Options().String()))
+				fmt.Fprintln(output.MessageWriter(ctx))
+			}
+			fmt.Fprintf(output.MessageWriter(ctx), "Prompt Template: %s %s\n", color.CyanString(chatTemplate.Name), color.HiBlackString("(Version: %s)", chatTemplate.Version))
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintf(output.MessageWriter(ctx), "Chat Completions: %s\n", color.CyanString("POST %s/chat/completions", baseUrl))
+			if extensionConfig.Ai.Models.Embeddings != "" {
+				fmt.Fprintf(output.MessageWriter(ctx), "Embeddings: %s\n", color.CyanString("POST %s/embeddings", baseUrl))
+			}
+			fmt.Fprintf(output.MessageWriter(ctx), "Models: %s\n", color.CyanString("GET %s/models", baseUrl))
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintf(output.MessageWriter(ctx), "%s\n", color.HiBlackString("Set the base URL of your OpenAI client to %s. Press Ctrl+C to stop the server.", baseUrl))
+			fmt.Fprintln(output.MessageWriter(ctx))
+
+			serveCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if err := openAiServer.ListenAndServe(serveCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return &ext.ErrorWithSuggestion{
+					Err:        fmt.Errorf("failed starting server on %s: %w", openAiServer.Address(), err),
+					Suggestion: fmt.Sprintf("Use %s to choose a different port", color.CyanString("--port")),
+				}
+			}
+
+			if errors.Is(serveCtx.Err(), context.Canceled) {
+				fmt.Fprintln(output.MessageWriter(ctx))
+				fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("Server stopped."))
+			}
+
+			return nil
+		},
+	}
+
+	serveCmd.Flags().StringVar(&flags.host, "host", "localhost", "Host name or IP address the server listens on")
+	serveCmd.Flags().IntVar(&flags.port, "port", 8080, "Port the server listens on")
+	serveCmd.Flags().StringVar(&flags.modelName, "model", "", "Chat completion model deployment to serve")
+	serveCmd.Flags().StringVar(&flags.systemMessage, "system-message", "", "System message used when requests don't include one")
+	serveCmd.Flags().StringVar(&flags.prompt, "prompt", "", "Name of the prompt template in the prompts folder, optionally pinned with <name>@<version>")
+	serveCmd.Flags().BoolVar(&flags.noSearch, "no-search", false, "Don't include search results in the chat completion prompts")
+	serveCmd.Flags().BoolVar(&flags.quiet, "quiet", false, "Disable request logging")
+	serveCmd.Flags().StringArrayVar(&flags.corsOrigins, "cors-origin", nil, "Browser origin allowed to call the server, such as http://localhost:3000 (repeatable)")
+	addRetrievalFlags(serveCmd, &flags.retrieval)
+
+	return serveCmd
+}
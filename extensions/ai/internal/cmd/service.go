@@ -1,12 +1,11 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/spf13/cobra"
 	"github.com/wbreza/azd-extensions/extensions/ai/internal"
 	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
 )
 
 type serviceSetFlags struct {
@@ -16,6 +15,31 @@ type serviceSetFlags struct {
 	modelName     string
 }
 
+type serviceResult struct {
+	Service       string `json:"service"`
+	Endpoint      string `json:"endpoint"`
+	ResourceGroup string `json:"resourceGroup"`
+	Subscription  string `json:"subscription"`
+}
+
+var serviceResultTableOptions = &output.TableOptions{
+	Columns: []output.Column{
+		{Heading: "Service", ValueTemplate: "{{.Service}}"},
+		{Heading: "Endpoint", ValueTemplate: "{{.Endpoint}}"},
+		{Heading: "Resource Group", ValueTemplate: "{{.ResourceGroup}}"},
+		{Heading: "Subscription ID", ValueTemplate: "{{.Subscription}}"},
+	},
+}
+
+func newServiceResult(extensionConfig *internal.ExtensionConfig) *serviceResult {
+	return &serviceResult{
+		Service:       extensionConfig.Ai.Service,
+		Endpoint:      extensionConfig.Ai.Endpoint,
+		ResourceGroup: extensionConfig.ResourceGroup,
+		Subscription:  extensionConfig.Subscription,
+	}
+}
+
 func newServiceCommand() *cobra.Command {
 	serviceCmd := &cobra.Command{
 		Use:   "service",
@@ -75,7 +99,7 @@ func newServiceCommand() *cobra.Command {
 				return err
 			}
 
-			return nil
+			return output.Print(ctx, newServiceResult(aiConfig), serviceResultTableOptions)
 		},
 	}
 
@@ -99,11 +123,11 @@ func newServiceCommand() *cobra.Command {
 				return err
 			}
 
-			fmt.Printf("Service: %s\n", aiConfig.Ai.Service)
-			fmt.Printf("Resource Group: %s\n", aiConfig.ResourceGroup)
-			fmt.Printf("Subscription ID: %s\n", aiConfig.Subscription)
+			if output.IsStructured() {
+				return output.Print(ctx, newServiceResult(aiConfig), serviceResultTableOptions)
+			}
 
-			return nil
+			return output.WriteTable(output.ResultWriter(ctx), newServiceResult(aiConfig), serviceResultTableOptions)
 		},
 	}
 
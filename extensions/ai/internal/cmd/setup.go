@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -35,7 +37,9 @@ var defaultUpWorkflow = &contracts.Workflow{
 
 // Flag structs for the azd ai document commands
 type SetupFlags struct {
-	Reset bool
+	Reset    bool
+	File     string
+	NoPrompt bool
 }
 
 // Command to initialize `azd ai document` command group
@@ -54,6 +58,20 @@ func newSetupCommand() *cobra.Command {
 
 			ctx := cmd.Context()
 
+			if flags.NoPrompt && flags.File == "" {
+				flags.File = internal.DefaultSetupSpecFile
+			}
+
+			if flags.File != "" {
+				if err := runSetupFromSpec(ctx, flags); err != nil {
+					return err
+				}
+
+				fmt.Fprintln(output.MessageWriter(ctx))
+				fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: AI project setup completed successfully"))
+				return printSetupResult(ctx)
+			}
+
 			azdContext, err := ext.CurrentContext(ctx)
 			if err != nil {
 				return err
@@ -64,8 +82,8 @@ func newSetupCommand() *cobra.Command {
 				return err
 			}
 
-			fmt.Println("Let's get started by setting up your AI project")
-			fmt.Println()
+			fmt.Fprintln(output.MessageWriter(ctx), "Let's get started by setting up your AI project")
+			fmt.Fprintln(output.MessageWriter(ctx))
 
 			var extensionConfig *internal.ExtensionConfig
 
@@ -98,8 +116,8 @@ func newSetupCommand() *cobra.Command {
 					DefaultValue: to.Ptr(true),
 				})
 
-				fmt.Println()
-				color.Yellow("A chat completion model was not found. Lets get that setup for you.")
+				fmt.Fprintln(output.MessageWriter(ctx))
+				fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("A chat completion model was not found. Lets get that setup for you."))
 
 				userChatConfirmed, err := chatConfirm.Ask()
 				if err != nil {
@@ -130,8 +148,8 @@ func newSetupCommand() *cobra.Command {
 
 			if *userCustomDataConfirmed {
 				if extensionConfig.Storage.Account == "" || extensionConfig.Storage.Container == "" {
-					fmt.Println()
-					color.Yellow("A storage account was not found. Lets get that setup for you.")
+					fmt.Fprintln(output.MessageWriter(ctx))
+					fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("A storage account was not found. Lets get that setup for you."))
 
 					storageAccount, err := internal.PromptStorageAccount(ctx, azdContext, azureContext)
 					if err != nil {
@@ -150,8 +168,8 @@ func newSetupCommand() *cobra.Command {
 				}
 
 				if extensionConfig.Search.Service == "" || extensionConfig.Search.Index == "" {
-					fmt.Println()
-					color.Yellow("An AI Search service was not found. Lets get that setup for you.")
+					fmt.Fprintln(output.MessageWriter(ctx))
+					fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("An AI Search service was not found. Lets get that setup for you."))
 
 					searchService, err := internal.PromptSearchService(ctx, azdContext, azureContext)
 					if err != nil {
@@ -169,49 +187,15 @@ func newSetupCommand() *cobra.Command {
 					extensionConfig.Search.Index = *searchIndex.Name
 				}
 
-				prepDataConfirm := ux.NewConfirm(&ux.ConfirmOptions{
-					Message:      "Would you like to prep documents for your AI project?",
-					DefaultValue: to.Ptr(true),
-				})
-
-				userPrepDataConfirmed, err := prepDataConfirm.Ask()
+				prepAnswers, err := promptDocumentPrep()
 				if err != nil {
 					return err
 				}
 
-				if *userPrepDataConfirmed {
-					sourcePrompt := ux.NewPrompt(&ux.PromptOptions{
-						Message:      "Enter the path to the source data",
-						DefaultValue: "./data",
-						Required:     true,
-					})
-
-					userSourcePath, err := sourcePrompt.Ask()
-					if err != nil {
-						return err
-					}
-
-					whichFilesPrompt := ux.NewPrompt(&ux.PromptOptions{
-						Message:      "Which files should be included?",
-						DefaultValue: "*",
-						Required:     true,
-					})
-
-					userFilePattern, err := whichFilesPrompt.Ask()
-					if err != nil {
-						return err
-					}
-
-					embeddingsOutputPrompt := ux.NewPrompt(&ux.PromptOptions{
-						Message:      "Enter the path for the embeddings output",
-						DefaultValue: "./embeddings",
-						Required:     true,
-					})
-
-					userOutputPath, err := embeddingsOutputPrompt.Ask()
-					if err != nil {
-						return err
-					}
+				if prepAnswers.PrepDocuments {
+					userSourcePath := prepAnswers.SourcePath
+					userFilePattern := prepAnswers.FilePattern
+					userOutputPath := prepAnswers.OutputPath
 
 					cwd, err := os.Getwd()
 					if err != nil {
@@ -258,8 +242,8 @@ func newSetupCommand() *cobra.Command {
 					}
 
 					if extensionConfig.Ai.Models.Embeddings == "" {
-						fmt.Println()
-						color.Yellow("A text embedding model was not found. Lets get that setup for you.")
+						fmt.Fprintln(output.MessageWriter(ctx))
+						fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("A text embedding model was not found. Lets get that setup for you."))
 
 						embeddingModelDeployment, err := internal.PromptModelDeployment(ctx, azdContext, azureContext, &internal.PromptModelDeploymentOptions{
 							Capabilities: []string{"embeddings"},
@@ -271,20 +255,37 @@ func newSetupCommand() *cobra.Command {
 						extensionConfig.Ai.Models.Embeddings = *embeddingModelDeployment.Name
 					}
 
-					fmt.Println()
-					fmt.Printf("AI Service: %s\n", color.CyanString(extensionConfig.Ai.Service))
-					fmt.Printf("Chat Completion Model: %s\n", color.CyanString(extensionConfig.Ai.Models.ChatCompletion))
-					fmt.Println()
-					fmt.Printf("Storage Account: %s\n", color.CyanString(extensionConfig.Storage.Account))
-					fmt.Printf("Storage Container: %s\n", color.CyanString(extensionConfig.Storage.Container))
-					fmt.Println()
-					fmt.Printf("Search Service: %s\n", color.CyanString(extensionConfig.Search.Service))
-					fmt.Printf("Search Index: %s\n", color.CyanString(extensionConfig.Search.Index))
-					fmt.Printf("Embeddings Model: %s\n", color.CyanString(extensionConfig.Ai.Models.Embeddings))
-					fmt.Println()
-					fmt.Printf("Source Data: %s\n", color.CyanString(absSourcePath))
-					fmt.Printf("Embeddings Output: %s\n", color.CyanString(absOutputPath))
-					fmt.Println()
+					if extensionConfig.Ai.Models.Audio == "" && slices.ContainsFunc(matchingFiles, docprep.IsAudioFile) {
+						fmt.Fprintln(output.MessageWriter(ctx))
+						fmt.Fprintln(output.MessageWriter(ctx), color.YellowString("An audio transcription model was not found. Lets get that setup for you."))
+
+						audioModelDeployment, err := internal.PromptModelDeployment(ctx, azdContext, azureContext, &internal.PromptModelDeploymentOptions{
+							Capabilities: []string{"audio"},
+						})
+						if err != nil {
+							return err
+						}
+
+						extensionConfig.Ai.Models.Audio = *audioModelDeployment.Name
+					}
+
+					fmt.Fprintln(output.MessageWriter(ctx))
+					fmt.Fprintf(output.MessageWriter(ctx), "AI Service: %s\n", color.CyanString(extensionConfig.Ai.Service))
+					fmt.Fprintf(output.MessageWriter(ctx), "Chat Completion Model: %s\n", color.CyanString(extensionConfig.Ai.Models.ChatCompletion))
+					fmt.Fprintln(output.MessageWriter(ctx))
+					fmt.Fprintf(output.MessageWriter(ctx), "Storage Account: %s\n", color.CyanString(extensionConfig.Storage.Account))
+					fmt.Fprintf(output.MessageWriter(ctx), "Storage Container: %s\n", color.CyanString(extensionConfig.Storage.Container))
+					fmt.Fprintln(output.MessageWriter(ctx))
+					fmt.Fprintf(output.MessageWriter(ctx), "Search Service: %s\n", color.CyanString(extensionConfig.Search.Service))
+					fmt.Fprintf(output.MessageWriter(ctx), "Search Index: %s\n", color.CyanString(extensionConfig.Search.Index))
+					fmt.Fprintf(output.MessageWriter(ctx), "Embeddings Model: %s\n", color.CyanString(extensionConfig.Ai.Models.Embeddings))
+					if extensionConfig.Ai.Models.Audio != "" {
+						fmt.Fprintf(output.MessageWriter(ctx), "Audio Model: %s\n", color.CyanString(extensionConfig.Ai.Models.Audio))
+					}
+					fmt.Fprintln(output.MessageWriter(ctx))
+					fmt.Fprintf(output.MessageWriter(ctx), "Source Data: %s\n", color.CyanString(absSourcePath))
+					fmt.Fprintf(output.MessageWriter(ctx), "Embeddings Output: %s\n", color.CyanString(absOutputPath))
+					fmt.Fprintln(output.MessageWriter(ctx))
 
 					readyConfirm := ux.NewConfirm(&ux.ConfirmOptions{
 						Message:      "Do you want to run this process now?",
@@ -308,68 +309,7 @@ func newSetupCommand() *cobra.Command {
 							return err
 						}
 
-						err = ux.NewTaskList(nil).
-							AddTask(ux.TaskOptions{
-								Title: "Uploading documents",
-								Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
-									setProgress(fmt.Sprintf("%d/%d", 0, len(matchingFiles)))
-
-									for index, file := range matchingFiles {
-										relativePath, err := filepath.Rel(cwd, file)
-										if err != nil {
-											return ux.Error, err
-										}
-
-										if docPrepService.Upload(ctx, file, relativePath); err != nil {
-											return ux.Error, common.NewDetailedError("Failed to upload document", err)
-										}
-
-										setProgress(fmt.Sprintf("%d/%d", index+1, len(matchingFiles)))
-									}
-
-									return ux.Success, nil
-								},
-							}).
-							AddTask(ux.TaskOptions{
-								Title: "Generating text embeddings",
-								Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
-									setProgress(fmt.Sprintf("%d/%d", 0, len(matchingFiles)))
-
-									for index, file := range matchingFiles {
-										if _, err := docPrepService.GenerateEmbedding(ctx, file, absOutputPath); err != nil {
-											return ux.Error, common.NewDetailedError("Failed generating embedding", err)
-										}
-
-										setProgress(fmt.Sprintf("%d/%d", index+1, len(matchingFiles)))
-									}
-
-									return ux.Success, nil
-								},
-							}).
-							AddTask(ux.TaskOptions{
-								Title: "Populating search index",
-								Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
-									embeddingDocuments, err := getMatchingFiles(absOutputPath, "*.json", true)
-									if err != nil {
-										return ux.Error, common.NewDetailedError("Failed fetching embedding documents", err)
-									}
-
-									setProgress(fmt.Sprintf("%d/%d", 0, len(embeddingDocuments)))
-
-									for index, file := range embeddingDocuments {
-										if docPrepService.IngestEmbedding(ctx, file); err != nil {
-											return ux.Error, common.NewDetailedError("Failed ingesting embedding", err)
-										}
-
-										setProgress(fmt.Sprintf("%d/%d", index+1, len(embeddingDocuments)))
-									}
-
-									return ux.Success, nil
-								},
-							}).
-							Run()
-
-						if err != nil {
+						if err := runDocumentPrep(ctx, docPrepService, cwd, matchingFiles, absOutputPath); err != nil {
 							return err
 						}
 					}
@@ -385,109 +325,352 @@ func newSetupCommand() *cobra.Command {
 					}
 
 					if *userUpdateWorkflowConfirmed {
-						azdProject, err := azdContext.Project(ctx)
-						if err != nil {
-							return err
+						documentSources := []*internal.DocumentSourceSpec{
+							{
+								Source:  userSourcePath,
+								Pattern: userFilePattern,
+								Output:  userOutputPath,
+							},
 						}
 
-						upWorkflow, has := azdProject.Workflows["up"]
-						if !has {
-							upWorkflow = defaultUpWorkflow
+						if err := updateUpWorkflow(ctx, azdContext, documentSources); err != nil {
+							return err
 						}
+					}
+				}
+			}
 
-						beforeSteps := []*contracts.Step{}
-						afterSteps := []*contracts.Step{}
-						aiSteps := []*contracts.Step{}
-						foundProvision := false
+			fmt.Fprintln(output.MessageWriter(ctx))
+			fmt.Fprintln(output.MessageWriter(ctx), color.GreenString("SUCCESS: AI project setup completed successfully"))
+			fmt.Fprintf(output.MessageWriter(ctx), "Run %s to start chatting with your AI model\n", color.CyanString("azd ai chat"))
 
-						for _, step := range upWorkflow.Steps {
-							if step.AzdCommand.Args[0] == "ai" {
-								continue
-							}
+			return printSetupResult(ctx)
+		},
+	}
 
-							if foundProvision {
-								afterSteps = append(afterSteps, step)
-							} else {
-								beforeSteps = append(beforeSteps, step)
-							}
+	setupCmd.Flags().BoolVar(&flags.Reset, "reset", false, "Resets the AI project configuration")
+	setupCmd.Flags().StringVarP(&flags.File, "file", "f", "", "Path to a setup spec file declaring the AI project resources (e.g. ai.yaml)")
+	setupCmd.Flags().BoolVar(&flags.NoPrompt, "no-prompt", false, "Apply the setup spec without prompting (defaults to ai.yaml)")
 
-							if slices.Contains(step.AzdCommand.Args, "provision") {
-								foundProvision = true
-							}
-						}
+	setupCmd.MarkFlagsMutuallyExclusive("reset", "file")
 
-						aiSteps = append(aiSteps, &contracts.Step{
-							AzdCommand: contracts.Command{
-								Args: []string{
-									"ai", "document", "upload",
-									"--source", userSourcePath,
-									"--pattern", userFilePattern,
-									// "--account", extensionConfig.Storage.Account,
-									// "--container", extensionConfig.Storage.Container,
-									"--force",
-								},
-							},
-						})
+	return setupCmd
+}
 
-						aiSteps = append(aiSteps, &contracts.Step{
-							AzdCommand: contracts.Command{
-								Args: []string{
-									"ai", "embedding", "generate",
-									"--source", userSourcePath,
-									"--pattern", userFilePattern,
-									"--output", userOutputPath,
-									// "--service", extensionConfig.Ai.Service,
-									// "--embedding-model", extensionConfig.Ai.Models.Embeddings,
-									// "--chat-completion-model", extensionConfig.Ai.Models.ChatCompletion,
-									"--force",
-								},
-							},
-						})
+// printSetupResult writes the profile configured by the setup in the structured output format.
+func printSetupResult(ctx context.Context) error {
+	if !output.IsStructured() {
+		return nil
+	}
 
-						aiSteps = append(aiSteps, &contracts.Step{
-							AzdCommand: contracts.Command{
-								Args: []string{
-									"ai", "embedding", "ingest",
-									"--source", userOutputPath,
-									// "--service", extensionConfig.Search.Service,
-									// "--index", extensionConfig.Search.Index,
-									"--force",
-								},
-							},
-						})
+	azdContext, err := ext.CurrentContext(ctx)
+	if err != nil {
+		return err
+	}
 
-						allSteps := append(beforeSteps, aiSteps...)
-						allSteps = append(allSteps, afterSteps...)
+	profileName, err := internal.ActiveProfile(ctx, azdContext)
+	if err != nil {
+		return err
+	}
 
-						upWorkflow.Steps = allSteps
-						if azdProject.Workflows == nil {
-							azdProject.Workflows = make(contracts.WorkflowMap)
-						}
-						azdProject.Workflows["up"] = upWorkflow
+	extensionConfig, err := internal.LoadExtensionConfig(ctx, azdContext)
+	if err != nil {
+		return err
+	}
 
-						azdCtx, err := azd.NewContext()
-						if err != nil {
-							return err
-						}
+	return output.Print(ctx, &profileResult{
+		Name:   profileName,
+		Config: extensionConfig,
+	}, profileResultTableOptions)
+}
 
-						if err := project.Save(ctx, azdProject, azdCtx.ProjectPath()); err != nil {
-							return err
-						}
+// runDocumentPrep uploads the documents, generates text embeddings and populates the search index.
+func runDocumentPrep(
+	ctx context.Context,
+	docPrepService *docprep.DocumentPrepService,
+	cwd string,
+	matchingFiles []string,
+	absOutputPath string,
+) error {
+	return ux.NewTaskList(nil).
+		AddTask(ux.TaskOptions{
+			Title: "Uploading documents",
+			Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
+				setProgress(fmt.Sprintf("%d/%d", 0, len(matchingFiles)))
+
+				for index, file := range matchingFiles {
+					relativePath, err := filepath.Rel(cwd, file)
+					if err != nil {
+						return ux.Error, err
 					}
+
+					if err := docPrepService.Upload(ctx, file, relativePath, nil); err != nil {
+						return ux.Error, common.NewDetailedError("Failed to upload document", err)
+					}
+
+					setProgress(fmt.Sprintf("%d/%d", index+1, len(matchingFiles)))
 				}
+
+				return ux.Success, nil
+			},
+		}).
+		AddTask(ux.TaskOptions{
+			Title: "Generating text embeddings",
+			Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
+				setProgress(fmt.Sprintf("%d/%d", 0, len(matchingFiles)))
+
+				for index, file := range matchingFiles {
+					if _, err := docPrepService.GenerateEmbedding(ctx, file, absOutputPath, nil); err != nil {
+						return ux.Error, common.NewDetailedError("Failed generating embedding", err)
+					}
+
+					setProgress(fmt.Sprintf("%d/%d", index+1, len(matchingFiles)))
+				}
+
+				return ux.Success, nil
+			},
+		}).
+		AddTask(ux.TaskOptions{
+			Title: "Populating search index",
+			Action: func(setProgress ux.SetProgressFunc) (ux.TaskState, error) {
+				embeddingDocuments, err := getMatchingFiles(absOutputPath, "*.json", true)
+				if err != nil {
+					return ux.Error, common.NewDetailedError("Failed fetching embedding documents", err)
+				}
+
+				setProgress(fmt.Sprintf("%d/%d", 0, len(embeddingDocuments)))
+
+				for index, file := range embeddingDocuments {
+					if err := docPrepService.IngestEmbedding(ctx, file); err != nil {
+						return ux.Error, common.NewDetailedError("Failed ingesting embedding", err)
+					}
+
+					setProgress(fmt.Sprintf("%d/%d", index+1, len(embeddingDocuments)))
+				}
+
+				return ux.Success, nil
+			},
+		}).
+		Run()
+}
+
+// updateUpWorkflow adds the document prep steps to the `up` workflow of the azd project
+// so documents are processed automatically after provisioning.
+func updateUpWorkflow(ctx context.Context, azdContext *ext.Context, documentSources []*internal.DocumentSourceSpec) error {
+	azdProject, err := azdContext.Project(ctx)
+	if err != nil {
+		return err
+	}
+
+	upWorkflow, has := azdProject.Workflows["up"]
+	if !has {
+		upWorkflow = defaultUpWorkflow
+	}
+
+	beforeSteps := []*contracts.Step{}
+	afterSteps := []*contracts.Step{}
+	aiSteps := []*contracts.Step{}
+	foundProvision := false
+
+	for _, step := range upWorkflow.Steps {
+		if step.AzdCommand.Args[0] == "ai" {
+			continue
+		}
+
+		if foundProvision {
+			afterSteps = append(afterSteps, step)
+		} else {
+			beforeSteps = append(beforeSteps, step)
+		}
+
+		if slices.Contains(step.AzdCommand.Args, "provision") {
+			foundProvision = true
+		}
+	}
+
+	for _, documentSource := range documentSources {
+		aiSteps = append(aiSteps, &contracts.Step{
+			AzdCommand: contracts.Command{
+				Args: []string{
+					"ai", "document", "upload",
+					"--source", documentSource.Source,
+					"--pattern", documentSource.Pattern,
+					"--force",
+				},
+			},
+		})
+
+		aiSteps = append(aiSteps, &contracts.Step{
+			AzdCommand: contracts.Command{
+				Args: []string{
+					"ai", "embedding", "generate",
+					"--source", documentSource.Source,
+					"--pattern", documentSource.Pattern,
+					"--output-dir", documentSource.Output,
+					"--force",
+				},
+			},
+		})
+
+		aiSteps = append(aiSteps, &contracts.Step{
+			AzdCommand: contracts.Command{
+				Args: []string{
+					"ai", "embedding", "ingest",
+					"--source", documentSource.Output,
+					"--force",
+				},
+			},
+		})
+	}
+
+	allSteps := append(beforeSteps, aiSteps...)
+	allSteps = append(allSteps, afterSteps...)
+
+	upWorkflow.Steps = allSteps
+	if azdProject.Workflows == nil {
+		azdProject.Workflows = make(contracts.WorkflowMap)
+	}
+	azdProject.Workflows["up"] = upWorkflow
+
+	azdCtx, err := azd.NewContext()
+	if err != nil {
+		return err
+	}
+
+	return project.Save(ctx, azdProject, azdCtx.ProjectPath())
+}
+
+// runSetupFromSpec converges the AI project to the state declared in the setup spec file.
+func runSetupFromSpec(ctx context.Context, flags *SetupFlags) error {
+	azdContext, err := ext.CurrentContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	azureContext, err := azdContext.AzureContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	spec, err := internal.LoadSetupSpec(flags.File)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &ext.ErrorWithSuggestion{
+				Err:        err,
+				Suggestion: fmt.Sprintf("Create a %s file or specify the setup spec with --file.", internal.DefaultSetupSpecFile),
 			}
+		}
 
-			fmt.Println()
-			color.Green("SUCCESS: AI project setup completed successfully")
-			fmt.Printf("Run %s to start chatting with your AI model\n", color.CyanString("azd ai chat"))
+		return err
+	}
 
-			return nil
-		},
+	if err := spec.Resolve(azureContext); err != nil {
+		return err
 	}
 
-	setupCmd.Flags().BoolVar(&flags.Reset, "reset", false, "Resets the AI project configuration")
+	planner, err := internal.NewSetupPlanner(azdContext, spec)
+	if err != nil {
+		return err
+	}
 
-	return setupCmd
+	loadingSpinner := ux.NewSpinner(&ux.SpinnerOptions{
+		Text:        "Comparing setup spec with Azure resources...",
+		ClearOnStop: true,
+	})
+
+	loadingSpinner.Start(ctx)
+	plan, err := planner.Plan(ctx)
+	loadingSpinner.Stop(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(output.MessageWriter(ctx), "Setup Spec: %s\n", color.CyanString(flags.File))
+	fmt.Fprintf(output.MessageWriter(ctx), "Subscription: %s\n", color.CyanString(spec.Subscription))
+	fmt.Fprintf(output.MessageWriter(ctx), "Resource Group: %s\n", color.CyanString(spec.ResourceGroup))
+	fmt.Fprintln(output.MessageWriter(ctx))
+	fmt.Fprintln(output.MessageWriter(ctx), color.CyanString("Plan"))
+	plan.Print(output.MessageWriter(ctx))
+	fmt.Fprintln(output.MessageWriter(ctx))
+
+	if !plan.HasChanges() {
+		fmt.Fprintln(output.MessageWriter(ctx), "No changes, the Azure resources match the setup spec.")
+		fmt.Fprintln(output.MessageWriter(ctx))
+	} else {
+		if !flags.NoPrompt {
+			applyConfirm := ux.NewConfirm(&ux.ConfirmOptions{
+				Message:      "Do you want to apply these changes?",
+				DefaultValue: to.Ptr(true),
+			})
+
+			userApplyConfirmed, err := applyConfirm.Ask()
+			if err != nil {
+				return err
+			}
+
+			if !*userApplyConfirmed {
+				return ux.ErrCancelled
+			}
+		}
+
+		if err := planner.Apply(ctx, plan); err != nil {
+			return err
+		}
+	}
+
+	extensionConfig := planner.Config()
+	if err := internal.SaveExtensionConfig(ctx, azdContext, extensionConfig); err != nil {
+		return err
+	}
+
+	if len(spec.Documents) == 0 {
+		return nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	docPrepService, err := docprep.NewDocumentPrepService(ctx, azdContext, extensionConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, documentSource := range spec.Documents {
+		absSourcePath := filepath.Join(cwd, documentSource.Source)
+		absOutputPath := filepath.Join(cwd, documentSource.Output)
+
+		matchingFiles, err := getMatchingFiles(absSourcePath, documentSource.Pattern, true)
+		if err != nil {
+			return err
+		}
+
+		if len(matchingFiles) == 0 {
+			return fmt.Errorf("no files found at source location %s", documentSource.Source)
+		}
+
+		if err := os.MkdirAll(absOutputPath, permissions.PermissionDirectory); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(output.MessageWriter(ctx))
+		fmt.Fprintf(output.MessageWriter(ctx), "Source Data: %s\n", color.CyanString(absSourcePath))
+		fmt.Fprintf(output.MessageWriter(ctx), "Embeddings Output: %s\n", color.CyanString(absOutputPath))
+
+		if err := runDocumentPrep(ctx, docPrepService, cwd, matchingFiles, absOutputPath); err != nil {
+			return err
+		}
+	}
+
+	if spec.UpdateUpWorkflow {
+		if err := updateUpWorkflow(ctx, azdContext, spec.Documents); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func containsFilesOrFolders(folderPath string) (bool, error) {
@@ -531,3 +714,62 @@ func deleteContents(folderPath string) error {
 	}
 	return nil
 }
+
+// documentPrepAnswers are the answers of the document preparation form.
+type documentPrepAnswers struct {
+	PrepDocuments bool
+	SourcePath    string
+	FilePattern   string
+	OutputPath    string
+}
+
+// promptDocumentPrep asks whether to prep documents and where the source data and embeddings are located.
+// The paths are only displayed when documents are prepped.
+func promptDocumentPrep() (*documentPrepAnswers, error) {
+	prepDocuments := func(answers *documentPrepAnswers) bool {
+		return answers.PrepDocuments
+	}
+
+	return ux.NewForm(&ux.FormOptions[documentPrepAnswers]{
+		Title: "Document preparation",
+		Initial: &documentPrepAnswers{
+			PrepDocuments: true,
+			SourcePath:    "./data",
+			FilePattern:   "*",
+			OutputPath:    "./embeddings",
+		},
+		// The configuration is summarized and confirmed before the documents are prepped
+		Review: to.Ptr(false),
+		Fields: []ux.FormField[documentPrepAnswers]{
+			ux.NewConfirmField(&ux.ConfirmFieldOptions[documentPrepAnswers]{
+				Message: "Would you like to prep documents for your AI project?",
+				Value:   func(answers *documentPrepAnswers) *bool { return &answers.PrepDocuments },
+			}),
+			ux.NewTextField(&ux.TextFieldOptions[documentPrepAnswers]{
+				Message:  "Enter the path to the source data",
+				Required: true,
+				Visible:  prepDocuments,
+				Value:    func(answers *documentPrepAnswers) *string { return &answers.SourcePath },
+				Validate: func(value string) error {
+					if _, err := os.Stat(value); err != nil {
+						return fmt.Errorf("the path '%s' doesn't exist", value)
+					}
+
+					return nil
+				},
+			}),
+			ux.NewTextField(&ux.TextFieldOptions[documentPrepAnswers]{
+				Message:  "Which files should be included?",
+				Required: true,
+				Visible:  prepDocuments,
+				Value:    func(answers *documentPrepAnswers) *string { return &answers.FilePattern },
+			}),
+			ux.NewTextField(&ux.TextFieldOptions[documentPrepAnswers]{
+				Message:  "Enter the path for the embeddings output",
+				Required: true,
+				Visible:  prepDocuments,
+				Value:    func(answers *documentPrepAnswers) *string { return &answers.OutputPath },
+			}),
+		},
+	}).Ask()
+}
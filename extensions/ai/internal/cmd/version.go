@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
 )
 
 var (
@@ -13,12 +14,35 @@ var (
 	BuildDate = "unknown"
 )
 
+type versionResult struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+}
+
 func newVersionCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
 		Short: "Prints the version of the application",
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Printf("Version: %s\nCommit: %s\nBuild Date: %s\n", Version, Commit, BuildDate)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if output.IsStructured() {
+				return output.Print(ctx, &versionResult{
+					Version:   Version,
+					Commit:    Commit,
+					BuildDate: BuildDate,
+				}, &output.TableOptions{
+					Columns: []output.Column{
+						{Heading: "Version", ValueTemplate: "{{.Version}}"},
+						{Heading: "Commit", ValueTemplate: "{{.Commit}}"},
+						{Heading: "Build Date", ValueTemplate: "{{.BuildDate}}"},
+					},
+				})
+			}
+
+			fmt.Fprintf(output.MessageWriter(ctx), "Version: %s\nCommit: %s\nBuild Date: %s\n", Version, Commit, BuildDate)
+			return nil
 		},
 	}
 }
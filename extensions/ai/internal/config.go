@@ -5,15 +5,31 @@ import (
 	"errors"
 
 	"github.com/wbreza/azd-extensions/sdk/core/config"
+	"github.com/wbreza/azd-extensions/sdk/core/environment"
 	"github.com/wbreza/azd-extensions/sdk/ext"
 )
 
 type ExtensionConfig struct {
-	Subscription  string        `json:"subscription"`
-	ResourceGroup string        `json:"resourceGroup"`
-	Ai            AiConfig      `json:"ai"`
-	Search        SearchConfig  `json:"search"`
-	Storage       StorageConfig `json:"storage"`
+	Subscription  string          `json:"subscription"`
+	ResourceGroup string          `json:"resourceGroup"`
+	Ai            AiConfig        `json:"ai"`
+	Search        SearchConfig    `json:"search"`
+	Storage       StorageConfig   `json:"storage"`
+	Pricing       PricingConfig   `json:"pricing,omitempty"`
+	Prompts       PromptsConfig   `json:"prompts,omitempty"`
+	Redaction     RedactionConfig `json:"redaction,omitempty"`
+	Retrieval     RetrievalConfig `json:"retrieval,omitempty"`
+	Memory        MemoryConfig    `json:"memory,omitempty"`
+}
+
+// MemoryConfig configures how azd ai chat keeps the history within the context window of the model.
+type MemoryConfig struct {
+	// Strategy is one of window, summary or vector. Defaults to summary.
+	Strategy string `json:"strategy,omitempty"`
+	// RecentTurns is the number of turns kept verbatim by the summary and vector strategies.
+	RecentTurns int `json:"recentTurns,omitempty"`
+	// RecallTurns is the number of earlier turns recalled by the vector strategy.
+	RecallTurns int `json:"recallTurns,omitempty"`
 }
 
 type AiConfig struct {
@@ -34,12 +50,53 @@ type ModelsConfig struct {
 	Audio          string `json:"audio"`
 }
 
+// PromptsConfig references the prompt templates used instead of the built-in templates.
+type PromptsConfig struct {
+	Chat        string `json:"chat,omitempty"`
+	ChatSummary string `json:"chatSummary,omitempty"`
+	Summarize   string `json:"summarize,omitempty"`
+	Evaluate    string `json:"evaluate,omitempty"`
+	Rerank      string `json:"rerank,omitempty"`
+}
+
+// RetrievalConfig configures the post-retrieval stages for each command.
+type RetrievalConfig struct {
+	Chat     RetrievalOptions `json:"chat,omitempty"`
+	Evaluate RetrievalOptions `json:"evaluate,omitempty"`
+}
+
+// RedactionConfig enables the detection of sensitive content before embeddings are generated.
+type RedactionConfig struct {
+	// Action is one of redact, skip or flag. Redaction is disabled when empty.
+	Action string `json:"action,omitempty"`
+	// Detectors are the built-in detector sets to run, pii and secrets by default.
+	Detectors []string `json:"detectors,omitempty"`
+	// Dictionaries are paths to files with custom terms to detect, one term per line.
+	Dictionaries []string `json:"dictionaries,omitempty"`
+}
+
 type SearchConfig struct {
 	Service  string `json:"service"`
 	Endpoint string `json:"endpoint"`
 	Index    string `json:"index"`
 }
 
+// Environment variables populated from the outputs of the Bicep generated by `azd ai infra generate`.
+const (
+	EnvAiServiceName          = "AZURE_AI_SERVICE_NAME"
+	EnvAiServiceLocation      = "AZURE_AI_LOCATION"
+	EnvAiEndpoint             = "AZURE_AI_ENDPOINT"
+	EnvAiChatDeployment       = "AZURE_AI_CHAT_DEPLOYMENT"
+	EnvAiEmbeddingsDeployment = "AZURE_AI_EMBEDDINGS_DEPLOYMENT"
+	EnvAiAudioDeployment      = "AZURE_AI_AUDIO_DEPLOYMENT"
+	EnvStorageAccountName     = "AZURE_STORAGE_ACCOUNT_NAME"
+	EnvStorageEndpoint        = "AZURE_STORAGE_BLOB_ENDPOINT"
+	EnvStorageContainer       = "AZURE_STORAGE_CONTAINER"
+	EnvSearchServiceName      = "AZURE_SEARCH_SERVICE_NAME"
+	EnvSearchEndpoint         = "AZURE_SEARCH_ENDPOINT"
+	EnvSearchIndex            = "AZURE_SEARCH_INDEX"
+)
+
 var (
 	ErrNotFound           = errors.New("service config not found")
 	ErrNoModelDeployments = errors.New("no model deployments found")
@@ -47,16 +104,9 @@ var (
 )
 
 func LoadExtensionConfig(ctx context.Context, azdContext *ext.Context) (*ExtensionConfig, error) {
-	var azdConfig config.Config
-
-	env, err := azdContext.Environment(ctx)
-	if err == nil {
-		azdConfig = env.Config
-	} else {
-		userConfig, err := azdContext.UserConfig(ctx)
-		if err == nil {
-			azdConfig = userConfig
-		}
+	store, err := loadConfigStore(ctx, azdContext)
+	if err != nil {
+		return nil, err
 	}
 
 	azureContext, err := azdContext.AzureContext(ctx)
@@ -64,12 +114,13 @@ func LoadExtensionConfig(ctx context.Context, azdContext *ext.Context) (*Extensi
 		return nil, err
 	}
 
-	if azdConfig == nil {
-		return nil, errors.New("azd configuration is not available")
+	profile, err := activeProfile(ctx, store.config)
+	if err != nil {
+		return nil, err
 	}
 
 	var config ExtensionConfig
-	has, err := azdConfig.GetSection("ai.config", &config)
+	has, err := store.config.GetSection(extensionConfigPath(profile), &config)
 	if err != nil {
 		return nil, err
 	}
@@ -86,9 +137,41 @@ func LoadExtensionConfig(ctx context.Context, azdContext *ext.Context) (*Extensi
 		return &config, nil
 	}
 
+	// Environments provisioned from the generated Bicep don't have an `ai.config` section yet
+	// but expose the provisioned resources through the environment variables.
+	if profile == DefaultProfile && store.env != nil && store.env.Getenv(EnvAiServiceName) != "" {
+		return extensionConfigFromEnv(store.env, azureContext), nil
+	}
+
 	return nil, ErrNotFound
 }
 
+func extensionConfigFromEnv(env *environment.Environment, azureContext *ext.AzureContext) *ExtensionConfig {
+	return &ExtensionConfig{
+		Subscription:  azureContext.Scope.SubscriptionId,
+		ResourceGroup: azureContext.Scope.ResourceGroup,
+		Ai: AiConfig{
+			Service:  env.Getenv(EnvAiServiceName),
+			Endpoint: env.Getenv(EnvAiEndpoint),
+			Models: ModelsConfig{
+				ChatCompletion: env.Getenv(EnvAiChatDeployment),
+				Embeddings:     env.Getenv(EnvAiEmbeddingsDeployment),
+				Audio:          env.Getenv(EnvAiAudioDeployment),
+			},
+		},
+		Storage: StorageConfig{
+			Account:   env.Getenv(EnvStorageAccountName),
+			Endpoint:  env.Getenv(EnvStorageEndpoint),
+			Container: env.Getenv(EnvStorageContainer),
+		},
+		Search: SearchConfig{
+			Service:  env.Getenv(EnvSearchServiceName),
+			Endpoint: env.Getenv(EnvSearchEndpoint),
+			Index:    env.Getenv(EnvSearchIndex),
+		},
+	}
+}
+
 func SaveExtensionConfig(ctx context.Context, azdContext *ext.Context, config *ExtensionConfig) error {
 	if azdContext == nil {
 		return errors.New("azdContext is required")
@@ -98,44 +181,68 @@ func SaveExtensionConfig(ctx context.Context, azdContext *ext.Context, config *E
 		return errors.New("config is required")
 	}
 
-	env, err := azdContext.Environment(ctx)
-	if err == nil && env != nil {
-		if err := env.Config.Set("ai.config", config); err != nil {
+	store, err := loadConfigStore(ctx, azdContext)
+	if err != nil {
+		return errors.New("unable to save service configuration")
+	}
+
+	// The scope of the environment is used when the config is stored within the environment
+	if store.env == nil {
+		azureContext, err := azdContext.AzureContext(ctx)
+		if err != nil {
 			return err
 		}
 
-		if err := azdContext.SaveEnvironment(ctx, env); err != nil {
-			return err
+		if config.Subscription == "" && azureContext.Scope.SubscriptionId != "" {
+			config.Subscription = azureContext.Scope.SubscriptionId
 		}
 
-		return nil
+		if config.ResourceGroup == "" && azureContext.Scope.ResourceGroup != "" {
+			config.ResourceGroup = azureContext.Scope.ResourceGroup
+		}
 	}
 
-	azureContext, err := azdContext.AzureContext(ctx)
+	profile, err := activeProfile(ctx, store.config)
 	if err != nil {
 		return err
 	}
 
-	if config.Subscription == "" && azureContext.Scope.SubscriptionId != "" {
-		config.Subscription = azureContext.Scope.SubscriptionId
+	if err := store.config.Set(extensionConfigPath(profile), config); err != nil {
+		return err
 	}
 
-	if config.ResourceGroup == "" && azureContext.Scope.ResourceGroup != "" {
-		config.ResourceGroup = azureContext.Scope.ResourceGroup
+	return store.save(ctx)
+}
+
+// configStore is the azd configuration the extension config is stored within.
+// The config of the current environment is used when available, otherwise the user config.
+type configStore struct {
+	config config.Config
+	env    *environment.Environment
+	save   func(ctx context.Context) error
+}
+
+func loadConfigStore(ctx context.Context, azdContext *ext.Context) (*configStore, error) {
+	env, err := azdContext.Environment(ctx)
+	if err == nil && env != nil {
+		return &configStore{
+			config: env.Config,
+			env:    env,
+			save: func(ctx context.Context) error {
+				return azdContext.SaveEnvironment(ctx, env)
+			},
+		}, nil
 	}
 
 	userConfig, err := azdContext.UserConfig(ctx)
 	if err == nil && userConfig != nil {
-		if err := userConfig.Set("ai.config", config); err != nil {
-			return err
-		}
-
-		if err := azdContext.SaveUserConfig(ctx, userConfig); err != nil {
-			return err
-		}
-
-		return nil
+		return &configStore{
+			config: userConfig,
+			save: func(ctx context.Context) error {
+				return azdContext.SaveUserConfig(ctx, userConfig)
+			},
+		}, nil
 	}
 
-	return errors.New("unable to save service configuration")
+	return nil, errors.New("azd configuration is not available")
 }
@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrBudgetExceeded = errors.New("cost budget exceeded")
+)
+
+// CostMetrics summarizes the cost of a job
+type CostMetrics struct {
+	EstimatedCost  float64           `json:"estimatedCost"`
+	TotalCost      float64           `json:"totalCost"`
+	MaxCost        float64           `json:"maxCost,omitempty"`
+	BudgetExceeded bool              `json:"budgetExceeded"`
+	Deployments    []*DeploymentCost `json:"deployments"`
+}
+
+// DeploymentCost is the token usage and cost for a single model deployment
+type DeploymentCost struct {
+	Deployment string     `json:"deployment"`
+	TokenUsage TokenUsage `json:"tokenUsage"`
+	Cost       float64    `json:"cost"`
+	Priced     bool       `json:"priced"`
+}
+
+// UsageEstimate is the estimated token usage per model deployment
+type UsageEstimate map[string]TokenUsage
+
+// Add adds the token counts to the estimate for the deployment.
+func (u UsageEstimate) Add(deployment string, promptTokens int32, completionTokens int32) {
+	if deployment == "" {
+		return
+	}
+
+	usage := u[deployment]
+	usage.PromptTokens += promptTokens
+	usage.CompletionTokens += completionTokens
+	usage.TotalTokens += promptTokens + completionTokens
+	u[deployment] = usage
+}
+
+// Merge adds all token counts from the other estimate.
+func (u UsageEstimate) Merge(other UsageEstimate) {
+	for deployment, usage := range other {
+		u.Add(deployment, usage.PromptTokens, usage.CompletionTokens)
+	}
+}
+
+// CostTracker accumulates the token usage and cost of model calls and enforces an optional budget.
+// A nil CostTracker is valid and tracks nothing.
+type CostTracker struct {
+	mu            sync.Mutex
+	prices        map[string]ModelPrice
+	maxCost       float64
+	estimatedCost float64
+	totalCost     float64
+	usage         map[string]*TokenUsage
+}
+
+// NewCostTracker creates a new cost tracker for the deployment prices.
+// When maxCost is greater than zero the budget is enforced by CheckBudget.
+func NewCostTracker(prices map[string]ModelPrice, maxCost float64) *CostTracker {
+	return &CostTracker{
+		prices:  prices,
+		maxCost: maxCost,
+		usage:   map[string]*TokenUsage{},
+	}
+}
+
+// Estimate calculates the cost for the estimated token usage per deployment.
+func (t *CostTracker) Estimate(usage UsageEstimate) float64 {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var cost float64
+	for deployment, tokenUsage := range usage {
+		price := t.prices[deployment]
+		cost += price.Cost(int(tokenUsage.PromptTokens), int(tokenUsage.CompletionTokens))
+	}
+
+	t.estimatedCost = cost
+
+	return cost
+}
+
+// Track records the token usage of a single model call.
+func (t *CostTracker) Track(deployment string, promptTokens int32, completionTokens int32) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage, has := t.usage[deployment]
+	if !has {
+		usage = &TokenUsage{}
+		t.usage[deployment] = usage
+	}
+
+	usage.PromptTokens += promptTokens
+	usage.CompletionTokens += completionTokens
+	usage.TotalTokens += promptTokens + completionTokens
+
+	price := t.prices[deployment]
+	t.totalCost += price.Cost(int(promptTokens), int(completionTokens))
+}
+
+// CheckBudget returns ErrBudgetExceeded once the tracked cost reaches the max cost.
+func (t *CostTracker) CheckBudget() error {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.maxCost > 0 && t.totalCost >= t.maxCost {
+		return ErrBudgetExceeded
+	}
+
+	return nil
+}
+
+// TotalCost returns the tracked cost in USD.
+func (t *CostTracker) TotalCost() float64 {
+	if t == nil {
+		return 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.totalCost
+}
+
+// Metrics returns a summary of the tracked usage and cost.
+func (t *CostTracker) Metrics() *CostMetrics {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	metrics := &CostMetrics{
+		EstimatedCost:  t.estimatedCost,
+		TotalCost:      t.totalCost,
+		MaxCost:        t.maxCost,
+		BudgetExceeded: t.maxCost > 0 && t.totalCost >= t.maxCost,
+		Deployments:    []*DeploymentCost{},
+	}
+
+	for deployment, usage := range t.usage {
+		price, priced := t.prices[deployment]
+		metrics.Deployments = append(metrics.Deployments, &DeploymentCost{
+			Deployment: deployment,
+			TokenUsage: *usage,
+			Cost:       price.Cost(int(usage.PromptTokens), int(usage.CompletionTokens)),
+			Priced:     priced,
+		})
+	}
+
+	sort.Slice(metrics.Deployments, func(i, j int) bool {
+		return metrics.Deployments[i].Deployment < metrics.Deployments[j].Deployment
+	})
+
+	return metrics
+}
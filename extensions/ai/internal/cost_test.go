@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CostTracker_Budget(t *testing.T) {
+	tracker := NewCostTracker(map[string]ModelPrice{
+		"chat": {InputPer1K: 0.01, OutputPer1K: 0.03},
+	}, 0.05)
+
+	tracker.Track("chat", 1000, 1000)
+	require.InDelta(t, 0.04, tracker.TotalCost(), 0.0001)
+	require.NoError(t, tracker.CheckBudget())
+
+	// Usage for deployments without pricing is tracked but has no cost
+	tracker.Track("embeddings", 5000, 0)
+	require.NoError(t, tracker.CheckBudget())
+
+	tracker.Track("chat", 1000, 0)
+	require.ErrorIs(t, tracker.CheckBudget(), ErrBudgetExceeded)
+
+	metrics := tracker.Metrics()
+	require.True(t, metrics.BudgetExceeded)
+	require.Len(t, metrics.Deployments, 2)
+	require.False(t, metrics.Deployments[1].Priced)
+}
@@ -0,0 +1,188 @@
+package docprep
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+// AudioFileTypes are the file extensions transcribed by the AudioParser.
+var AudioFileTypes = []string{".mp3", ".wav", ".m4a"}
+
+const (
+	// maxAudioFileSize is the largest file accepted by the audio transcription API.
+	maxAudioFileSize = 25 * 1024 * 1024
+	// defaultAudioChunkDuration is the target length of the transcript covered by each chunk.
+	defaultAudioChunkDuration = 60 * time.Second
+)
+
+// IsAudioFile returns true when the file at the path is transcribed by the AudioParser.
+func IsAudioFile(path string) bool {
+	return slices.Contains(AudioFileTypes, strings.ToLower(filepath.Ext(path)))
+}
+
+// TranscriptSegment is a span of transcribed speech.
+type TranscriptSegment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// AudioParser transcribes audio files with the configured audio model deployment and splits the
+// transcript into chunks of consecutive segments.
+type AudioParser struct {
+	openAiClient   *azopenai.Client
+	deploymentName string
+	chunkDuration  time.Duration
+}
+
+func NewAudioParser(openAiClient *azopenai.Client, deploymentName string) *AudioParser {
+	return &AudioParser{
+		openAiClient:   openAiClient,
+		deploymentName: deploymentName,
+		chunkDuration:  defaultAudioChunkDuration,
+	}
+}
+
+func (p *AudioParser) SuggestSummarization() bool {
+	return false
+}
+
+func (p *AudioParser) Parse(ctx context.Context, document *Document) ([]*DocumentChunk, error) {
+	if document.Size > maxAudioFileSize {
+		return nil, fmt.Errorf(
+			"audio file %s is %d MB, files larger than %d MB are not supported",
+			document.Name,
+			document.Size/1024/1024,
+			maxAudioFileSize/1024/1024,
+		)
+	}
+
+	audioBytes, err := os.ReadFile(document.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	transcriptionResponse, err := p.openAiClient.GetAudioTranscription(ctx, azopenai.AudioTranscriptionOptions{
+		File:           audioBytes,
+		Filename:       &document.Name,
+		DeploymentName: &p.deploymentName,
+		ResponseFormat: to.Ptr(azopenai.AudioTranscriptionFormatVerboseJSON),
+		TimestampGranularities: []azopenai.AudioTranscriptionTimestampGranularity{
+			azopenai.AudioTranscriptionTimestampGranularitySegment,
+		},
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed transcribing %s: %w", document.Name, err)
+	}
+
+	segments := []*TranscriptSegment{}
+	for _, segment := range transcriptionResponse.Segments {
+		if segment.Text == nil || segment.Start == nil || segment.End == nil {
+			continue
+		}
+
+		segments = append(segments, &TranscriptSegment{
+			Start: secondsToDuration(*segment.Start),
+			End:   secondsToDuration(*segment.End),
+			Text:  *segment.Text,
+		})
+	}
+
+	// Fallback to a single segment when the service doesn't return segment timestamps
+	if len(segments) == 0 && transcriptionResponse.Text != nil {
+		var duration time.Duration
+		if transcriptionResponse.Duration != nil {
+			duration = secondsToDuration(*transcriptionResponse.Duration)
+		}
+
+		segments = append(segments, &TranscriptSegment{
+			End:  duration,
+			Text: *transcriptionResponse.Text,
+		})
+	}
+
+	return chunkTranscript(document, segments, p.chunkDuration), nil
+}
+
+// chunkTranscript groups consecutive transcript segments into chunks spanning at least the chunk duration.
+// The start and end offsets of each chunk are recorded in the chunk metadata.
+func chunkTranscript(document *Document, segments []*TranscriptSegment, chunkDuration time.Duration) []*DocumentChunk {
+	sourceHash := sha256.Sum256([]byte(document.Path))
+	parentId := hex.EncodeToString(sourceHash[:])
+
+	chunks := []*DocumentChunk{}
+	var chunkSegments []*TranscriptSegment
+
+	completeChunk := func() {
+		if len(chunkSegments) == 0 {
+			return
+		}
+
+		texts := make([]string, len(chunkSegments))
+		for i, segment := range chunkSegments {
+			texts[i] = strings.TrimSpace(segment.Text)
+		}
+
+		start := chunkSegments[0].Start
+		end := chunkSegments[len(chunkSegments)-1].End
+		content := strings.Join(texts, " ")
+
+		idHash := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", document.Path, start.Milliseconds())))
+		contentHash := sha256.Sum256([]byte(content))
+
+		chunks = append(chunks, &DocumentChunk{
+			Id:       hex.EncodeToString(idHash[:]),
+			ParentId: parentId,
+			Hash:     hex.EncodeToString(contentHash[:]),
+			Content:  content,
+			Path:     fmt.Sprintf("%s#t=%d", document.Path, int(start.Seconds())),
+			Metadata: map[string]string{
+				"start": formatOffset(start),
+				"end":   formatOffset(end),
+			},
+		})
+
+		chunkSegments = nil
+	}
+
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.Text) == "" {
+			continue
+		}
+
+		chunkSegments = append(chunkSegments, segment)
+		if segment.End-chunkSegments[0].Start >= chunkDuration {
+			completeChunk()
+		}
+	}
+
+	completeChunk()
+
+	return chunks
+}
+
+// formatOffset formats the offset as hh:mm:ss.mmm
+func formatOffset(offset time.Duration) string {
+	hours := offset / time.Hour
+	offset -= hours * time.Hour
+	minutes := offset / time.Minute
+	offset -= minutes * time.Minute
+	seconds := offset / time.Second
+	offset -= seconds * time.Second
+
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, offset/time.Millisecond)
+}
+
+func secondsToDuration(seconds float32) time.Duration {
+	return time.Duration(float64(seconds) * float64(time.Second))
+}
@@ -0,0 +1,42 @@
+package docprep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ChunkTranscript(t *testing.T) {
+	document := &Document{
+		Name: "support-call.mp3",
+		Path: "/data/support-call.mp3",
+	}
+
+	segments := []*TranscriptSegment{
+		{Start: 0, End: 20 * time.Second, Text: " Thanks for calling."},
+		{Start: 20 * time.Second, End: 45 * time.Second, Text: " How can I help?"},
+		{Start: 45 * time.Second, End: 70 * time.Second, Text: " My deployment failed."},
+		{Start: 70 * time.Second, End: 71 * time.Second, Text: "   "},
+		{Start: 71 * time.Second, End: 95500 * time.Millisecond, Text: " Let's take a look."},
+	}
+
+	chunks := chunkTranscript(document, segments, time.Minute)
+	require.Len(t, chunks, 2)
+
+	require.Equal(t, "Thanks for calling. How can I help? My deployment failed.", chunks[0].Content)
+	require.Equal(t, "00:00:00.000", chunks[0].Metadata["start"])
+	require.Equal(t, "00:01:10.000", chunks[0].Metadata["end"])
+	require.Equal(t, "/data/support-call.mp3#t=0", chunks[0].Path)
+
+	require.Equal(t, "Let's take a look.", chunks[1].Content)
+	require.Equal(t, "00:01:11.000", chunks[1].Metadata["start"])
+	require.Equal(t, "00:01:35.500", chunks[1].Metadata["end"])
+	require.Equal(t, "/data/support-call.mp3#t=71", chunks[1].Path)
+
+	require.Equal(t, chunks[0].ParentId, chunks[1].ParentId)
+	require.NotEqual(t, chunks[0].Id, chunks[1].Id)
+
+	require.True(t, IsAudioFile("/data/meeting.M4A"))
+	require.False(t, IsAudioFile("/data/notes.md"))
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -16,6 +18,7 @@ import (
 	"github.com/wbreza/azd-extensions/sdk/azure/storage"
 	"github.com/wbreza/azd-extensions/sdk/common/permissions"
 	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azure-sdk-for-go/sdk/data/azsearch"
 	"github.com/wbreza/azure-sdk-for-go/sdk/data/azsearchindex"
 )
 
@@ -26,9 +29,21 @@ type DocumentPrepService struct {
 	cwd            string
 	openAiClient   *azopenai.Client
 	documentClient *azsearchindex.DocumentsClient
+	indexesClient  *azsearch.IndexesClient
 	blobClient     storage.BlobClient
+	costTracker    *internal.CostTracker
+	// summaryTemplate is the prompt template used to summarize chunks of parsers that suggest summarization.
+	summaryTemplate *internal.PromptTemplate
+	redactor        *Redactor
+
+	// indexFields are the names of the fields of the search index, loaded by the first successful ingestion.
+	indexFields      map[string]bool
+	indexFieldsMutex sync.Mutex
 }
 
+// estimatedSummaryTokens is the assumed size of a generated document summary.
+const estimatedSummaryTokens = 256
+
 func NewDocumentPrepService(ctx context.Context, azdContext *ext.Context, extensionConfig *internal.ExtensionConfig) (*DocumentPrepService, error) {
 	var azClientOptions *azcore.ClientOptions
 
@@ -61,6 +76,11 @@ func NewDocumentPrepService(ctx context.Context, azdContext *ext.Context, extens
 		return nil, err
 	}
 
+	indexesClient, err := azsearch.NewIndexesClient(extensionConfig.Search.Endpoint, credential, azClientOptions)
+	if err != nil {
+		return nil, err
+	}
+
 	azBlobClient, err := azblob.NewClient(extensionConfig.Storage.Endpoint, credential, &azblob.ClientOptions{
 		ClientOptions: *azClientOptions,
 	})
@@ -75,17 +95,43 @@ func NewDocumentPrepService(ctx context.Context, azdContext *ext.Context, extens
 	}
 	blobClient := storage.NewBlobClient(&storageConfig, azBlobClient)
 
+	promptTemplates, err := internal.NewProjectPromptTemplateStore(azdContext)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryTemplate, err := promptTemplates.Resolve(internal.SummarizePromptTemplate, extensionConfig.Prompts.Summarize)
+	if err != nil {
+		return nil, err
+	}
+
 	return &DocumentPrepService{
-		cwd:            cwd,
-		azdContext:     azdContext,
-		aiConfig:       extensionConfig,
-		openAiClient:   openAiClient,
-		documentClient: documentClient,
-		blobClient:     blobClient,
+		summaryTemplate: summaryTemplate,
+		cwd:             cwd,
+		azdContext:      azdContext,
+		aiConfig:        extensionConfig,
+		openAiClient:    openAiClient,
+		documentClient:  documentClient,
+		indexesClient:   indexesClient,
+		blobClient:      blobClient,
 	}, nil
 }
 
-func (d *DocumentPrepService) Upload(ctx context.Context, sourcePath string, targetPath string) error {
+// SetCostTracker configures the tracker used to record the cost of model calls and enforce the budget.
+func (d *DocumentPrepService) SetCostTracker(tracker *internal.CostTracker) {
+	d.costTracker = tracker
+}
+
+// SetRedactor configures the stage that detects sensitive content before chunks are sent to the model.
+func (d *DocumentPrepService) SetRedactor(redactor *Redactor) {
+	d.redactor = redactor
+}
+
+// ProgressFunc reports the amount of work completed, such as bytes uploaded or chunks embedded, out of the total.
+type ProgressFunc func(completed int64, total int64)
+
+// Upload uploads the document to the storage container. The optional progress func reports the bytes uploaded.
+func (d *DocumentPrepService) Upload(ctx context.Context, sourcePath string, targetPath string, onProgress ProgressFunc) error {
 	file, err := os.Open(sourcePath)
 	if err != nil {
 		return err
@@ -93,7 +139,18 @@ func (d *DocumentPrepService) Upload(ctx context.Context, sourcePath string, tar
 
 	defer file.Close()
 
-	err = d.blobClient.Upload(ctx, targetPath, file)
+	var reader io.Reader = file
+	if onProgress != nil {
+		fileInfo, err := file.Stat()
+		if err != nil {
+			return err
+		}
+
+		reader = &progressReader{reader: file, total: fileInfo.Size(), onProgress: onProgress}
+		onProgress(0, fileInfo.Size())
+	}
+
+	err = d.blobClient.Upload(ctx, targetPath, reader)
 	if err != nil {
 		return err
 	}
@@ -102,18 +159,35 @@ func (d *DocumentPrepService) Upload(ctx context.Context, sourcePath string, tar
 }
 
 func (d *DocumentPrepService) createParser(document *Document) (DocumentParser, error) {
+	if IsAudioFile(document.Path) {
+		if d.aiConfig.Ai.Models.Audio == "" {
+			return nil, fmt.Errorf("an audio model deployment is required to transcribe %s", document.Name)
+		}
+
+		return NewAudioParser(d.openAiClient, d.aiConfig.Ai.Models.Audio), nil
+	}
+
 	extension := filepath.Ext(document.Type)
 	switch extension {
 	case ".md":
 		return NewMarkdownParser(), nil
 	case ".json":
 		return NewJsonParser(), nil
+	case ".pdf":
+		return NewPdfParser(maxPdfDocumentTextSize), nil
 	default:
 		return NewDefaultParser(), nil
 	}
 }
 
-func (d *DocumentPrepService) GenerateEmbedding(ctx context.Context, sourcePath string, outputDir string) (string, error) {
+// GenerateEmbedding writes an embedding document for each chunk of the document to the output directory.
+// The optional progress func reports the chunks processed.
+func (d *DocumentPrepService) GenerateEmbedding(
+	ctx context.Context,
+	sourcePath string,
+	outputDir string,
+	onProgress ProgressFunc,
+) (string, error) {
 	sourceDoc, err := ParseDocument(sourcePath)
 	if err != nil {
 		return "", err
@@ -124,34 +198,62 @@ func (d *DocumentPrepService) GenerateEmbedding(ctx context.Context, sourcePath
 		return "", err
 	}
 
-	chunks, err := parser.Parse(sourceDoc)
+	chunks, err := parser.Parse(ctx, sourceDoc)
 	if err != nil {
 		return "", err
 	}
 
-	for _, chunk := range chunks {
+	relativeSourcePath, err := filepath.Rel(d.cwd, sourcePath)
+	if err != nil {
+		return "", err
+	}
+
+	for i, chunk := range chunks {
+		if onProgress != nil {
+			onProgress(int64(i), int64(len(chunks)))
+		}
+
 		if chunk.Content == "" {
 			continue
 		}
 
+		if d.redactor != nil && !d.redactor.Apply(filepath.ToSlash(relativeSourcePath), chunk) {
+			continue
+		}
+
+		if err := d.costTracker.CheckBudget(); err != nil {
+			return "", err
+		}
+
 		embeddingText := chunk.Content
 
 		if parser.SuggestSummarization() {
+			summaryMessages, err := d.summaryTemplate.Render(map[string]string{
+				internal.PromptVariableContent: chunk.Content,
+			})
+			if err != nil {
+				return "", err
+			}
+
+			// Templates with only a system message are sent the chunk as the user message
+			if !d.summaryTemplate.HasMessage("user") {
+				summaryMessages = append(summaryMessages, &internal.PromptMessage{Role: "user", Content: chunk.Content})
+			}
+
 			completionsResponse, err := d.openAiClient.GetChatCompletions(ctx, azopenai.ChatCompletionsOptions{
-				Messages: []azopenai.ChatRequestMessageClassification{
-					&azopenai.ChatRequestSystemMessage{
-						Content: azopenai.NewChatRequestSystemMessageContent("You are helping generate summary embeddings for specified document. Please provide a summary of the document."),
-					},
-					&azopenai.ChatRequestUserMessage{
-						Content: azopenai.NewChatRequestUserMessageContent(chunk.Content),
-					},
-				},
+				Messages:       internal.ChatRequestMessages(summaryMessages),
 				DeploymentName: &d.aiConfig.Ai.Models.ChatCompletion,
 			}, nil)
 			if err != nil {
 				return "", err
 			}
 
+			d.costTracker.Track(
+				d.aiConfig.Ai.Models.ChatCompletion,
+				*completionsResponse.Usage.PromptTokens,
+				*completionsResponse.Usage.CompletionTokens,
+			)
+
 			embeddingText = *completionsResponse.ChatCompletions.Choices[0].Message.Content
 		}
 
@@ -166,10 +268,7 @@ func (d *DocumentPrepService) GenerateEmbedding(ctx context.Context, sourcePath
 			return "", err
 		}
 
-		relativeSourcePath, err := filepath.Rel(d.cwd, sourcePath)
-		if err != nil {
-			return "", err
-		}
+		d.costTracker.Track(d.aiConfig.Ai.Models.Embeddings, *response.Usage.PromptTokens, 0)
 
 		embeddingDoc := EmbeddingDocument{
 			Id:       chunk.Id,
@@ -178,6 +277,7 @@ func (d *DocumentPrepService) GenerateEmbedding(ctx context.Context, sourcePath
 			Content:  chunk.Content,
 			Summary:  embeddingText,
 			Vector:   response.Embeddings.Data[0].Embedding,
+			Metadata: chunk.Metadata,
 		}
 
 		jsonData, err := json.MarshalIndent(embeddingDoc, "", "  ")
@@ -191,9 +291,76 @@ func (d *DocumentPrepService) GenerateEmbedding(ctx context.Context, sourcePath
 		}
 	}
 
+	if onProgress != nil {
+		onProgress(int64(len(chunks)), int64(len(chunks)))
+	}
+
 	return outputDir, nil
 }
 
+// EstimateEmbeddingUsage estimates the token usage per model deployment for generating embeddings of the document.
+// Tokens are counted with the tokenizer of the model of each deployment.
+func (d *DocumentPrepService) EstimateEmbeddingUsage(
+	ctx context.Context,
+	sourcePath string,
+	tokenizers internal.DeploymentTokenizers,
+) (internal.UsageEstimate, error) {
+	sourceDoc, err := ParseDocument(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := internal.UsageEstimate{}
+
+	// The transcript of an audio file isn't known until it has been transcribed and
+	// transcription is billed by the minute, so audio files are not included in the estimate.
+	if IsAudioFile(sourceDoc.Path) {
+		return usage, nil
+	}
+
+	parser, err := d.createParser(sourceDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := parser.Parse(ctx, sourceDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	summaryMessages, err := d.summaryTemplate.Render(map[string]string{
+		internal.PromptVariableContent: "",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chatTokenizer := tokenizers.Get(d.aiConfig.Ai.Models.ChatCompletion)
+	embeddingTokenizer := tokenizers.Get(d.aiConfig.Ai.Models.Embeddings)
+
+	var summaryPromptTokens int32
+	for _, message := range summaryMessages {
+		summaryPromptTokens += int32(chatTokenizer.CountTokens(message.Content))
+	}
+
+	for _, chunk := range chunks {
+		if chunk.Content == "" {
+			continue
+		}
+
+		if parser.SuggestSummarization() {
+			chunkTokens := int32(chatTokenizer.CountTokens(chunk.Content))
+			summaryTokens := min(chunkTokens, estimatedSummaryTokens)
+			usage.Add(d.aiConfig.Ai.Models.ChatCompletion, summaryPromptTokens+chunkTokens, summaryTokens)
+			usage.Add(d.aiConfig.Ai.Models.Embeddings, summaryTokens, 0)
+		} else {
+			usage.Add(d.aiConfig.Ai.Models.Embeddings, int32(embeddingTokenizer.CountTokens(chunk.Content)), 0)
+		}
+	}
+
+	return usage, nil
+}
+
 func (d *DocumentPrepService) IngestEmbedding(ctx context.Context, sourcePath string) error {
 	jsonBytes, err := os.ReadFile(sourcePath)
 	if err != nil {
@@ -205,6 +372,26 @@ func (d *DocumentPrepService) IngestEmbedding(ctx context.Context, sourcePath st
 		return err
 	}
 
+	// The search index stores metadata as a JSON string since the keys vary by document type.
+	// Indexes created before the metadata field was added reject documents with metadata, so it's left out.
+	if metadata, has := embeddingDoc["metadata"]; has {
+		hasMetadataField, err := d.indexHasField(ctx, "metadata")
+		if err != nil {
+			return err
+		}
+
+		if hasMetadataField {
+			metadataJson, err := json.Marshal(metadata)
+			if err != nil {
+				return err
+			}
+
+			embeddingDoc["metadata"] = string(metadataJson)
+		} else {
+			delete(embeddingDoc, "metadata")
+		}
+	}
+
 	batch := azsearchindex.IndexBatch{
 		Actions: []*azsearchindex.IndexAction{
 			{
@@ -225,3 +412,44 @@ func (d *DocumentPrepService) IngestEmbedding(ctx context.Context, sourcePath st
 
 	return nil
 }
+
+// indexHasField returns true when the search index has the field. The fields are loaded once, a failed lookup
+// such as a 403 before a new role assignment is enforced is returned and retried by the next ingestion.
+func (d *DocumentPrepService) indexHasField(ctx context.Context, name string) (bool, error) {
+	d.indexFieldsMutex.Lock()
+	defer d.indexFieldsMutex.Unlock()
+
+	if d.indexFields == nil {
+		indexResponse, err := d.indexesClient.Get(ctx, d.aiConfig.Search.Index, nil, nil)
+		if err != nil {
+			return false, fmt.Errorf("failed reading the fields of search index %s: %w", d.aiConfig.Search.Index, err)
+		}
+
+		indexFields := map[string]bool{}
+		for _, field := range indexResponse.Fields {
+			if field.Name != nil {
+				indexFields[*field.Name] = true
+			}
+		}
+
+		d.indexFields = indexFields
+	}
+
+	return d.indexFields[name], nil
+}
+
+// progressReader reports the bytes read from the reader.
+type progressReader struct {
+	reader     io.Reader
+	read       int64
+	total      int64
+	onProgress ProgressFunc
+}
+
+func (r *progressReader) Read(buffer []byte) (int, error) {
+	count, err := r.reader.Read(buffer)
+	r.read += int64(count)
+	r.onProgress(r.read, r.total)
+
+	return count, err
+}
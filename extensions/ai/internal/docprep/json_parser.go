@@ -1,6 +1,7 @@
 package docprep
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"os"
@@ -17,7 +18,7 @@ func (p *JsonParser) SuggestSummarization() bool {
 	return true
 }
 
-func (p *JsonParser) Parse(document *Document) ([]*DocumentChunk, error) {
+func (p *JsonParser) Parse(ctx context.Context, document *Document) ([]*DocumentChunk, error) {
 	rawBytes, err := os.ReadFile(document.Path)
 	if err != nil {
 		return nil, err
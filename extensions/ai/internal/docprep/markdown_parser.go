@@ -1,6 +1,7 @@
 package docprep
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -25,7 +26,7 @@ func (p *MarkdownParser) SuggestSummarization() bool {
 	return false
 }
 
-func (p *MarkdownParser) Parse(document *Document) ([]*DocumentChunk, error) {
+func (p *MarkdownParser) Parse(ctx context.Context, document *Document) ([]*DocumentChunk, error) {
 	rawBytes, err := os.ReadFile(document.Path)
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package docprep
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,7 +12,7 @@ func Test_Markdown(t *testing.T) {
 	document, err := ParseDocument("D:\\dev\\azure\\azure-sdk-blog\\posts\\2024\\08-06-azd-august-2024.md")
 	require.NoError(t, err)
 
-	chunks, err := parser.Parse(document)
+	chunks, err := parser.Parse(context.Background(), document)
 	require.NoError(t, err)
 	require.Greater(t, len(chunks), 0)
 }
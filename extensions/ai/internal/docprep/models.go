@@ -1,6 +1,7 @@
 package docprep
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -15,10 +16,12 @@ type EmbeddingDocument struct {
 	Content  string    `json:"content"`
 	Path     string    `json:"path"`
 	Vector   []float32 `json:"vector"`
+	// Metadata holds parser specific details about the chunk such as the timestamps of an audio transcript.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 type DocumentParser interface {
-	Parse(document *Document) ([]*DocumentChunk, error)
+	Parse(ctx context.Context, document *Document) ([]*DocumentChunk, error)
 	SuggestSummarization() bool
 }
 
@@ -55,4 +58,5 @@ type DocumentChunk struct {
 	Hash     string
 	Content  string
 	Path     string
+	Metadata map[string]string
 }
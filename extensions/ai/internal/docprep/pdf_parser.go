@@ -0,0 +1,46 @@
+package docprep
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// maxPdfDocumentTextSize is the largest text extracted from a PDF document when generating embeddings.
+const maxPdfDocumentTextSize = 10 * 1024 * 1024
+
+// PdfParser extracts the text of a PDF document with ExtractPdfText.
+// Documents that exceed the page, decompressed content or text size limits fail with ErrPdfTooLarge.
+type PdfParser struct {
+	maxTextSize int
+}
+
+func NewPdfParser(maxTextSize int) *PdfParser {
+	return &PdfParser{
+		maxTextSize: maxTextSize,
+	}
+}
+
+func (p *PdfParser) SuggestSummarization() bool {
+	return true
+}
+
+func (p *PdfParser) Parse(ctx context.Context, document *Document) ([]*DocumentChunk, error) {
+	content, err := ExtractPdfText(document.Path, p.maxTextSize)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceHash := sha256.Sum256([]byte(document.Path))
+	contentHash := sha256.Sum256([]byte(content))
+
+	fileChunk := &DocumentChunk{
+		Id:       hex.EncodeToString(sourceHash[:]),
+		ParentId: hex.EncodeToString(sourceHash[:]),
+		Hash:     hex.EncodeToString(contentHash[:]),
+		Content:  content,
+		Path:     document.Path,
+	}
+
+	return []*DocumentChunk{fileChunk}, nil
+}
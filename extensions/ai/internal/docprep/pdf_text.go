@@ -0,0 +1,156 @@
+package docprep
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	// maxPdfPages is the largest number of pages read from a PDF document.
+	maxPdfPages = 1000
+	// maxPdfContentSize is the largest total size of the decompressed page content and font streams read from a
+	// PDF document. Compressed streams can expand to many times the size of the document.
+	maxPdfContentSize = 64 * 1024 * 1024
+)
+
+var ErrPdfTooLarge = errors.New("pdf document is too large")
+
+// ExtractPdfText returns the text of the pages of the PDF document at the path, one line for each line of text.
+// Fonts with ToUnicode maps, compressed object streams and cross reference streams are supported.
+// ErrPdfTooLarge is returned when the document exceeds the page or decompressed size limits or when the text
+// is larger than maxTextSize.
+func ExtractPdfText(path string, maxTextSize int) (text string, err error) {
+	file, reader, err := pdf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed reading pdf: %w", err)
+	}
+
+	defer file.Close()
+
+	// The reader panics on malformed documents
+	defer func() {
+		if r := recover(); r != nil {
+			text = ""
+			err = fmt.Errorf("failed reading pdf: %v", r)
+		}
+	}()
+
+	pageCount := reader.NumPage()
+	if pageCount > maxPdfPages {
+		return "", fmt.Errorf("%w: %d pages, the limit is %d pages", ErrPdfTooLarge, pageCount, maxPdfPages)
+	}
+
+	var builder strings.Builder
+	contentSize := int64(0)
+
+	for i := 1; i <= pageCount; i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() || page.V.Key("Contents").Kind() == pdf.Null {
+			continue
+		}
+
+		// Streams are decompressed into a discarded buffer first so the content and font maps can't exhaust
+		// the memory while the text is extracted.
+		streams := []pdf.Value{page.V.Key("Contents")}
+		for _, name := range page.Fonts() {
+			streams = append(streams, page.Font(name).V.Key("ToUnicode"))
+		}
+
+		for _, stream := range streams {
+			size, err := pdfStreamSize(stream, maxPdfContentSize-contentSize)
+			if err != nil {
+				return "", err
+			}
+
+			contentSize += size
+		}
+
+		for _, line := range pdfTextLines(page.Content().Text) {
+			builder.WriteString(line)
+			builder.WriteString("\n")
+		}
+
+		if builder.Len() > maxTextSize {
+			return "", fmt.Errorf("%w: the text is larger than %d KB", ErrPdfTooLarge, maxTextSize/1024)
+		}
+	}
+
+	return strings.TrimSpace(builder.String()), nil
+}
+
+// pdfStreamSize returns the decompressed size of the stream or array of streams.
+// ErrPdfTooLarge is returned when the size exceeds the limit.
+func pdfStreamSize(value pdf.Value, limit int64) (int64, error) {
+	switch value.Kind() {
+	case pdf.Array:
+		total := int64(0)
+		for i := 0; i < value.Len(); i++ {
+			size, err := pdfStreamSize(value.Index(i), limit-total)
+			if err != nil {
+				return 0, err
+			}
+
+			total += size
+		}
+
+		return total, nil
+	case pdf.Stream:
+		reader := value.Reader()
+		defer reader.Close()
+
+		size, err := io.Copy(io.Discard, io.LimitReader(reader, limit+1))
+		if err != nil {
+			return 0, fmt.Errorf("failed reading pdf stream: %w", err)
+		}
+
+		if size > limit {
+			return 0, fmt.Errorf("%w: the decompressed content is larger than %d MB", ErrPdfTooLarge, maxPdfContentSize/1024/1024)
+		}
+
+		return size, nil
+	default:
+		return 0, nil
+	}
+}
+
+// pdfTextLines joins the glyphs of a page into lines. A glyph starts a new line when it moves up or down by more
+// than half of the font size and is separated by a space when there's a gap after the previous glyph.
+func pdfTextLines(glyphs []pdf.Text) []string {
+	lines := []string{}
+	var line strings.Builder
+	var previous *pdf.Text
+
+	flush := func() {
+		if text := strings.Join(strings.Fields(line.String()), " "); text != "" {
+			lines = append(lines, text)
+		}
+
+		line.Reset()
+	}
+
+	for i := range glyphs {
+		glyph := &glyphs[i]
+
+		if previous != nil {
+			fontSize := math.Max(glyph.FontSize, previous.FontSize)
+
+			if math.Abs(glyph.Y-previous.Y) > fontSize/2 {
+				flush()
+			} else if glyph.X-(previous.X+previous.W) > fontSize/4 {
+				line.WriteString(" ")
+			}
+		}
+
+		line.WriteString(glyph.S)
+		previous = glyph
+	}
+
+	flush()
+
+	return lines
+}
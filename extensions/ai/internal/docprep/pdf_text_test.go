@@ -0,0 +1,125 @@
+package docprep
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExtractPdfText(t *testing.T) {
+	t.Run("Text", func(t *testing.T) {
+		content := "BT /F1 12 Tf 72 720 Td (Quarterly \\(Q3\\) report) Tj 0 -14 Td [(Revenue ) -250 (grew)] TJ ET"
+		path := writeTestPdf(t, compressedPdfStream(t, content), "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+
+		text, err := ExtractPdfText(path, 1024)
+		require.NoError(t, err)
+		require.Equal(t, "Quarterly (Q3) report\nRevenue grew", text)
+	})
+
+	t.Run("ToUnicode", func(t *testing.T) {
+		cmap := "/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n" +
+			"1 begincodespacerange <0000> <FFFF> endcodespacerange\n" +
+			"2 beginbfchar <0001> <0048> <0002> <0069> endbfchar\n" +
+			"endcmap CMapName currentdict /CMap defineresource pop end end"
+		path := writeTestPdf(
+			t,
+			compressedPdfStream(t, "BT /F1 12 Tf 72 720 Td <00010002> Tj ET"),
+			"<< /Type /Font /Subtype /Type0 /BaseFont /Subset /Encoding /Identity-H /ToUnicode 6 0 R >>",
+			compressedPdfStream(t, cmap),
+		)
+
+		text, err := ExtractPdfText(path, 1024)
+		require.NoError(t, err)
+		require.Equal(t, "Hi", text)
+	})
+
+	t.Run("TextTooLarge", func(t *testing.T) {
+		content := "BT /F1 12 Tf 72 720 Td (" + strings.Repeat("a", 2048) + ") Tj ET"
+		path := writeTestPdf(t, compressedPdfStream(t, content), "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+
+		_, err := ExtractPdfText(path, 1024)
+		require.ErrorIs(t, err, ErrPdfTooLarge)
+	})
+
+	t.Run("DecompressedContentTooLarge", func(t *testing.T) {
+		content := strings.Repeat(" ", maxPdfContentSize+1)
+		path := writeTestPdf(t, compressedPdfStream(t, content), "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+
+		_, err := ExtractPdfText(path, 1024)
+		require.ErrorIs(t, err, ErrPdfTooLarge)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "malformed.pdf")
+		require.NoError(t, os.WriteFile(path, []byte("%PDF-1.4\nnot a pdf"), 0600))
+
+		_, err := ExtractPdfText(path, 1024)
+		require.Error(t, err)
+	})
+}
+
+func Test_PdfParser(t *testing.T) {
+	content := "BT /F1 12 Tf 72 720 Td (Quarterly report) Tj ET"
+	path := writeTestPdf(t, compressedPdfStream(t, content), "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+
+	document, err := ParseDocument(path)
+	require.NoError(t, err)
+
+	chunks, err := NewPdfParser(1024).Parse(context.Background(), document)
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	require.Equal(t, "Quarterly report", chunks[0].Content)
+	require.Equal(t, path, chunks[0].Path)
+}
+
+// compressedPdfStream returns a FlateDecode stream object with the content.
+func compressedPdfStream(t *testing.T, content string) string {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	_, err := writer.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.String())
+}
+
+// writeTestPdf writes a single page PDF document with the content stream, the font F1 and any additional objects,
+// which are numbered from 6.
+func writeTestPdf(t *testing.T, content string, font string, objects ...string) string {
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		content,
+		font,
+	}, objects...)
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xrefOffset := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	path := filepath.Join(t.TempDir(), "document.pdf")
+	require.NoError(t, os.WriteFile(path, document.Bytes(), 0600))
+
+	return path
+}
@@ -0,0 +1,448 @@
+package docprep
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactionAction is the action taken for chunks that contain sensitive content.
+type RedactionAction string
+
+const (
+	// RedactAction replaces the sensitive content with a placeholder before the chunk is sent to the model.
+	RedactAction RedactionAction = "redact"
+	// SkipAction excludes the chunk from the generated embeddings.
+	SkipAction RedactionAction = "skip"
+	// FlagAction keeps the chunk unchanged and records the findings in the report and the chunk metadata.
+	FlagAction RedactionAction = "flag"
+)
+
+// Names of the built-in detector sets.
+const (
+	PiiDetectors    = "pii"
+	SecretDetectors = "secrets"
+)
+
+// redactedMetadataKey is the chunk metadata key listing the categories found within a flagged or redacted chunk.
+const redactedMetadataKey = "redactions"
+
+var ErrUnsupportedRedactionAction = errors.New("unsupported redaction action")
+
+// Detection is a match of sensitive content within a chunk.
+type Detection struct {
+	Detector string
+	Category string
+	// Start and End are the byte offsets of the match within the content.
+	Start int
+	End   int
+}
+
+// Detector finds sensitive content within the content of a chunk.
+type Detector interface {
+	Name() string
+	Detect(content string) []*Detection
+}
+
+// RegexDetector detects content matching a regular expression.
+type RegexDetector struct {
+	name     string
+	category string
+	pattern  *regexp.Regexp
+}
+
+func NewRegexDetector(name string, category string, pattern *regexp.Regexp) *RegexDetector {
+	return &RegexDetector{
+		name:     name,
+		category: category,
+		pattern:  pattern,
+	}
+}
+
+func (d *RegexDetector) Name() string {
+	return d.name
+}
+
+func (d *RegexDetector) Detect(content string) []*Detection {
+	detections := []*Detection{}
+
+	for _, match := range d.pattern.FindAllStringSubmatchIndex(content, -1) {
+		start, end := match[0], match[1]
+
+		// Patterns with a capture group only redact the captured value, e.g. the value of `password=...`
+		if len(match) >= 4 && match[2] >= 0 {
+			start, end = match[2], match[3]
+		}
+
+		detections = append(detections, &Detection{
+			Detector: d.name,
+			Category: d.category,
+			Start:    start,
+			End:      end,
+		})
+	}
+
+	return detections
+}
+
+// DictionaryDetector detects whole word, case insensitive matches of a list of custom terms
+// such as internal project names or customer names.
+type DictionaryDetector struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+func NewDictionaryDetector(name string, terms []string) *DictionaryDetector {
+	quotedTerms := []string{}
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term != "" {
+			quotedTerms = append(quotedTerms, regexp.QuoteMeta(term))
+		}
+	}
+
+	// Longer terms first so the longest match wins for overlapping terms
+	sort.SliceStable(quotedTerms, func(i, j int) bool {
+		return len(quotedTerms[i]) > len(quotedTerms[j])
+	})
+
+	var pattern *regexp.Regexp
+	if len(quotedTerms) > 0 {
+		pattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join(quotedTerms, "|") + `)\b`)
+	}
+
+	return &DictionaryDetector{
+		name:    name,
+		pattern: pattern,
+	}
+}
+
+// LoadDictionaryDetector loads a dictionary with one term per line. Empty lines and lines starting with # are ignored.
+func LoadDictionaryDetector(path string) (*DictionaryDetector, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading dictionary: %w", err)
+	}
+
+	defer file.Close()
+
+	terms := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		terms = append(terms, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed loading dictionary: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	return NewDictionaryDetector(name, terms), nil
+}
+
+func (d *DictionaryDetector) Name() string {
+	return d.name
+}
+
+func (d *DictionaryDetector) Detect(content string) []*Detection {
+	detections := []*Detection{}
+	if d.pattern == nil {
+		return detections
+	}
+
+	for _, match := range d.pattern.FindAllStringIndex(content, -1) {
+		detections = append(detections, &Detection{
+			Detector: d.name,
+			Category: "dictionary",
+			Start:    match[0],
+			End:      match[1],
+		})
+	}
+
+	return detections
+}
+
+// NewPiiDetectors returns the detectors for personally identifiable information.
+func NewPiiDetectors() []Detector {
+	return []Detector{
+		NewRegexDetector("email", "pii", regexp.MustCompile(
+			`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`,
+		)),
+		NewRegexDetector("phone", "pii", regexp.MustCompile(
+			`(?:\+\d{1,3}[\s.\-]?)?\(?\b\d{3}\)?[\s.\-]\d{3}[\s.\-]\d{4}\b`,
+		)),
+		NewRegexDetector("credit-card", "pii", regexp.MustCompile(
+			`\b(?:\d{4}[\s\-]?){3}\d{4}\b`,
+		)),
+		NewRegexDetector("us-ssn", "pii", regexp.MustCompile(
+			`\b\d{3}-\d{2}-\d{4}\b`,
+		)),
+		NewRegexDetector("ip-address", "pii", regexp.MustCompile(
+			`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`,
+		)),
+	}
+}
+
+// NewSecretDetectors returns the detectors for keys, tokens and passwords.
+func NewSecretDetectors() []Detector {
+	return []Detector{
+		NewRegexDetector("private-key", "secret", regexp.MustCompile(
+			`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+		)),
+		NewRegexDetector("azure-storage-key", "secret", regexp.MustCompile(
+			`(?i)AccountKey=([a-zA-Z0-9+/=]{40,})`,
+		)),
+		NewRegexDetector("azure-sas-token", "secret", regexp.MustCompile(
+			`(?i)[?&]sig=([a-zA-Z0-9%+/=]{20,})`,
+		)),
+		NewRegexDetector("aws-access-key", "secret", regexp.MustCompile(
+			`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`,
+		)),
+		NewRegexDetector("github-token", "secret", regexp.MustCompile(
+			`\bgh[pousr]_[a-zA-Z0-9]{36,}\b`,
+		)),
+		NewRegexDetector("jwt", "secret", regexp.MustCompile(
+			`\beyJ[a-zA-Z0-9_\-]{10,}\.eyJ[a-zA-Z0-9_\-]{10,}\.[a-zA-Z0-9_\-]{10,}\b`,
+		)),
+		NewRegexDetector("password", "secret", regexp.MustCompile(
+			`(?i)\b(?:password|passwd|pwd|secret|api[_\-]?key|access[_\-]?key|client[_\-]?secret)\s*[:=]\s*["']?([^\s"';,]{6,})`,
+		)),
+	}
+}
+
+// NewDetectors returns the built-in detectors of the named sets.
+func NewDetectors(names []string) ([]Detector, error) {
+	detectors := []Detector{}
+
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case PiiDetectors:
+			detectors = append(detectors, NewPiiDetectors()...)
+		case SecretDetectors:
+			detectors = append(detectors, NewSecretDetectors()...)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown detectors '%s', supported detectors are %s, %s", name, PiiDetectors, SecretDetectors)
+		}
+	}
+
+	return detectors, nil
+}
+
+// ParseRedactionAction parses the action taken for chunks with sensitive content.
+func ParseRedactionAction(value string) (RedactionAction, error) {
+	action := RedactionAction(strings.ToLower(value))
+	if !slices.Contains([]RedactionAction{RedactAction, SkipAction, FlagAction}, action) {
+		return "", fmt.Errorf(
+			"%w '%s', supported actions are %s, %s, %s",
+			ErrUnsupportedRedactionAction,
+			value,
+			RedactAction,
+			SkipAction,
+			FlagAction,
+		)
+	}
+
+	return action, nil
+}
+
+// RedactionFinding describes where sensitive content was found. The sensitive value itself is never recorded.
+type RedactionFinding struct {
+	Path     string `json:"path"`
+	ChunkId  string `json:"chunkId"`
+	Detector string `json:"detector"`
+	Category string `json:"category"`
+	// Line and Column are the 1-based position of the match within the chunk content.
+	Line   int             `json:"line"`
+	Column int             `json:"column"`
+	Length int             `json:"length"`
+	Action RedactionAction `json:"action"`
+}
+
+// RedactionReport collects the findings of all processed documents.
+type RedactionReport struct {
+	Action RedactionAction `json:"action"`
+	// Detections counts the findings by detector.
+	Detections    map[string]int      `json:"detections"`
+	SkippedChunks int                 `json:"skippedChunks"`
+	Findings      []*RedactionFinding `json:"findings"`
+
+	mutex sync.Mutex
+}
+
+func (r *RedactionReport) add(findings []*RedactionFinding, skipped bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, finding := range findings {
+		r.Detections[finding.Detector]++
+	}
+
+	r.Findings = append(r.Findings, findings...)
+
+	if skipped {
+		r.SkippedChunks++
+	}
+}
+
+// Sorted returns the findings ordered by path and position.
+func (r *RedactionReport) Sorted() []*RedactionFinding {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	findings := slices.Clone(r.Findings)
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+
+		return findings[i].Column < findings[j].Column
+	})
+
+	return findings
+}
+
+// Redactor runs the detectors against each chunk before it is summarized or embedded.
+// All detection runs locally, no content is sent to a service.
+type Redactor struct {
+	action    RedactionAction
+	detectors []Detector
+	report    *RedactionReport
+}
+
+func NewRedactor(action RedactionAction, detectors []Detector) *Redactor {
+	return &Redactor{
+		action:    action,
+		detectors: detectors,
+		report: &RedactionReport{
+			Action:     action,
+			Detections: map[string]int{},
+			Findings:   []*RedactionFinding{},
+		},
+	}
+}
+
+// Report returns the findings of all chunks processed so far.
+func (r *Redactor) Report() *RedactionReport {
+	return r.report
+}
+
+// Apply runs the detectors against the chunk and returns false when the chunk should be skipped.
+// Redacted chunks have their content replaced in place.
+func (r *Redactor) Apply(path string, chunk *DocumentChunk) bool {
+	detections := r.detect(chunk.Content)
+	if len(detections) == 0 {
+		return true
+	}
+
+	findings := make([]*RedactionFinding, len(detections))
+	categories := []string{}
+
+	for i, detection := range detections {
+		line, column := lineColumn(chunk.Content, detection.Start)
+		findings[i] = &RedactionFinding{
+			Path:     path,
+			ChunkId:  chunk.Id,
+			Detector: detection.Detector,
+			Category: detection.Category,
+			Line:     line,
+			Column:   column,
+			Length:   detection.End - detection.Start,
+			Action:   r.action,
+		}
+
+		if !slices.Contains(categories, detection.Category) {
+			categories = append(categories, detection.Category)
+		}
+	}
+
+	skipped := r.action == SkipAction
+	r.report.add(findings, skipped)
+
+	if skipped {
+		return false
+	}
+
+	if r.action == RedactAction {
+		chunk.Content = redact(chunk.Content, detections)
+	}
+
+	if chunk.Metadata == nil {
+		chunk.Metadata = map[string]string{}
+	}
+
+	chunk.Metadata[redactedMetadataKey] = strings.Join(categories, ",")
+
+	return true
+}
+
+// detect returns the non overlapping detections ordered by position, the earliest and longest match wins.
+func (r *Redactor) detect(content string) []*Detection {
+	detections := []*Detection{}
+	for _, detector := range r.detectors {
+		detections = append(detections, detector.Detect(content)...)
+	}
+
+	sort.SliceStable(detections, func(i, j int) bool {
+		if detections[i].Start != detections[j].Start {
+			return detections[i].Start < detections[j].Start
+		}
+
+		return detections[i].End > detections[j].End
+	})
+
+	result := []*Detection{}
+	end := -1
+
+	for _, detection := range detections {
+		if detection.Start < end || detection.Start == detection.End {
+			continue
+		}
+
+		result = append(result, detection)
+		end = detection.End
+	}
+
+	return result
+}
+
+func redact(content string, detections []*Detection) string {
+	var builder strings.Builder
+	position := 0
+
+	for _, detection := range detections {
+		builder.WriteString(content[position:detection.Start])
+		builder.WriteString(fmt.Sprintf("[REDACTED:%s]", detection.Detector))
+		position = detection.End
+	}
+
+	builder.WriteString(content[position:])
+
+	return builder.String()
+}
+
+// lineColumn returns the 1-based line and column of the byte offset.
+func lineColumn(content string, offset int) (int, int) {
+	before := content[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := offset - strings.LastIndex(before, "\n")
+
+	return line, column
+}
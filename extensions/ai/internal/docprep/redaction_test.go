@@ -0,0 +1,71 @@
+package docprep
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Redactor(t *testing.T) {
+	content := "Contact jane.doe@contoso.com or 425-555-0100.\n" +
+		"DefaultEndpointsProtocol=https;AccountKey=c2VjcmV0c2VjcmV0c2VjcmV0c2VjcmV0c2VjcmV0c2VjcmV0;\n" +
+		"Project Falcon ships next week."
+
+	newDetectors := func() []Detector {
+		detectors := append(NewPiiDetectors(), NewSecretDetectors()...)
+		return append(detectors, NewDictionaryDetector("codenames", []string{"falcon"}))
+	}
+
+	t.Run("Redact", func(t *testing.T) {
+		redactor := NewRedactor(RedactAction, newDetectors())
+		chunk := &DocumentChunk{Id: "chunk-1", Content: content}
+
+		require.True(t, redactor.Apply("docs/contacts.md", chunk))
+		require.Equal(t,
+			"Contact [REDACTED:email] or [REDACTED:phone].\n"+
+				"DefaultEndpointsProtocol=https;AccountKey=[REDACTED:azure-storage-key];\n"+
+				"Project [REDACTED:codenames] ships next week.",
+			chunk.Content,
+		)
+		require.Equal(t, "pii,secret,dictionary", chunk.Metadata["redactions"])
+
+		findings := redactor.Report().Sorted()
+		require.Len(t, findings, 4)
+		require.Equal(t, "email", findings[0].Detector)
+		require.Equal(t, 1, findings[0].Line)
+		require.Equal(t, 9, findings[0].Column)
+		require.Equal(t, "azure-storage-key", findings[2].Detector)
+		require.Equal(t, 2, findings[2].Line)
+		require.Equal(t, "docs/contacts.md", findings[2].Path)
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		redactor := NewRedactor(SkipAction, newDetectors())
+		chunk := &DocumentChunk{Id: "chunk-1", Content: content}
+
+		require.False(t, redactor.Apply("docs/contacts.md", chunk))
+		require.Equal(t, content, chunk.Content)
+		require.Equal(t, 1, redactor.Report().SkippedChunks)
+
+		require.True(t, redactor.Apply("docs/readme.md", &DocumentChunk{Content: "Nothing to see here."}))
+	})
+
+	t.Run("Flag", func(t *testing.T) {
+		redactor := NewRedactor(FlagAction, NewSecretDetectors())
+		chunk := &DocumentChunk{Id: "chunk-1", Content: content}
+
+		require.True(t, redactor.Apply("docs/contacts.md", chunk))
+		require.Equal(t, content, chunk.Content)
+		require.Equal(t, "secret", chunk.Metadata["redactions"])
+		require.Equal(t, 1, redactor.Report().Detections["azure-storage-key"])
+	})
+
+	t.Run("ParseAction", func(t *testing.T) {
+		action, err := ParseRedactionAction("Skip")
+		require.NoError(t, err)
+		require.Equal(t, SkipAction, action)
+
+		_, err = ParseRedactionAction("delete")
+		require.ErrorIs(t, err, ErrUnsupportedRedactionAction)
+	})
+}
@@ -0,0 +1,599 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/search/armsearch"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/fatih/color"
+	"github.com/wbreza/azd-extensions/sdk/azure"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azd-extensions/sdk/ext/account"
+	"github.com/wbreza/azure-sdk-for-go/sdk/data/azsearch"
+)
+
+// ErrDoctorSkipped is returned by checks that cannot run because a prerequisite check failed.
+var ErrDoctorSkipped = errors.New("skipped, a prerequisite check failed")
+
+const endpointTimeout = 10 * time.Second
+
+// DoctorCheck is a single diagnostic run by `azd ai doctor`.
+type DoctorCheck struct {
+	Title string
+	Run   func(ctx context.Context) error
+}
+
+// DoctorIssue is returned by a check that found a problem, it includes the command that fixes it.
+type DoctorIssue struct {
+	Err        error
+	Suggestion string
+	// Warning issues don't prevent the AI commands from running but may cause unexpected results.
+	Warning bool
+}
+
+func (i *DoctorIssue) Error() string {
+	return i.Err.Error()
+}
+
+func (i *DoctorIssue) Unwrap() error {
+	return i.Err
+}
+
+// Doctor diagnoses the configuration and permissions of the AI resources referenced by the extension config.
+// Checks run in order and resources resolved by earlier checks are reused by later checks.
+type Doctor struct {
+	azdContext       *ext.Context
+	credential       azcore.TokenCredential
+	armClientOptions *arm.ClientOptions
+	azClientOptions  *azcore.ClientOptions
+
+	principal      *account.Principal
+	config         *ExtensionConfig
+	resourceGroup  bool
+	aiAccount      *armcognitiveservices.Account
+	storageAccount *armstorage.Account
+	searchService  *armsearch.Service
+	searchIndex    *azsearch.Index
+	embeddingsOk   bool
+}
+
+func NewDoctor(azdContext *ext.Context) (*Doctor, error) {
+	credential, err := azdContext.Credential()
+	if err != nil {
+		return nil, err
+	}
+
+	var armClientOptions *arm.ClientOptions
+	var azClientOptions *azcore.ClientOptions
+
+	azdContext.Invoke(func(options1 *arm.ClientOptions, options2 *azcore.ClientOptions) error {
+		armClientOptions = options1
+		azClientOptions = options2
+		return nil
+	})
+
+	return &Doctor{
+		azdContext:       azdContext,
+		credential:       credential,
+		armClientOptions: armClientOptions,
+		azClientOptions:  azClientOptions,
+	}, nil
+}
+
+// PrerequisiteChecks returns the checks for the sign in and extension configuration.
+func (d *Doctor) PrerequisiteChecks() []*DoctorCheck {
+	return []*DoctorCheck{
+		{Title: "Azure sign in", Run: d.checkSignIn},
+		{Title: "AI configuration", Run: d.checkConfig},
+	}
+}
+
+// ResourceChecks returns the checks for each of the configured resources.
+// The configuration is loaded by the prerequisite checks, no checks are returned until they have run successfully.
+func (d *Doctor) ResourceChecks() []*DoctorCheck {
+	if d.config == nil {
+		return nil
+	}
+
+	checks := []*DoctorCheck{
+		{
+			Title: fmt.Sprintf("Subscription %s", color.CyanString(d.config.Subscription)),
+			Run:   d.checkSubscription,
+		},
+		{
+			Title: fmt.Sprintf("Resource group %s", color.CyanString(d.config.ResourceGroup)),
+			Run:   d.checkResourceGroup,
+		},
+		{
+			Title: fmt.Sprintf("Azure AI service %s", color.CyanString(d.config.Ai.Service)),
+			Run:   d.checkAiService,
+		},
+		{
+			Title: "Azure AI service endpoint",
+			Run: func(ctx context.Context) error {
+				if d.aiAccount == nil {
+					return ErrDoctorSkipped
+				}
+
+				return checkEndpoint(ctx, d.config.Ai.Endpoint)
+			},
+		},
+		{
+			Title: "Azure AI service role assignments",
+			Run: func(ctx context.Context) error {
+				if d.aiAccount == nil {
+					return ErrDoctorSkipped
+				}
+
+				return d.checkRoles(ctx, *d.aiAccount.ID, azure.RoleCognitiveServicesOpenAIContributor)
+			},
+		},
+	}
+
+	for _, deployment := range []struct {
+		kind string
+		name string
+	}{
+		{kind: "Chat completion", name: d.config.Ai.Models.ChatCompletion},
+		{kind: "Embeddings", name: d.config.Ai.Models.Embeddings},
+		{kind: "Audio", name: d.config.Ai.Models.Audio},
+	} {
+		if deployment.name == "" {
+			continue
+		}
+
+		checks = append(checks, &DoctorCheck{
+			Title: fmt.Sprintf("%s deployment %s", deployment.kind, color.CyanString(deployment.name)),
+			Run: func(ctx context.Context) error {
+				return d.checkDeployment(ctx, deployment.name)
+			},
+		})
+	}
+
+	if d.config.Storage.Account != "" {
+		checks = append(checks,
+			&DoctorCheck{
+				Title: fmt.Sprintf("Storage account %s", color.CyanString(d.config.Storage.Account)),
+				Run:   d.checkStorage,
+			},
+			&DoctorCheck{
+				Title: fmt.Sprintf("Storage container %s", color.CyanString(d.config.Storage.Container)),
+				Run:   d.checkStorageContainer,
+			},
+			&DoctorCheck{
+				Title: "Storage endpoint",
+				Run: func(ctx context.Context) error {
+					if d.storageAccount == nil {
+						return ErrDoctorSkipped
+					}
+
+					return checkEndpoint(ctx, d.config.Storage.Endpoint)
+				},
+			},
+			&DoctorCheck{
+				Title: "Storage role assignments",
+				Run: func(ctx context.Context) error {
+					if d.storageAccount == nil {
+						return ErrDoctorSkipped
+					}
+
+					return d.checkRoles(ctx, *d.storageAccount.ID, azure.RoleDefinitionStorageBlobDataContributor)
+				},
+			},
+		)
+	}
+
+	if d.config.Search.Service != "" {
+		checks = append(checks,
+			&DoctorCheck{
+				Title: fmt.Sprintf("Azure AI Search service %s", color.CyanString(d.config.Search.Service)),
+				Run:   d.checkSearch,
+			},
+			&DoctorCheck{
+				Title: "Azure AI Search endpoint",
+				Run: func(ctx context.Context) error {
+					if d.searchService == nil {
+						return ErrDoctorSkipped
+					}
+
+					return checkEndpoint(ctx, d.config.Search.Endpoint)
+				},
+			},
+			&DoctorCheck{
+				Title: "Azure AI Search role assignments",
+				Run: func(ctx context.Context) error {
+					if d.searchService == nil {
+						return ErrDoctorSkipped
+					}
+
+					return d.checkRoles(
+						ctx,
+						*d.searchService.ID,
+						azure.RoleSearchIndexDataContributor,
+						azure.RoleSearchServiceContributor,
+					)
+				},
+			},
+			&DoctorCheck{
+				Title: fmt.Sprintf("Azure AI Search index %s", color.CyanString(d.config.Search.Index)),
+				Run:   d.checkSearchIndex,
+			},
+		)
+
+		if d.config.Ai.Models.Embeddings != "" {
+			checks = append(checks, &DoctorCheck{
+				Title: "Embedding dimensions match search index",
+				Run:   d.checkEmbeddingDimensions,
+			})
+		}
+	}
+
+	return checks
+}
+
+func (d *Doctor) checkSignIn(ctx context.Context) error {
+	principal, err := d.azdContext.Principal(ctx)
+	if err != nil {
+		return &DoctorIssue{
+			Err:        err,
+			Suggestion: "azd auth login",
+		}
+	}
+
+	d.principal = principal
+
+	return nil
+}
+
+func (d *Doctor) checkConfig(ctx context.Context) error {
+	config, err := LoadExtensionConfig(ctx, d.azdContext)
+	if err != nil {
+		return &DoctorIssue{
+			Err:        err,
+			Suggestion: "azd ai setup",
+		}
+	}
+
+	missing := []string{}
+	if config.Subscription == "" {
+		missing = append(missing, "subscription")
+	}
+
+	if config.ResourceGroup == "" {
+		missing = append(missing, "resource group")
+	}
+
+	if config.Ai.Service == "" {
+		missing = append(missing, "AI service")
+	}
+
+	if len(missing) > 0 {
+		return &DoctorIssue{
+			Err:        fmt.Errorf("configuration is missing %v", missing),
+			Suggestion: "azd ai setup --reset",
+		}
+	}
+
+	d.config = config
+
+	return nil
+}
+
+func (d *Doctor) checkSubscription(ctx context.Context) error {
+	if d.principal == nil {
+		return ErrDoctorSkipped
+	}
+
+	return d.azdContext.Invoke(func(subscriptionService *azure.SubscriptionsService) error {
+		if _, err := subscriptionService.GetSubscription(ctx, d.config.Subscription, d.principal.TenantId); err != nil {
+			return &DoctorIssue{
+				Err:        err,
+				Suggestion: "azd ai setup --reset",
+			}
+		}
+
+		return nil
+	})
+}
+
+func (d *Doctor) checkResourceGroup(ctx context.Context) error {
+	if d.principal == nil {
+		return ErrDoctorSkipped
+	}
+
+	return d.azdContext.Invoke(func(resourceService *azure.ResourceService) error {
+		if _, err := resourceService.GetResourceGroup(ctx, d.config.Subscription, d.config.ResourceGroup); err != nil {
+			return &DoctorIssue{
+				Err:        resourceError("resource group", d.config.ResourceGroup, err),
+				Suggestion: "azd ai setup --reset",
+			}
+		}
+
+		d.resourceGroup = true
+
+		return nil
+	})
+}
+
+func (d *Doctor) checkAiService(ctx context.Context) error {
+	if !d.resourceGroup {
+		return ErrDoctorSkipped
+	}
+
+	accountsClient, err := armcognitiveservices.NewAccountsClient(d.config.Subscription, d.credential, d.armClientOptions)
+	if err != nil {
+		return err
+	}
+
+	accountResponse, err := accountsClient.Get(ctx, d.config.ResourceGroup, d.config.Ai.Service, nil)
+	if err != nil {
+		return &DoctorIssue{
+			Err:        resourceError("AI service", d.config.Ai.Service, err),
+			Suggestion: "azd ai service set",
+		}
+	}
+
+	d.aiAccount = &accountResponse.Account
+
+	if d.aiAccount.Properties != nil && d.aiAccount.Properties.Endpoint != nil &&
+		*d.aiAccount.Properties.Endpoint != d.config.Ai.Endpoint {
+		return &DoctorIssue{
+			Err:        fmt.Errorf("configured endpoint does not match %s", *d.aiAccount.Properties.Endpoint),
+			Suggestion: "azd ai service set",
+			Warning:    true,
+		}
+	}
+
+	return nil
+}
+
+func (d *Doctor) checkDeployment(ctx context.Context, deploymentName string) error {
+	if d.aiAccount == nil {
+		return ErrDoctorSkipped
+	}
+
+	if _, err := GetModelDeploymentInfo(ctx, d.azdContext, d.config, deploymentName); err != nil {
+		suggestion := "azd ai model deployment select"
+		if deploymentName != d.config.Ai.Models.ChatCompletion {
+			suggestion = "azd ai setup --reset"
+		}
+
+		return &DoctorIssue{
+			Err:        resourceError("model deployment", deploymentName, err),
+			Suggestion: suggestion,
+		}
+	}
+
+	if deploymentName == d.config.Ai.Models.Embeddings {
+		d.embeddingsOk = true
+	}
+
+	return nil
+}
+
+func (d *Doctor) checkStorage(ctx context.Context) error {
+	if !d.resourceGroup {
+		return ErrDoctorSkipped
+	}
+
+	accountsClient, err := armstorage.NewAccountsClient(d.config.Subscription, d.credential, d.armClientOptions)
+	if err != nil {
+		return err
+	}
+
+	accountResponse, err := accountsClient.GetProperties(ctx, d.config.ResourceGroup, d.config.Storage.Account, nil)
+	if err != nil {
+		return &DoctorIssue{
+			Err:        resourceError("storage account", d.config.Storage.Account, err),
+			Suggestion: "azd ai setup --reset",
+		}
+	}
+
+	d.storageAccount = &accountResponse.Account
+
+	return nil
+}
+
+func (d *Doctor) checkStorageContainer(ctx context.Context) error {
+	if d.storageAccount == nil {
+		return ErrDoctorSkipped
+	}
+
+	containersClient, err := armstorage.NewBlobContainersClient(d.config.Subscription, d.credential, d.armClientOptions)
+	if err != nil {
+		return err
+	}
+
+	if _, err := containersClient.Get(ctx, d.config.ResourceGroup, d.config.Storage.Account, d.config.Storage.Container, nil); err != nil {
+		return &DoctorIssue{
+			Err:        resourceError("storage container", d.config.Storage.Container, err),
+			Suggestion: "azd ai setup --reset",
+		}
+	}
+
+	return nil
+}
+
+func (d *Doctor) checkSearch(ctx context.Context) error {
+	if !d.resourceGroup {
+		return ErrDoctorSkipped
+	}
+
+	servicesClient, err := armsearch.NewServicesClient(d.config.Subscription, d.credential, d.armClientOptions)
+	if err != nil {
+		return err
+	}
+
+	serviceResponse, err := servicesClient.Get(ctx, d.config.ResourceGroup, d.config.Search.Service, nil, nil)
+	if err != nil {
+		return &DoctorIssue{
+			Err:        resourceError("search service", d.config.Search.Service, err),
+			Suggestion: "azd ai setup --reset",
+		}
+	}
+
+	d.searchService = &serviceResponse.Service
+
+	return nil
+}
+
+func (d *Doctor) checkSearchIndex(ctx context.Context) error {
+	if d.searchService == nil {
+		return ErrDoctorSkipped
+	}
+
+	indexesClient, err := azsearch.NewIndexesClient(d.config.Search.Endpoint, d.credential, d.azClientOptions)
+	if err != nil {
+		return err
+	}
+
+	indexResponse, err := indexesClient.Get(ctx, d.config.Search.Index, nil, nil)
+	if err != nil {
+		return &DoctorIssue{
+			Err:        resourceError("search index", d.config.Search.Index, err),
+			Suggestion: "azd ai index create",
+		}
+	}
+
+	d.searchIndex = &indexResponse.Index
+
+	return nil
+}
+
+func (d *Doctor) checkEmbeddingDimensions(ctx context.Context) error {
+	if d.searchIndex == nil || !d.embeddingsOk {
+		return ErrDoctorSkipped
+	}
+
+	var indexDimensions int32
+	for _, field := range d.searchIndex.Fields {
+		if field.VectorSearchDimensions != nil {
+			indexDimensions = *field.VectorSearchDimensions
+			break
+		}
+	}
+
+	if indexDimensions == 0 {
+		return &DoctorIssue{
+			Err:        fmt.Errorf("index %s does not contain a vector field", d.config.Search.Index),
+			Suggestion: "azd ai index create",
+		}
+	}
+
+	openAiClient, err := azopenai.NewClient(d.config.Ai.Endpoint, d.credential, &azopenai.ClientOptions{
+		ClientOptions: *d.azClientOptions,
+	})
+	if err != nil {
+		return err
+	}
+
+	embeddingsResponse, err := openAiClient.GetEmbeddings(ctx, azopenai.EmbeddingsOptions{
+		DeploymentName: &d.config.Ai.Models.Embeddings,
+		Input:          []string{"azd ai doctor"},
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(embeddingsResponse.Data) == 0 {
+		return errors.New("embeddings deployment returned no data")
+	}
+
+	embeddingDimensions := len(embeddingsResponse.Data[0].Embedding)
+	if embeddingDimensions != int(indexDimensions) {
+		return &DoctorIssue{
+			Err: fmt.Errorf(
+				"embeddings deployment produces %d dimensions, index expects %d",
+				embeddingDimensions,
+				indexDimensions,
+			),
+			Suggestion: "azd ai index create",
+		}
+	}
+
+	return nil
+}
+
+// checkRoles verifies the signed in principal holds each of the roles on the scope.
+func (d *Doctor) checkRoles(ctx context.Context, scope string, roleNames ...azure.RoleName) error {
+	if d.principal == nil {
+		return ErrDoctorSkipped
+	}
+
+	return d.azdContext.Invoke(func(entraIdService *azure.EntraIdService) error {
+		missingRoles := []error{}
+		suggestions := []string{}
+
+		for _, roleName := range roleNames {
+			hasRole, err := entraIdService.HasRoleAssignment(ctx, d.config.Subscription, scope, d.principal.Oid, roleName)
+			if err != nil {
+				return err
+			}
+
+			if !hasRole {
+				missingRoles = append(missingRoles, fmt.Errorf("missing role '%s'", roleName))
+				suggestions = append(suggestions, fmt.Sprintf(
+					"az role assignment create --assignee %s --role \"%s\" --scope %s",
+					d.principal.Oid,
+					roleName,
+					scope,
+				))
+			}
+		}
+
+		if len(missingRoles) == 0 {
+			return nil
+		}
+
+		return &DoctorIssue{
+			Err:        errors.Join(missingRoles...),
+			Suggestion: strings.Join(suggestions, "\n"),
+		}
+	})
+}
+
+// checkEndpoint verifies the endpoint can be reached, any HTTP response is considered reachable.
+func checkEndpoint(ctx context.Context, endpoint string) error {
+	if endpoint == "" {
+		return &DoctorIssue{
+			Err:        errors.New("endpoint is not configured"),
+			Suggestion: "azd ai setup --reset",
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, endpointTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return &DoctorIssue{
+			Err:        fmt.Errorf("endpoint %s is not reachable: %w", endpoint, err),
+			Suggestion: "Verify network access and firewall rules for the resource",
+		}
+	}
+	defer res.Body.Close()
+
+	return nil
+}
+
+func resourceError(resourceType string, name string, err error) error {
+	if isNotFound(err) {
+		return fmt.Errorf("%s %s not found", resourceType, name)
+	}
+
+	return err
+}
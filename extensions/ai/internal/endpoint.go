@@ -0,0 +1,307 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultEndpointTimeout is the time allowed for each request to the evaluated endpoint.
+	DefaultEndpointTimeout = 60 * time.Second
+	// maxEndpointErrorBody limits the response body included in errors.
+	maxEndpointErrorBody = 512
+)
+
+// EndpointMapping maps test cases to requests of an HTTP endpoint and its responses to answers.
+type EndpointMapping struct {
+	Request  EndpointRequestMapping  `yaml:"request"`
+	Response EndpointResponseMapping `yaml:"response"`
+}
+
+// EndpointRequestMapping is the template of the request sent for each test case.
+// The `{{question}}` and `{{id}}` variables are JSON escaped in the body and URL escaped in the URL.
+// Environment variables such as `${API_KEY}` are expanded in the header values.
+type EndpointRequestMapping struct {
+	Method  string            `yaml:"method,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Body    string            `yaml:"body,omitempty"`
+}
+
+// EndpointResponseMapping contains the JSONPath expressions selecting values from the response body.
+// Only the answer is required, unmatched token usage paths are reported as zero.
+type EndpointResponseMapping struct {
+	Answer           string `yaml:"answer"`
+	Context          string `yaml:"context,omitempty"`
+	PromptTokens     string `yaml:"promptTokens,omitempty"`
+	CompletionTokens string `yaml:"completionTokens,omitempty"`
+	TotalTokens      string `yaml:"totalTokens,omitempty"`
+}
+
+// DefaultEndpointMapping maps test cases to OpenAI compatible chat completion requests, such as the ones
+// served by `azd ai serve`.
+func DefaultEndpointMapping() *EndpointMapping {
+	return &EndpointMapping{
+		Request: EndpointRequestMapping{
+			Method: http.MethodPost,
+			Body:   `{"messages": [{"role": "user", "content": "{{question}}"}]}`,
+		},
+		Response: EndpointResponseMapping{
+			Answer:           "$.choices[0].message.content",
+			PromptTokens:     "$.usage.prompt_tokens",
+			CompletionTokens: "$.usage.completion_tokens",
+			TotalTokens:      "$.usage.total_tokens",
+		},
+	}
+}
+
+// LoadEndpointMapping loads a mapping from a YAML or JSON file.
+func LoadEndpointMapping(path string) (*EndpointMapping, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := &EndpointMapping{}
+	if err := yaml.Unmarshal(content, mapping); err != nil {
+		return nil, fmt.Errorf("failed parsing endpoint mapping '%s': %w", path, err)
+	}
+
+	if mapping.Request.Method == "" {
+		mapping.Request.Method = http.MethodPost
+	}
+
+	return mapping, nil
+}
+
+// EndpointClient queries an HTTP endpoint with the requests described by the mapping.
+type EndpointClient struct {
+	url        string
+	mapping    *EndpointMapping
+	httpClient *http.Client
+
+	answerPath           *JsonPath
+	contextPath          *JsonPath
+	promptTokensPath     *JsonPath
+	completionTokensPath *JsonPath
+	totalTokensPath      *JsonPath
+}
+
+// NewEndpointClient validates the mapping and creates the client.
+func NewEndpointClient(endpointUrl string, mapping *EndpointMapping, timeout time.Duration) (*EndpointClient, error) {
+	parsedUrl, err := url.Parse(endpointUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint URL '%s': an absolute http or https URL is required", endpointUrl)
+	}
+
+	if mapping.Response.Answer == "" {
+		return nil, errors.New("the endpoint mapping requires a JSONPath for the answer")
+	}
+
+	client := &EndpointClient{
+		url:        endpointUrl,
+		mapping:    mapping,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+
+	paths := []struct {
+		expression string
+		target     **JsonPath
+	}{
+		{mapping.Response.Answer, &client.answerPath},
+		{mapping.Response.Context, &client.contextPath},
+		{mapping.Response.PromptTokens, &client.promptTokensPath},
+		{mapping.Response.CompletionTokens, &client.completionTokensPath},
+		{mapping.Response.TotalTokens, &client.totalTokensPath},
+	}
+
+	for _, path := range paths {
+		if path.expression == "" {
+			continue
+		}
+
+		*path.target, err = ParseJsonPath(path.expression)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// Url returns the URL of the endpoint.
+func (c *EndpointClient) Url() string {
+	return c.url
+}
+
+// Mapping returns the request and response mapping of the endpoint.
+func (c *EndpointClient) Mapping() *EndpointMapping {
+	return c.mapping
+}
+
+// Query sends the request for the test case and maps the response. The duration is measured on the client side
+// and includes the network latency.
+func (c *EndpointClient) Query(ctx context.Context, testCase *EvaluationTestCase) (*ModelResponse, error) {
+	variables := map[string]string{
+		"question": testCase.Question,
+		"id":       testCase.Id,
+	}
+
+	requestUrl := renderEndpointTemplate(c.url, variables, url.QueryEscape)
+	body := renderEndpointTemplate(c.mapping.Request.Body, variables, jsonEscape)
+
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, c.mapping.Request.Method, requestUrl, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	for name, value := range c.mapping.Request.Headers {
+		request.Header.Set(name, os.ExpandEnv(value))
+	}
+
+	startTime := time.Now()
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	duration := time.Since(startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf(
+			"endpoint returned status %d: %s",
+			response.StatusCode,
+			truncate(string(bytes.TrimSpace(responseBody)), maxEndpointErrorBody),
+		)
+	}
+
+	modelResponse, err := c.mapResponse(responseBody)
+	if err != nil {
+		return nil, err
+	}
+
+	modelResponse.Duration = int(duration.Milliseconds())
+
+	return modelResponse, nil
+}
+
+// mapResponse selects the answer, context and token usage from the response body.
+func (c *EndpointClient) mapResponse(body []byte) (*ModelResponse, error) {
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("endpoint response is not valid JSON: %w", err)
+	}
+
+	answers := c.answerPath.Select(document)
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("answer path '%s' didn't match the endpoint response", c.answerPath)
+	}
+
+	modelResponse := &ModelResponse{
+		Message: jsonValueText(answers[0]),
+	}
+
+	if c.contextPath != nil {
+		for _, match := range c.contextPath.Select(document) {
+			if items, ok := match.([]any); ok {
+				for _, item := range items {
+					modelResponse.Context = append(modelResponse.Context, jsonValueText(item))
+				}
+
+				continue
+			}
+
+			modelResponse.Context = append(modelResponse.Context, jsonValueText(match))
+		}
+	}
+
+	modelResponse.TokenUsage.PromptTokens = selectTokens(c.promptTokensPath, document)
+	modelResponse.TokenUsage.CompletionTokens = selectTokens(c.completionTokensPath, document)
+	modelResponse.TokenUsage.TotalTokens = selectTokens(c.totalTokensPath, document)
+
+	if modelResponse.TokenUsage.TotalTokens == 0 {
+		modelResponse.TokenUsage.TotalTokens = modelResponse.TokenUsage.PromptTokens + modelResponse.TokenUsage.CompletionTokens
+	}
+
+	return modelResponse, nil
+}
+
+func selectTokens(path *JsonPath, document any) int32 {
+	if path == nil {
+		return 0
+	}
+
+	for _, match := range path.Select(document) {
+		if value, ok := match.(float64); ok {
+			return int32(value)
+		}
+	}
+
+	return 0
+}
+
+// jsonValueText returns strings as is and encodes all other values as JSON.
+func jsonValueText(value any) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(jsonBytes)
+}
+
+// renderEndpointTemplate replaces the variables of the template with the escaped values.
+// Unknown variables are kept as is.
+func renderEndpointTemplate(template string, variables map[string]string, escape func(string) string) string {
+	return promptVariableRegex.ReplaceAllStringFunc(template, func(match string) string {
+		value, has := variables[promptVariableRegex.FindStringSubmatch(match)[1]]
+		if !has {
+			return match
+		}
+
+		return escape(value)
+	})
+}
+
+// jsonEscape escapes the value for use within a JSON string.
+func jsonEscape(value string) string {
+	jsonBytes, _ := json.Marshal(value)
+	return string(jsonBytes[1 : len(jsonBytes)-1])
+}
+
+func truncate(value string, maxLength int) string {
+	if len(value) <= maxLength {
+		return value
+	}
+
+	return value[:maxLength] + "..."
+}
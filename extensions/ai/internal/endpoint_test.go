@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JsonPath(t *testing.T) {
+	var document any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"choices": [{"message": {"content": "Paris"}}],
+		"context": {"documents": [{"title": "a", "content": "one"}, {"title": "b", "content": "two"}]},
+		"odd key": 1
+	}`), &document))
+
+	tests := []struct {
+		expression string
+		expected   []any
+	}{
+		{"$.choices[0].message.content", []any{"Paris"}},
+		{"$['choices'][-1]['message'].content", []any{"Paris"}},
+		{"$.context.documents[*].content", []any{"one", "two"}},
+		{"$..content", []any{"Paris", "one", "two"}},
+		{"$['odd key']", []any{float64(1)}},
+		{"$.missing[0]", []any{}},
+	}
+
+	for _, test := range tests {
+		path, err := ParseJsonPath(test.expression)
+		require.NoError(t, err, test.expression)
+		require.Equal(t, test.expected, path.Select(document), test.expression)
+	}
+
+	for _, expression := range []string{"choices", "$.", "$[0", "$[?(@.a)]", "$['a"} {
+		_, err := ParseJsonPath(expression)
+		require.Error(t, err, expression)
+	}
+}
+
+func Test_EndpointClient(t *testing.T) {
+	t.Setenv("TEST_API_KEY", "secret")
+
+	var request *http.Request
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &requestBody)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"answer": {"text": "Paris"},
+			"citations": [{"content": "France's capital is Paris"}],
+			"usage": {"input": 20, "output": 5}
+		}`))
+	}))
+	defer server.Close()
+
+	mapping := &EndpointMapping{
+		Request: EndpointRequestMapping{
+			Method:  http.MethodPost,
+			Headers: map[string]string{"Authorization": "Bearer ${TEST_API_KEY}"},
+			Body:    `{"query": "{{question}}"}`,
+		},
+		Response: EndpointResponseMapping{
+			Answer:           "$.answer.text",
+			Context:          "$.citations[*].content",
+			PromptTokens:     "$.usage.input",
+			CompletionTokens: "$.usage.output",
+		},
+	}
+
+	client, err := NewEndpointClient(server.URL+"?id={{id}}", mapping, time.Minute)
+	require.NoError(t, err)
+
+	response, err := client.Query(context.Background(), &EvaluationTestCase{
+		Id:       "tc-1",
+		Question: `What is the "capital" of France?`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Bearer secret", request.Header.Get("Authorization"))
+	require.Equal(t, "tc-1", request.URL.Query().Get("id"))
+	require.Equal(t, `What is the "capital" of France?`, requestBody["query"])
+	require.Equal(t, "Paris", response.Message)
+	require.Equal(t, []string{"France's capital is Paris"}, response.Context)
+	require.Equal(t, TokenUsage{PromptTokens: 20, CompletionTokens: 5, TotalTokens: 25}, response.TokenUsage)
+
+	t.Run("UnmatchedAnswer", func(t *testing.T) {
+		client, err := NewEndpointClient(server.URL, &EndpointMapping{
+			Request:  mapping.Request,
+			Response: EndpointResponseMapping{Answer: "$.choices[0].message.content"},
+		}, time.Minute)
+		require.NoError(t, err)
+
+		_, err = client.Query(context.Background(), &EvaluationTestCase{Id: "tc-1", Question: "?"})
+		require.ErrorContains(t, err, "didn't match")
+	})
+
+	t.Run("Percentiles", func(t *testing.T) {
+		latencies := []int{}
+		for i := 1; i <= 20; i++ {
+			latencies = append(latencies, i*10)
+		}
+
+		require.Equal(t, 100, percentile(latencies, 50))
+		require.Equal(t, 190, percentile(latencies, 95))
+		require.Equal(t, 200, percentile(latencies, 99))
+		require.Equal(t, 0, percentile([]int{}, 50))
+	})
+}
@@ -2,25 +2,35 @@ package internal
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/texttheater/golang-levenshtein/levenshtein"
+	"github.com/wbreza/azd-extensions/sdk/common"
 	"github.com/wbreza/azd-extensions/sdk/ext"
 	"github.com/wbreza/azure-sdk-for-go/sdk/data/azsearchindex"
 )
 
 type EvalService struct {
-	azdContext   *ext.Context
-	aiConfig     *ExtensionConfig
-	openAiClient *azopenai.Client
-	searchClient *azsearchindex.DocumentsClient
+	azdContext    *ext.Context
+	aiConfig      *ExtensionConfig
+	openAiClient  *azopenai.Client
+	searchClient  *azsearchindex.DocumentsClient
+	responseCache *ResponseCache
+	costTracker   *CostTracker
+
+	modelVersionsMu sync.Mutex
+	modelVersions   map[string]string
 }
 
 func NewEvalService(ctx context.Context, azdContext *ext.Context, extensionConfig *ExtensionConfig) (*EvalService, error) {
@@ -48,13 +58,24 @@ func NewEvalService(ctx context.Context, azdContext *ext.Context, extensionConfi
 	}
 
 	return &EvalService{
-		azdContext:   azdContext,
-		aiConfig:     extensionConfig,
-		openAiClient: openAiClient,
-		searchClient: searchClient,
+		azdContext:    azdContext,
+		aiConfig:      extensionConfig,
+		openAiClient:  openAiClient,
+		searchClient:  searchClient,
+		modelVersions: map[string]string{},
 	}, nil
 }
 
+// SetResponseCache configures the cache used to store and replay model responses.
+func (s *EvalService) SetResponseCache(cache *ResponseCache) {
+	s.responseCache = cache
+}
+
+// SetCostTracker configures the tracker used to record the cost of model calls and enforce the budget.
+func (s *EvalService) SetCostTracker(tracker *CostTracker) {
+	s.costTracker = tracker
+}
+
 func (s *EvalService) EvaluateTestCase(ctx context.Context, testCase *EvaluationTestCase, options EvaluationOptions) (*EvaluationTestCaseResult, error) {
 	if options.FuzzyMatchThreshold == nil {
 		options.FuzzyMatchThreshold = to.Ptr(float32(0.8))
@@ -64,7 +85,11 @@ func (s *EvalService) EvaluateTestCase(ctx context.Context, testCase *Evaluation
 		options.SimilarityMatchThreshold = to.Ptr(float32(0.8))
 	}
 
-	modelResponse, err := s.queryModel(ctx, testCase, options)
+	if err := s.costTracker.CheckBudget(); err != nil {
+		return nil, err
+	}
+
+	modelResponse, err := s.getModelResponse(ctx, testCase, options)
 	if err != nil {
 		return nil, err
 	}
@@ -77,8 +102,183 @@ func (s *EvalService) EvaluateTestCase(ctx context.Context, testCase *Evaluation
 	return result, nil
 }
 
+// getModelResponse returns the cached model response when available, otherwise queries the model.
+// In replay mode the cached response is returned without calling Azure and a missing entry results in ErrCacheMiss.
+func (s *EvalService) getModelResponse(ctx context.Context, testCase *EvaluationTestCase, options EvaluationOptions) (*ModelResponse, error) {
+	if s.responseCache == nil {
+		if options.Replay {
+			return nil, fmt.Errorf("replay requires a response cache")
+		}
+
+		return s.queryModel(ctx, testCase, options)
+	}
+
+	cacheKey, err := s.responseCacheKey(testCase, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Replay {
+		cacheKey.ModelVersion = options.ModelVersion
+		return s.responseCache.Get(cacheKey)
+	}
+
+	cacheKey.ModelVersion, err = s.modelVersion(ctx, options.ChatCompletionModel)
+	if err != nil {
+		return nil, err
+	}
+
+	// The retrieved context is part of the prompt, so changes to the search index invalidate the cached response.
+	retrieved, err := s.retrieveContext(ctx, testCase, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.EvaluationType == EvaluationTypeFlow {
+		contextHash := sha256.Sum256([]byte(retrieved.content))
+		cacheKey.ContextHash = hex.EncodeToString(contextHash[:])
+	}
+
+	cachedResponse, err := s.responseCache.Get(cacheKey)
+	if err == nil {
+		return cachedResponse, nil
+	}
+
+	if !errors.Is(err, ErrCacheMiss) {
+		return nil, err
+	}
+
+	modelResponse, err := s.completeChat(ctx, testCase, options, retrieved)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.responseCache.Set(cacheKey, modelResponse); err != nil {
+		return nil, common.NewDetailedError("Failed caching model response", err)
+	}
+
+	return modelResponse, nil
+}
+
+// responseCacheKey builds the cache key from all inputs that influence the model response.
+// For flow evaluations the retrieval parameters are included since the retrieved context becomes part of the prompt.
+// The model version and the context hash are set by the caller since they require calls to Azure.
+func (s *EvalService) responseCacheKey(testCase *EvaluationTestCase, options EvaluationOptions) (ResponseCacheKey, error) {
+	systemMessage, err := options.PromptTemplate.SystemMessage()
+	if err != nil {
+		return ResponseCacheKey{}, err
+	}
+
+	parameters := map[string]any{
+		"evaluationType": options.EvaluationType,
+		"promptTemplate": options.PromptTemplate.Hash,
+	}
+
+	if options.EvaluationType == EvaluationTypeFlow {
+		parameters["embeddingModel"] = options.EmbeddingModel
+		parameters["searchIndex"] = options.IndexName
+		retrieval := options.Retrieval.WithDefaults()
+		parameters["searchTop"] = retrieval.Top
+
+		if retrieval.Dedupe || retrieval.Mmr || retrieval.Rerank {
+			parameters["retrieval"] = retrieval
+		}
+
+		if retrieval.Rerank && options.RerankTemplate != nil {
+			parameters["rerankTemplate"] = options.RerankTemplate.Hash
+		}
+	}
+
+	return ResponseCacheKey{
+		Deployment: options.ChatCompletionModel,
+		Messages: []CacheMessage{
+			{Role: "system", Content: systemMessage},
+			{Role: "user", Content: testCase.Question},
+		},
+		Parameters: parameters,
+	}, nil
+}
+
+// modelVersion returns the model version backing the specified deployment.
+// Versions are resolved once per deployment and reused across test cases.
+func (s *EvalService) modelVersion(ctx context.Context, deploymentName string) (string, error) {
+	s.modelVersionsMu.Lock()
+	defer s.modelVersionsMu.Unlock()
+
+	if version, has := s.modelVersions[deploymentName]; has {
+		return version, nil
+	}
+
+	deploymentInfo, err := GetModelDeploymentInfo(ctx, s.azdContext, s.aiConfig, deploymentName)
+	if err != nil {
+		return "", err
+	}
+
+	version := deploymentInfo.Version
+	s.modelVersions[deploymentName] = version
+
+	return version, nil
+}
+
+// estimatedContextTokens is the assumed size of the retrieved context for each flow evaluation test case.
+const estimatedContextTokens = 3 * 512
+
+// EstimateEvaluationUsage estimates the token usage per model deployment for evaluating the test data.
+// Completion tokens are estimated from the length of the expected answers.
+func EstimateEvaluationUsage(testData *EvaluationTestData, options EvaluationOptions, tokenizers DeploymentTokenizers) UsageEstimate {
+	usage := UsageEstimate{}
+	chatTokenizer := tokenizers.Get(options.ChatCompletionModel)
+	embeddingTokenizer := tokenizers.Get(options.EmbeddingModel)
+
+	// Templates are validated before the evaluation starts, a missing variable only affects the estimate
+	var systemTokens int32
+	if options.PromptTemplate != nil {
+		systemMessage, _ := options.PromptTemplate.SystemMessage()
+		systemTokens = int32(chatTokenizer.CountTokens(systemMessage))
+	}
+
+	for _, testCase := range testData.TestCases {
+		questionTokens := int32(chatTokenizer.CountTokens(testCase.Question))
+
+		// The response is estimated as long as the longest expected answer
+		var answerTokens, embeddedAnswerTokens int32
+		for _, answer := range testCase.ExpectedAnswers {
+			answerTokens = max(answerTokens, int32(chatTokenizer.CountTokens(answer)))
+			embeddedAnswerTokens = max(embeddedAnswerTokens, int32(embeddingTokenizer.CountTokens(answer)))
+		}
+
+		// Endpoints report their own usage, only the scoring is estimated
+		if !options.Replay && options.EvaluationType != EvaluationTypeEndpoint {
+			promptTokens := systemTokens + questionTokens
+			if options.EvaluationType == EvaluationTypeFlow {
+				usage.Add(options.EmbeddingModel, int32(embeddingTokenizer.CountTokens(testCase.Question)), 0)
+				promptTokens += estimatedContextTokens
+
+				rerankPromptTokens, rerankCompletionTokens := EstimateRerankUsage(
+					chatTokenizer,
+					options.Retrieval,
+					options.RerankTemplate,
+					testCase.Question,
+				)
+				usage.Add(options.ChatCompletionModel, rerankPromptTokens, rerankCompletionTokens)
+			}
+
+			usage.Add(options.ChatCompletionModel, promptTokens, answerTokens)
+		}
+
+		// Semantic matching embeds the response and each expected answer
+		for _, answer := range testCase.ExpectedAnswers {
+			usage.Add(options.EmbeddingModel, embeddedAnswerTokens+int32(embeddingTokenizer.CountTokens(answer)), 0)
+		}
+	}
+
+	return usage
+}
+
 func (s *EvalService) GenerateReport(results []*EvaluationTestCaseResult) *EvaluationReport {
 	overallResult := &EvaluationReport{}
+	overallResult.Metrics.Cost = s.costTracker.Metrics()
+
 	if len(results) == 0 {
 		return overallResult
 	}
@@ -150,6 +350,12 @@ func (s *EvalService) GenerateReport(results []*EvaluationTestCaseResult) *Evalu
 		}
 	}
 
+	// Latency Percentiles
+	overallResult.Metrics.Latency.P50Duration = percentile(latencies, 50)
+	overallResult.Metrics.Latency.P90Duration = percentile(latencies, 90)
+	overallResult.Metrics.Latency.P95Duration = percentile(latencies, 95)
+	overallResult.Metrics.Latency.P99Duration = percentile(latencies, 99)
+
 	// Token Usage Metrics
 	overallResult.Metrics.TokenUsage.TotalTokens = int32(totalTokens)
 	if totalCount > 0 {
@@ -179,54 +385,86 @@ func (s *EvalService) GenerateReport(results []*EvaluationTestCaseResult) *Evalu
 }
 
 func (s *EvalService) queryModel(ctx context.Context, testCase *EvaluationTestCase, options EvaluationOptions) (*ModelResponse, error) {
-	startTime := time.Now()
-	tokenUsage := TokenUsage{}
-	chatMessage := testCase.Question
+	if options.EvaluationType == EvaluationTypeEndpoint {
+		return options.Endpoint.Query(ctx, testCase)
+	}
 
-	// Only use embeddings for flow evaluation type
-	if options.EvaluationType == EvaluationTypeFlow {
-		embeddingsResponse, err := s.openAiClient.GetEmbeddings(ctx, azopenai.EmbeddingsOptions{
-			Input:          []string{testCase.Question},
-			DeploymentName: &options.EmbeddingModel,
-		}, nil)
-		if err != nil {
-			return nil, err
-		}
+	retrieved, err := s.retrieveContext(ctx, testCase, options)
+	if err != nil {
+		return nil, err
+	}
 
-		tokenUsage.PromptTokens += *embeddingsResponse.Usage.PromptTokens
-		tokenUsage.TotalTokens += *embeddingsResponse.Usage.TotalTokens
-
-		searchResponse, err := s.searchClient.SearchPost(ctx, azsearchindex.SearchRequest{
-			Select:    to.Ptr("chunk_id, chunk, title"),
-			QueryType: to.Ptr(azsearchindex.QueryTypeSimple),
-			VectorQueries: []azsearchindex.VectorQueryClassification{
-				&azsearchindex.VectorizedQuery{
-					Kind:       to.Ptr(azsearchindex.VectorQueryKindVector),
-					Fields:     to.Ptr("text_vector"),
-					Exhaustive: to.Ptr(true),
-					K:          to.Ptr(int32(3)),
-					Vector:     ConvertToFloatPtrSlice(embeddingsResponse.Data[0].Embedding),
-				},
-			},
-		}, nil, nil)
-		if err != nil {
-			return nil, nil
-		}
+	return s.completeChat(ctx, testCase, options, retrieved)
+}
+
+// retrievedContext is the search context of a test case and the tokens used to retrieve it.
+type retrievedContext struct {
+	startTime  time.Time
+	content    string
+	tokenUsage TokenUsage
+}
 
-		if len(searchResponse.Results) > 0 {
-			contextResults := make([]string, len(searchResponse.Results))
+// retrieveContext searches the index for the context of the question, only flow evaluations use a search context.
+func (s *EvalService) retrieveContext(ctx context.Context, testCase *EvaluationTestCase, options EvaluationOptions) (*retrievedContext, error) {
+	retrieved := &retrievedContext{
+		startTime: time.Now(),
+	}
 
-			for i, result := range searchResponse.Results {
-				contextResults[i] = fmt.Sprintf("- [%d] %s", i+1, fmt.Sprint(result.AdditionalProperties["chunk"]))
-			}
+	if options.EvaluationType != EvaluationTypeFlow {
+		return retrieved, nil
+	}
 
-			chatMessage = fmt.Sprintf("Question: \"%s\"\n\nContext:\n%s", testCase.Question, strings.Join(contextResults, "\n\n"))
-		}
+	embeddingsResponse, err := s.openAiClient.GetEmbeddings(ctx, azopenai.EmbeddingsOptions{
+		Input:          []string{testCase.Question},
+		DeploymentName: &options.EmbeddingModel,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieved.tokenUsage.PromptTokens += *embeddingsResponse.Usage.PromptTokens
+	retrieved.tokenUsage.TotalTokens += *embeddingsResponse.Usage.TotalTokens
+	s.costTracker.Track(options.EmbeddingModel, *embeddingsResponse.Usage.PromptTokens, 0)
+
+	retriever := NewRetriever(s.searchClient, s.openAiClient, IntegratedSearchFields, options.Retrieval)
+	retriever.SetReranker(options.ChatCompletionModel, options.RerankTemplate)
+	retriever.SetCostTracker(s.costTracker)
+
+	documents, rerankUsage, err := retriever.Retrieve(ctx, testCase.Question, embeddingsResponse.Data[0].Embedding)
+	if err != nil {
+		return nil, err
+	}
+
+	retrieved.tokenUsage.PromptTokens += rerankUsage.PromptTokens
+	retrieved.tokenUsage.CompletionTokens += rerankUsage.CompletionTokens
+	retrieved.tokenUsage.TotalTokens += rerankUsage.TotalTokens
+	retrieved.content = FormatRetrievedContext(documents)
+
+	return retrieved, nil
+}
+
+// completeChat asks the chat completion model the question of the test case with the retrieved context.
+func (s *EvalService) completeChat(
+	ctx context.Context,
+	testCase *EvaluationTestCase,
+	options EvaluationOptions,
+	retrieved *retrievedContext,
+) (*ModelResponse, error) {
+	tokenUsage := retrieved.tokenUsage
+
+	systemMessage, err := options.PromptTemplate.SystemMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	chatMessage, err := options.PromptTemplate.UserMessage(testCase.Question, retrieved.content)
+	if err != nil {
+		return nil, err
 	}
 
 	chatMessages := []azopenai.ChatRequestMessageClassification{
 		&azopenai.ChatRequestSystemMessage{
-			Content: azopenai.NewChatRequestSystemMessageContent("You are a helpful AI assistant."),
+			Content: azopenai.NewChatRequestSystemMessageContent(systemMessage),
 		},
 		&azopenai.ChatRequestUserMessage{
 			Content: azopenai.NewChatRequestUserMessageContent(chatMessage),
@@ -244,10 +482,11 @@ func (s *EvalService) queryModel(ctx context.Context, testCase *EvaluationTestCa
 	tokenUsage.PromptTokens += *chatResponse.Usage.PromptTokens
 	tokenUsage.CompletionTokens += *chatResponse.Usage.CompletionTokens
 	tokenUsage.TotalTokens += *chatResponse.Usage.TotalTokens
+	s.costTracker.Track(options.ChatCompletionModel, *chatResponse.Usage.PromptTokens, *chatResponse.Usage.CompletionTokens)
 
 	return &ModelResponse{
 		Message:    *chatResponse.ChatCompletions.Choices[0].Message.Content,
-		Duration:   int(time.Since(startTime).Milliseconds()),
+		Duration:   int(time.Since(retrieved.startTime).Milliseconds()),
 		TokenUsage: tokenUsage,
 	}, nil
 }
@@ -377,9 +616,21 @@ func (s *EvalService) getEmbedding(ctx context.Context, deploymentName string, t
 		return nil, err
 	}
 
+	s.costTracker.Track(deploymentName, *embeddingResponse.Usage.PromptTokens, 0)
+
 	return embeddingResponse.Embeddings.Data[0].Embedding, nil
 }
 
+// percentile returns the nearest-rank percentile of the sorted values.
+func percentile(sortedValues []int, p float64) int {
+	if len(sortedValues) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sortedValues))))
+	return sortedValues[min(max(rank, 1), len(sortedValues))-1]
+}
+
 // cosineSimilarity calculates the cosine similarity between two embedding vectors.
 func cosineSimilarity(vec1, vec2 []float32) float32 {
 	if len(vec1) != len(vec2) {
@@ -0,0 +1,208 @@
+package infra
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/wbreza/azd-extensions/extensions/ai/internal"
+	"github.com/wbreza/azd-extensions/sdk/azure"
+	"github.com/wbreza/azd-extensions/sdk/common/permissions"
+	"github.com/wbreza/azd-extensions/sdk/core/environment"
+)
+
+const (
+	AiModuleFile       = "ai.bicep"
+	MainFile           = "main.bicep"
+	MainParametersFile = "main.parameters.json"
+)
+
+// builtInRoleIds maps the roles assigned during `azd ai setup` to their built-in role definition ids.
+var builtInRoleIds = map[azure.RoleName]string{
+	azure.RoleCognitiveServicesOpenAIContributor:   "a001fd3d-188f-4b5d-821b-7da978bf7442",
+	azure.RoleDefinitionStorageBlobDataContributor: "ba92f5b4-2d11-453d-a403-e96b0029c9fe",
+	azure.RoleSearchIndexDataContributor:           "8ebe5a00-799e-43f5-93ac-243d3dce84a7",
+	azure.RoleSearchIndexDataReader:                "1407120a-92aa-4202-b7e9-c0e197c71c8f",
+	azure.RoleSearchServiceContributor:             "7ca78c08-252a-4471-8644-bb5ff32d4ba0",
+}
+
+var templateFuncs = template.FuncMap{
+	"roleId": func(roleName string) string {
+		return builtInRoleIds[azure.RoleName(roleName)]
+	},
+}
+
+var (
+	aiModuleTemplate = template.Must(template.New(AiModuleFile).Funcs(templateFuncs).Parse(aiModuleBicep))
+	mainTemplate     = template.Must(template.New(MainFile).Funcs(templateFuncs).Parse(mainBicep))
+)
+
+type FileStatus string
+
+const (
+	FileStatusCreated FileStatus = "Created"
+	FileStatusUpdated FileStatus = "Updated"
+	FileStatusSkipped FileStatus = "Skipped"
+)
+
+// GeneratedFile is a file written by Generate.
+type GeneratedFile struct {
+	Path   string     `json:"path"`
+	Status FileStatus `json:"status"`
+}
+
+// RenderAiModule renders the Bicep module for the AI resources.
+func RenderAiModule(spec *InfraSpec) ([]byte, error) {
+	return render(aiModuleTemplate, spec)
+}
+
+// RenderMain renders a subscription scoped main.bicep that provisions the resource group and the AI module.
+func RenderMain(spec *InfraSpec) ([]byte, error) {
+	return render(mainTemplate, spec)
+}
+
+// RenderMainParameters renders the main.parameters.json file that maps azd environment variables
+// to the parameters of the generated main.bicep.
+func RenderMainParameters(spec *InfraSpec) ([]byte, error) {
+	parameters := map[string]string{
+		"environmentName":   fmt.Sprintf("${%s}", environment.EnvNameEnvVarName),
+		"location":          fmt.Sprintf("${%s}", environment.LocationEnvVarName),
+		"principalId":       fmt.Sprintf("${%s}", environment.PrincipalIdEnvVarName),
+		"resourceGroupName": fmt.Sprintf("${%s}", environment.ResourceGroupEnvVarName),
+		"aiServiceName":     fmt.Sprintf("${%s}", internal.EnvAiServiceName),
+		"aiServiceLocation": fmt.Sprintf("${%s}", internal.EnvAiServiceLocation),
+	}
+
+	if spec.Storage != nil {
+		parameters["storageAccountName"] = fmt.Sprintf("${%s}", internal.EnvStorageAccountName)
+	}
+
+	if spec.Search != nil {
+		parameters["searchServiceName"] = fmt.Sprintf("${%s}", internal.EnvSearchServiceName)
+	}
+
+	parameterValues := map[string]any{}
+	for key, value := range parameters {
+		parameterValues[key] = map[string]string{"value": value}
+	}
+
+	parametersFile := map[string]any{
+		"$schema":        "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+		"contentVersion": "1.0.0.0",
+		"parameters":     parameterValues,
+	}
+
+	parametersJson, err := json.MarshalIndent(parametersFile, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(parametersJson, '\n'), nil
+}
+
+// Generate writes the Bicep for the AI resources into the infra directory.
+// The AI module is always written, main.bicep and main.parameters.json are only created when they don't exist
+// so existing project infrastructure is never overwritten.
+func Generate(spec *InfraSpec, infraPath string) ([]*GeneratedFile, error) {
+	if err := os.MkdirAll(infraPath, permissions.PermissionDirectory); err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name      string
+		render    func(*InfraSpec) ([]byte, error)
+		overwrite bool
+	}{
+		{name: AiModuleFile, render: RenderAiModule, overwrite: true},
+		{name: MainFile, render: RenderMain},
+		{name: MainParametersFile, render: RenderMainParameters},
+	}
+
+	generatedFiles := []*GeneratedFile{}
+
+	for _, file := range files {
+		filePath := filepath.Join(infraPath, file.name)
+		status := FileStatusCreated
+
+		if _, err := os.Stat(filePath); err == nil {
+			if !file.overwrite {
+				generatedFiles = append(generatedFiles, &GeneratedFile{Path: filePath, Status: FileStatusSkipped})
+				continue
+			}
+
+			status = FileStatusUpdated
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		content, err := file.render(spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed rendering %s: %w", file.name, err)
+		}
+
+		if err := os.WriteFile(filePath, content, permissions.PermissionFile); err != nil {
+			return nil, err
+		}
+
+		generatedFiles = append(generatedFiles, &GeneratedFile{Path: filePath, Status: status})
+	}
+
+	return generatedFiles, nil
+}
+
+// ReferencesAiModule returns true when the main.bicep within the infra directory references the AI module.
+func ReferencesAiModule(infraPath string) (bool, error) {
+	mainBytes, err := os.ReadFile(filepath.Join(infraPath, MainFile))
+	if err != nil {
+		return false, err
+	}
+
+	return strings.Contains(string(mainBytes), fmt.Sprintf("'%s'", AiModuleFile)), nil
+}
+
+// EnvironmentValues returns the azd environment values that map the generated parameters to the
+// existing resources so provisioning the current environment reuses them.
+func EnvironmentValues(spec *InfraSpec) map[string]string {
+	values := map[string]string{
+		internal.EnvAiServiceName:     spec.Ai.Name,
+		internal.EnvAiServiceLocation: spec.Ai.Location,
+	}
+
+	if spec.Ai.Chat != nil {
+		values[internal.EnvAiChatDeployment] = spec.Ai.Chat.Name
+	}
+
+	if spec.Ai.Embeddings != nil {
+		values[internal.EnvAiEmbeddingsDeployment] = spec.Ai.Embeddings.Name
+	}
+
+	if spec.Ai.Audio != nil {
+		values[internal.EnvAiAudioDeployment] = spec.Ai.Audio.Name
+	}
+
+	if spec.Storage != nil {
+		values[internal.EnvStorageAccountName] = spec.Storage.Account
+		values[internal.EnvStorageContainer] = spec.Storage.Container
+	}
+
+	if spec.Search != nil {
+		values[internal.EnvSearchServiceName] = spec.Search.Service
+		values[internal.EnvSearchIndex] = spec.Search.Index
+	}
+
+	return values
+}
+
+func render(tmpl *template.Template, spec *InfraSpec) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, spec); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,101 @@
+package infra
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Generate(t *testing.T) {
+	spec := &InfraSpec{
+		Ai: AiServiceInfra{
+			Name:     "my-ai-service",
+			Location: "eastus2",
+			Sku:      "S0",
+		},
+		Storage: &StorageInfra{
+			Account:   "myaistorage",
+			Sku:       "Standard_LRS",
+			Container: "documents",
+		},
+	}
+
+	spec.Ai.Chat = &ModelDeploymentInfra{
+		Name:     "gpt-4o",
+		Model:    "gpt-4o",
+		Version:  "2024-08-06",
+		Format:   "OpenAI",
+		Sku:      "Standard",
+		Capacity: 10,
+	}
+	spec.Ai.Deployments = []*ModelDeploymentInfra{spec.Ai.Chat}
+
+	t.Run("NewProject", func(t *testing.T) {
+		infraPath := filepath.Join(t.TempDir(), "infra")
+
+		generatedFiles, err := Generate(spec, infraPath)
+		require.NoError(t, err)
+		require.Len(t, generatedFiles, 3)
+
+		for _, generatedFile := range generatedFiles {
+			require.Equal(t, FileStatusCreated, generatedFile.Status)
+		}
+
+		aiModule, err := os.ReadFile(filepath.Join(infraPath, AiModuleFile))
+		require.NoError(t, err)
+		require.Contains(t, string(aiModule), "name: 'gpt-4o'")
+		require.Contains(t, string(aiModule), "version: '2024-08-06'")
+		require.Contains(t, string(aiModule), "storageBlobDataContributor: 'ba92f5b4-2d11-453d-a403-e96b0029c9fe'")
+		require.Contains(t, string(aiModule), "output AZURE_STORAGE_ACCOUNT_NAME string = storage.name")
+		require.NotContains(t, string(aiModule), "searchServices")
+
+		hasAiModule, err := ReferencesAiModule(infraPath)
+		require.NoError(t, err)
+		require.True(t, hasAiModule)
+
+		parametersBytes, err := os.ReadFile(filepath.Join(infraPath, MainParametersFile))
+		require.NoError(t, err)
+
+		var parametersFile struct {
+			Parameters map[string]struct {
+				Value string `json:"value"`
+			} `json:"parameters"`
+		}
+		require.NoError(t, json.Unmarshal(parametersBytes, &parametersFile))
+		require.Equal(t, "${AZURE_AI_SERVICE_NAME}", parametersFile.Parameters["aiServiceName"].Value)
+		require.Equal(t, "${AZURE_STORAGE_ACCOUNT_NAME}", parametersFile.Parameters["storageAccountName"].Value)
+		require.NotContains(t, parametersFile.Parameters, "searchServiceName")
+	})
+
+	t.Run("ExistingProject", func(t *testing.T) {
+		infraPath := t.TempDir()
+		existingMain := "targetScope = 'subscription'\n"
+		require.NoError(t, os.WriteFile(filepath.Join(infraPath, MainFile), []byte(existingMain), 0600))
+
+		generatedFiles, err := Generate(spec, infraPath)
+		require.NoError(t, err)
+
+		statuses := map[string]FileStatus{}
+		for _, generatedFile := range generatedFiles {
+			statuses[filepath.Base(generatedFile.Path)] = generatedFile.Status
+		}
+
+		require.Equal(t, FileStatusCreated, statuses[AiModuleFile])
+		require.Equal(t, FileStatusSkipped, statuses[MainFile])
+		require.Equal(t, FileStatusCreated, statuses[MainParametersFile])
+
+		mainBytes, err := os.ReadFile(filepath.Join(infraPath, MainFile))
+		require.NoError(t, err)
+		require.Equal(t, existingMain, string(mainBytes))
+
+		hasAiModule, err := ReferencesAiModule(infraPath)
+		require.NoError(t, err)
+		require.False(t, hasAiModule)
+
+		_, err = Generate(spec, infraPath)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,204 @@
+package infra
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/search/armsearch"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal"
+	"github.com/wbreza/azd-extensions/sdk/common"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+)
+
+// InfraSpec describes the AI resources rendered into Bicep.
+type InfraSpec struct {
+	Ai      AiServiceInfra
+	Storage *StorageInfra
+	Search  *SearchInfra
+}
+
+type AiServiceInfra struct {
+	Name        string
+	Location    string
+	Sku         string
+	Chat        *ModelDeploymentInfra
+	Embeddings  *ModelDeploymentInfra
+	Audio       *ModelDeploymentInfra
+	Deployments []*ModelDeploymentInfra
+}
+
+type ModelDeploymentInfra struct {
+	Name     string
+	Model    string
+	Version  string
+	Format   string
+	Sku      string
+	Capacity int32
+}
+
+type StorageInfra struct {
+	Account   string
+	Sku       string
+	Container string
+}
+
+type SearchInfra struct {
+	Service string
+	Sku     string
+	Index   string
+}
+
+// LoadInfraSpec loads the current state of the configured AI resources from Azure.
+func LoadInfraSpec(ctx context.Context, azdContext *ext.Context, config *internal.ExtensionConfig) (*InfraSpec, error) {
+	if config.Ai.Service == "" {
+		return nil, errors.New("AI service is not configured")
+	}
+
+	credential, err := azdContext.Credential()
+	if err != nil {
+		return nil, err
+	}
+
+	var armClientOptions *arm.ClientOptions
+	azdContext.Invoke(func(clientOptions *arm.ClientOptions) error {
+		armClientOptions = clientOptions
+		return nil
+	})
+
+	accountsClient, err := armcognitiveservices.NewAccountsClient(config.Subscription, credential, armClientOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := accountsClient.Get(ctx, config.ResourceGroup, config.Ai.Service, nil)
+	if err != nil {
+		return nil, common.NewDetailedError("Failed loading AI service", err)
+	}
+
+	spec := &InfraSpec{
+		Ai: AiServiceInfra{
+			Name:     config.Ai.Service,
+			Location: *account.Location,
+			Sku:      "S0",
+		},
+	}
+
+	if account.SKU != nil && account.SKU.Name != nil {
+		spec.Ai.Sku = *account.SKU.Name
+	}
+
+	if config.Ai.Models.ChatCompletion != "" {
+		spec.Ai.Chat, err = loadModelDeployment(ctx, azdContext, config, config.Ai.Models.ChatCompletion)
+		if err != nil {
+			return nil, err
+		}
+
+		spec.Ai.Deployments = append(spec.Ai.Deployments, spec.Ai.Chat)
+	}
+
+	if config.Ai.Models.Embeddings != "" {
+		if config.Ai.Models.Embeddings == config.Ai.Models.ChatCompletion {
+			spec.Ai.Embeddings = spec.Ai.Chat
+		} else {
+			spec.Ai.Embeddings, err = loadModelDeployment(ctx, azdContext, config, config.Ai.Models.Embeddings)
+			if err != nil {
+				return nil, err
+			}
+
+			spec.Ai.Deployments = append(spec.Ai.Deployments, spec.Ai.Embeddings)
+		}
+	}
+
+	if config.Ai.Models.Audio != "" {
+		spec.Ai.Audio, err = loadModelDeployment(ctx, azdContext, config, config.Ai.Models.Audio)
+		if err != nil {
+			return nil, err
+		}
+
+		spec.Ai.Deployments = append(spec.Ai.Deployments, spec.Ai.Audio)
+	}
+
+	if config.Storage.Account != "" {
+		storageClient, err := armstorage.NewAccountsClient(config.Subscription, credential, armClientOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		storageAccount, err := storageClient.GetProperties(ctx, config.ResourceGroup, config.Storage.Account, nil)
+		if err != nil {
+			return nil, common.NewDetailedError("Failed loading storage account", err)
+		}
+
+		spec.Storage = &StorageInfra{
+			Account:   config.Storage.Account,
+			Sku:       string(armstorage.SKUNameStandardLRS),
+			Container: config.Storage.Container,
+		}
+
+		if storageAccount.SKU != nil && storageAccount.SKU.Name != nil {
+			spec.Storage.Sku = string(*storageAccount.SKU.Name)
+		}
+	}
+
+	if config.Search.Service != "" {
+		searchClient, err := armsearch.NewServicesClient(config.Subscription, credential, armClientOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		searchService, err := searchClient.Get(ctx, config.ResourceGroup, config.Search.Service, nil, nil)
+		if err != nil {
+			return nil, common.NewDetailedError("Failed loading search service", err)
+		}
+
+		spec.Search = &SearchInfra{
+			Service: config.Search.Service,
+			Sku:     string(armsearch.SKUNameStandard),
+			Index:   config.Search.Index,
+		}
+
+		if searchService.SKU != nil && searchService.SKU.Name != nil {
+			spec.Search.Sku = string(*searchService.SKU.Name)
+		}
+	}
+
+	return spec, nil
+}
+
+func loadModelDeployment(
+	ctx context.Context,
+	azdContext *ext.Context,
+	config *internal.ExtensionConfig,
+	deploymentName string,
+) (*ModelDeploymentInfra, error) {
+	deploymentInfo, err := internal.GetModelDeploymentInfo(ctx, azdContext, config, deploymentName)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment := &ModelDeploymentInfra{
+		Name:     deploymentInfo.Name,
+		Model:    deploymentInfo.Model,
+		Version:  deploymentInfo.Version,
+		Format:   deploymentInfo.Format,
+		Sku:      deploymentInfo.Sku,
+		Capacity: deploymentInfo.Capacity,
+	}
+
+	if deployment.Format == "" {
+		deployment.Format = "OpenAI"
+	}
+
+	if deployment.Sku == "" {
+		deployment.Sku = "Standard"
+	}
+
+	if deployment.Capacity == 0 {
+		deployment.Capacity = 10
+	}
+
+	return deployment, nil
+}
@@ -0,0 +1,314 @@
+package infra
+
+const aiModuleBicep = `// Generated by 'azd ai infra generate'.
+// Re-running the command overwrites this file.
+
+@description('Primary location for the AI resources')
+param location string = resourceGroup().location
+
+@description('Unique token used to generate resource names')
+param resourceToken string
+
+@description('Tags applied to all resources')
+param tags object = {}
+
+@description('Id of the user or app granted access to the AI resources')
+param principalId string = ''
+
+@description('Type of the principal granted access to the AI resources')
+param principalType string = 'User'
+
+@description('Name of the Azure AI service, a name is generated when empty')
+param aiServiceName string = ''
+
+@description('Location of the Azure AI service, model availability varies by region')
+param aiServiceLocation string = location
+
+param aiServiceSku string = '{{ .Ai.Sku }}'
+{{- if .Storage }}
+
+@description('Name of the storage account, a name is generated when empty')
+param storageAccountName string = ''
+
+param storageAccountSku string = '{{ .Storage.Sku }}'
+
+param storageContainerName string = '{{ .Storage.Container }}'
+{{- end }}
+{{- if .Search }}
+
+@description('Name of the Azure AI Search service, a name is generated when empty')
+param searchServiceName string = ''
+
+param searchServiceSku string = '{{ .Search.Sku }}'
+
+param searchIndexName string = '{{ .Search.Index }}'
+{{- end }}
+
+var roleDefinitionIds = {
+  cognitiveServicesOpenAIContributor: '{{ roleId "Cognitive Services OpenAI Contributor" }}'
+{{- if .Storage }}
+  storageBlobDataContributor: '{{ roleId "Storage Blob Data Contributor" }}'
+{{- end }}
+{{- if .Search }}
+  searchIndexDataContributor: '{{ roleId "Search Index Data Contributor" }}'
+  searchIndexDataReader: '{{ roleId "Search Index Data Reader" }}'
+  searchServiceContributor: '{{ roleId "Search Service Contributor" }}'
+{{- end }}
+}
+
+var aiServiceResourceName = !empty(aiServiceName) ? aiServiceName : 'ai-${resourceToken}'
+
+resource aiService 'Microsoft.CognitiveServices/accounts@2023-05-01' = {
+  name: aiServiceResourceName
+  location: aiServiceLocation
+  tags: tags
+  kind: 'OpenAI'
+  sku: {
+    name: aiServiceSku
+  }
+  identity: {
+    type: 'SystemAssigned'
+  }
+  properties: {
+    customSubDomainName: aiServiceResourceName
+    publicNetworkAccess: 'Enabled'
+    disableLocalAuth: false
+  }
+}
+
+var modelDeployments = [
+{{- range .Ai.Deployments }}
+  {
+    name: '{{ .Name }}'
+    model: {
+      format: '{{ .Format }}'
+      name: '{{ .Model }}'
+{{- if .Version }}
+      version: '{{ .Version }}'
+{{- end }}
+    }
+    sku: {
+      name: '{{ .Sku }}'
+      capacity: {{ .Capacity }}
+    }
+  }
+{{- end }}
+]
+
+// Model deployments on the same account must be created one at a time
+@batchSize(1)
+resource aiDeployments 'Microsoft.CognitiveServices/accounts/deployments@2023-05-01' = [for deployment in modelDeployments: {
+  parent: aiService
+  name: deployment.name
+  sku: deployment.sku
+  properties: {
+    model: deployment.model
+  }
+}]
+
+resource aiServiceUserRoles 'Microsoft.Authorization/roleAssignments@2022-04-01' = if (!empty(principalId)) {
+  name: guid(aiService.id, principalId, roleDefinitionIds.cognitiveServicesOpenAIContributor)
+  scope: aiService
+  properties: {
+    principalId: principalId
+    principalType: principalType
+    roleDefinitionId: subscriptionResourceId('Microsoft.Authorization/roleDefinitions', roleDefinitionIds.cognitiveServicesOpenAIContributor)
+  }
+}
+{{- if .Storage }}
+
+resource storage 'Microsoft.Storage/storageAccounts@2023-01-01' = {
+  name: !empty(storageAccountName) ? storageAccountName : 'st${resourceToken}'
+  location: location
+  tags: tags
+  kind: 'StorageV2'
+  sku: {
+    name: storageAccountSku
+  }
+  properties: {
+    accessTier: 'Hot'
+    allowBlobPublicAccess: false
+    minimumTlsVersion: 'TLS1_2'
+    publicNetworkAccess: 'Enabled'
+  }
+}
+
+resource blobService 'Microsoft.Storage/storageAccounts/blobServices@2023-01-01' = {
+  parent: storage
+  name: 'default'
+}
+
+resource storageContainer 'Microsoft.Storage/storageAccounts/blobServices/containers@2023-01-01' = {
+  parent: blobService
+  name: storageContainerName
+  properties: {
+    publicAccess: 'None'
+  }
+}
+
+resource storageUserRoles 'Microsoft.Authorization/roleAssignments@2022-04-01' = if (!empty(principalId)) {
+  name: guid(storage.id, principalId, roleDefinitionIds.storageBlobDataContributor)
+  scope: storage
+  properties: {
+    principalId: principalId
+    principalType: principalType
+    roleDefinitionId: subscriptionResourceId('Microsoft.Authorization/roleDefinitions', roleDefinitionIds.storageBlobDataContributor)
+  }
+}
+{{- end }}
+{{- if .Search }}
+
+resource search 'Microsoft.Search/searchServices@2023-11-01' = {
+  name: !empty(searchServiceName) ? searchServiceName : 'srch-${resourceToken}'
+  location: location
+  tags: tags
+  sku: {
+    name: searchServiceSku
+  }
+  identity: {
+    type: 'SystemAssigned'
+  }
+  properties: {
+    hostingMode: 'default'
+    publicNetworkAccess: 'enabled'
+    authOptions: {
+      aadOrApiKey: {
+        aadAuthFailureMode: 'http403'
+      }
+    }
+  }
+}
+
+resource searchUserRoles 'Microsoft.Authorization/roleAssignments@2022-04-01' = [for roleDefinitionId in [
+  roleDefinitionIds.searchIndexDataContributor
+  roleDefinitionIds.searchServiceContributor
+]: if (!empty(principalId)) {
+  name: guid(search.id, principalId, roleDefinitionId)
+  scope: search
+  properties: {
+    principalId: principalId
+    principalType: principalType
+    roleDefinitionId: subscriptionResourceId('Microsoft.Authorization/roleDefinitions', roleDefinitionId)
+  }
+}]
+
+// Allows the AI service to query the search index when using your own data
+resource searchAiServiceRoles 'Microsoft.Authorization/roleAssignments@2022-04-01' = [for roleDefinitionId in [
+  roleDefinitionIds.searchIndexDataReader
+  roleDefinitionIds.searchServiceContributor
+]: {
+  name: guid(search.id, aiService.id, roleDefinitionId)
+  scope: search
+  properties: {
+    principalId: aiService.identity.principalId
+    principalType: 'ServicePrincipal'
+    roleDefinitionId: subscriptionResourceId('Microsoft.Authorization/roleDefinitions', roleDefinitionId)
+  }
+}]
+{{- end }}
+
+output AZURE_AI_SERVICE_NAME string = aiService.name
+output AZURE_AI_LOCATION string = aiService.location
+output AZURE_AI_ENDPOINT string = aiService.properties.endpoint
+{{- if .Ai.Chat }}
+output AZURE_AI_CHAT_DEPLOYMENT string = '{{ .Ai.Chat.Name }}'
+{{- end }}
+{{- if .Ai.Embeddings }}
+output AZURE_AI_EMBEDDINGS_DEPLOYMENT string = '{{ .Ai.Embeddings.Name }}'
+{{- end }}
+{{- if .Ai.Audio }}
+output AZURE_AI_AUDIO_DEPLOYMENT string = '{{ .Ai.Audio.Name }}'
+{{- end }}
+{{- if .Storage }}
+output AZURE_STORAGE_ACCOUNT_NAME string = storage.name
+output AZURE_STORAGE_BLOB_ENDPOINT string = storage.properties.primaryEndpoints.blob
+output AZURE_STORAGE_CONTAINER string = storageContainer.name
+{{- end }}
+{{- if .Search }}
+output AZURE_SEARCH_SERVICE_NAME string = search.name
+output AZURE_SEARCH_ENDPOINT string = 'https://${search.name}.search.windows.net'
+output AZURE_SEARCH_INDEX string = searchIndexName
+{{- end }}
+`
+
+const mainBicep = `targetScope = 'subscription'
+
+@minLength(1)
+@maxLength(64)
+@description('Name of the environment used to generate a short unique hash for resources')
+param environmentName string
+
+@minLength(1)
+@description('Primary location for all resources')
+param location string
+
+@description('Id of the user or app granted access to the AI resources')
+param principalId string = ''
+
+param resourceGroupName string = ''
+
+param aiServiceName string = ''
+
+param aiServiceLocation string = ''
+{{- if .Storage }}
+
+param storageAccountName string = ''
+{{- end }}
+{{- if .Search }}
+
+param searchServiceName string = ''
+{{- end }}
+
+var tags = { 'azd-env-name': environmentName }
+var resourceToken = toLower(uniqueString(subscription().id, environmentName, location))
+
+resource rg 'Microsoft.Resources/resourceGroups@2021-04-01' = {
+  name: !empty(resourceGroupName) ? resourceGroupName : 'rg-${environmentName}'
+  location: location
+  tags: tags
+}
+
+module ai 'ai.bicep' = {
+  name: 'ai'
+  scope: rg
+  params: {
+    location: location
+    resourceToken: resourceToken
+    tags: tags
+    principalId: principalId
+    aiServiceName: aiServiceName
+    aiServiceLocation: !empty(aiServiceLocation) ? aiServiceLocation : location
+{{- if .Storage }}
+    storageAccountName: storageAccountName
+{{- end }}
+{{- if .Search }}
+    searchServiceName: searchServiceName
+{{- end }}
+  }
+}
+
+output AZURE_LOCATION string = location
+output AZURE_RESOURCE_GROUP string = rg.name
+output AZURE_AI_SERVICE_NAME string = ai.outputs.AZURE_AI_SERVICE_NAME
+output AZURE_AI_LOCATION string = ai.outputs.AZURE_AI_LOCATION
+output AZURE_AI_ENDPOINT string = ai.outputs.AZURE_AI_ENDPOINT
+{{- if .Ai.Chat }}
+output AZURE_AI_CHAT_DEPLOYMENT string = ai.outputs.AZURE_AI_CHAT_DEPLOYMENT
+{{- end }}
+{{- if .Ai.Embeddings }}
+output AZURE_AI_EMBEDDINGS_DEPLOYMENT string = ai.outputs.AZURE_AI_EMBEDDINGS_DEPLOYMENT
+{{- end }}
+{{- if .Ai.Audio }}
+output AZURE_AI_AUDIO_DEPLOYMENT string = ai.outputs.AZURE_AI_AUDIO_DEPLOYMENT
+{{- end }}
+{{- if .Storage }}
+output AZURE_STORAGE_ACCOUNT_NAME string = ai.outputs.AZURE_STORAGE_ACCOUNT_NAME
+output AZURE_STORAGE_BLOB_ENDPOINT string = ai.outputs.AZURE_STORAGE_BLOB_ENDPOINT
+output AZURE_STORAGE_CONTAINER string = ai.outputs.AZURE_STORAGE_CONTAINER
+{{- end }}
+{{- if .Search }}
+output AZURE_SEARCH_SERVICE_NAME string = ai.outputs.AZURE_SEARCH_SERVICE_NAME
+output AZURE_SEARCH_ENDPOINT string = ai.outputs.AZURE_SEARCH_ENDPOINT
+output AZURE_SEARCH_INDEX string = ai.outputs.AZURE_SEARCH_INDEX
+{{- end }}
+`
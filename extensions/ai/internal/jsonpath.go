@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// JsonPath is a compiled JSONPath expression. The supported subset covers child members (`.name`, `['name']`),
+// array indexes (`[0]`, `[-1]`), wildcards (`.*`, `[*]`) and recursive descent (`..name`).
+type JsonPath struct {
+	expression string
+	steps      []jsonPathStep
+}
+
+type jsonPathStep struct {
+	recursive bool
+	wildcard  bool
+	name      string
+	index     *int
+}
+
+// ParseJsonPath compiles the JSONPath expression.
+func ParseJsonPath(expression string) (*JsonPath, error) {
+	expression = strings.TrimSpace(expression)
+	if !strings.HasPrefix(expression, "$") {
+		return nil, fmt.Errorf("invalid JSONPath '%s': expressions must start with '$'", expression)
+	}
+
+	path := &JsonPath{expression: expression}
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid JSONPath '%s': %s", expression, reason)
+	}
+
+	for i := 1; i < len(expression); {
+		step := jsonPathStep{}
+
+		switch expression[i] {
+		case '.':
+			i++
+			if i < len(expression) && expression[i] == '.' {
+				step.recursive = true
+				i++
+			}
+
+			if i < len(expression) && expression[i] == '[' && step.recursive {
+				break
+			}
+
+			if i < len(expression) && expression[i] == '*' {
+				step.wildcard = true
+				i++
+				path.steps = append(path.steps, step)
+				continue
+			}
+
+			end := i
+			for end < len(expression) && expression[end] != '.' && expression[end] != '[' {
+				end++
+			}
+
+			if end == i {
+				return nil, invalid(fmt.Sprintf("missing member name at position %d", i))
+			}
+
+			step.name = expression[i:end]
+			i = end
+			path.steps = append(path.steps, step)
+			continue
+		case '[':
+		default:
+			return nil, invalid(fmt.Sprintf("unexpected '%c' at position %d", expression[i], i))
+		}
+
+		// Bracket notation
+		i++
+		if i < len(expression) && (expression[i] == '\'' || expression[i] == '"') {
+			quote := expression[i]
+			end := strings.IndexByte(expression[i+1:], quote)
+			if end < 0 || i+end+2 >= len(expression) || expression[i+end+2] != ']' {
+				return nil, invalid("unterminated quoted member name")
+			}
+
+			step.name = expression[i+1 : i+end+1]
+			i += end + 3
+			path.steps = append(path.steps, step)
+			continue
+		}
+
+		end := strings.IndexByte(expression[i:], ']')
+		if end < 0 {
+			return nil, invalid("missing ']'")
+		}
+
+		selector := strings.TrimSpace(expression[i : i+end])
+		i += end + 1
+
+		if selector == "*" {
+			step.wildcard = true
+		} else {
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, invalid(fmt.Sprintf("unsupported selector '[%s]'", selector))
+			}
+
+			step.index = &index
+		}
+
+		path.steps = append(path.steps, step)
+	}
+
+	return path, nil
+}
+
+// String returns the expression of the path.
+func (p *JsonPath) String() string {
+	return p.expression
+}
+
+// Select returns all values matching the path within the decoded JSON value.
+func (p *JsonPath) Select(value any) []any {
+	current := []any{value}
+
+	for _, step := range p.steps {
+		next := []any{}
+
+		for _, node := range current {
+			candidates := []any{node}
+			if step.recursive {
+				candidates = descendants(node)
+			}
+
+			for _, candidate := range candidates {
+				next = append(next, step.apply(candidate)...)
+			}
+		}
+
+		current = next
+	}
+
+	return current
+}
+
+func (s jsonPathStep) apply(node any) []any {
+	switch value := node.(type) {
+	case map[string]any:
+		if s.wildcard {
+			keys := make([]string, 0, len(value))
+			for key := range value {
+				keys = append(keys, key)
+			}
+
+			slices.Sort(keys)
+
+			matches := make([]any, 0, len(keys))
+			for _, key := range keys {
+				matches = append(matches, value[key])
+			}
+
+			return matches
+		}
+
+		if child, has := value[s.name]; has && s.index == nil {
+			return []any{child}
+		}
+	case []any:
+		if s.wildcard {
+			return value
+		}
+
+		if s.index != nil {
+			index := *s.index
+			if index < 0 {
+				index += len(value)
+			}
+
+			if index >= 0 && index < len(value) {
+				return []any{value[index]}
+			}
+		}
+	}
+
+	return nil
+}
+
+// descendants returns the node and all nested values in document order.
+func descendants(node any) []any {
+	nodes := []any{node}
+
+	switch value := node.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			nodes = append(nodes, descendants(value[key])...)
+		}
+	case []any:
+		for _, item := range value {
+			nodes = append(nodes, descendants(item)...)
+		}
+	}
+
+	return nodes
+}
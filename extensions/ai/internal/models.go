@@ -3,8 +3,9 @@ package internal
 type EvaluationType string
 
 const (
-	EvaluationTypeModel EvaluationType = "model"
-	EvaluationTypeFlow  EvaluationType = "flow"
+	EvaluationTypeModel    EvaluationType = "model"
+	EvaluationTypeFlow     EvaluationType = "flow"
+	EvaluationTypeEndpoint EvaluationType = "endpoint"
 )
 
 type EvaluationOptions struct {
@@ -15,12 +16,26 @@ type EvaluationOptions struct {
 	FuzzyMatchThreshold      *float32
 	SimilarityMatchThreshold *float32
 	BatchSize                int
+	// Replay evaluates previously cached model responses without calling the model.
+	Replay bool
+	// ModelVersion is the model version of the cached responses evaluated by replays.
+	ModelVersion string
+	// PromptTemplate builds the messages sent to the model for each test case.
+	PromptTemplate *PromptTemplate
+	// Retrieval configures the post-retrieval stages of flow evaluations.
+	Retrieval RetrievalOptions
+	// RerankTemplate scores the retrieved documents when reranking is enabled.
+	RerankTemplate *PromptTemplate
+	// Endpoint queries the external HTTP endpoint of endpoint evaluations.
+	Endpoint *EndpointClient
 }
 
 type ModelResponse struct {
 	Message    string     `json:"message"`
 	Duration   int        `json:"duration"`
 	TokenUsage TokenUsage `json:"totalUsage"`
+	// Context contains the retrieved context returned by endpoint evaluations.
+	Context []string `json:"context,omitempty"`
 }
 
 type TokenUsage struct {
@@ -49,6 +64,8 @@ type EvaluationTestCaseMetadata struct {
 }
 
 type EvaluationReport struct {
+	// Prompt identifies the prompt template used to query the model.
+	Prompt  *PromptTemplateInfo         `json:"prompt,omitempty"`
 	Metrics EvaluationMetrics           `json:"metrics"`
 	Results []*EvaluationTestCaseResult `json:"results"`
 }
@@ -60,6 +77,7 @@ type EvaluationMetrics struct {
 	F1         float32                  `json:"f1"`
 	Latency    EvaluationLatencyMetrics `json:"latency"`
 	TokenUsage TokenUsageMetrics        `json:"tokenUsage"`
+	Cost       *CostMetrics             `json:"cost,omitempty"`
 }
 
 type EvaluationLatencyMetrics struct {
@@ -68,6 +86,10 @@ type EvaluationLatencyMetrics struct {
 	MedianLatency int     `json:"medianLatency"`
 	MinDuration   int     `json:"minDuration"`
 	MaxDuration   int     `json:"maxDuration"`
+	P50Duration   int     `json:"p50Duration"`
+	P90Duration   int     `json:"p90Duration"`
+	P95Duration   int     `json:"p95Duration"`
+	P99Duration   int     `json:"p99Duration"`
 }
 
 type TokenUsageMetrics struct {
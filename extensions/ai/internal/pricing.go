@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+	"github.com/wbreza/azd-extensions/sdk/common"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+)
+
+// ModelPrice is the price in USD per 1,000 tokens for a model.
+type ModelPrice struct {
+	InputPer1K  float64 `json:"inputPer1K"`
+	OutputPer1K float64 `json:"outputPer1K"`
+}
+
+// Cost returns the cost in USD for the specified token counts.
+func (p ModelPrice) Cost(promptTokens int, completionTokens int) float64 {
+	return (float64(promptTokens)/1000)*p.InputPer1K + (float64(completionTokens)/1000)*p.OutputPer1K
+}
+
+// PricingConfig maps a model name or a `<model>/<sku>` pair to a price.
+// Entries in the extension config override the default pricing.
+type PricingConfig map[string]ModelPrice
+
+// DefaultPricing contains list prices for common Azure OpenAI models.
+// Prices change over time and vary by region, override them with the `pricing` extension config.
+var DefaultPricing = PricingConfig{
+	"gpt-4o":                  {InputPer1K: 0.0025, OutputPer1K: 0.01},
+	"gpt-4o/DataZoneStandard": {InputPer1K: 0.00275, OutputPer1K: 0.011},
+	"gpt-4o/Standard":         {InputPer1K: 0.00275, OutputPer1K: 0.011},
+	"gpt-4o-mini":             {InputPer1K: 0.00015, OutputPer1K: 0.0006},
+	"gpt-4o-mini/Standard":    {InputPer1K: 0.000165, OutputPer1K: 0.00066},
+	"gpt-4":                   {InputPer1K: 0.03, OutputPer1K: 0.06},
+	"gpt-4-32k":               {InputPer1K: 0.06, OutputPer1K: 0.12},
+	"gpt-35-turbo":            {InputPer1K: 0.0005, OutputPer1K: 0.0015},
+	"gpt-35-turbo-16k":        {InputPer1K: 0.003, OutputPer1K: 0.004},
+	"text-embedding-ada-002":  {InputPer1K: 0.0001},
+	"text-embedding-3-small":  {InputPer1K: 0.00002},
+	"text-embedding-3-large":  {InputPer1K: 0.00013},
+}
+
+// Lookup finds the price for the model and SKU, falling back to the model price when no SKU specific price exists.
+func (p PricingConfig) Lookup(model string, sku string) (ModelPrice, bool) {
+	if sku != "" {
+		if price, has := p[fmt.Sprintf("%s/%s", model, sku)]; has {
+			return price, true
+		}
+	}
+
+	price, has := p[model]
+	return price, has
+}
+
+// ResolvePricing merges the configured pricing overrides with the default pricing.
+func ResolvePricing(overrides PricingConfig) PricingConfig {
+	pricing := PricingConfig{}
+	for key, value := range DefaultPricing {
+		pricing[key] = value
+	}
+
+	for key, value := range overrides {
+		pricing[key] = value
+	}
+
+	return pricing
+}
+
+// ModelDeploymentInfo describes the model backing an Azure OpenAI model deployment.
+type ModelDeploymentInfo struct {
+	Name     string
+	Model    string
+	Version  string
+	Format   string
+	Sku      string
+	Capacity int32
+}
+
+// GetModelDeploymentInfo loads the model details for the specified model deployment.
+func GetModelDeploymentInfo(
+	ctx context.Context,
+	azdContext *ext.Context,
+	extensionConfig *ExtensionConfig,
+	deploymentName string,
+) (*ModelDeploymentInfo, error) {
+	credential, err := azdContext.Credential()
+	if err != nil {
+		return nil, err
+	}
+
+	var armClientOptions *arm.ClientOptions
+	azdContext.Invoke(func(clientOptions *arm.ClientOptions) error {
+		armClientOptions = clientOptions
+		return nil
+	})
+
+	deploymentsClient, err := armcognitiveservices.NewDeploymentsClient(extensionConfig.Subscription, credential, armClientOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := deploymentsClient.Get(ctx, extensionConfig.ResourceGroup, extensionConfig.Ai.Service, deploymentName, nil)
+	if err != nil {
+		return nil, common.NewDetailedError(fmt.Sprintf("Failed loading model deployment %s", deploymentName), err)
+	}
+
+	info := &ModelDeploymentInfo{
+		Name: deploymentName,
+	}
+
+	if deployment.SKU != nil {
+		if deployment.SKU.Name != nil {
+			info.Sku = *deployment.SKU.Name
+		}
+
+		if deployment.SKU.Capacity != nil {
+			info.Capacity = *deployment.SKU.Capacity
+		}
+	}
+
+	if deployment.Properties != nil && deployment.Properties.Model != nil {
+		if deployment.Properties.Model.Name != nil {
+			info.Model = strings.ToLower(*deployment.Properties.Model.Name)
+		}
+
+		if deployment.Properties.Model.Version != nil {
+			info.Version = *deployment.Properties.Model.Version
+		}
+
+		if deployment.Properties.Model.Format != nil {
+			info.Format = *deployment.Properties.Model.Format
+		}
+	}
+
+	return info, nil
+}
+
+// LoadDeploymentPrices resolves the price for each of the specified model deployments.
+// Deployments without a known price are returned separately so callers can warn the user.
+func LoadDeploymentPrices(
+	ctx context.Context,
+	azdContext *ext.Context,
+	extensionConfig *ExtensionConfig,
+	deploymentNames ...string,
+) (map[string]ModelPrice, []string, error) {
+	pricing := ResolvePricing(extensionConfig.Pricing)
+	prices := map[string]ModelPrice{}
+	missing := []string{}
+
+	for _, deploymentName := range deploymentNames {
+		if deploymentName == "" {
+			continue
+		}
+
+		if _, has := prices[deploymentName]; has {
+			continue
+		}
+
+		deploymentInfo, err := GetModelDeploymentInfo(ctx, azdContext, extensionConfig, deploymentName)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		price, has := pricing.Lookup(deploymentInfo.Model, deploymentInfo.Sku)
+		if !has {
+			missing = append(missing, deploymentName)
+			continue
+		}
+
+		prices[deploymentName] = price
+	}
+
+	return prices, missing, nil
+}
+
+// LoadDeploymentTokenizers resolves the tokenizer of the model of each of the specified model deployments.
+func LoadDeploymentTokenizers(
+	ctx context.Context,
+	azdContext *ext.Context,
+	extensionConfig *ExtensionConfig,
+	deploymentNames ...string,
+) (DeploymentTokenizers, error) {
+	tokenizers := DeploymentTokenizers{}
+
+	for _, deploymentName := range deploymentNames {
+		if deploymentName == "" {
+			continue
+		}
+
+		if _, has := tokenizers[deploymentName]; has {
+			continue
+		}
+
+		deploymentInfo, err := GetModelDeploymentInfo(ctx, azdContext, extensionConfig, deploymentName)
+		if err != nil {
+			return nil, err
+		}
+
+		tokenizers[deploymentName] = NewTokenizer(deploymentInfo.Model)
+	}
+
+	return tokenizers, nil
+}
@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/wbreza/azd-extensions/sdk/core/config"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+)
+
+// DefaultProfile is the name of the profile stored in the `ai.config` section.
+const DefaultProfile = "default"
+
+const (
+	defaultConfigPath = "ai.config"
+	profilesPath      = "ai.profiles"
+	activeProfilePath = "ai.activeProfile"
+)
+
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrProfileExists   = errors.New("profile already exists")
+
+	profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+)
+
+type profileContextKey struct{}
+
+// WithProfile returns a context that overrides the active profile for the current invocation.
+func WithProfile(ctx context.Context, profile string) context.Context {
+	return context.WithValue(ctx, profileContextKey{}, profile)
+}
+
+// ProfileInfo describes a named AI configuration profile.
+type ProfileInfo struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// ValidateProfileName ensures the name can be used as a key within the azd config.
+func ValidateProfileName(name string) error {
+	if !profileNameRegex.MatchString(name) {
+		return fmt.Errorf(
+			"invalid profile name '%s', names must start with a letter or number and only contain letters, numbers, '-' and '_'",
+			name,
+		)
+	}
+
+	return nil
+}
+
+// ActiveProfile returns the name of the profile used by the current invocation.
+func ActiveProfile(ctx context.Context, azdContext *ext.Context) (string, error) {
+	store, err := loadConfigStore(ctx, azdContext)
+	if err != nil {
+		return "", err
+	}
+
+	return activeProfile(ctx, store.config)
+}
+
+// ListProfiles returns the default profile and all named profiles sorted by name.
+func ListProfiles(ctx context.Context, azdContext *ext.Context) ([]*ProfileInfo, error) {
+	store, err := loadConfigStore(ctx, azdContext)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := activeProfile(ctx, store.config)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	if profiles, has := store.config.GetMap(profilesPath); has {
+		for name := range profiles {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	names = append([]string{DefaultProfile}, names...)
+
+	profiles := make([]*ProfileInfo, len(names))
+	for i, name := range names {
+		profiles[i] = &ProfileInfo{
+			Name:   name,
+			Active: name == active,
+		}
+	}
+
+	return profiles, nil
+}
+
+// LoadProfile loads the extension config stored within the profile.
+func LoadProfile(ctx context.Context, azdContext *ext.Context, name string) (*ExtensionConfig, error) {
+	return LoadExtensionConfig(WithProfile(ctx, name), azdContext)
+}
+
+// UseProfile sets the active profile used by all subsequent commands.
+func UseProfile(ctx context.Context, azdContext *ext.Context, name string) error {
+	store, err := loadConfigStore(ctx, azdContext)
+	if err != nil {
+		return err
+	}
+
+	if name == DefaultProfile {
+		if err := store.config.Unset(activeProfilePath); err != nil {
+			return err
+		}
+
+		return store.save(ctx)
+	}
+
+	if !hasProfile(store.config, name) {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+
+	if err := store.config.Set(activeProfilePath, name); err != nil {
+		return err
+	}
+
+	return store.save(ctx)
+}
+
+// CreateProfile creates a new named profile with the specified extension config.
+func CreateProfile(ctx context.Context, azdContext *ext.Context, name string, extensionConfig *ExtensionConfig) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	store, err := loadConfigStore(ctx, azdContext)
+	if err != nil {
+		return err
+	}
+
+	if name == DefaultProfile || hasProfile(store.config, name) {
+		return fmt.Errorf("%w: %s", ErrProfileExists, name)
+	}
+
+	if extensionConfig == nil {
+		extensionConfig = &ExtensionConfig{}
+	}
+
+	if err := store.config.Set(extensionConfigPath(name), extensionConfig); err != nil {
+		return err
+	}
+
+	return store.save(ctx)
+}
+
+// DeleteProfile deletes the named profile and switches back to the default profile when it was active.
+func DeleteProfile(ctx context.Context, azdContext *ext.Context, name string) error {
+	if name == DefaultProfile {
+		return errors.New("the default profile can't be deleted")
+	}
+
+	store, err := loadConfigStore(ctx, azdContext)
+	if err != nil {
+		return err
+	}
+
+	if !hasProfile(store.config, name) {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+
+	if err := store.config.Unset(extensionConfigPath(name)); err != nil {
+		return err
+	}
+
+	if active, has := store.config.GetString(activeProfilePath); has && active == name {
+		if err := store.config.Unset(activeProfilePath); err != nil {
+			return err
+		}
+	}
+
+	return store.save(ctx)
+}
+
+// activeProfile returns the profile overridden for the current invocation or the profile set as active within the config.
+// An overridden profile must exist, profiles are only created by CreateProfile.
+func activeProfile(ctx context.Context, azdConfig config.Config) (string, error) {
+	if profile, ok := ctx.Value(profileContextKey{}).(string); ok && profile != "" {
+		if profile != DefaultProfile {
+			if err := ValidateProfileName(profile); err != nil {
+				return "", err
+			}
+
+			if !hasProfile(azdConfig, profile) {
+				return "", fmt.Errorf("%w: %s", ErrProfileNotFound, profile)
+			}
+		}
+
+		return profile, nil
+	}
+
+	if profile, has := azdConfig.GetString(activeProfilePath); has && profile != "" {
+		return profile, nil
+	}
+
+	return DefaultProfile, nil
+}
+
+func hasProfile(azdConfig config.Config, name string) bool {
+	_, has := azdConfig.Get(extensionConfigPath(name))
+	return has
+}
+
+func extensionConfigPath(profile string) string {
+	if profile == "" || profile == DefaultProfile {
+		return defaultConfigPath
+	}
+
+	return fmt.Sprintf("%s.%s", profilesPath, profile)
+}
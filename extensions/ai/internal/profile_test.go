@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wbreza/azd-extensions/sdk/core/config"
+)
+
+func Test_ActiveProfile(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		profile, err := activeProfile(context.Background(), config.NewEmptyConfig())
+		require.NoError(t, err)
+		require.Equal(t, DefaultProfile, profile)
+		require.Equal(t, "ai.config", extensionConfigPath(profile))
+	})
+
+	t.Run("ActivePointer", func(t *testing.T) {
+		azdConfig := config.NewEmptyConfig()
+		require.NoError(t, azdConfig.Set(activeProfilePath, "load-test"))
+
+		profile, err := activeProfile(context.Background(), azdConfig)
+		require.NoError(t, err)
+		require.Equal(t, "load-test", profile)
+		require.Equal(t, "ai.profiles.load-test", extensionConfigPath(profile))
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		azdConfig := config.NewEmptyConfig()
+		require.NoError(t, azdConfig.Set(activeProfilePath, "load-test"))
+
+		require.NoError(t, azdConfig.Set(extensionConfigPath("dev"), &ExtensionConfig{}))
+
+		profile, err := activeProfile(WithProfile(context.Background(), "dev"), azdConfig)
+		require.NoError(t, err)
+		require.Equal(t, "dev", profile)
+
+		profile, err = activeProfile(WithProfile(context.Background(), DefaultProfile), azdConfig)
+		require.NoError(t, err)
+		require.Equal(t, DefaultProfile, profile)
+
+		_, err = activeProfile(WithProfile(context.Background(), "dev.ai"), azdConfig)
+		require.Error(t, err)
+	})
+
+	t.Run("UnknownOverride", func(t *testing.T) {
+		azdConfig := config.NewEmptyConfig()
+		require.NoError(t, azdConfig.Set(extensionConfigPath("load-test"), &ExtensionConfig{}))
+
+		// A mistyped --profile must not read an empty config that is later saved as a new profile
+		_, err := activeProfile(WithProfile(context.Background(), "lodtest"), azdConfig)
+		require.ErrorIs(t, err, ErrProfileNotFound)
+		require.False(t, hasProfile(azdConfig, "lodtest"))
+	})
+}
@@ -1128,6 +1128,15 @@ func defaultSearchIndex(indexName string) *azsearch.Index {
 				Facetable:   to.Ptr(false),
 				Searchable:  to.Ptr(true),
 			},
+			{
+				Name:        to.Ptr("metadata"),
+				Type:        to.Ptr(azsearch.SearchFieldDataTypeString),
+				Retrievable: to.Ptr(true),
+				Filterable:  to.Ptr(false),
+				Sortable:    to.Ptr(false),
+				Facetable:   to.Ptr(false),
+				Searchable:  to.Ptr(false),
+			},
 			{
 				Name:                    to.Ptr("vector"),
 				Type:                    to.Ptr(azsearch.SearchFieldDataType("Collection(Edm.Single)")),
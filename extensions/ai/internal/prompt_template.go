@@ -0,0 +1,420 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/wbreza/azd-extensions/sdk/core/azd"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultPromptTemplateDir is the folder at the root of the azd project containing the prompt templates.
+	DefaultPromptTemplateDir = "prompts"
+	// PromptTemplateFileType is the file extension of prompt templates.
+	PromptTemplateFileType = ".prompty"
+)
+
+// Names of the built-in prompt templates used when a project doesn't define its own.
+const (
+	ChatPromptTemplate        = "chat"
+	ChatSummaryPromptTemplate = "chat-summary"
+	SummarizePromptTemplate   = "summarize"
+	EvaluatePromptTemplate    = "evaluate"
+	RerankPromptTemplate      = "rerank"
+)
+
+// Variables available to prompt templates.
+const (
+	PromptVariableQuestion = "question"
+	PromptVariableContext  = "context"
+	PromptVariableContent  = "content"
+	PromptVariableDate     = "date"
+)
+
+var (
+	ErrPromptTemplateNotFound = errors.New("prompt template not found")
+
+	promptVariableRegex = regexp.MustCompile(`{{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*}}`)
+	promptRoleRegex     = regexp.MustCompile(`(?m)^(system|user|assistant):[ \t]*$`)
+
+	builtInPromptTemplates = map[string]string{
+		ChatPromptTemplate: `---
+name: chat
+description: Default system message for azd ai chat
+version: builtin
+---
+system:
+You are an AI assistant that helps people find information.
+`,
+		ChatSummaryPromptTemplate: `---
+name: chat-summary
+description: Summarizes the chat history once it grows too large
+version: builtin
+---
+user:
+Summarize the following conversation:
+
+{{content}}
+`,
+		SummarizePromptTemplate: `---
+name: summarize
+description: Summarizes document chunks before generating embeddings
+version: builtin
+---
+system:
+You are helping generate summary embeddings for specified document. Please provide a summary of the document.
+
+user:
+{{content}}
+`,
+		EvaluatePromptTemplate: `---
+name: evaluate
+description: System message used to query the model during evaluations
+version: builtin
+---
+system:
+You are a helpful AI assistant.
+`,
+		RerankPromptTemplate: `---
+name: rerank
+description: Scores the relevance of a retrieved document to the question
+version: builtin
+---
+system:
+You rate how relevant a passage is for answering a question.
+Respond with a single integer from 0 (not relevant) to 10 (answers the question) and nothing else.
+
+user:
+Question: {{question}}
+
+Passage:
+{{context}}
+`,
+	}
+)
+
+// PromptTemplate is a set of chat messages with {{variable}} placeholders.
+//
+// Templates are stored as `prompts/<name>.prompty` files with YAML front matter followed by
+// the messages, each message starting with a `system:`, `user:` or `assistant:` line.
+type PromptTemplate struct {
+	Name        string           `yaml:"name"`
+	Description string           `yaml:"description,omitempty"`
+	Version     string           `yaml:"version,omitempty"`
+	Messages    []*PromptMessage `yaml:"-"`
+	// Hash is the SHA256 hash of the template file content.
+	Hash string `yaml:"-"`
+	Path string `yaml:"-"`
+}
+
+type PromptMessage struct {
+	Role    string
+	Content string
+}
+
+// PromptTemplateInfo identifies the version of a prompt template used to produce a result.
+type PromptTemplateInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Hash    string `json:"hash"`
+}
+
+func (t *PromptTemplate) Info() *PromptTemplateInfo {
+	return &PromptTemplateInfo{
+		Name:    t.Name,
+		Version: t.Version,
+		Hash:    t.Hash,
+	}
+}
+
+// HasMessage returns true when the template contains a message with the role.
+func (t *PromptTemplate) HasMessage(role string) bool {
+	return slices.ContainsFunc(t.Messages, func(message *PromptMessage) bool {
+		return message.Role == role
+	})
+}
+
+// Render replaces the variables of all messages with the specified values.
+// The date variable defaults to the current date and all other variables must be specified.
+func (t *PromptTemplate) Render(variables map[string]string) ([]*PromptMessage, error) {
+	values := map[string]string{
+		PromptVariableDate: time.Now().Format(time.DateOnly),
+	}
+
+	for key, value := range variables {
+		values[key] = value
+	}
+
+	missing := []string{}
+	messages := make([]*PromptMessage, len(t.Messages))
+
+	for i, message := range t.Messages {
+		content := promptVariableRegex.ReplaceAllStringFunc(message.Content, func(match string) string {
+			name := promptVariableRegex.FindStringSubmatch(match)[1]
+			value, has := values[name]
+			if !has {
+				if !slices.Contains(missing, name) {
+					missing = append(missing, name)
+				}
+
+				return match
+			}
+
+			return value
+		})
+
+		messages[i] = &PromptMessage{
+			Role:    message.Role,
+			Content: content,
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("prompt template '%s' is missing values for variables: %s", t.Name, strings.Join(missing, ", "))
+	}
+
+	return messages, nil
+}
+
+// UserMessage renders the user message for the question and the retrieved context.
+// Templates without a user message send the question along with any retrieved context.
+func (t *PromptTemplate) UserMessage(question string, context string) (string, error) {
+	if t.HasMessage("user") {
+		return t.RenderMessage("user", map[string]string{
+			PromptVariableQuestion: question,
+			PromptVariableContext:  context,
+		})
+	}
+
+	if context == "" {
+		return question, nil
+	}
+
+	return fmt.Sprintf("Question: \"%s\"\n\nContext:\n%s", question, context), nil
+}
+
+// SystemMessage renders the system message of the template.
+// The question and context variables are empty since they're only known for each user message.
+func (t *PromptTemplate) SystemMessage() (string, error) {
+	return t.RenderMessage("system", map[string]string{
+		PromptVariableQuestion: "",
+		PromptVariableContext:  "",
+	})
+}
+
+// RenderMessage renders the template and returns the content of the first message with the role.
+func (t *PromptTemplate) RenderMessage(role string, variables map[string]string) (string, error) {
+	messages, err := t.Render(variables)
+	if err != nil {
+		return "", err
+	}
+
+	for _, message := range messages {
+		if message.Role == role {
+			return message.Content, nil
+		}
+	}
+
+	return "", nil
+}
+
+// ParsePromptTemplate parses the content of a prompt template file.
+func ParsePromptTemplate(name string, content []byte) (*PromptTemplate, error) {
+	contentHash := sha256.Sum256(content)
+	template := &PromptTemplate{
+		Name: name,
+		Hash: hex.EncodeToString(contentHash[:]),
+	}
+
+	body := string(bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n")))
+
+	if strings.HasPrefix(body, "---\n") {
+		frontMatter, rest, found := strings.Cut(body[len("---\n"):], "\n---")
+		if !found {
+			return nil, fmt.Errorf("prompt template '%s' has an unterminated front matter", name)
+		}
+
+		if err := yaml.Unmarshal([]byte(frontMatter), template); err != nil {
+			return nil, fmt.Errorf("failed parsing front matter of prompt template '%s': %w", name, err)
+		}
+
+		body = strings.TrimPrefix(rest, "\n")
+	}
+
+	if template.Name == "" {
+		template.Name = name
+	}
+
+	roleMatches := promptRoleRegex.FindAllStringSubmatchIndex(body, -1)
+	if len(roleMatches) == 0 {
+		// Templates without role markers are a single system message
+		template.Messages = []*PromptMessage{{Role: "system", Content: strings.TrimSpace(body)}}
+		return template, nil
+	}
+
+	if strings.TrimSpace(body[:roleMatches[0][0]]) != "" {
+		return nil, fmt.Errorf("prompt template '%s' has content before the first role", name)
+	}
+
+	for i, match := range roleMatches {
+		end := len(body)
+		if i+1 < len(roleMatches) {
+			end = roleMatches[i+1][0]
+		}
+
+		template.Messages = append(template.Messages, &PromptMessage{
+			Role:    body[match[2]:match[3]],
+			Content: strings.TrimSpace(body[match[1]:end]),
+		})
+	}
+
+	return template, nil
+}
+
+// ChatRequestMessages converts the rendered messages into chat completion request messages.
+func ChatRequestMessages(messages []*PromptMessage) []azopenai.ChatRequestMessageClassification {
+	chatMessages := []azopenai.ChatRequestMessageClassification{}
+
+	for _, message := range messages {
+		switch message.Role {
+		case "system":
+			chatMessages = append(chatMessages, &azopenai.ChatRequestSystemMessage{
+				Content: azopenai.NewChatRequestSystemMessageContent(message.Content),
+			})
+		case "assistant":
+			chatMessages = append(chatMessages, &azopenai.ChatRequestAssistantMessage{
+				Content: azopenai.NewChatRequestAssistantMessageContent(message.Content),
+			})
+		default:
+			chatMessages = append(chatMessages, &azopenai.ChatRequestUserMessage{
+				Content: azopenai.NewChatRequestUserMessageContent(message.Content),
+			})
+		}
+	}
+
+	return chatMessages
+}
+
+// PromptTemplateStore loads prompt templates from the project prompts folder
+// and falls back to the built-in templates.
+type PromptTemplateStore struct {
+	dir string
+}
+
+func NewPromptTemplateStore(dir string) *PromptTemplateStore {
+	return &PromptTemplateStore{
+		dir: dir,
+	}
+}
+
+// NewProjectPromptTemplateStore returns the store of the prompts folder at the root of the azd project,
+// so the same templates are used from any directory of the project.
+func NewProjectPromptTemplateStore(azdContext *ext.Context) (*PromptTemplateStore, error) {
+	var store *PromptTemplateStore
+
+	err := azdContext.Invoke(func(projectContext *azd.Context) {
+		store = NewPromptTemplateStore(filepath.Join(projectContext.ProjectDirectory(), DefaultPromptTemplateDir))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Resolve loads the first non-empty template reference or the built-in template when none are set.
+func (s *PromptTemplateStore) Resolve(builtIn string, references ...string) (*PromptTemplate, error) {
+	for _, reference := range references {
+		if reference != "" {
+			return s.Get(reference)
+		}
+	}
+
+	return s.Get(builtIn)
+}
+
+// Get loads the template by name. A version can be pinned with `<name>@<version>`
+// and loading fails when the template has a different version.
+func (s *PromptTemplateStore) Get(reference string) (*PromptTemplate, error) {
+	name, version, _ := strings.Cut(reference, "@")
+
+	template, err := s.load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != "" && template.Version != version {
+		return nil, fmt.Errorf(
+			"prompt template '%s' is version '%s' but version '%s' was requested",
+			name,
+			template.Version,
+			version,
+		)
+	}
+
+	return template, nil
+}
+
+// List returns the names of the project and built-in templates.
+func (s *PromptTemplateStore) List() ([]string, error) {
+	names := []string{}
+	for name := range builtInPromptTemplates {
+		names = append(names, name)
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != PromptTemplateFileType {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), PromptTemplateFileType)
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+	return names, nil
+}
+
+func (s *PromptTemplateStore) load(name string) (*PromptTemplate, error) {
+	path := filepath.Join(s.dir, name+PromptTemplateFileType)
+
+	content, err := os.ReadFile(path)
+	if err == nil {
+		template, err := ParsePromptTemplate(name, content)
+		if err != nil {
+			return nil, err
+		}
+
+		template.Path = path
+		return template, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if builtIn, has := builtInPromptTemplates[name]; has {
+		return ParsePromptTemplate(name, []byte(builtIn))
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrPromptTemplateNotFound, path)
+}
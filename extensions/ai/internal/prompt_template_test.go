@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wbreza/azd-extensions/sdk/core/azd"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+)
+
+const testPromptTemplate = `---
+name: support
+version: "2"
+---
+system:
+You are a support assistant.
+
+user:
+Context:
+{{context}}
+
+Question: {{ question }}
+`
+
+func Test_PromptTemplate(t *testing.T) {
+	t.Run("Parse", func(t *testing.T) {
+		template, err := ParsePromptTemplate("support", []byte(testPromptTemplate))
+		require.NoError(t, err)
+		require.Equal(t, "support", template.Name)
+		require.Equal(t, "2", template.Version)
+		require.Len(t, template.Messages, 2)
+		require.Equal(t, "system", template.Messages[0].Role)
+		require.Equal(t, "You are a support assistant.", template.Messages[0].Content)
+		require.Len(t, template.Hash, 64)
+	})
+
+	t.Run("Render", func(t *testing.T) {
+		template, err := ParsePromptTemplate("support", []byte(testPromptTemplate))
+		require.NoError(t, err)
+
+		userMessage, err := template.UserMessage("How do I reset my password?", "Use the portal.")
+		require.NoError(t, err)
+		require.Equal(t, "Context:\nUse the portal.\n\nQuestion: How do I reset my password?", userMessage)
+
+		_, err = template.Render(map[string]string{PromptVariableQuestion: "Hello"})
+		require.ErrorContains(t, err, "context")
+	})
+
+	t.Run("NoUserMessage", func(t *testing.T) {
+		template, err := ParsePromptTemplate("plain", []byte("Answer briefly."))
+		require.NoError(t, err)
+
+		systemMessage, err := template.SystemMessage()
+		require.NoError(t, err)
+		require.Equal(t, "Answer briefly.", systemMessage)
+
+		userMessage, err := template.UserMessage("Hi", "")
+		require.NoError(t, err)
+		require.Equal(t, "Hi", userMessage)
+	})
+
+	t.Run("Store", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "support.prompty"), []byte(testPromptTemplate), 0600))
+
+		store := NewPromptTemplateStore(dir)
+
+		template, err := store.Resolve(ChatPromptTemplate, "", "support@2")
+		require.NoError(t, err)
+		require.Equal(t, "support", template.Name)
+
+		_, err = store.Get("support@1")
+		require.Error(t, err)
+
+		template, err = store.Resolve(ChatPromptTemplate)
+		require.NoError(t, err)
+		require.Equal(t, "builtin", template.Version)
+
+		_, err = store.Get("missing")
+		require.ErrorIs(t, err, ErrPromptTemplateNotFound)
+
+		names, err := store.List()
+		require.NoError(t, err)
+		require.Contains(t, names, "support")
+		require.Contains(t, names, EvaluatePromptTemplate)
+	})
+
+	t.Run("ProjectStore", func(t *testing.T) {
+		projectDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, azd.ProjectFileName), []byte("name: test\n"), 0600))
+		require.NoError(t, os.MkdirAll(filepath.Join(projectDir, DefaultPromptTemplateDir), 0700))
+		require.NoError(t, os.WriteFile(
+			filepath.Join(projectDir, DefaultPromptTemplateDir, "support.prompty"), []byte(testPromptTemplate), 0600),
+		)
+
+		// The templates are resolved from the project root when running in a sub folder
+		workingDir := filepath.Join(projectDir, "src")
+		require.NoError(t, os.MkdirAll(workingDir, 0700))
+
+		cwd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(workingDir))
+		t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+		azdContext, err := ext.CurrentContext(context.Background())
+		require.NoError(t, err)
+
+		store, err := NewProjectPromptTemplateStore(azdContext)
+		require.NoError(t, err)
+
+		template, err := store.Get("support")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(projectDir, DefaultPromptTemplateDir, "support.prompty"), template.Path)
+	})
+}
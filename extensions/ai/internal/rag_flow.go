@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+)
+
+// RagFlow builds the user messages sent to the chat completion model. Questions are augmented with the documents
+// retrieved from the search index and rendered with the chat prompt template.
+type RagFlow struct {
+	openAiClient   *azopenai.Client
+	template       *PromptTemplate
+	embeddingModel string
+	retriever      *Retriever
+}
+
+// NewRagFlow creates the flow. Retrieval is disabled when the retriever is nil.
+func NewRagFlow(openAiClient *azopenai.Client, template *PromptTemplate, embeddingModel string, retriever *Retriever) *RagFlow {
+	return &RagFlow{
+		openAiClient:   openAiClient,
+		template:       template,
+		embeddingModel: embeddingModel,
+		retriever:      retriever,
+	}
+}
+
+// Template returns the chat prompt template.
+func (f *RagFlow) Template() *PromptTemplate {
+	return f.template
+}
+
+// Retriever returns the retriever or nil when retrieval is disabled.
+func (f *RagFlow) Retriever() *Retriever {
+	return f.retriever
+}
+
+// UserMessage retrieves the documents relevant to the question and renders the user message.
+func (f *RagFlow) UserMessage(ctx context.Context, question string) (string, error) {
+	searchContext := ""
+
+	if f.retriever != nil {
+		embeddingsResponse, err := f.openAiClient.GetEmbeddings(ctx, azopenai.EmbeddingsOptions{
+			Input:          []string{question},
+			DeploymentName: &f.embeddingModel,
+		}, nil)
+		if err != nil {
+			return "", err
+		}
+
+		documents, _, err := f.retriever.Retrieve(ctx, question, embeddingsResponse.Data[0].Embedding)
+		if err != nil {
+			return "", err
+		}
+
+		searchContext = FormatRetrievedContext(documents)
+	}
+
+	return f.template.UserMessage(question, searchContext)
+}
@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/wbreza/azd-extensions/sdk/common/permissions"
+)
+
+var (
+	ErrCacheMiss = errors.New("response not found in cache")
+)
+
+// ResponseCacheKey contains all the inputs that influence a model response.
+// Two requests with the same key are expected to produce an equivalent response.
+//
+// The hash of the retrieved context is only known after searching the index, so it isn't part of the address of
+// the entry. It's stored in the entry and a cached response only matches when it's equal. An empty context hash
+// matches any entry, which replays flow responses without calling Azure.
+type ResponseCacheKey struct {
+	Deployment   string         `json:"deployment"`
+	ModelVersion string         `json:"modelVersion,omitempty"`
+	ContextHash  string         `json:"contextHash,omitempty"`
+	Messages     []CacheMessage `json:"messages"`
+	Parameters   map[string]any `json:"parameters,omitempty"`
+}
+
+type CacheMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Hash returns the content address for the cache key.
+func (k ResponseCacheKey) Hash() (string, error) {
+	k.ContextHash = ""
+
+	keyBytes, err := json.Marshal(k)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(keyBytes)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+type ResponseCacheEntry struct {
+	Key       ResponseCacheKey `json:"key"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Response  *ModelResponse   `json:"response"`
+}
+
+// modelVersionsFile records the model version of the last response cached for each deployment.
+const modelVersionsFile = "model_versions.json"
+
+// ResponseCache is a content addressed store of model responses persisted on the local file system.
+type ResponseCache struct {
+	root string
+
+	modelVersionsMutex sync.Mutex
+}
+
+func NewResponseCache(root string) (*ResponseCache, error) {
+	if err := os.MkdirAll(root, permissions.PermissionDirectory); err != nil {
+		return nil, fmt.Errorf("failed creating cache directory: %w", err)
+	}
+
+	return &ResponseCache{
+		root: root,
+	}, nil
+}
+
+// Get returns the cached response for the specified key or ErrCacheMiss when not found or when the context hash of
+// the entry is different.
+func (c *ResponseCache) Get(key ResponseCacheKey) (*ModelResponse, error) {
+	hash, err := key.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	entryBytes, err := os.ReadFile(c.entryPath(hash))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrCacheMiss
+		}
+
+		return nil, err
+	}
+
+	var entry ResponseCacheEntry
+	if err := json.Unmarshal(entryBytes, &entry); err != nil {
+		return nil, fmt.Errorf("failed reading cache entry %s: %w", hash, err)
+	}
+
+	if entry.Response == nil {
+		return nil, ErrCacheMiss
+	}
+
+	if key.ContextHash != "" && key.ContextHash != entry.Key.ContextHash {
+		return nil, ErrCacheMiss
+	}
+
+	return entry.Response, nil
+}
+
+// Set stores the response for the specified key and records the model version as the last version of the deployment.
+func (c *ResponseCache) Set(key ResponseCacheKey, response *ModelResponse) error {
+	hash, err := key.Hash()
+	if err != nil {
+		return err
+	}
+
+	entry := ResponseCacheEntry{
+		Key:       key,
+		CreatedAt: time.Now().UTC(),
+		Response:  response,
+	}
+
+	entryBytes, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(c.entryPath(hash), entryBytes, permissions.PermissionFile); err != nil {
+		return err
+	}
+
+	if key.ModelVersion == "" {
+		return nil
+	}
+
+	c.modelVersionsMutex.Lock()
+	defer c.modelVersionsMutex.Unlock()
+
+	modelVersions, err := c.readModelVersions()
+	if err != nil {
+		return err
+	}
+
+	if modelVersions[key.Deployment] == key.ModelVersion {
+		return nil
+	}
+
+	modelVersions[key.Deployment] = key.ModelVersion
+
+	versionsBytes, err := json.MarshalIndent(modelVersions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(c.root, modelVersionsFile), versionsBytes, permissions.PermissionFile)
+}
+
+// ModelVersion returns the model version of the last response cached for the deployment or ErrCacheMiss when no
+// response of the deployment was cached.
+func (c *ResponseCache) ModelVersion(deployment string) (string, error) {
+	c.modelVersionsMutex.Lock()
+	defer c.modelVersionsMutex.Unlock()
+
+	modelVersions, err := c.readModelVersions()
+	if err != nil {
+		return "", err
+	}
+
+	modelVersion, has := modelVersions[deployment]
+	if !has {
+		return "", ErrCacheMiss
+	}
+
+	return modelVersion, nil
+}
+
+func (c *ResponseCache) readModelVersions() (map[string]string, error) {
+	modelVersions := map[string]string{}
+
+	versionsBytes, err := os.ReadFile(filepath.Join(c.root, modelVersionsFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return modelVersions, nil
+		}
+
+		return nil, err
+	}
+
+	if err := json.Unmarshal(versionsBytes, &modelVersions); err != nil {
+		return nil, fmt.Errorf("failed reading %s: %w", modelVersionsFile, err)
+	}
+
+	return modelVersions, nil
+}
+
+func (c *ResponseCache) entryPath(hash string) string {
+	return filepath.Join(c.root, fmt.Sprintf("%s.json", hash))
+}
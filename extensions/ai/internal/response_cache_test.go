@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResponseCache(t *testing.T) {
+	cache, err := NewResponseCache(t.TempDir())
+	require.NoError(t, err)
+
+	key := ResponseCacheKey{
+		Deployment:   "gpt-4o",
+		ModelVersion: "2024-08-06",
+		Messages: []CacheMessage{
+			{Role: "system", Content: "You are a helpful AI assistant."},
+			{Role: "user", Content: "What is the capital of France?"},
+		},
+		Parameters: map[string]any{"evaluationType": EvaluationTypeModel},
+	}
+
+	_, err = cache.Get(key)
+	require.ErrorIs(t, err, ErrCacheMiss)
+
+	response := &ModelResponse{
+		Message:  "Paris",
+		Duration: 120,
+		TokenUsage: TokenUsage{
+			PromptTokens:     20,
+			CompletionTokens: 1,
+			TotalTokens:      21,
+		},
+	}
+
+	require.NoError(t, cache.Set(key, response))
+
+	cachedResponse, err := cache.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, response, cachedResponse)
+
+	modelVersion, err := cache.ModelVersion("gpt-4o")
+	require.NoError(t, err)
+	require.Equal(t, "2024-08-06", modelVersion)
+
+	_, err = cache.ModelVersion("gpt-4o-mini")
+	require.ErrorIs(t, err, ErrCacheMiss)
+
+	// Replays don't know the retrieved context
+	key.ContextHash = "9f86d081"
+	require.NoError(t, cache.Set(key, response))
+
+	replayKey := key
+	replayKey.ContextHash = ""
+	cachedResponse, err = cache.Get(replayKey)
+	require.NoError(t, err)
+	require.Equal(t, response, cachedResponse)
+
+	key.ContextHash = "2c26b46b"
+	_, err = cache.Get(key)
+	require.ErrorIs(t, err, ErrCacheMiss)
+
+	// Each model version has its own entry
+	upgradedKey := key
+	upgradedKey.ContextHash = ""
+	upgradedKey.ModelVersion = "2024-11-20"
+	_, err = cache.Get(upgradedKey)
+	require.ErrorIs(t, err, ErrCacheMiss)
+
+	require.NoError(t, cache.Set(upgradedKey, &ModelResponse{Message: "Paris, France"}))
+
+	cachedResponse, err = cache.Get(upgradedKey)
+	require.NoError(t, err)
+	require.Equal(t, "Paris, France", cachedResponse.Message)
+
+	cachedResponse, err = cache.Get(replayKey)
+	require.NoError(t, err)
+	require.Equal(t, "Paris", cachedResponse.Message)
+
+	modelVersion, err = cache.ModelVersion("gpt-4o")
+	require.NoError(t, err)
+	require.Equal(t, "2024-11-20", modelVersion)
+}
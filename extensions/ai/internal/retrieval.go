@@ -0,0 +1,445 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/wbreza/azure-sdk-for-go/sdk/data/azsearchindex"
+)
+
+const (
+	// DefaultRetrievalTop is the number of retrieved documents included in the prompt.
+	DefaultRetrievalTop = 3
+	// DefaultMmrLambda balances relevance (1) against diversity (0) when selecting documents with MMR.
+	DefaultMmrLambda = 0.7
+	// candidatesPerResult is the over-fetch factor used when post-retrieval stages are enabled.
+	candidatesPerResult = 4
+	// maxRerankScore is the highest relevance score returned by the rerank prompt.
+	maxRerankScore = 10
+	// estimatedCandidateTokens is the assumed size of each candidate scored by the reranker.
+	estimatedCandidateTokens = 512
+)
+
+// RetrievalOptions configures the post-retrieval stages applied to the vector search results.
+type RetrievalOptions struct {
+	// Top is the number of documents included in the prompt.
+	Top int `json:"top,omitempty"`
+	// Candidates is the number of documents fetched from the index before the post-retrieval stages.
+	Candidates int `json:"candidates,omitempty"`
+	// Dedupe keeps only the highest ranked chunk of each parent document.
+	Dedupe bool `json:"dedupe,omitempty"`
+	// Mmr selects documents with Maximal Marginal Relevance using the stored vectors.
+	Mmr bool `json:"mmr,omitempty"`
+	// MmrLambda balances relevance (1) against diversity (0).
+	MmrLambda float64 `json:"mmrLambda,omitempty"`
+	// Rerank scores each candidate against the query with the chat completion model.
+	Rerank bool `json:"rerank,omitempty"`
+}
+
+// WithDefaults returns the options with defaults applied for unset values.
+func (o RetrievalOptions) WithDefaults() RetrievalOptions {
+	if o.Top <= 0 {
+		o.Top = DefaultRetrievalTop
+	}
+
+	if o.Candidates <= 0 {
+		o.Candidates = o.Top
+		if o.Dedupe || o.Mmr || o.Rerank {
+			o.Candidates = o.Top * candidatesPerResult
+		}
+	}
+
+	o.Candidates = max(o.Candidates, o.Top)
+
+	if o.MmrLambda <= 0 || o.MmrLambda > 1 {
+		o.MmrLambda = DefaultMmrLambda
+	}
+
+	return o
+}
+
+// String describes the enabled stages, e.g. `top 3 of 12, dedupe, mmr (0.70)`.
+func (o RetrievalOptions) String() string {
+	stages := []string{fmt.Sprintf("top %d of %d", o.Top, o.Candidates)}
+
+	if o.Dedupe {
+		stages = append(stages, "dedupe")
+	}
+
+	if o.Rerank {
+		stages = append(stages, "rerank")
+	}
+
+	if o.Mmr {
+		stages = append(stages, fmt.Sprintf("mmr (%.2f)", o.MmrLambda))
+	}
+
+	return strings.Join(stages, ", ")
+}
+
+// SearchFields maps the retrieved document properties to the fields of the search index.
+type SearchFields struct {
+	Id       string
+	ParentId string
+	Content  string
+	Vector   string
+}
+
+var (
+	// DocumentSearchFields are the fields of indexes created by `azd ai index create`.
+	DocumentSearchFields = SearchFields{
+		Id:       "id",
+		ParentId: "parentId",
+		Content:  "summary",
+		Vector:   "vector",
+	}
+
+	// IntegratedSearchFields are the fields of indexes created with integrated vectorization.
+	IntegratedSearchFields = SearchFields{
+		Id:       "chunk_id",
+		ParentId: "parent_id",
+		Content:  "chunk",
+		Vector:   "text_vector",
+	}
+)
+
+// RetrievedDocument is a document returned from the search index.
+type RetrievedDocument struct {
+	Id       string
+	ParentId string
+	Content  string
+	Vector   []float32
+	// Score is the search score or the normalized rerank score when reranking is enabled.
+	Score float64
+}
+
+// Retriever finds the documents relevant to a query with vector search followed by
+// de-duplication, reranking and MMR diversity selection.
+type Retriever struct {
+	searchClient *azsearchindex.DocumentsClient
+	openAiClient *azopenai.Client
+	fields       SearchFields
+	options      RetrievalOptions
+	costTracker  *CostTracker
+
+	rerankModel    string
+	rerankTemplate *PromptTemplate
+}
+
+func NewRetriever(
+	searchClient *azsearchindex.DocumentsClient,
+	openAiClient *azopenai.Client,
+	fields SearchFields,
+	options RetrievalOptions,
+) *Retriever {
+	return &Retriever{
+		searchClient: searchClient,
+		openAiClient: openAiClient,
+		fields:       fields,
+		options:      options.WithDefaults(),
+	}
+}
+
+// SetReranker configures the chat completion model and prompt template used to score candidates.
+func (r *Retriever) SetReranker(deploymentName string, template *PromptTemplate) {
+	r.rerankModel = deploymentName
+	r.rerankTemplate = template
+}
+
+// SetCostTracker configures the tracker used to record the cost of rerank calls.
+func (r *Retriever) SetCostTracker(tracker *CostTracker) {
+	r.costTracker = tracker
+}
+
+// Options returns the retrieval options with defaults applied.
+func (r *Retriever) Options() RetrievalOptions {
+	return r.options
+}
+
+// Retrieve returns the documents for the query vector and the token usage of the rerank calls.
+func (r *Retriever) Retrieve(ctx context.Context, query string, queryVector []float32) ([]*RetrievedDocument, TokenUsage, error) {
+	tokenUsage := TokenUsage{}
+
+	selectFields := []string{r.fields.Id, r.fields.Content}
+	if r.options.Dedupe {
+		selectFields = append(selectFields, r.fields.ParentId)
+	}
+
+	if r.options.Mmr {
+		selectFields = append(selectFields, r.fields.Vector)
+	}
+
+	searchResponse, err := r.searchClient.SearchPost(ctx, azsearchindex.SearchRequest{
+		Select:    to.Ptr(strings.Join(selectFields, ", ")),
+		QueryType: to.Ptr(azsearchindex.QueryTypeSimple),
+		Top:       to.Ptr(int32(r.options.Candidates)),
+		VectorQueries: []azsearchindex.VectorQueryClassification{
+			&azsearchindex.VectorizedQuery{
+				Kind:       to.Ptr(azsearchindex.VectorQueryKindVector),
+				Fields:     to.Ptr(r.fields.Vector),
+				Exhaustive: to.Ptr(true),
+				K:          to.Ptr(int32(r.options.Candidates)),
+				Vector:     ConvertToFloatPtrSlice(queryVector),
+			},
+		},
+	}, nil, nil)
+	if err != nil {
+		return nil, tokenUsage, err
+	}
+
+	documents := make([]*RetrievedDocument, len(searchResponse.Results))
+	for i, result := range searchResponse.Results {
+		documents[i] = r.parseResult(result)
+	}
+
+	if r.options.Dedupe {
+		documents = DedupeByParent(documents)
+	}
+
+	if r.options.Rerank && r.rerankTemplate != nil {
+		rerankUsage, err := r.rerank(ctx, query, documents)
+		if err != nil {
+			return nil, tokenUsage, err
+		}
+
+		tokenUsage = rerankUsage
+	}
+
+	if r.options.Mmr {
+		documents = SelectMmr(documents, r.options.Top, r.options.MmrLambda)
+	} else {
+		sortByScore(documents)
+		documents = documents[:min(r.options.Top, len(documents))]
+	}
+
+	for _, document := range documents {
+		log.Printf("Retrieved: %s, Parent: %s, Score: %f\n", document.Id, document.ParentId, document.Score)
+	}
+
+	return documents, tokenUsage, nil
+}
+
+// EstimateRerankUsage estimates the prompt and completion tokens of reranking the candidates for a query.
+// The tokenizer is the one of the chat model used for reranking.
+func EstimateRerankUsage(tokenizer *Tokenizer, options RetrievalOptions, template *PromptTemplate, query string) (int32, int32) {
+	if !options.Rerank || template == nil {
+		return 0, 0
+	}
+
+	options = options.WithDefaults()
+
+	// A missing variable only affects the estimate, it fails once the template is rendered
+	messages, _ := template.Render(map[string]string{
+		PromptVariableQuestion: query,
+		PromptVariableContext:  "",
+	})
+
+	var templateTokens int32
+	for _, message := range messages {
+		templateTokens += int32(tokenizer.CountTokens(message.Content))
+	}
+
+	candidates := int32(options.Candidates)
+	return candidates * (templateTokens + estimatedCandidateTokens), candidates * 2
+}
+
+func (r *Retriever) parseResult(result *azsearchindex.SearchResult) *RetrievedDocument {
+	document := &RetrievedDocument{
+		Id:       fmt.Sprint(result.AdditionalProperties[r.fields.Id]),
+		Content:  fmt.Sprint(result.AdditionalProperties[r.fields.Content]),
+		ParentId: stringValue(result.AdditionalProperties[r.fields.ParentId]),
+	}
+
+	if result.Score != nil {
+		document.Score = *result.Score
+	}
+
+	if values, ok := result.AdditionalProperties[r.fields.Vector].([]any); ok {
+		document.Vector = make([]float32, 0, len(values))
+		for _, value := range values {
+			if number, ok := value.(float64); ok {
+				document.Vector = append(document.Vector, float32(number))
+			}
+		}
+	}
+
+	return document
+}
+
+// rerank replaces the search score of each document with the relevance score returned by the model.
+func (r *Retriever) rerank(ctx context.Context, query string, documents []*RetrievedDocument) (TokenUsage, error) {
+	tokenUsage := TokenUsage{}
+
+	for _, document := range documents {
+		if err := r.costTracker.CheckBudget(); err != nil {
+			return tokenUsage, err
+		}
+
+		messages, err := r.rerankTemplate.Render(map[string]string{
+			PromptVariableQuestion: query,
+			PromptVariableContext:  document.Content,
+		})
+		if err != nil {
+			return tokenUsage, err
+		}
+
+		response, err := r.openAiClient.GetChatCompletions(ctx, azopenai.ChatCompletionsOptions{
+			DeploymentName: &r.rerankModel,
+			Messages:       ChatRequestMessages(messages),
+			MaxTokens:      to.Ptr(int32(4)),
+			Temperature:    to.Ptr(float32(0)),
+		}, nil)
+		if err != nil {
+			return tokenUsage, err
+		}
+
+		tokenUsage.PromptTokens += *response.Usage.PromptTokens
+		tokenUsage.CompletionTokens += *response.Usage.CompletionTokens
+		tokenUsage.TotalTokens += *response.Usage.TotalTokens
+		r.costTracker.Track(r.rerankModel, *response.Usage.PromptTokens, *response.Usage.CompletionTokens)
+
+		score, err := parseRerankScore(*response.Choices[0].Message.Content)
+		if err != nil {
+			log.Printf("Ignoring rerank score of %s: %v\n", document.Id, err)
+			score = 0
+		}
+
+		document.Score = score
+	}
+
+	return tokenUsage, nil
+}
+
+// DedupeByParent keeps the highest scoring document of each parent document.
+// Documents without a parent are always kept.
+func DedupeByParent(documents []*RetrievedDocument) []*RetrievedDocument {
+	best := map[string]*RetrievedDocument{}
+	for _, document := range documents {
+		if document.ParentId == "" {
+			continue
+		}
+
+		if current, has := best[document.ParentId]; !has || document.Score > current.Score {
+			best[document.ParentId] = document
+		}
+	}
+
+	result := []*RetrievedDocument{}
+	for _, document := range documents {
+		if document.ParentId == "" || best[document.ParentId] == document {
+			result = append(result, document)
+		}
+	}
+
+	return result
+}
+
+// SelectMmr selects the top documents with Maximal Marginal Relevance.
+//
+// Each step picks the document maximizing `lambda * relevance - (1 - lambda) * similarity` where relevance is the
+// normalized score and similarity is the highest cosine similarity to an already selected document.
+func SelectMmr(documents []*RetrievedDocument, top int, lambda float64) []*RetrievedDocument {
+	candidates := append([]*RetrievedDocument{}, documents...)
+	sortByScore(candidates)
+
+	relevance := normalizedScores(candidates)
+	selected := []*RetrievedDocument{}
+	selectedIndexes := map[int]bool{}
+
+	for len(selected) < top && len(selected) < len(candidates) {
+		bestIndex := -1
+		bestValue := 0.0
+
+		for i, candidate := range candidates {
+			if selectedIndexes[i] {
+				continue
+			}
+
+			maxSimilarity := 0.0
+			for _, document := range selected {
+				similarity := float64(cosineSimilarity(candidate.Vector, document.Vector))
+				maxSimilarity = max(maxSimilarity, similarity)
+			}
+
+			value := lambda*relevance[i] - (1-lambda)*maxSimilarity
+			if bestIndex == -1 || value > bestValue {
+				bestIndex = i
+				bestValue = value
+			}
+		}
+
+		selectedIndexes[bestIndex] = true
+		selected = append(selected, candidates[bestIndex])
+	}
+
+	return selected
+}
+
+// normalizedScores scales the scores of the documents to the range [0, 1].
+func normalizedScores(documents []*RetrievedDocument) []float64 {
+	scores := make([]float64, len(documents))
+	if len(documents) == 0 {
+		return scores
+	}
+
+	minScore, maxScore := documents[0].Score, documents[0].Score
+	for _, document := range documents {
+		minScore = min(minScore, document.Score)
+		maxScore = max(maxScore, document.Score)
+	}
+
+	for i, document := range documents {
+		if maxScore == minScore {
+			scores[i] = 1
+		} else {
+			scores[i] = (document.Score - minScore) / (maxScore - minScore)
+		}
+	}
+
+	return scores
+}
+
+func sortByScore(documents []*RetrievedDocument) {
+	sort.SliceStable(documents, func(i, j int) bool {
+		return documents[i].Score > documents[j].Score
+	})
+}
+
+// parseRerankScore parses the score returned by the rerank prompt and normalizes it to the range [0, 1].
+func parseRerankScore(response string) (float64, error) {
+	value := strings.Trim(strings.TrimSpace(response), ".")
+	if fields := strings.Fields(value); len(fields) > 0 {
+		value = fields[0]
+	}
+
+	score, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rerank score '%s'", response)
+	}
+
+	return min(max(score, 0), maxRerankScore) / maxRerankScore, nil
+}
+
+// FormatRetrievedContext formats the documents as the numbered context of the user message.
+func FormatRetrievedContext(documents []*RetrievedDocument) string {
+	contextResults := make([]string, len(documents))
+	for i, document := range documents {
+		contextResults[i] = fmt.Sprintf("- [%d] %s", i+1, document.Content)
+	}
+
+	return strings.Join(contextResults, "\n\n")
+}
+
+func stringValue(value any) string {
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Retrieval(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		options := RetrievalOptions{}.WithDefaults()
+		require.Equal(t, DefaultRetrievalTop, options.Top)
+		require.Equal(t, DefaultRetrievalTop, options.Candidates)
+
+		options = RetrievalOptions{Top: 5, Mmr: true}.WithDefaults()
+		require.Equal(t, 20, options.Candidates)
+		require.Equal(t, DefaultMmrLambda, options.MmrLambda)
+	})
+
+	t.Run("DedupeByParent", func(t *testing.T) {
+		documents := DedupeByParent([]*RetrievedDocument{
+			{Id: "a-1", ParentId: "a", Score: 0.9},
+			{Id: "a-2", ParentId: "a", Score: 0.95},
+			{Id: "b-1", ParentId: "b", Score: 0.8},
+			{Id: "c-1", Score: 0.7},
+		})
+
+		ids := []string{}
+		for _, document := range documents {
+			ids = append(ids, document.Id)
+		}
+
+		require.Equal(t, []string{"a-2", "b-1", "c-1"}, ids)
+	})
+
+	t.Run("SelectMmr", func(t *testing.T) {
+		documents := []*RetrievedDocument{
+			{Id: "a-1", Score: 0.95, Vector: []float32{1, 0, 0}},
+			{Id: "a-2", Score: 0.94, Vector: []float32{0.99, 0.01, 0}},
+			{Id: "b-1", Score: 0.90, Vector: []float32{0, 1, 0}},
+			{Id: "c-1", Score: 0.80, Vector: []float32{0, 0, 1}},
+		}
+
+		selected := SelectMmr(documents, 2, 0.5)
+		require.Equal(t, "a-1", selected[0].Id)
+		require.Equal(t, "b-1", selected[1].Id)
+
+		// Relevance only selects the near duplicate
+		selected = SelectMmr(documents, 2, 1)
+		require.Equal(t, "a-2", selected[1].Id)
+	})
+
+	t.Run("ParseRerankScore", func(t *testing.T) {
+		score, err := parseRerankScore(" 8.\n")
+		require.NoError(t, err)
+		require.InDelta(t, 0.8, score, 0.0001)
+
+		score, err = parseRerankScore("12")
+		require.NoError(t, err)
+		require.Equal(t, 1.0, score)
+
+		_, err = parseRerankScore("very relevant")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+)
+
+// The request and response types follow the OpenAI REST API so existing OpenAI clients and SDKs can be used.
+
+type chatCompletionRequest struct {
+	Model       string            `json:"model,omitempty"`
+	Messages    []*requestMessage `json:"messages"`
+	Temperature *float32          `json:"temperature,omitempty"`
+	TopP        *float32          `json:"top_p,omitempty"`
+	MaxTokens   *int32            `json:"max_tokens,omitempty"`
+	Stop        []string          `json:"stop,omitempty"`
+	Stream      bool              `json:"stream,omitempty"`
+}
+
+type requestMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type contentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageUrl *struct {
+		Url string `json:"url"`
+	} `json:"image_url,omitempty"`
+}
+
+type chatCompletionResponse struct {
+	Id      string                  `json:"id"`
+	Object  string                  `json:"object"`
+	Created int64                   `json:"created"`
+	Model   string                  `json:"model"`
+	Choices []*chatCompletionChoice `json:"choices"`
+	Usage   *usage                  `json:"usage,omitempty"`
+}
+
+type chatCompletionChoice struct {
+	Index        int              `json:"index"`
+	Message      *responseMessage `json:"message,omitempty"`
+	Delta        *responseMessage `json:"delta,omitempty"`
+	FinishReason *string          `json:"finish_reason"`
+}
+
+type responseMessage struct {
+	Role    string  `json:"role,omitempty"`
+	Content *string `json:"content,omitempty"`
+}
+
+type usage struct {
+	PromptTokens     int32 `json:"prompt_tokens"`
+	CompletionTokens int32 `json:"completion_tokens,omitempty"`
+	TotalTokens      int32 `json:"total_tokens"`
+}
+
+type embeddingsRequest struct {
+	Model string          `json:"model,omitempty"`
+	Input json.RawMessage `json:"input"`
+}
+
+type embeddingsResponse struct {
+	Object string           `json:"object"`
+	Data   []*embeddingData `json:"data"`
+	Model  string           `json:"model"`
+	Usage  *usage           `json:"usage"`
+}
+
+type embeddingData struct {
+	Object    string    `json:"object"`
+	Index     int       `json:"index"`
+	Embedding []float32 `json:"embedding"`
+}
+
+type modelsResponse struct {
+	Object string       `json:"object"`
+	Data   []*modelInfo `json:"data"`
+}
+
+type modelInfo struct {
+	Id      string `json:"id"`
+	Object  string `json:"object"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type errorResponse struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// parseContent returns the text and the image URLs of the message content.
+// The content is either a string or an array of text and image_url parts.
+func parseContent(content json.RawMessage) (string, []string, error) {
+	if len(content) == 0 || string(content) == "null" {
+		return "", nil, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text, nil, nil
+	}
+
+	var parts []*contentPart
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return "", nil, errors.New("message content must be a string or an array of content parts")
+	}
+
+	texts := []string{}
+	imageUrls := []string{}
+
+	for _, part := range parts {
+		switch part.Type {
+		case "text":
+			texts = append(texts, part.Text)
+		case "image_url":
+			if part.ImageUrl == nil || part.ImageUrl.Url == "" {
+				return "", nil, errors.New("image_url content parts require a url")
+			}
+
+			imageUrls = append(imageUrls, part.ImageUrl.Url)
+		default:
+			return "", nil, fmt.Errorf("unsupported content part type '%s'", part.Type)
+		}
+	}
+
+	return strings.Join(texts, "\n"), imageUrls, nil
+}
+
+// parseEmbeddingsInput returns the inputs of an embeddings request, either a string or an array of strings.
+func parseEmbeddingsInput(input json.RawMessage) ([]string, error) {
+	var text string
+	if err := json.Unmarshal(input, &text); err == nil {
+		return []string{text}, nil
+	}
+
+	var texts []string
+	if err := json.Unmarshal(input, &texts); err != nil || len(texts) == 0 {
+		return nil, errors.New("input must be a string or a non-empty array of strings")
+	}
+
+	return texts, nil
+}
+
+func newUserMessage(text string, imageUrls []string) *azopenai.ChatRequestUserMessage {
+	if len(imageUrls) == 0 {
+		return &azopenai.ChatRequestUserMessage{
+			Content: azopenai.NewChatRequestUserMessageContent(text),
+		}
+	}
+
+	parts := []azopenai.ChatCompletionRequestMessageContentPartClassification{
+		&azopenai.ChatCompletionRequestMessageContentPartText{Text: &text},
+	}
+
+	for _, imageUrl := range imageUrls {
+		parts = append(parts, &azopenai.ChatCompletionRequestMessageContentPartImage{
+			ImageURL: &azopenai.ChatCompletionRequestMessageContentPartImageURL{URL: &imageUrl},
+		})
+	}
+
+	return &azopenai.ChatRequestUserMessage{
+		Content: azopenai.NewChatRequestUserMessageContent(parts),
+	}
+}
+
+func finishReason(reason *azopenai.CompletionsFinishReason) *string {
+	if reason == nil {
+		return nil
+	}
+
+	value := string(*reason)
+	return &value
+}
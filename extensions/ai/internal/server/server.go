@@ -0,0 +1,487 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/fatih/color"
+	"github.com/wbreza/azd-extensions/extensions/ai/internal"
+)
+
+const (
+	// maxRequestSize allows requests with image attachments encoded as data URLs.
+	maxRequestSize = 25 * 1024 * 1024
+	// shutdownTimeout is the time given to in-flight requests when the server is stopped.
+	shutdownTimeout = 5 * time.Second
+)
+
+var errInvalidRequest = errors.New("invalid request")
+
+// Options configures the OpenAI compatible server.
+type Options struct {
+	Host string
+	Port int
+	// ChatCompletionModel is the deployment used for all chat completion requests.
+	ChatCompletionModel string
+	// EmbeddingModel is the deployment used for all embeddings requests.
+	EmbeddingModel string
+	// SystemMessage is sent when the request doesn't include a system message.
+	SystemMessage string
+	// LogWriter receives a line for each request, request logging is disabled when nil.
+	LogWriter io.Writer
+	// CorsOrigins are the browser origins allowed to call the server, such as http://localhost:3000.
+	// Cross-origin requests are rejected by browsers when empty.
+	CorsOrigins []string
+}
+
+// Server exposes the configured RAG flow with OpenAI compatible `/v1/chat/completions` and `/v1/embeddings` endpoints.
+type Server struct {
+	openAiClient *azopenai.Client
+	ragFlow      *internal.RagFlow
+	options      Options
+}
+
+func New(openAiClient *azopenai.Client, ragFlow *internal.RagFlow, options Options) *Server {
+	return &Server{
+		openAiClient: openAiClient,
+		ragFlow:      ragFlow,
+		options:      options,
+	}
+}
+
+// Address returns the address the server listens on.
+func (s *Server) Address() string {
+	return net.JoinHostPort(s.options.Host, strconv.Itoa(s.options.Port))
+}
+
+// Handler returns the HTTP handler with all the routes of the server.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chat/completions", s.handleChatCompletions)
+	mux.HandleFunc("POST /v1/embeddings", s.handleEmbeddings)
+	mux.HandleFunc("GET /v1/models", s.handleModels)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return s.withLogging(s.withCors(mux))
+}
+
+// ListenAndServe serves requests until the context is cancelled.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.Address())
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	request := &chatCompletionRequest{}
+	if err := decodeRequest(w, r, request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	messages, err := s.chatMessages(r.Context(), request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errInvalidRequest) {
+			status = http.StatusBadRequest
+		}
+
+		writeError(w, status, err)
+		return
+	}
+
+	options := azopenai.ChatCompletionsOptions{
+		DeploymentName: &s.options.ChatCompletionModel,
+		Messages:       messages,
+		Temperature:    request.Temperature,
+		TopP:           request.TopP,
+		MaxTokens:      request.MaxTokens,
+		Stop:           request.Stop,
+	}
+
+	if request.Stream {
+		s.streamChatCompletions(w, r, options)
+		return
+	}
+
+	response, err := s.openAiClient.GetChatCompletions(r.Context(), options, nil)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	result := &chatCompletionResponse{
+		Id:      stringValue(response.ID),
+		Object:  "chat.completion",
+		Created: unixTime(response.Created),
+		Model:   s.options.ChatCompletionModel,
+		Choices: []*chatCompletionChoice{},
+	}
+
+	for _, choice := range response.Choices {
+		content := ""
+		if choice.Message != nil && choice.Message.Content != nil {
+			content = *choice.Message.Content
+		}
+
+		result.Choices = append(result.Choices, &chatCompletionChoice{
+			Index:        int(*choice.Index),
+			Message:      &responseMessage{Role: "assistant", Content: &content},
+			FinishReason: finishReason(choice.FinishReason),
+		})
+	}
+
+	if response.Usage != nil {
+		result.Usage = &usage{
+			PromptTokens:     *response.Usage.PromptTokens,
+			CompletionTokens: *response.Usage.CompletionTokens,
+			TotalTokens:      *response.Usage.TotalTokens,
+		}
+	}
+
+	writeJson(w, http.StatusOK, result)
+}
+
+// streamChatCompletions writes the completion chunks as server-sent events.
+func (s *Server) streamChatCompletions(w http.ResponseWriter, r *http.Request, options azopenai.ChatCompletionsOptions) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	response, err := s.openAiClient.GetChatCompletionsStream(r.Context(), options, nil)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	defer response.ChatCompletionsStream.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		chunk, err := response.ChatCompletionsStream.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			log.Printf("Streaming chat completions failed: %v\n", err)
+			writeEvent(w, &errorResponse{Error: errorDetail{Message: err.Error(), Type: "server_error"}})
+			flusher.Flush()
+			return
+		}
+
+		// Azure OpenAI sends the prompt filter results in a chunk without choices
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		result := &chatCompletionResponse{
+			Id:      stringValue(chunk.ID),
+			Object:  "chat.completion.chunk",
+			Created: unixTime(chunk.Created),
+			Model:   s.options.ChatCompletionModel,
+			Choices: []*chatCompletionChoice{},
+		}
+
+		for _, choice := range chunk.Choices {
+			delta := &responseMessage{}
+			if choice.Delta != nil {
+				if choice.Delta.Role != nil {
+					delta.Role = string(*choice.Delta.Role)
+				}
+
+				delta.Content = choice.Delta.Content
+			}
+
+			result.Choices = append(result.Choices, &chatCompletionChoice{
+				Index:        int(*choice.Index),
+				Delta:        delta,
+				FinishReason: finishReason(choice.FinishReason),
+			})
+		}
+
+		writeEvent(w, result)
+		flusher.Flush()
+	}
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+// chatMessages converts the request messages. The last user message is augmented with the retrieved documents and
+// rendered with the chat prompt template. The system message of the template is used when the request has none.
+func (s *Server) chatMessages(ctx context.Context, request *chatCompletionRequest) ([]azopenai.ChatRequestMessageClassification, error) {
+	if len(request.Messages) == 0 {
+		return nil, fmt.Errorf("%w: messages are required", errInvalidRequest)
+	}
+
+	lastMessage := request.Messages[len(request.Messages)-1]
+	if lastMessage.Role != "user" {
+		return nil, fmt.Errorf("%w: the last message must be a user message", errInvalidRequest)
+	}
+
+	messages := []azopenai.ChatRequestMessageClassification{}
+
+	if request.Messages[0].Role != "system" && s.options.SystemMessage != "" {
+		messages = append(messages, &azopenai.ChatRequestSystemMessage{
+			Content: azopenai.NewChatRequestSystemMessageContent(s.options.SystemMessage),
+		})
+	}
+
+	for i, message := range request.Messages {
+		text, imageUrls, err := parseContent(message.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidRequest, err)
+		}
+
+		switch message.Role {
+		case "system", "developer":
+			messages = append(messages, &azopenai.ChatRequestSystemMessage{
+				Content: azopenai.NewChatRequestSystemMessageContent(text),
+			})
+		case "assistant":
+			messages = append(messages, &azopenai.ChatRequestAssistantMessage{
+				Content: azopenai.NewChatRequestAssistantMessageContent(text),
+			})
+		case "user":
+			if i == len(request.Messages)-1 {
+				text, err = s.ragFlow.UserMessage(ctx, text)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			messages = append(messages, newUserMessage(text, imageUrls))
+		default:
+			return nil, fmt.Errorf("%w: unsupported message role '%s'", errInvalidRequest, message.Role)
+		}
+	}
+
+	return messages, nil
+}
+
+func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	if s.options.EmbeddingModel == "" {
+		writeError(w, http.StatusNotFound, errors.New("no embedding model deployment is configured"))
+		return
+	}
+
+	request := &embeddingsRequest{}
+	if err := decodeRequest(w, r, request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	inputs, err := parseEmbeddingsInput(request.Input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response, err := s.openAiClient.GetEmbeddings(r.Context(), azopenai.EmbeddingsOptions{
+		DeploymentName: &s.options.EmbeddingModel,
+		Input:          inputs,
+	}, nil)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	result := &embeddingsResponse{
+		Object: "list",
+		Data:   []*embeddingData{},
+		Model:  s.options.EmbeddingModel,
+		Usage: &usage{
+			PromptTokens: *response.Usage.PromptTokens,
+			TotalTokens:  *response.Usage.TotalTokens,
+		},
+	}
+
+	for _, item := range response.Data {
+		result.Data = append(result.Data, &embeddingData{
+			Object:    "embedding",
+			Index:     int(*item.Index),
+			Embedding: item.Embedding,
+		})
+	}
+
+	writeJson(w, http.StatusOK, result)
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	result := &modelsResponse{
+		Object: "list",
+		Data:   []*modelInfo{},
+	}
+
+	for _, deploymentName := range []string{s.options.ChatCompletionModel, s.options.EmbeddingModel} {
+		if deploymentName != "" {
+			result.Data = append(result.Data, &modelInfo{Id: deploymentName, Object: "model", OwnedBy: "azure"})
+		}
+	}
+
+	writeJson(w, http.StatusOK, result)
+}
+
+// withLogging writes a line with the method, path, status and duration of each request.
+func (s *Server) withLogging(next http.Handler) http.Handler {
+	if s.options.LogWriter == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		statusText := color.GreenString("%d", recorder.status)
+		if recorder.status >= http.StatusBadRequest {
+			statusText = color.RedString("%d", recorder.status)
+		}
+
+		fmt.Fprintf(
+			s.options.LogWriter,
+			"%s %s %s %s %s\n",
+			color.HiBlackString(startTime.Format(time.TimeOnly)),
+			r.Method,
+			r.URL.Path,
+			statusText,
+			color.HiBlackString("(%s)", time.Since(startTime).Round(time.Millisecond)),
+		)
+	})
+}
+
+// withCors allows browser based front ends from the configured origins to call the server.
+// The server calls Azure with the credential of the developer, so other web pages must not be able to call it.
+func (s *Server) withCors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && slices.Contains(s.options.CorsOrigins, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		}
+
+		w.Header().Add("Vary", "Origin")
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, value any) error {
+	body := http.MaxBytesReader(w, r.Body, maxRequestSize)
+	if err := json.NewDecoder(body).Decode(value); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return nil
+}
+
+func writeJson(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Failed writing response: %v\n", err)
+	}
+}
+
+func writeEvent(w io.Writer, value any) {
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Failed writing event: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(w, "data: %s\n\n", jsonBytes)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	errorType := "invalid_request_error"
+	if status >= http.StatusInternalServerError {
+		errorType = "server_error"
+	}
+
+	log.Printf("Request failed with status %d: %v\n", status, err)
+	writeJson(w, status, &errorResponse{Error: errorDetail{Message: err.Error(), Type: errorType}})
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
+
+func unixTime(value *time.Time) int64 {
+	if value == nil {
+		return time.Now().Unix()
+	}
+
+	return value.Unix()
+}
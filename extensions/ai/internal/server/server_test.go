@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Server(t *testing.T) {
+	t.Run("ParseContent", func(t *testing.T) {
+		text, imageUrls, err := parseContent(json.RawMessage(`"Hello"`))
+		require.NoError(t, err)
+		require.Equal(t, "Hello", text)
+		require.Empty(t, imageUrls)
+
+		text, imageUrls, err = parseContent(json.RawMessage(`[
+			{"type": "text", "text": "What is in"},
+			{"type": "text", "text": "this image?"},
+			{"type": "image_url", "image_url": {"url": "data:image/png;base64,AAAA"}}
+		]`))
+		require.NoError(t, err)
+		require.Equal(t, "What is in\nthis image?", text)
+		require.Equal(t, []string{"data:image/png;base64,AAAA"}, imageUrls)
+
+		_, _, err = parseContent(json.RawMessage(`[{"type": "input_audio"}]`))
+		require.Error(t, err)
+	})
+
+	t.Run("ParseEmbeddingsInput", func(t *testing.T) {
+		inputs, err := parseEmbeddingsInput(json.RawMessage(`"Hello"`))
+		require.NoError(t, err)
+		require.Equal(t, []string{"Hello"}, inputs)
+
+		inputs, err = parseEmbeddingsInput(json.RawMessage(`["Hello", "World"]`))
+		require.NoError(t, err)
+		require.Equal(t, []string{"Hello", "World"}, inputs)
+
+		_, err = parseEmbeddingsInput(json.RawMessage(`[]`))
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidChatRequest", func(t *testing.T) {
+		handler := New(nil, nil, Options{ChatCompletionModel: "gpt-4o"}).Handler()
+
+		for _, body := range []string{
+			`{"messages": []}`,
+			`{"messages": [{"role": "user", "content": "Hi"}, {"role": "assistant", "content": "Hello"}]}`,
+			`{"messages": [{"role": "tool", "content": "{}"}, {"role": "user", "content": "Hi"}]}`,
+			`not json`,
+		} {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+
+			require.Equal(t, http.StatusBadRequest, recorder.Code, body)
+
+			response := &errorResponse{}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+			require.Equal(t, "invalid_request_error", response.Error.Type)
+		}
+	})
+
+	t.Run("Models", func(t *testing.T) {
+		handler := New(nil, nil, Options{ChatCompletionModel: "gpt-4o"}).Handler()
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		response := &modelsResponse{}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+		require.Len(t, response.Data, 1)
+		require.Equal(t, "gpt-4o", response.Data[0].Id)
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(`{"input": "Hi"}`)))
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("Cors", func(t *testing.T) {
+		handler := New(nil, nil, Options{
+			ChatCompletionModel: "gpt-4o",
+			CorsOrigins:         []string{"http://localhost:3000"},
+		}).Handler()
+
+		preflight := func(origin string) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(http.MethodOptions, "/v1/chat/completions", nil)
+			request.Header.Set("Origin", origin)
+			request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			return recorder
+		}
+
+		recorder := preflight("http://localhost:3000")
+		require.Equal(t, http.StatusNoContent, recorder.Code)
+		require.Equal(t, "http://localhost:3000", recorder.Header().Get("Access-Control-Allow-Origin"))
+		require.NotEmpty(t, recorder.Header().Get("Access-Control-Allow-Headers"))
+
+		// Pages from other origins don't get CORS headers, so browsers block their requests
+		recorder = preflight("https://attacker.example")
+		require.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, recorder.Header().Get("Access-Control-Allow-Headers"))
+		require.Empty(t, recorder.Header().Get("Access-Control-Allow-Methods"))
+	})
+}
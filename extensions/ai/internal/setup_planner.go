@@ -0,0 +1,895 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/search/armsearch"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/fatih/color"
+	"github.com/sethvargo/go-retry"
+	"github.com/wbreza/azd-extensions/sdk/azure"
+	"github.com/wbreza/azd-extensions/sdk/common"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azd-extensions/sdk/ux"
+	"github.com/wbreza/azure-sdk-for-go/sdk/data/azsearch"
+)
+
+type SetupAction string
+
+const (
+	SetupActionCreate SetupAction = "create"
+	SetupActionUpdate SetupAction = "update"
+	SetupActionEnsure SetupAction = "ensure"
+	SetupActionNone   SetupAction = "none"
+)
+
+// SetupChange is a single step required to converge the AI project to the setup spec
+type SetupChange struct {
+	Action       SetupAction
+	ResourceType string
+	Name         string
+	Details      string
+
+	apply func(ctx context.Context) error
+	// unknown is set when the current state couldn't be read and is only known when the change is applied
+	unknown bool
+}
+
+// SetupPlan is the ordered list of changes required to converge the AI project to the setup spec
+type SetupPlan struct {
+	Changes []*SetupChange
+}
+
+// HasChanges returns true when the plan creates or updates any resources or the state of a resource is unknown.
+func (p *SetupPlan) HasChanges() bool {
+	for _, change := range p.Changes {
+		if change.Action == SetupActionCreate || change.Action == SetupActionUpdate || change.unknown {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Print writes the plan to the writer.
+func (p *SetupPlan) Print(writer io.Writer) {
+	for _, change := range p.Changes {
+		var symbol string
+		switch change.Action {
+		case SetupActionCreate:
+			symbol = color.GreenString("+ create")
+		case SetupActionUpdate:
+			symbol = color.YellowString("~ update")
+		case SetupActionEnsure:
+			symbol = color.CyanString("~ ensure")
+		default:
+			symbol = color.HiBlackString("= exists")
+		}
+
+		details := ""
+		if change.Details != "" {
+			details = color.HiBlackString(" (%s)", change.Details)
+		}
+
+		fmt.Fprintf(writer, "  %s %s %s%s\n", symbol, change.ResourceType, color.CyanString(change.Name), details)
+	}
+}
+
+// SetupPlanner compares the setup spec with the current state in Azure and converges the differences.
+type SetupPlanner struct {
+	azdContext *ext.Context
+	spec       *SetupSpec
+	config     *ExtensionConfig
+
+	credential       azcore.TokenCredential
+	armClientOptions *arm.ClientOptions
+	azClientOptions  *azcore.ClientOptions
+}
+
+func NewSetupPlanner(azdContext *ext.Context, spec *SetupSpec) (*SetupPlanner, error) {
+	credential, err := azdContext.Credential()
+	if err != nil {
+		return nil, err
+	}
+
+	var armClientOptions *arm.ClientOptions
+	var azClientOptions *azcore.ClientOptions
+
+	azdContext.Invoke(func(options1 *arm.ClientOptions, options2 *azcore.ClientOptions) error {
+		armClientOptions = options1
+		azClientOptions = options2
+		return nil
+	})
+
+	return &SetupPlanner{
+		azdContext:       azdContext,
+		spec:             spec,
+		credential:       credential,
+		armClientOptions: armClientOptions,
+		azClientOptions:  azClientOptions,
+		config: &ExtensionConfig{
+			Subscription:  spec.Subscription,
+			ResourceGroup: spec.ResourceGroup,
+		},
+	}, nil
+}
+
+// Config returns the extension config that results from applying the plan.
+func (p *SetupPlanner) Config() *ExtensionConfig {
+	return p.config
+}
+
+// Plan compares the resources from the spec with the existing resources and returns the changes required.
+func (p *SetupPlanner) Plan(ctx context.Context) (*SetupPlan, error) {
+	plan := &SetupPlan{}
+	spec := p.spec
+
+	if err := p.planResourceGroup(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	aiCreated, err := p.planAiService(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deployment := range spec.Ai.Deployments.all() {
+		if deployment == nil {
+			continue
+		}
+
+		if err := p.planModelDeployment(ctx, plan, deployment, aiCreated); err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.Ai.Deployments.ChatCompletion != nil {
+		p.config.Ai.Models.ChatCompletion = spec.Ai.Deployments.ChatCompletion.Name
+	}
+
+	if spec.Ai.Deployments.Embeddings != nil {
+		p.config.Ai.Models.Embeddings = spec.Ai.Deployments.Embeddings.Name
+	}
+
+	if spec.Ai.Deployments.Audio != nil {
+		p.config.Ai.Models.Audio = spec.Ai.Deployments.Audio.Name
+	}
+
+	if spec.Storage != nil {
+		if err := p.planStorage(ctx, plan); err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.Search != nil {
+		if err := p.planSearch(ctx, plan); err != nil {
+			return nil, err
+		}
+	}
+
+	if hasCreate(plan) && spec.Location == "" {
+		return nil, errors.New("location is required to create resources")
+	}
+
+	return plan, nil
+}
+
+// Apply runs the changes from the plan in order.
+func (p *SetupPlanner) Apply(ctx context.Context, plan *SetupPlan) error {
+	// Later changes depend on earlier ones so stop applying after the first failure
+	taskList := ux.NewTaskList(&ux.TaskListConfig{
+		FailurePolicy: ux.FailFast,
+	})
+
+	for _, change := range plan.Changes {
+		if change.apply == nil {
+			continue
+		}
+
+		taskList.AddTask(ux.TaskOptions{
+			Title: fmt.Sprintf("%s %s %s", actionTitle(change.Action), change.ResourceType, color.CyanString(change.Name)),
+			ActionWithContext: func(ctx context.Context, setProgress ux.SetProgressFunc) (ux.TaskState, error) {
+				if err := change.apply(ctx); err != nil {
+					return ux.Error, common.NewDetailedError(fmt.Sprintf("Failed to %s %s", change.Action, change.ResourceType), err)
+				}
+
+				return ux.Success, nil
+			},
+		})
+	}
+
+	return taskList.RunWithContext(ctx)
+}
+
+// hasCreate returns true when the plan creates any resources.
+func hasCreate(plan *SetupPlan) bool {
+	for _, change := range plan.Changes {
+		if change.Action == SetupActionCreate {
+			return true
+		}
+	}
+
+	return false
+}
+
+func actionTitle(action SetupAction) string {
+	switch action {
+	case SetupActionCreate:
+		return "Creating"
+	case SetupActionUpdate:
+		return "Updating"
+	case SetupActionEnsure:
+		return "Ensuring"
+	default:
+		return "Checking"
+	}
+}
+
+func (p *SetupPlanner) planResourceGroup(ctx context.Context, plan *SetupPlan) error {
+	return p.azdContext.Invoke(func(resourceService *azure.ResourceService) error {
+		_, err := resourceService.GetResourceGroup(ctx, p.spec.Subscription, p.spec.ResourceGroup)
+		if err == nil {
+			plan.Changes = append(plan.Changes, &SetupChange{
+				Action:       SetupActionNone,
+				ResourceType: "resource group",
+				Name:         p.spec.ResourceGroup,
+			})
+
+			return nil
+		}
+
+		if !isNotFound(err) {
+			return err
+		}
+
+		plan.Changes = append(plan.Changes, &SetupChange{
+			Action:       SetupActionCreate,
+			ResourceType: "resource group",
+			Name:         p.spec.ResourceGroup,
+			Details:      p.spec.Location,
+			apply: func(ctx context.Context) error {
+				_, err := resourceService.CreateOrUpdateResourceGroup(ctx, p.spec.Subscription, p.spec.ResourceGroup, p.spec.Location, nil)
+				return err
+			},
+		})
+
+		return nil
+	})
+}
+
+func (p *SetupPlanner) planAiService(ctx context.Context, plan *SetupPlan) (bool, error) {
+	accountsClient, err := armcognitiveservices.NewAccountsClient(p.spec.Subscription, p.credential, p.armClientOptions)
+	if err != nil {
+		return false, err
+	}
+
+	accountName := p.spec.Ai.Service
+	p.config.Ai.Service = accountName
+
+	ensureRoles := &SetupChange{
+		Action:       SetupActionEnsure,
+		ResourceType: "role assignments for",
+		Name:         accountName,
+		Details:      "Cognitive Services OpenAI Contributor",
+		apply: func(ctx context.Context) error {
+			account, err := accountsClient.Get(ctx, p.spec.ResourceGroup, accountName, nil)
+			if err != nil {
+				return err
+			}
+
+			return p.ensureCurrentUserRoles(ctx, *account.ID, azure.RoleCognitiveServicesOpenAIContributor)
+		},
+	}
+
+	existingAccount, err := accountsClient.Get(ctx, p.spec.ResourceGroup, accountName, nil)
+	if err == nil {
+		p.config.Ai.Endpoint = *existingAccount.Properties.Endpoint
+
+		drift := propertyDrift{}
+		if existingAccount.SKU != nil {
+			drift.compare("SKU", existingAccount.SKU.Name, p.spec.Ai.Sku)
+		}
+
+		if len(drift) == 0 {
+			plan.Changes = append(plan.Changes, &SetupChange{
+				Action:       SetupActionNone,
+				ResourceType: "Azure AI service",
+				Name:         accountName,
+			}, ensureRoles)
+
+			return false, nil
+		}
+
+		plan.Changes = append(plan.Changes, &SetupChange{
+			Action:       SetupActionUpdate,
+			ResourceType: "Azure AI service",
+			Name:         accountName,
+			Details:      drift.String(),
+			apply: func(ctx context.Context) error {
+				poller, err := accountsClient.BeginUpdate(ctx, p.spec.ResourceGroup, accountName, armcognitiveservices.Account{
+					SKU: &armcognitiveservices.SKU{
+						Name: &p.spec.Ai.Sku,
+					},
+				}, nil)
+				if err != nil {
+					return err
+				}
+
+				_, err = poller.PollUntilDone(ctx, nil)
+				return err
+			},
+		}, ensureRoles)
+
+		return false, nil
+	}
+
+	if !isNotFound(err) {
+		return false, err
+	}
+
+	plan.Changes = append(plan.Changes, &SetupChange{
+		Action:       SetupActionCreate,
+		ResourceType: "Azure AI service",
+		Name:         accountName,
+		Details:      fmt.Sprintf("SKU: %s", p.spec.Ai.Sku),
+		apply: func(ctx context.Context) error {
+			account := armcognitiveservices.Account{
+				Name: &accountName,
+				Identity: &armcognitiveservices.Identity{
+					Type: to.Ptr(armcognitiveservices.ResourceIdentityTypeSystemAssigned),
+				},
+				Location: &p.spec.Location,
+				Kind:     to.Ptr("OpenAI"),
+				SKU: &armcognitiveservices.SKU{
+					Name: &p.spec.Ai.Sku,
+				},
+				Properties: &armcognitiveservices.AccountProperties{
+					CustomSubDomainName: &accountName,
+					PublicNetworkAccess: to.Ptr(armcognitiveservices.PublicNetworkAccessEnabled),
+					DisableLocalAuth:    to.Ptr(false),
+				},
+			}
+
+			poller, err := accountsClient.BeginCreate(ctx, p.spec.ResourceGroup, accountName, account, nil)
+			if err != nil {
+				return err
+			}
+
+			accountResponse, err := poller.PollUntilDone(ctx, nil)
+			if err != nil {
+				return err
+			}
+
+			p.config.Ai.Endpoint = *accountResponse.Properties.Endpoint
+
+			return nil
+		},
+	}, ensureRoles)
+
+	return true, nil
+}
+
+func (p *SetupPlanner) planModelDeployment(
+	ctx context.Context,
+	plan *SetupPlan,
+	deploymentSpec *ModelDeploymentSpec,
+	aiCreated bool,
+) error {
+	deploymentsClient, err := armcognitiveservices.NewDeploymentsClient(p.spec.Subscription, p.credential, p.armClientOptions)
+	if err != nil {
+		return err
+	}
+
+	createOrUpdate := func(ctx context.Context) error {
+		deploymentModel := &armcognitiveservices.DeploymentModel{
+			Format: &deploymentSpec.Format,
+			Name:   &deploymentSpec.Model,
+		}
+
+		if deploymentSpec.Version != "" {
+			deploymentModel.Version = &deploymentSpec.Version
+		}
+
+		deployment := armcognitiveservices.Deployment{
+			Name: &deploymentSpec.Name,
+			SKU: &armcognitiveservices.SKU{
+				Name:     &deploymentSpec.Sku,
+				Capacity: &deploymentSpec.Capacity,
+			},
+			Properties: &armcognitiveservices.DeploymentProperties{
+				Model:                deploymentModel,
+				RaiPolicyName:        to.Ptr("Microsoft.DefaultV2"),
+				VersionUpgradeOption: to.Ptr(armcognitiveservices.DeploymentModelVersionUpgradeOptionOnceNewDefaultVersionAvailable),
+			},
+		}
+
+		poller, err := deploymentsClient.BeginCreateOrUpdate(ctx, p.spec.ResourceGroup, p.spec.Ai.Service, deploymentSpec.Name, deployment, nil)
+		if err != nil {
+			return err
+		}
+
+		_, err = poller.PollUntilDone(ctx, nil)
+		return err
+	}
+
+	if !aiCreated {
+		existingDeployment, err := deploymentsClient.Get(ctx, p.spec.ResourceGroup, p.spec.Ai.Service, deploymentSpec.Name, nil)
+		if err == nil {
+			drift := modelDeploymentDrift(deploymentSpec, &existingDeployment.Deployment)
+			if len(drift) == 0 {
+				plan.Changes = append(plan.Changes, &SetupChange{
+					Action:       SetupActionNone,
+					ResourceType: "model deployment",
+					Name:         deploymentSpec.Name,
+				})
+
+				return nil
+			}
+
+			plan.Changes = append(plan.Changes, &SetupChange{
+				Action:       SetupActionUpdate,
+				ResourceType: "model deployment",
+				Name:         deploymentSpec.Name,
+				Details:      drift.String(),
+				apply:        createOrUpdate,
+			})
+
+			return nil
+		}
+
+		if !isNotFound(err) {
+			return err
+		}
+	}
+
+	details := deploymentSpec.Model
+	if deploymentSpec.Version != "" {
+		details = fmt.Sprintf("%s, Version: %s", details, deploymentSpec.Version)
+	}
+
+	plan.Changes = append(plan.Changes, &SetupChange{
+		Action:       SetupActionCreate,
+		ResourceType: "model deployment",
+		Name:         deploymentSpec.Name,
+		Details:      fmt.Sprintf("Model: %s", details),
+		apply:        createOrUpdate,
+	})
+
+	return nil
+}
+
+// modelDeploymentDrift returns the properties of the existing model deployment that differ from the spec.
+// The model version is only compared when the spec pins a version.
+func modelDeploymentDrift(deploymentSpec *ModelDeploymentSpec, deployment *armcognitiveservices.Deployment) propertyDrift {
+	drift := propertyDrift{}
+
+	if deployment.SKU != nil {
+		drift.compare("SKU", deployment.SKU.Name, deploymentSpec.Sku)
+
+		if deployment.SKU.Capacity != nil && *deployment.SKU.Capacity != deploymentSpec.Capacity {
+			drift = append(drift, fmt.Sprintf("Capacity: %d -> %d", *deployment.SKU.Capacity, deploymentSpec.Capacity))
+		}
+	}
+
+	if deployment.Properties != nil && deployment.Properties.Model != nil {
+		model := deployment.Properties.Model
+		drift.compare("Model", model.Name, deploymentSpec.Model)
+		drift.compare("Format", model.Format, deploymentSpec.Format)
+
+		if deploymentSpec.Version != "" {
+			drift.compare("Version", model.Version, deploymentSpec.Version)
+		}
+	}
+
+	return drift
+}
+
+func (p *SetupPlanner) planStorage(ctx context.Context, plan *SetupPlan) error {
+	accountsClient, err := armstorage.NewAccountsClient(p.spec.Subscription, p.credential, p.armClientOptions)
+	if err != nil {
+		return err
+	}
+
+	containersClient, err := armstorage.NewBlobContainersClient(p.spec.Subscription, p.credential, p.armClientOptions)
+	if err != nil {
+		return err
+	}
+
+	accountName := p.spec.Storage.Account
+	containerName := p.spec.Storage.Container
+
+	p.config.Storage.Account = accountName
+	p.config.Storage.Container = containerName
+
+	ensureRoles := &SetupChange{
+		Action:       SetupActionEnsure,
+		ResourceType: "role assignments for",
+		Name:         accountName,
+		Details:      "Storage Blob Data Contributor",
+		apply: func(ctx context.Context) error {
+			account, err := accountsClient.GetProperties(ctx, p.spec.ResourceGroup, accountName, nil)
+			if err != nil {
+				return err
+			}
+
+			return p.ensureCurrentUserRoles(ctx, *account.ID, azure.RoleDefinitionStorageBlobDataContributor)
+		},
+	}
+
+	accountCreated := false
+
+	existingAccount, err := accountsClient.GetProperties(ctx, p.spec.ResourceGroup, accountName, nil)
+	if err == nil {
+		p.config.Storage.Endpoint = *existingAccount.Properties.PrimaryEndpoints.Blob
+
+		drift := propertyDrift{}
+		if existingAccount.SKU != nil {
+			drift.compare("SKU", (*string)(existingAccount.SKU.Name), p.spec.Storage.Sku)
+		}
+
+		if len(drift) == 0 {
+			plan.Changes = append(plan.Changes, &SetupChange{
+				Action:       SetupActionNone,
+				ResourceType: "storage account",
+				Name:         accountName,
+			}, ensureRoles)
+		} else {
+			plan.Changes = append(plan.Changes, &SetupChange{
+				Action:       SetupActionUpdate,
+				ResourceType: "storage account",
+				Name:         accountName,
+				Details:      drift.String(),
+				apply: func(ctx context.Context) error {
+					_, err := accountsClient.Update(ctx, p.spec.ResourceGroup, accountName, armstorage.AccountUpdateParameters{
+						SKU: &armstorage.SKU{
+							Name: to.Ptr(armstorage.SKUName(p.spec.Storage.Sku)),
+						},
+					}, nil)
+					return err
+				},
+			}, ensureRoles)
+		}
+	} else if isNotFound(err) {
+		accountCreated = true
+		plan.Changes = append(plan.Changes, &SetupChange{
+			Action:       SetupActionCreate,
+			ResourceType: "storage account",
+			Name:         accountName,
+			Details:      fmt.Sprintf("SKU: %s", p.spec.Storage.Sku),
+			apply: func(ctx context.Context) error {
+				accountCreateParams := armstorage.AccountCreateParameters{
+					Location: &p.spec.Location,
+					SKU: &armstorage.SKU{
+						Name: to.Ptr(armstorage.SKUName(p.spec.Storage.Sku)),
+					},
+					Kind: to.Ptr(armstorage.KindStorageV2),
+					Properties: &armstorage.AccountPropertiesCreateParameters{
+						AccessTier:            to.Ptr(armstorage.AccessTierHot),
+						AllowBlobPublicAccess: to.Ptr(true),
+						MinimumTLSVersion:     to.Ptr(armstorage.MinimumTLSVersionTLS12),
+						PublicNetworkAccess:   to.Ptr(armstorage.PublicNetworkAccessEnabled),
+					},
+				}
+
+				poller, err := accountsClient.BeginCreate(ctx, p.spec.ResourceGroup, accountName, accountCreateParams, nil)
+				if err != nil {
+					return err
+				}
+
+				createResponse, err := poller.PollUntilDone(ctx, nil)
+				if err != nil {
+					return err
+				}
+
+				p.config.Storage.Endpoint = *createResponse.Properties.PrimaryEndpoints.Blob
+
+				return nil
+			},
+		}, ensureRoles)
+	} else {
+		return err
+	}
+
+	if !accountCreated {
+		_, err := containersClient.Get(ctx, p.spec.ResourceGroup, accountName, containerName, nil)
+		if err == nil {
+			plan.Changes = append(plan.Changes, &SetupChange{
+				Action:       SetupActionNone,
+				ResourceType: "blob container",
+				Name:         containerName,
+			})
+
+			return nil
+		}
+
+		if !isNotFound(err) {
+			return err
+		}
+	}
+
+	plan.Changes = append(plan.Changes, &SetupChange{
+		Action:       SetupActionCreate,
+		ResourceType: "blob container",
+		Name:         containerName,
+		apply: func(ctx context.Context) error {
+			newContainer := armstorage.BlobContainer{
+				Name: &containerName,
+				ContainerProperties: &armstorage.ContainerProperties{
+					PublicAccess: to.Ptr(armstorage.PublicAccessNone),
+				},
+			}
+
+			_, err := containersClient.Create(ctx, p.spec.ResourceGroup, accountName, containerName, newContainer, nil)
+			return err
+		},
+	})
+
+	return nil
+}
+
+func (p *SetupPlanner) planSearch(ctx context.Context, plan *SetupPlan) error {
+	servicesClient, err := armsearch.NewServicesClient(p.spec.Subscription, p.credential, p.armClientOptions)
+	if err != nil {
+		return err
+	}
+
+	serviceName := p.spec.Search.Service
+	indexName := p.spec.Search.Index
+	endpoint := fmt.Sprintf("https://%s.search.windows.net", serviceName)
+
+	p.config.Search.Service = serviceName
+	p.config.Search.Endpoint = endpoint
+	p.config.Search.Index = indexName
+
+	indexesClient, err := azsearch.NewIndexesClient(endpoint, p.credential, p.azClientOptions)
+	if err != nil {
+		return err
+	}
+
+	ensureRoles := &SetupChange{
+		Action:       SetupActionEnsure,
+		ResourceType: "role assignments for",
+		Name:         serviceName,
+		Details:      "Search Index Data Contributor, Search Service Contributor",
+		apply: func(ctx context.Context) error {
+			searchService, err := servicesClient.Get(ctx, p.spec.ResourceGroup, serviceName, nil, nil)
+			if err != nil {
+				return err
+			}
+
+			if err := p.ensureCurrentUserRoles(
+				ctx,
+				*searchService.ID,
+				azure.RoleSearchIndexDataContributor,
+				azure.RoleSearchServiceContributor,
+			); err != nil {
+				return err
+			}
+
+			accountsClient, err := armcognitiveservices.NewAccountsClient(p.spec.Subscription, p.credential, p.armClientOptions)
+			if err != nil {
+				return err
+			}
+
+			aiAccount, err := accountsClient.Get(ctx, p.spec.ResourceGroup, p.spec.Ai.Service, nil)
+			if err != nil {
+				return err
+			}
+
+			if aiAccount.Identity == nil || aiAccount.Identity.PrincipalID == nil {
+				return nil
+			}
+
+			return p.azdContext.Invoke(func(rbacClient *azure.EntraIdService) error {
+				return rbacClient.EnsureRoleAssignment(
+					ctx,
+					p.spec.Subscription,
+					*searchService.ID,
+					*aiAccount.Identity.PrincipalID,
+					azure.RoleSearchIndexDataReader,
+					azure.RoleSearchServiceContributor,
+				)
+			})
+		},
+	}
+
+	serviceCreated := false
+
+	existingService, err := servicesClient.Get(ctx, p.spec.ResourceGroup, serviceName, nil, nil)
+	if err == nil {
+		// The pricing tier of a search service is fixed when the service is created
+		if existingService.SKU != nil && existingService.SKU.Name != nil &&
+			!strings.EqualFold(string(*existingService.SKU.Name), p.spec.Search.Sku) {
+			return &ext.ErrorWithSuggestion{
+				Err: fmt.Errorf(
+					"search service %s has SKU %s but the setup spec requires %s",
+					serviceName,
+					*existingService.SKU.Name,
+					p.spec.Search.Sku,
+				),
+				Suggestion: "The SKU of a search service can't be changed, update search.sku in the setup spec or use a different search service.",
+			}
+		}
+
+		plan.Changes = append(plan.Changes, &SetupChange{
+			Action:       SetupActionNone,
+			ResourceType: "Azure AI Search service",
+			Name:         serviceName,
+		}, ensureRoles)
+	} else if isNotFound(err) {
+		serviceCreated = true
+		plan.Changes = append(plan.Changes, &SetupChange{
+			Action:       SetupActionCreate,
+			ResourceType: "Azure AI Search service",
+			Name:         serviceName,
+			Details:      fmt.Sprintf("SKU: %s", p.spec.Search.Sku),
+			apply: func(ctx context.Context) error {
+				searchService := armsearch.Service{
+					Name:     &serviceName,
+					Location: &p.spec.Location,
+					Identity: &armsearch.Identity{
+						Type: to.Ptr(armsearch.IdentityTypeSystemAssigned),
+					},
+					SKU: &armsearch.SKU{
+						Name: to.Ptr(armsearch.SKUName(p.spec.Search.Sku)),
+					},
+					Properties: &armsearch.ServiceProperties{
+						PublicNetworkAccess: to.Ptr(armsearch.PublicNetworkAccessEnabled),
+						HostingMode:         to.Ptr(armsearch.HostingModeDefault),
+						ReplicaCount:        to.Ptr(int32(1)),
+						PartitionCount:      to.Ptr(int32(1)),
+						AuthOptions: &armsearch.DataPlaneAuthOptions{
+							AADOrAPIKey: &armsearch.DataPlaneAADOrAPIKeyAuthOption{
+								AADAuthFailureMode: to.Ptr(armsearch.AADAuthFailureModeHttp403),
+							},
+						},
+					},
+				}
+
+				poller, err := servicesClient.BeginCreateOrUpdate(ctx, p.spec.ResourceGroup, serviceName, searchService, nil, nil)
+				if err != nil {
+					return err
+				}
+
+				_, err = poller.PollUntilDone(ctx, nil)
+				return err
+			},
+		}, ensureRoles)
+	} else {
+		return err
+	}
+
+	createIndex := func(ctx context.Context) error {
+		_, err := indexesClient.CreateOrUpdate(
+			ctx,
+			indexName,
+			azsearch.Enum0ReturnRepresentation,
+			*defaultSearchIndex(indexName),
+			nil,
+			nil,
+		)
+		return err
+	}
+
+	if !serviceCreated {
+		_, err := indexesClient.Get(ctx, indexName, nil, nil)
+		if err == nil {
+			plan.Changes = append(plan.Changes, &SetupChange{
+				Action:       SetupActionNone,
+				ResourceType: "search index",
+				Name:         indexName,
+			})
+
+			return nil
+		}
+
+		// The data plane roles are only assigned when the plan is applied, until then the index can't be read.
+		// The index is checked again after the role assignments and created when it's missing.
+		if isForbidden(err) {
+			plan.Changes = append(plan.Changes, &SetupChange{
+				Action:       SetupActionEnsure,
+				ResourceType: "search index",
+				Name:         indexName,
+				Details:      "checked after the role assignments",
+				unknown:      true,
+				apply: func(ctx context.Context) error {
+					return retryForbidden(ctx, func(ctx context.Context) error {
+						_, err := indexesClient.Get(ctx, indexName, nil, nil)
+						if isNotFound(err) {
+							return createIndex(ctx)
+						}
+
+						return err
+					})
+				},
+			})
+
+			return nil
+		}
+
+		if !isNotFound(err) {
+			return err
+		}
+	}
+
+	plan.Changes = append(plan.Changes, &SetupChange{
+		Action:       SetupActionCreate,
+		ResourceType: "search index",
+		Name:         indexName,
+		apply: func(ctx context.Context) error {
+			return retryForbidden(ctx, createIndex)
+		},
+	})
+
+	return nil
+}
+
+func (p *SetupPlanner) ensureCurrentUserRoles(ctx context.Context, scope string, roles ...azure.RoleName) error {
+	principal, err := p.azdContext.Principal(ctx)
+	if err != nil {
+		return err
+	}
+
+	return p.azdContext.Invoke(func(rbacClient *azure.EntraIdService) error {
+		return rbacClient.EnsureRoleAssignment(ctx, p.spec.Subscription, scope, principal.Oid, roles...)
+	})
+}
+
+// isNotFound returns true when the error is an Azure 404 response.
+func isNotFound(err error) bool {
+	var responseErr *azcore.ResponseError
+	return errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound
+}
+
+// isForbidden returns true when the error is an Azure 403 response.
+func isForbidden(err error) bool {
+	var responseErr *azcore.ResponseError
+	return errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusForbidden
+}
+
+// retryForbidden retries the action while it fails with a 403 response.
+// New role assignments can take a few minutes before they're enforced by the data plane.
+func retryForbidden(ctx context.Context, action func(ctx context.Context) error) error {
+	return retry.Do(
+		ctx,
+		retry.WithMaxDuration(5*time.Minute, retry.NewConstant(10*time.Second)),
+		func(ctx context.Context) error {
+			err := action(ctx)
+			if isForbidden(err) {
+				return retry.RetryableError(err)
+			}
+
+			return err
+		},
+	)
+}
+
+// propertyDrift is the list of properties of an existing resource that differ from the setup spec.
+type propertyDrift []string
+
+// compare adds the property when the current value differs from the desired value. Values are compared case
+// insensitive since Azure doesn't preserve the casing of SKU and model names.
+func (d *propertyDrift) compare(name string, current *string, desired string) {
+	currentValue := ""
+	if current != nil {
+		currentValue = *current
+	}
+
+	if !strings.EqualFold(currentValue, desired) {
+		*d = append(*d, fmt.Sprintf("%s: %s -> %s", name, currentValue, desired))
+	}
+}
+
+func (d propertyDrift) String() string {
+	return strings.Join(d, ", ")
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ModelDeploymentDrift(t *testing.T) {
+	deploymentSpec := &ModelDeploymentSpec{
+		Name:     "gpt-4o",
+		Model:    "gpt-4o",
+		Version:  "2024-08-06",
+		Format:   "OpenAI",
+		Sku:      "Standard",
+		Capacity: 10,
+	}
+
+	newDeployment := func(sku string, capacity int32, version string) *armcognitiveservices.Deployment {
+		return &armcognitiveservices.Deployment{
+			SKU: &armcognitiveservices.SKU{
+				Name:     to.Ptr(sku),
+				Capacity: to.Ptr(capacity),
+			},
+			Properties: &armcognitiveservices.DeploymentProperties{
+				Model: &armcognitiveservices.DeploymentModel{
+					Name:    to.Ptr("gpt-4o"),
+					Format:  to.Ptr("OpenAI"),
+					Version: to.Ptr(version),
+				},
+			},
+		}
+	}
+
+	t.Run("NoDrift", func(t *testing.T) {
+		drift := modelDeploymentDrift(deploymentSpec, newDeployment("standard", 10, "2024-08-06"))
+		require.Empty(t, drift)
+	})
+
+	t.Run("Drift", func(t *testing.T) {
+		drift := modelDeploymentDrift(deploymentSpec, newDeployment("GlobalStandard", 20, "2024-05-13"))
+		require.Equal(t, "SKU: GlobalStandard -> Standard, Capacity: 20 -> 10, Version: 2024-05-13 -> 2024-08-06", drift.String())
+	})
+
+	t.Run("UnpinnedVersion", func(t *testing.T) {
+		unpinned := *deploymentSpec
+		unpinned.Version = ""
+
+		drift := modelDeploymentDrift(&unpinned, newDeployment("Standard", 10, "2024-05-13"))
+		require.Empty(t, drift)
+	})
+}
+
+func Test_SetupPlan_HasChanges(t *testing.T) {
+	plan := &SetupPlan{
+		Changes: []*SetupChange{
+			{Action: SetupActionNone, ResourceType: "Azure AI service", Name: "my-ai-service"},
+			{Action: SetupActionEnsure, ResourceType: "role assignments for", Name: "my-ai-service"},
+		},
+	}
+	require.False(t, plan.HasChanges())
+
+	plan.Changes = append(plan.Changes, &SetupChange{Action: SetupActionEnsure, ResourceType: "search index", Name: "documents", unknown: true})
+	require.True(t, plan.HasChanges())
+
+	plan.Changes = []*SetupChange{{Action: SetupActionUpdate, ResourceType: "model deployment", Name: "gpt-4o"}}
+	require.True(t, plan.HasChanges())
+}
@@ -0,0 +1,205 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/wbreza/azd-extensions/sdk/ext"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSetupSpecFile is the spec file used by `azd ai setup --no-prompt` when no file is specified.
+const DefaultSetupSpecFile = "ai.yaml"
+
+// SetupSpec is the declarative description of an AI project consumed by `azd ai setup --file`.
+type SetupSpec struct {
+	Subscription     string                `yaml:"subscription,omitempty"`
+	ResourceGroup    string                `yaml:"resourceGroup,omitempty"`
+	Location         string                `yaml:"location,omitempty"`
+	Ai               AiServiceSpec         `yaml:"ai"`
+	Storage          *StorageSpec          `yaml:"storage,omitempty"`
+	Search           *SearchSpec           `yaml:"search,omitempty"`
+	Documents        []*DocumentSourceSpec `yaml:"documents,omitempty"`
+	UpdateUpWorkflow bool                  `yaml:"updateUpWorkflow,omitempty"`
+}
+
+type AiServiceSpec struct {
+	Service     string               `yaml:"service"`
+	Sku         string               `yaml:"sku,omitempty"`
+	Deployments ModelDeploymentsSpec `yaml:"deployments,omitempty"`
+}
+
+type ModelDeploymentsSpec struct {
+	ChatCompletion *ModelDeploymentSpec `yaml:"chatCompletion,omitempty"`
+	Embeddings     *ModelDeploymentSpec `yaml:"embeddings,omitempty"`
+	Audio          *ModelDeploymentSpec `yaml:"audio,omitempty"`
+}
+
+type ModelDeploymentSpec struct {
+	Name     string `yaml:"name"`
+	Model    string `yaml:"model"`
+	Version  string `yaml:"version,omitempty"`
+	Format   string `yaml:"format,omitempty"`
+	Sku      string `yaml:"sku,omitempty"`
+	Capacity int32  `yaml:"capacity,omitempty"`
+}
+
+func (m *ModelDeploymentsSpec) all() []*ModelDeploymentSpec {
+	return []*ModelDeploymentSpec{m.ChatCompletion, m.Embeddings, m.Audio}
+}
+
+type StorageSpec struct {
+	Account   string `yaml:"account"`
+	Sku       string `yaml:"sku,omitempty"`
+	Container string `yaml:"container"`
+}
+
+type SearchSpec struct {
+	Service string `yaml:"service"`
+	Sku     string `yaml:"sku,omitempty"`
+	Index   string `yaml:"index"`
+}
+
+type DocumentSourceSpec struct {
+	Source  string `yaml:"source"`
+	Pattern string `yaml:"pattern,omitempty"`
+	Output  string `yaml:"output,omitempty"`
+}
+
+// LoadSetupSpec reads the setup spec from the specified file.
+// Environment variable references such as ${AZURE_LOCATION} are expanded before parsing.
+func LoadSetupSpec(path string) (*SetupSpec, error) {
+	specBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading setup spec %s: %w", path, err)
+	}
+
+	var spec SetupSpec
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(specBytes))), &spec); err != nil {
+		return nil, fmt.Errorf("failed parsing setup spec %s: %w", path, err)
+	}
+
+	return &spec, nil
+}
+
+// Resolve applies default values from the azure context and validates the spec.
+func (s *SetupSpec) Resolve(azureContext *ext.AzureContext) error {
+	if azureContext != nil {
+		if s.Subscription == "" {
+			s.Subscription = azureContext.Scope.SubscriptionId
+		}
+
+		if s.ResourceGroup == "" {
+			s.ResourceGroup = azureContext.Scope.ResourceGroup
+		}
+
+		if s.Location == "" {
+			s.Location = azureContext.Scope.Location
+		}
+	}
+
+	if s.Ai.Sku == "" {
+		s.Ai.Sku = "S0"
+	}
+
+	for _, deployment := range s.Ai.Deployments.all() {
+		if deployment == nil {
+			continue
+		}
+
+		if deployment.Name == "" {
+			deployment.Name = deployment.Model
+		}
+
+		if deployment.Format == "" {
+			deployment.Format = "OpenAI"
+		}
+
+		if deployment.Sku == "" {
+			deployment.Sku = "Standard"
+		}
+
+		if deployment.Capacity == 0 {
+			deployment.Capacity = 10
+		}
+	}
+
+	if s.Storage != nil && s.Storage.Sku == "" {
+		s.Storage.Sku = "Standard_LRS"
+	}
+
+	if s.Search != nil && s.Search.Sku == "" {
+		s.Search.Sku = "standard"
+	}
+
+	for _, document := range s.Documents {
+		if document.Pattern == "" {
+			document.Pattern = "*"
+		}
+
+		if document.Output == "" {
+			document.Output = "./embeddings"
+		}
+	}
+
+	return s.validate()
+}
+
+func (s *SetupSpec) validate() error {
+	errs := []error{}
+
+	if s.Subscription == "" {
+		errs = append(errs, errors.New("subscription is required"))
+	}
+
+	if s.ResourceGroup == "" {
+		errs = append(errs, errors.New("resourceGroup is required"))
+	}
+
+	if s.Ai.Service == "" {
+		errs = append(errs, errors.New("ai.service is required"))
+	}
+
+	// Deployments are checked in a fixed order so the errors are reported in the same order on every run
+	deployments := []struct {
+		key        string
+		deployment *ModelDeploymentSpec
+	}{
+		{"ai.deployments.chatCompletion", s.Ai.Deployments.ChatCompletion},
+		{"ai.deployments.embeddings", s.Ai.Deployments.Embeddings},
+		{"ai.deployments.audio", s.Ai.Deployments.Audio},
+	}
+
+	for _, entry := range deployments {
+		if entry.deployment != nil && entry.deployment.Model == "" {
+			errs = append(errs, fmt.Errorf("%s.model is required", entry.key))
+		}
+	}
+
+	if s.Storage != nil && (s.Storage.Account == "" || s.Storage.Container == "") {
+		errs = append(errs, errors.New("storage.account and storage.container are required"))
+	}
+
+	if s.Search != nil && (s.Search.Service == "" || s.Search.Index == "") {
+		errs = append(errs, errors.New("search.service and search.index are required"))
+	}
+
+	if len(s.Documents) > 0 {
+		if s.Storage == nil || s.Search == nil || s.Ai.Deployments.Embeddings == nil {
+			errs = append(errs, errors.New("documents require storage, search and an embeddings deployment"))
+		}
+
+		for i, document := range s.Documents {
+			if document.Source == "" {
+				errs = append(errs, fmt.Errorf("documents[%d].source is required", i))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid setup spec: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
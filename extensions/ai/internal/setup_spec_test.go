@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wbreza/azd-extensions/sdk/ext"
+)
+
+func Test_SetupSpec_Resolve(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "ai.yaml")
+	specYaml := `
+resourceGroup: ${TEST_AI_RESOURCE_GROUP}
+ai:
+  service: my-ai-service
+  deployments:
+    chatCompletion:
+      model: gpt-4o
+storage:
+  account: myaistorage
+  container: documents
+search:
+  service: my-ai-search
+  index: documents
+documents:
+  - source: ./data
+`
+	require.NoError(t, os.WriteFile(specPath, []byte(specYaml), 0600))
+	t.Setenv("TEST_AI_RESOURCE_GROUP", "rg-test")
+
+	spec, err := LoadSetupSpec(specPath)
+	require.NoError(t, err)
+	require.Equal(t, "rg-test", spec.ResourceGroup)
+
+	azureContext := ext.NewEmptyAzureContext()
+	azureContext.Scope.SubscriptionId = "00000000-0000-0000-0000-000000000000"
+	azureContext.Scope.Location = "eastus2"
+
+	// Documents require an embeddings deployment
+	require.Error(t, spec.Resolve(azureContext))
+
+	spec.Ai.Deployments.Embeddings = &ModelDeploymentSpec{Model: "text-embedding-ada-002"}
+	require.NoError(t, spec.Resolve(azureContext))
+
+	require.Equal(t, "00000000-0000-0000-0000-000000000000", spec.Subscription)
+	require.Equal(t, "eastus2", spec.Location)
+	require.Equal(t, "gpt-4o", spec.Ai.Deployments.ChatCompletion.Name)
+	require.Equal(t, "Standard", spec.Ai.Deployments.ChatCompletion.Sku)
+	require.Equal(t, "*", spec.Documents[0].Pattern)
+	require.Equal(t, "./embeddings", spec.Documents[0].Output)
+}
+
+func Test_SetupSpec_Validate(t *testing.T) {
+	spec := &SetupSpec{
+		Subscription:  "00000000-0000-0000-0000-000000000000",
+		ResourceGroup: "rg-test",
+		Ai: AiServiceSpec{
+			Service: "my-ai-service",
+			Deployments: ModelDeploymentsSpec{
+				ChatCompletion: &ModelDeploymentSpec{},
+				Embeddings:     &ModelDeploymentSpec{},
+				Audio:          &ModelDeploymentSpec{},
+			},
+		},
+	}
+
+	// The errors are reported in the same order on every run
+	for i := 0; i < 10; i++ {
+		err := spec.validate()
+		require.EqualError(
+			t,
+			err,
+			"invalid setup spec: ai.deployments.chatCompletion.model is required\n"+
+				"ai.deployments.embeddings.model is required\n"+
+				"ai.deployments.audio.model is required",
+		)
+	}
+}
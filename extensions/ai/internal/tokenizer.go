@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Names of the BPE encodings used by the OpenAI models.
+const (
+	Cl100kBaseEncoding = "cl100k_base"
+	O200kBaseEncoding  = "o200k_base"
+)
+
+// defaultEncoding is used for models without a known encoding.
+const defaultEncoding = Cl100kBaseEncoding
+
+// modelEncodings are the BPE encodings of model families.
+// Entries are matched by exact model name first and then by the longest model name prefix.
+var modelEncodings = map[string]string{
+	"gpt-35-turbo":           Cl100kBaseEncoding,
+	"gpt-4":                  Cl100kBaseEncoding,
+	"gpt-4o":                 O200kBaseEncoding,
+	"gpt-4.1":                O200kBaseEncoding,
+	"gpt-4.5":                O200kBaseEncoding,
+	"gpt-5":                  O200kBaseEncoding,
+	"o1":                     O200kBaseEncoding,
+	"o3":                     O200kBaseEncoding,
+	"o4-mini":                O200kBaseEncoding,
+	"text-embedding-ada-002": Cl100kBaseEncoding,
+	"text-embedding-3-small": Cl100kBaseEncoding,
+	"text-embedding-3-large": Cl100kBaseEncoding,
+}
+
+var (
+	encodings      = map[string]*tiktoken.Tiktoken{}
+	encodingsMutex sync.Mutex
+)
+
+func init() {
+	// The encodings are embedded in the binary instead of downloaded on first use
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+}
+
+// Tokenizer counts tokens with the BPE encoding of a model.
+type Tokenizer struct {
+	encodingName string
+	encoding     *tiktoken.Tiktoken
+}
+
+// NewTokenizer returns the tokenizer for the model, models without a known encoding use cl100k_base.
+// Panics when the embedded encoding can't be loaded.
+func NewTokenizer(modelName string) *Tokenizer {
+	encodingName := lookupEncoding(strings.ToLower(modelName))
+
+	encodingsMutex.Lock()
+	defer encodingsMutex.Unlock()
+
+	encoding, has := encodings[encodingName]
+	if !has {
+		var err error
+		encoding, err = tiktoken.GetEncoding(encodingName)
+		if err != nil {
+			panic(fmt.Sprintf("failed loading the %s encoding: %v", encodingName, err))
+		}
+
+		encodings[encodingName] = encoding
+	}
+
+	return &Tokenizer{
+		encodingName: encodingName,
+		encoding:     encoding,
+	}
+}
+
+// Encoding returns the name of the BPE encoding.
+func (t *Tokenizer) Encoding() string {
+	return t.encodingName
+}
+
+// CountTokens returns the number of tokens of the text. Special tokens in the text are counted as plain text.
+// Messages sent to chat models add a few tokens of overhead that aren't included.
+func (t *Tokenizer) CountTokens(text string) int {
+	return len(t.encoding.Encode(text, nil, nil))
+}
+
+func lookupEncoding(modelName string) string {
+	if encoding, has := modelEncodings[modelName]; has {
+		return encoding
+	}
+
+	bestPrefix := ""
+	for prefix := range modelEncodings {
+		if strings.HasPrefix(modelName, prefix+"-") && len(prefix) > len(bestPrefix) {
+			bestPrefix = prefix
+		}
+	}
+
+	if bestPrefix != "" {
+		return modelEncodings[bestPrefix]
+	}
+
+	return defaultEncoding
+}
+
+// DeploymentTokenizers are the tokenizers of the models of deployments keyed by deployment name.
+type DeploymentTokenizers map[string]*Tokenizer
+
+// Get returns the tokenizer of the deployment or the default tokenizer when the model of the deployment isn't known.
+func (t DeploymentTokenizers) Get(deploymentName string) *Tokenizer {
+	if tokenizer, has := t[deploymentName]; has {
+		return tokenizer
+	}
+
+	return NewTokenizer("")
+}
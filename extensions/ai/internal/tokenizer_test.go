@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Tokenizer(t *testing.T) {
+	t.Run("Encodings", func(t *testing.T) {
+		require.Equal(t, O200kBaseEncoding, NewTokenizer("gpt-4o").Encoding())
+		require.Equal(t, O200kBaseEncoding, NewTokenizer("gpt-4o-mini").Encoding())
+		require.Equal(t, O200kBaseEncoding, NewTokenizer("GPT-4.1").Encoding())
+		require.Equal(t, O200kBaseEncoding, NewTokenizer("o1-mini").Encoding())
+		require.Equal(t, Cl100kBaseEncoding, NewTokenizer("gpt-4").Encoding())
+		require.Equal(t, Cl100kBaseEncoding, NewTokenizer("gpt-35-turbo-16k").Encoding())
+		require.Equal(t, Cl100kBaseEncoding, NewTokenizer("text-embedding-3-small").Encoding())
+		require.Equal(t, Cl100kBaseEncoding, NewTokenizer("custom-model").Encoding())
+	})
+
+	// Token counts of the reference tiktoken encodings
+	t.Run("Cl100kBase", func(t *testing.T) {
+		tokenizer := NewTokenizer("gpt-4")
+
+		require.Equal(t, 0, tokenizer.CountTokens(""))
+		require.Equal(t, 4, tokenizer.CountTokens("Hello, world!"))
+		require.Equal(t, 6, tokenizer.CountTokens("tiktoken is great!"))
+		require.Equal(t, 6, tokenizer.CountTokens("antidisestablishmentarianism"))
+		require.Equal(t, 7, tokenizer.CountTokens("2 + 2 = 4"))
+		require.Equal(t, 9, tokenizer.CountTokens("お誕生日おめでとう"))
+	})
+
+	t.Run("O200kBase", func(t *testing.T) {
+		tokenizer := NewTokenizer("gpt-4o")
+
+		require.Equal(t, 0, tokenizer.CountTokens(""))
+		require.Equal(t, 4, tokenizer.CountTokens("Hello, world!"))
+		require.Equal(t, 6, tokenizer.CountTokens("tiktoken is great!"))
+		require.Equal(t, 6, tokenizer.CountTokens("antidisestablishmentarianism"))
+		require.Equal(t, 7, tokenizer.CountTokens("2 + 2 = 4"))
+		require.Equal(t, 8, tokenizer.CountTokens("お誕生日おめでとう"))
+	})
+
+	t.Run("SpecialTokens", func(t *testing.T) {
+		// Special tokens in user text are counted as plain text instead of failing
+		require.Equal(t, 7, NewTokenizer("gpt-4o").CountTokens("<|endoftext|>"))
+	})
+
+	t.Run("DeploymentTokenizers", func(t *testing.T) {
+		tokenizers := DeploymentTokenizers{"chat": NewTokenizer("gpt-4o")}
+
+		require.Equal(t, O200kBaseEncoding, tokenizers.Get("chat").Encoding())
+		require.Equal(t, Cl100kBaseEncoding, tokenizers.Get("embeddings").Encoding())
+	})
+}
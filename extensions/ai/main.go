@@ -9,6 +9,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/wbreza/azd-extensions/extensions/ai/internal/cmd"
 	"github.com/wbreza/azd-extensions/sdk/ext"
+	"github.com/wbreza/azd-extensions/sdk/ext/output"
 )
 
 func main() {
@@ -18,12 +19,13 @@ func main() {
 
 	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		var errWithSuggestion *ext.ErrorWithSuggestion
+		writer := output.MessageWriter(ctx)
 
 		if ok := errors.As(err, &errWithSuggestion); ok {
-			color.Red("Error: %v", errWithSuggestion.Err)
-			fmt.Printf("%s: %s\n", color.YellowString("Suggestion:"), errWithSuggestion.Suggestion)
+			fmt.Fprintln(writer, color.RedString("Error: %v", errWithSuggestion.Err))
+			fmt.Fprintf(writer, "%s: %s\n", color.YellowString("Suggestion:"), errWithSuggestion.Suggestion)
 		} else {
-			color.Red("Error: %v", err)
+			fmt.Fprintln(writer, color.RedString("Error: %v", err))
 		}
 
 		os.Exit(1)
@@ -75,6 +75,57 @@ func (eis *EntraIdService) EnsureRoleAssignment(ctx context.Context, subscriptio
 	return nil
 }
 
+// HasRoleAssignment returns true when the principal has been assigned the role at or above the specified scope.
+func (eis *EntraIdService) HasRoleAssignment(ctx context.Context, subscriptionId string, scope string, principalId string, roleName RoleName) (bool, error) {
+	authClient, err := armauthorization.NewClientFactory(subscriptionId, eis.credential, eis.armClientOptions)
+	if err != nil {
+		return false, err
+	}
+
+	roleDefinitionId, err := eis.getRoleDefinitionId(ctx, subscriptionId, roleName)
+	if err != nil {
+		return false, err
+	}
+
+	rbacClient := authClient.NewRoleAssignmentsClient()
+	pager := rbacClient.NewListForScopePager(scope, &armauthorization.RoleAssignmentsClientListForScopeOptions{
+		Filter: to.Ptr(fmt.Sprintf("assignedTo('%s')", principalId)),
+	})
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		for _, roleAssignment := range page.Value {
+			if roleAssignment.Properties == nil ||
+				roleAssignment.Properties.RoleDefinitionID == nil ||
+				roleAssignment.Properties.Scope == nil {
+				continue
+			}
+
+			// Role definition ids are returned relative to the scope of the assignment so only the trailing guid is compared.
+			if !strings.EqualFold(lastSegment(*roleAssignment.Properties.RoleDefinitionID), lastSegment(roleDefinitionId)) {
+				continue
+			}
+
+			// The filter also returns assignments on child resources which don't grant access to the requested scope.
+			assignmentScope := strings.TrimSuffix(strings.ToLower(*roleAssignment.Properties.Scope), "/")
+			requestedScope := strings.TrimSuffix(strings.ToLower(scope), "/")
+			if assignmentScope == "" || requestedScope == assignmentScope || strings.HasPrefix(requestedScope, assignmentScope+"/") {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func lastSegment(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
 func (eis *EntraIdService) getRoleDefinitionId(ctx context.Context, subscriptionId string, roleName RoleName) (string, error) {
 	authClient, err := armauthorization.NewClientFactory(subscriptionId, eis.credential, eis.armClientOptions)
 	if err != nil {
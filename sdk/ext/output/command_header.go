@@ -12,7 +12,12 @@ type CommandHeader struct {
 	Description string
 }
 
+// Print writes the header when the output is interactive.
 func (ch CommandHeader) Print() {
+	if !IsInteractive() {
+		return
+	}
+
 	color.White(ux.BoldString(ch.Title))
 	if ch.Description != "" {
 		color.HiBlack(ch.Description)
@@ -0,0 +1,249 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/fatih/color"
+	"github.com/wbreza/azd-extensions/sdk/ux"
+)
+
+// Format is the format used to write the results of a command.
+type Format string
+
+const (
+	// NoneFormat writes the human readable output of the command.
+	NoneFormat Format = "none"
+	// JsonFormat writes the results of the command as JSON.
+	JsonFormat Format = "json"
+	// TableFormat writes the results of the command as a table.
+	TableFormat Format = "table"
+)
+
+// SupportedFormats are the formats accepted by the `--output` flag.
+var SupportedFormats = []Format{NoneFormat, JsonFormat, TableFormat}
+
+var ErrUnsupportedFormat = errors.New("unsupported output format")
+
+var currentFormat = NoneFormat
+
+type writersKey struct{}
+
+// writers are the destinations of the output of a command.
+type writers struct {
+	result  io.Writer
+	message io.Writer
+}
+
+// WithWriters returns a context with the writers of a command, usually cmd.OutOrStdout() and cmd.ErrOrStderr().
+// Results are written to stdout, messages are written to stdout or to stderr for structured formats.
+func WithWriters(ctx context.Context, stdout io.Writer, stderr io.Writer) context.Context {
+	return context.WithValue(ctx, writersKey{}, &writers{result: stdout, message: stderr})
+}
+
+// ResultWriter returns the writer of the results of the command (default: os.Stdout).
+func ResultWriter(ctx context.Context) io.Writer {
+	if writers, has := ctx.Value(writersKey{}).(*writers); has {
+		return writers.result
+	}
+
+	return os.Stdout
+}
+
+// MessageWriter returns the writer of the human readable messages of the command. Messages are written next to the
+// results unless the format is structured, then they're written to stderr so stdout only contains the results.
+func MessageWriter(ctx context.Context) io.Writer {
+	if !IsStructured() {
+		return ResultWriter(ctx)
+	}
+
+	if writers, has := ctx.Value(writersKey{}).(*writers); has {
+		return writers.message
+	}
+
+	return os.Stderr
+}
+
+// ParseFormat parses the value of the `--output` flag.
+func ParseFormat(value string) (Format, error) {
+	if value == "" {
+		return NoneFormat, nil
+	}
+
+	format := Format(strings.ToLower(value))
+	if !slices.Contains(SupportedFormats, format) {
+		return "", fmt.Errorf("%w '%s', supported formats are %s", ErrUnsupportedFormat, value, joinFormats())
+	}
+
+	return format, nil
+}
+
+// SetFormat configures the process for the output format.
+//
+// Structured formats (json & table) reserve stdout for the results written with Print. Prompts are written to
+// stderr and commands write their messages to MessageWriter. Headers, spinners, task lists, progress bars and
+// colors are disabled for structured formats. Headers and colors are also disabled when the ux visuals aren't
+// interactive, for example when stdout isn't a terminal or on CI, where spinners, task lists and progress bars
+// write append-only lines instead.
+func SetFormat(format Format) {
+	currentFormat = format
+
+	if IsStructured() {
+		ux.DefaultFormWriter = os.Stderr
+		ux.DefaultPromptOptions.Writer = os.Stderr
+		ux.DefaultConfirmOptions.Writer = os.Stderr
+		ux.DefaultSelectOptions.Writer = os.Stderr
+		ux.DefaultMultiSelectOptions.Writer = os.Stderr
+
+		ux.DefaultSpinnerOptions.Writer = io.Discard
+		ux.DefaultTaskListConfig.Writer = io.Discard
+		ux.DefaultProgressBarOptions.Writer = io.Discard
+		ux.DefaultProgressGroupOptions.Writer = io.Discard
+	}
+
+	if !IsInteractive() {
+		color.NoColor = IsStructured() || os.Getenv("FORCE_COLOR") != "1" || os.Getenv("NO_COLOR") != ""
+	}
+}
+
+// CurrentFormat returns the output format of the current command.
+func CurrentFormat() Format {
+	return currentFormat
+}
+
+// IsStructured returns true when the results of the command are written in a machine readable format.
+func IsStructured() bool {
+	return currentFormat == JsonFormat || currentFormat == TableFormat
+}
+
+// IsInteractive returns true when decorations such as headers, spinners and colors are displayed.
+func IsInteractive() bool {
+	return !IsStructured() && ux.IsInteractive()
+}
+
+// Column is a column of a table. The value is a text/template evaluated against each row.
+type Column struct {
+	Heading       string
+	ValueTemplate string
+}
+
+// TableOptions configure how results are written with the table format.
+type TableOptions struct {
+	Columns []Column
+}
+
+// Print writes the results in the current output format to the result writer of the context.
+// Results are only written for structured formats, the human readable output is written by the command.
+func Print(ctx context.Context, value any, options *TableOptions) error {
+	switch currentFormat {
+	case JsonFormat:
+		return WriteJson(ResultWriter(ctx), value)
+	case TableFormat:
+		return WriteTable(ResultWriter(ctx), value, options)
+	default:
+		return nil
+	}
+}
+
+// WriteJson writes the value as indented JSON.
+func WriteJson(writer io.Writer, value any) error {
+	jsonBytes, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(writer, string(jsonBytes))
+	return err
+}
+
+// WriteTable writes the value as a ux.Table with a row for each element of a slice, any other value is written as
+// ux.Properties with a line for each column. Commands use WriteTable for their human readable results as well so the
+// table format and the default output are rendered the same way.
+func WriteTable(writer io.Writer, value any, options *TableOptions) error {
+	if options == nil || len(options.Columns) == 0 {
+		return errors.New("table format requires at least one column")
+	}
+
+	templates := make([]*template.Template, len(options.Columns))
+	for i, column := range options.Columns {
+		columnTemplate, err := template.New(column.Heading).Option("missingkey=zero").Parse(column.ValueTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid template for column '%s': %w", column.Heading, err)
+		}
+
+		templates[i] = columnTemplate
+	}
+
+	rowValues := func(row any) ([]string, error) {
+		values := make([]string, len(templates))
+
+		for i, columnTemplate := range templates {
+			var buffer bytes.Buffer
+			if err := columnTemplate.Execute(&buffer, row); err != nil {
+				return nil, err
+			}
+
+			values[i] = strings.ReplaceAll(buffer.String(), "<no value>", "")
+		}
+
+		return values, nil
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() != reflect.Slice && reflectValue.Kind() != reflect.Array {
+		properties := ux.NewProperties(&ux.PropertiesOptions{Writer: writer})
+		if value == nil {
+			return properties.Print()
+		}
+
+		values, err := rowValues(value)
+		if err != nil {
+			return err
+		}
+
+		for i, column := range options.Columns {
+			properties.Add(column.Heading, values[i])
+		}
+
+		return properties.Print()
+	}
+
+	columns := make([]ux.TableColumn, len(options.Columns))
+	for i, column := range options.Columns {
+		columns[i] = ux.TableColumn{Heading: column.Heading}
+	}
+
+	table := ux.NewTable(&ux.TableOptions{
+		Writer:  writer,
+		Columns: columns,
+	})
+
+	for i := 0; i < reflectValue.Len(); i++ {
+		values, err := rowValues(reflectValue.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+
+		table.AddRow(values...)
+	}
+
+	return table.Print()
+}
+
+func joinFormats() string {
+	formats := make([]string, len(SupportedFormats))
+	for i, format := range SupportedFormats {
+		formats[i] = string(format)
+	}
+
+	return strings.Join(formats, ", ")
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wbreza/azd-extensions/sdk/ux"
+)
+
+func Test_WriteTable(t *testing.T) {
+	type deployment struct {
+		Name    string
+		Version string
+	}
+
+	options := &TableOptions{
+		Columns: []Column{
+			{Heading: "Name", ValueTemplate: "{{.Name}}"},
+			{Heading: "Version", ValueTemplate: "{{.Version}}"},
+		},
+	}
+
+	t.Run("Slice", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		require.NoError(t, WriteTable(writer, []*deployment{
+			{Name: "gpt-4o", Version: "2024-08-06"},
+			{Name: "text-embedding-3-small", Version: "1"},
+		}, options))
+
+		require.Equal(t, ""+
+			"Name                     Version\n"+
+			"──────────────────────   ──────────\n"+
+			"gpt-4o                   2024-08-06\n"+
+			"text-embedding-3-small   1\n",
+			ux.StripEscapeSequences(writer.String()),
+		)
+	})
+
+	t.Run("SingleValue", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		require.NoError(t, WriteTable(writer, &deployment{Name: "gpt-4o", Version: "2024-08-06"}, options))
+
+		require.Equal(t, ""+
+			"Name:    gpt-4o\n"+
+			"Version: 2024-08-06\n",
+			ux.StripEscapeSequences(writer.String()),
+		)
+	})
+
+	t.Run("NoColumns", func(t *testing.T) {
+		require.Error(t, WriteTable(&bytes.Buffer{}, []string{}, &TableOptions{}))
+	})
+}
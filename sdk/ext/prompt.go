@@ -15,6 +15,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/fatih/color"
 	"github.com/wbreza/azd-extensions/sdk/azure"
+	"github.com/wbreza/azd-extensions/sdk/core/config"
 	"github.com/wbreza/azd-extensions/sdk/ux"
 )
 
@@ -55,6 +56,34 @@ type CustomResourceOptions[T any] struct {
 	CreateResource func(ctx context.Context) (*T, error)
 }
 
+// MultiResourceOptions contains options for prompting the user to select multiple resources.
+type MultiResourceOptions struct {
+	// ResourceType is the type of resource to select.
+	ResourceType *azure.ResourceType
+	// Kinds is a list of resource kinds to filter by.
+	Kinds []string
+	// ResourceTypeDisplayName is the display name of the resource type.
+	ResourceTypeDisplayName string
+	// SelectorOptions contains options for the resource selector.
+	SelectorOptions *MultiSelectOptions
+	// Selected is a function that determines if a resource is selected by default
+	Selected func(resource *azure.ResourceExtended) bool
+}
+
+// CustomResourcesOptions contains options for prompting the user to select multiple custom resources.
+type CustomResourcesOptions[T any] struct {
+	// SelectorOptions contains options for the resource selector.
+	SelectorOptions *MultiSelectOptions
+	// LoadData is a function that loads the resource data.
+	LoadData func(ctx context.Context) ([]*T, error)
+	// DisplayResource is a function that displays the resource.
+	DisplayResource func(resource *T) (string, error)
+	// SortResource is a function that sorts the resources.
+	SortResource func(a *T, b *T) int
+	// Selected is a function that determines if a resource is selected by default
+	Selected func(resource *T) bool
+}
+
 // ResourceGroupOptions contains options for prompting the user to select a resource group.
 type ResourceGroupOptions struct {
 	// SelectorOptions contains options for the resource group selector.
@@ -83,6 +112,36 @@ type SelectOptions struct {
 	DisplayCount int
 }
 
+// MultiSelectOptions contains options for prompting the user to select multiple resources.
+type MultiSelectOptions struct {
+	// Message is the message to display to the user.
+	Message string
+	// HelpMessage is the help message to display to the user.
+	HelpMessage string
+	// LoadingMessage is the loading message to display to the user.
+	LoadingMessage string
+	// DisplayNumbers specifies whether to display numbers next to the choices.
+	DisplayNumbers *bool
+	// DisplayCount is the number of choices to display at a time.
+	DisplayCount int
+	// MinSelections is the minimum number of resources that must be selected.
+	MinSelections int
+	// MaxSelections is the maximum number of resources that can be selected, 0 for no limit.
+	MaxSelections int
+}
+
+// SecretOptions contains options for prompting the user for a secret such as an API key or connection string.
+type SecretOptions struct {
+	// Message is the message to display to the user.
+	Message string
+	// HelpMessage is the help message to display to the user.
+	HelpMessage string
+	// Required specifies whether an empty secret is rejected.
+	Required *bool
+	// Input is the source of key events, for example scripted input in tests (default: the console).
+	Input ux.InputSource
+}
+
 type ResourceSelection[T any] struct {
 	Resource *T
 	Exists   bool
@@ -412,69 +471,139 @@ func PromptSubscriptionResource(ctx context.Context, azureContext *AzureContext,
 	resource, err := PromptCustomResource(ctx, CustomResourceOptions[azure.ResourceExtended]{
 		SelectorOptions: mergedSelectorOptions,
 		LoadData: func(ctx context.Context) ([]*azure.ResourceExtended, error) {
-			var resourceListOptions *armresources.ClientListOptions
-			if options.ResourceType != nil {
-				resourceListOptions = &armresources.ClientListOptions{
-					Filter: to.Ptr(fmt.Sprintf("resourceType eq '%s'", string(*options.ResourceType))),
-				}
-			}
+			return listSubscriptionResources(ctx, azureContext, options.ResourceType, options.Kinds)
+		},
+		DisplayResource: displaySubscriptionResource,
+		Selected:        options.Selected,
+		CreateResource:  options.CreateResource,
+	})
 
-			azdContext, err := CurrentContext(ctx)
-			if err != nil {
-				return nil, err
-			}
+	if err != nil {
+		return nil, err
+	}
 
-			credential, err := azdContext.Credential()
-			if err != nil {
-				return nil, err
-			}
+	if err := azureContext.Resources.Add(resource.Id); err != nil {
+		return nil, err
+	}
 
-			resourceService := azure.NewResourceService(credential, nil)
-			resourceList, err := resourceService.ListSubscriptionResources(ctx, azureContext.Scope.SubscriptionId, resourceListOptions)
-			if err != nil {
-				return nil, err
-			}
+	return resource, nil
+}
 
-			filteredResources := []*azure.ResourceExtended{}
-			hasKindFilter := len(options.Kinds) > 0
+// PromptSubscriptionResources prompts the user to select multiple Azure subscription resources.
+func PromptSubscriptionResources(ctx context.Context, azureContext *AzureContext, options MultiResourceOptions) ([]*azure.ResourceExtended, error) {
+	if azureContext == nil {
+		azureContext = NewEmptyAzureContext()
+	}
 
-			for _, resource := range resourceList {
-				if !hasKindFilter || slices.Contains(options.Kinds, resource.Kind) {
-					filteredResources = append(filteredResources, resource)
-				}
-			}
+	if err := azureContext.EnsureSubscription(ctx); err != nil {
+		return nil, err
+	}
 
-			if len(filteredResources) == 0 {
-				if options.ResourceType == nil {
-					return nil, ErrNoResourcesFound
-				}
+	mergedSelectorOptions := &MultiSelectOptions{}
 
-				return nil, fmt.Errorf("no resources found with type '%v'", *options.ResourceType)
-			}
+	if options.SelectorOptions == nil {
+		options.SelectorOptions = &MultiSelectOptions{}
+	}
 
-			return filteredResources, nil
-		},
-		DisplayResource: func(resource *azure.ResourceExtended) (string, error) {
-			parsedResource, err := arm.ParseResourceID(resource.Id)
-			if err != nil {
-				return "", fmt.Errorf("parsing resource id: %w", err)
-			}
+	resourceName := options.ResourceTypeDisplayName
+
+	if resourceName == "" && options.ResourceType != nil {
+		resourceName = string(*options.ResourceType)
+	}
+
+	if resourceName == "" {
+		resourceName = "resource"
+	}
 
-			return fmt.Sprintf("%s %s", parsedResource.Name, color.HiBlackString("(%s)", parsedResource.ResourceGroupName)), nil
+	defaultSelectorOptions := &MultiSelectOptions{
+		Message:        fmt.Sprintf("Select %s resources", resourceName),
+		LoadingMessage: fmt.Sprintf("Loading %s resources...", resourceName),
+		HelpMessage:    fmt.Sprintf("Choose one or more Azure %s resources.", resourceName),
+	}
+
+	mergo.Merge(mergedSelectorOptions, options.SelectorOptions, mergo.WithoutDereference)
+	mergo.Merge(mergedSelectorOptions, defaultSelectorOptions, mergo.WithoutDereference)
+
+	resources, err := PromptCustomResources(ctx, CustomResourcesOptions[azure.ResourceExtended]{
+		SelectorOptions: mergedSelectorOptions,
+		LoadData: func(ctx context.Context) ([]*azure.ResourceExtended, error) {
+			return listSubscriptionResources(ctx, azureContext, options.ResourceType, options.Kinds)
 		},
-		Selected:       options.Selected,
-		CreateResource: options.CreateResource,
+		DisplayResource: displaySubscriptionResource,
+		Selected:        options.Selected,
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	if err := azureContext.Resources.Add(resource.Id); err != nil {
+	for _, resource := range resources {
+		if err := azureContext.Resources.Add(resource.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	return resources, nil
+}
+
+// listSubscriptionResources lists the resources in the subscription filtered by resource type and kinds.
+func listSubscriptionResources(
+	ctx context.Context,
+	azureContext *AzureContext,
+	resourceType *azure.ResourceType,
+	kinds []string,
+) ([]*azure.ResourceExtended, error) {
+	var resourceListOptions *armresources.ClientListOptions
+	if resourceType != nil {
+		resourceListOptions = &armresources.ClientListOptions{
+			Filter: to.Ptr(fmt.Sprintf("resourceType eq '%s'", string(*resourceType))),
+		}
+	}
+
+	azdContext, err := CurrentContext(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	return resource, nil
+	credential, err := azdContext.Credential()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceService := azure.NewResourceService(credential, nil)
+	resourceList, err := resourceService.ListSubscriptionResources(ctx, azureContext.Scope.SubscriptionId, resourceListOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	filteredResources := []*azure.ResourceExtended{}
+	hasKindFilter := len(kinds) > 0
+
+	for _, resource := range resourceList {
+		if !hasKindFilter || slices.Contains(kinds, resource.Kind) {
+			filteredResources = append(filteredResources, resource)
+		}
+	}
+
+	if len(filteredResources) == 0 {
+		if resourceType == nil {
+			return nil, ErrNoResourcesFound
+		}
+
+		return nil, fmt.Errorf("no resources found with type '%v'", *resourceType)
+	}
+
+	return filteredResources, nil
+}
+
+// displaySubscriptionResource displays the resource name with its resource group.
+func displaySubscriptionResource(resource *azure.ResourceExtended) (string, error) {
+	parsedResource, err := arm.ParseResourceID(resource.Id)
+	if err != nil {
+		return "", fmt.Errorf("parsing resource id: %w", err)
+	}
+
+	return fmt.Sprintf("%s %s", parsedResource.Name, color.HiBlackString("(%s)", parsedResource.ResourceGroupName)), nil
 }
 
 // PromptResourceGroupResource prompts the user to select an Azure resource group resource.
@@ -748,3 +877,136 @@ func PromptCustomResource[T any](ctx context.Context, options CustomResourceOpti
 
 	return selectedResource, nil
 }
+
+// PromptCustomResources prompts the user to select multiple custom resources from a list of resources.
+func PromptCustomResources[T any](ctx context.Context, options CustomResourcesOptions[T]) ([]*T, error) {
+	mergedSelectorOptions := &MultiSelectOptions{}
+
+	if options.SelectorOptions == nil {
+		options.SelectorOptions = &MultiSelectOptions{}
+	}
+
+	defaultSelectorOptions := &MultiSelectOptions{
+		Message:        "Select resources",
+		LoadingMessage: "Loading resources...",
+		HelpMessage:    "Choose one or more resources.",
+		DisplayNumbers: ux.Ptr(true),
+		DisplayCount:   10,
+	}
+
+	mergo.Merge(mergedSelectorOptions, options.SelectorOptions, mergo.WithoutDereference)
+	mergo.Merge(mergedSelectorOptions, defaultSelectorOptions, mergo.WithoutDereference)
+
+	var resources []*T
+
+	loadingSpinner := ux.NewSpinner(&ux.SpinnerOptions{
+		Text: mergedSelectorOptions.LoadingMessage,
+	})
+
+	err := loadingSpinner.Run(ctx, func(ctx context.Context) error {
+		resourceList, err := options.LoadData(ctx)
+		if err != nil {
+			return err
+		}
+
+		resources = resourceList
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resources) == 0 {
+		return nil, ErrNoResourcesFound
+	}
+
+	if options.SortResource != nil {
+		slices.SortFunc(resources, options.SortResource)
+	}
+
+	choices := make([]string, len(resources))
+	defaultIndexes := []int{}
+
+	for i, resource := range resources {
+		if options.Selected != nil && options.Selected(resource) {
+			defaultIndexes = append(defaultIndexes, i)
+		}
+
+		if options.DisplayResource == nil {
+			choices[i] = fmt.Sprintf("%v", resource)
+			continue
+		}
+
+		displayValue, err := options.DisplayResource(resource)
+		if err != nil {
+			return nil, err
+		}
+
+		choices[i] = displayValue
+	}
+
+	resourceSelector := ux.NewMultiSelect(&ux.MultiSelectOptions{
+		Message:         mergedSelectorOptions.Message,
+		HelpMessage:     mergedSelectorOptions.HelpMessage,
+		DisplayCount:    mergedSelectorOptions.DisplayCount,
+		DisplayNumbers:  mergedSelectorOptions.DisplayNumbers,
+		MinSelections:   mergedSelectorOptions.MinSelections,
+		MaxSelections:   mergedSelectorOptions.MaxSelections,
+		Allowed:         choices,
+		SelectedIndexes: defaultIndexes,
+	})
+
+	selectedIndexes, err := resourceSelector.Ask()
+	if err != nil {
+		return nil, err
+	}
+
+	selectedResources := make([]*T, len(selectedIndexes))
+	for i, index := range selectedIndexes {
+		selectedResources[i] = resources[index]
+	}
+
+	log.Printf("Selected %d resources", len(selectedResources))
+
+	return selectedResources, nil
+}
+
+// PromptSecret prompts the user for a secret without echoing the value and stores it at the path with config.SetSecret.
+// The value is kept in the local user vault and the config only references it, the caller is responsible for saving the config.
+func PromptSecret(ctx context.Context, cfg config.Config, path string, options *SecretOptions) error {
+	mergedOptions := &SecretOptions{}
+	if options != nil {
+		mergedOptions = options
+	}
+
+	if mergedOptions.Message == "" {
+		mergedOptions.Message = fmt.Sprintf("Enter the value for %s", path)
+	}
+
+	if mergedOptions.Required == nil {
+		mergedOptions.Required = to.Ptr(true)
+	}
+
+	secretPrompt := ux.NewPrompt(&ux.PromptOptions{
+		Message:     mergedOptions.Message,
+		HelpMessage: mergedOptions.HelpMessage,
+		Required:    *mergedOptions.Required,
+		Input:       mergedOptions.Input,
+		Secret:      true,
+	})
+
+	value, err := secretPrompt.Ask()
+	if err != nil {
+		return err
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	if err := cfg.SetSecret(path, value); err != nil {
+		return fmt.Errorf("failed storing secret %s: %w", path, err)
+	}
+
+	return nil
+}
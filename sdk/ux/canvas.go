@@ -1,7 +1,9 @@
 package ux
 
 import (
+	"bytes"
 	"io"
+	"strings"
 	"sync"
 )
 
@@ -11,6 +13,9 @@ type canvas struct {
 	writer     io.Writer
 	renderMap  map[Visual]*VisualContext
 	updateLock sync.Mutex
+	// writtenLines are the lines written since the canvas was run in the append only render mode, or the lines of the
+	// last update in the screen reader render mode
+	writtenLines map[string]bool
 }
 
 type Canvas interface {
@@ -34,7 +39,11 @@ func (c *canvas) WithWriter(writer io.Writer) Canvas {
 }
 
 func (c *canvas) Run() error {
+	c.updateLock.Lock()
 	c.printer = NewPrinter(c.writer)
+	c.writtenLines = map[string]bool{}
+	c.updateLock.Unlock()
+
 	return c.Update()
 }
 
@@ -46,13 +55,61 @@ func (c *canvas) Update() error {
 		return nil
 	}
 
+	if !IsInteractive() {
+		return c.append()
+	}
+
 	c.printer.ClearCanvas()
-	return c.render()
+	return c.render(c.printer)
+}
+
+// append renders the visuals off screen and writes the lines that haven't been written yet, so the output
+// only grows and doesn't depend on cursor movement. Blank lines are skipped. Screen readers announce a line again
+// when it comes back after a change, for example the focused choice of a select.
+func (c *canvas) append() error {
+	buffer := &frameBuffer{size: c.printer.ConsoleSize()}
+	if err := c.render(NewPrinter(buffer)); err != nil {
+		return err
+	}
+
+	frameLines := map[string]bool{}
+
+	for _, line := range strings.Split(buffer.String(), "\n") {
+		line = strings.TrimRight(line, " ")
+		if strings.TrimSpace(StripEscapeSequences(line)) == "" {
+			continue
+		}
+
+		frameLines[line] = true
+
+		if c.writtenLines[line] {
+			continue
+		}
+
+		c.writtenLines[line] = true
+		c.printer.Fprintf("%s\n", line)
+	}
+
+	if CurrentRenderMode() == ScreenReaderRenderMode {
+		c.writtenLines = frameLines
+	}
+
+	return nil
+}
+
+// frameBuffer collects a frame rendered off screen and reports the console size of the canvas writer.
+type frameBuffer struct {
+	bytes.Buffer
+	size CanvasSize
+}
+
+func (b *frameBuffer) Size() CanvasSize {
+	return b.size
 }
 
-func (c *canvas) render() error {
+func (c *canvas) render(printer Printer) error {
 	for _, visual := range c.visuals {
-		if err := c.renderVisual(visual); err != nil {
+		if err := c.renderVisual(printer, visual); err != nil {
 			return err
 		}
 	}
@@ -60,8 +117,8 @@ func (c *canvas) render() error {
 	return nil
 }
 
-func (c *canvas) renderVisual(visual Visual) error {
-	err := visual.Render(c.printer)
+func (c *canvas) renderVisual(printer Printer, visual Visual) error {
+	err := visual.Render(printer)
 	if err != nil {
 		return err
 	}
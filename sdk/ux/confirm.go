@@ -1,6 +1,7 @@
 package ux
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,7 +12,6 @@ import (
 
 	"dario.cat/mergo"
 	"github.com/eiannone/keyboard"
-	"github.com/fatih/color"
 )
 
 type ConfirmOptions struct {
@@ -19,7 +19,9 @@ type ConfirmOptions struct {
 	Writer io.Writer
 	// The reader to use for input (default: os.Stdin)
 	Reader io.Reader
-	// The default value to use for the prompt (default: nil)
+	// The source of key events (default: the terminal when Reader is a terminal, otherwise decoded from Reader)
+	Input InputSource
+	// The default value to use for the prompt, also the answer when the render mode can't prompt (default: nil)
 	DefaultValue *bool
 	// The message to display before the prompt
 	Message string
@@ -87,7 +89,7 @@ func NewConfirm(options *ConfirmOptions) *Confirm {
 	}
 
 	return &Confirm{
-		input:        internal.NewInput(),
+		input:        internal.NewInput(resolveInputSource(mergedOptions.Input, mergedOptions.Reader)),
 		options:      &mergedOptions,
 		displayValue: displayValue,
 		value:        mergedOptions.DefaultValue,
@@ -104,7 +106,16 @@ func (p *Confirm) Ask() (*bool, error) {
 		p.canvas = NewCanvas(p).WithWriter(p.options.Writer)
 	}
 
-	if err := p.canvas.Run(); err != nil {
+	if !canReadInput(p.options.Input, p.options.Reader) {
+		if p.options.DefaultValue == nil {
+			return nil, noPromptValueError(p.options.Message)
+		}
+
+		p.complete = true
+		return p.value, p.canvas.Run()
+	}
+
+	if err := showPrompt(p.canvas, p); err != nil {
 		return nil, err
 	}
 
@@ -112,69 +123,75 @@ func (p *Confirm) Ask() (*bool, error) {
 		InitialValue:   p.displayValue,
 		IgnoreHintKeys: true,
 	}
-	input, done, err := p.input.ReadInput(inputConfig)
+	next, done, err := p.input.ReadInput(inputConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	for {
-		select {
-		case <-p.input.SigChan:
-			p.cancelled = true
+		msg, err := next()
+		if err != nil {
 			done()
-			p.canvas.Update()
-			return nil, ErrCancelled
 
-		case msg := <-input:
-			p.showHelp = msg.Hint
+			if errors.Is(err, internal.ErrInterrupted) {
+				p.cancelled = true
+				updatePrompt(p.canvas, p, true)
+				return nil, ErrCancelled
+			}
 
-			if msg.Key == keyboard.KeyEnter {
-				p.submitted = true
+			return nil, err
+		}
 
-				if !p.hasValidationError {
-					p.complete = true
-				}
+		p.showHelp = msg.Hint
+
+		if msg.Key == keyboard.KeyEnter {
+			p.submitted = true
+
+			if !p.hasValidationError {
+				p.complete = true
+			}
+		} else {
+			p.hasValidationError = false
+			if msg.Value == "" && p.options.DefaultValue != nil {
+				p.value = p.options.DefaultValue
+				p.displayValue = getBooleanString(*p.value)
 			} else {
-				p.hasValidationError = false
-				if msg.Value == "" && p.options.DefaultValue != nil {
-					p.value = p.options.DefaultValue
-					p.displayValue = getBooleanString(*p.value)
+				value, err := parseBooleanString(string(msg.Char))
+				if err != nil {
+					p.hasValidationError = true
+					p.value = nil
+					p.displayValue = msg.Value
 				} else {
-					value, err := parseBooleanString(string(msg.Char))
-					if err != nil {
-						p.hasValidationError = true
-						p.value = nil
-						p.displayValue = msg.Value
-					} else {
-						p.value = value
-						p.displayValue = getBooleanString(*value)
-					}
+					p.value = value
+					p.displayValue = getBooleanString(*value)
 				}
 			}
+		}
 
-			if !p.hasValidationError {
-				p.input.ResetValue()
-			}
+		if !p.hasValidationError {
+			p.input.ResetValue()
+		}
 
-			p.canvas.Update()
+		updatePrompt(p.canvas, p, p.complete)
 
-			if p.complete {
-				done()
-				return p.value, nil
-			}
+		if p.complete {
+			done()
+			return p.value, nil
 		}
 	}
 }
 
 func (p *Confirm) Render(printer Printer) error {
-	printer.Fprintf(color.CyanString("? "))
+	theme := activeTheme()
+
+	printer.Fprintf(theme.Palette.Accent.Sprintf("%s ", theme.Glyphs.Question))
 
 	// Message
 	printer.Fprintf(BoldString("%s: ", p.options.Message))
 
 	// Hint
 	if !p.cancelled && !p.complete && p.options.Hint != "" {
-		printer.Fprintf("%s ", color.CyanString(p.options.Hint))
+		printer.Fprintf("%s ", theme.Palette.Accent.Sprint(p.options.Hint))
 	}
 
 	// Value
@@ -182,11 +199,11 @@ func (p *Confirm) Render(printer Printer) error {
 	valueOutput := rawStringValue
 
 	if p.complete || p.value == p.options.DefaultValue {
-		valueOutput = color.CyanString(rawStringValue)
+		valueOutput = theme.Palette.Accent.Sprint(rawStringValue)
 	}
 
 	if p.cancelled {
-		valueOutput = color.RedString("(Cancelled)")
+		valueOutput = theme.Palette.Error.Sprint("(Cancelled)")
 	}
 
 	printer.Fprintf(valueOutput)
@@ -200,14 +217,14 @@ func (p *Confirm) Render(printer Printer) error {
 
 	// Validation error
 	if !p.showHelp && p.hasValidationError {
-		printer.Fprintln(color.YellowString("Enter a valid value"))
+		printer.Fprintln(theme.Palette.Warning.Sprint("Enter a valid value"))
 	}
 
 	// Hint
 	if p.showHelp && p.options.HelpMessage != "" {
 		printer.Fprintln()
 		printer.Fprintf(
-			color.HiMagentaString("%s %s\n",
+			theme.Palette.Hint.Sprintf("%s %s\n",
 				BoldString("Hint:"),
 				p.options.HelpMessage,
 			),
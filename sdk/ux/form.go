@@ -0,0 +1,386 @@
+package ux
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/eiannone/keyboard"
+	"github.com/fatih/color"
+	"github.com/wbreza/azd-extensions/sdk/ux/internal"
+)
+
+// DefaultFormWriter is the writer of forms without a writer, generic options can't have a package level default.
+var DefaultFormWriter io.Writer = os.Stdout
+
+type FormOptions[T any] struct {
+	// The writer to use for output (default: DefaultFormWriter)
+	Writer io.Writer
+	// The reader to use for input (default: os.Stdin)
+	Reader io.Reader
+	// The source of key events (default: the terminal when Reader is a terminal, otherwise decoded from Reader)
+	Input InputSource
+	// The optional title displayed above the fields (default: "")
+	Title string
+	// The fields of the form in the order they're displayed
+	Fields []FormField[T]
+	// The initial values of the fields. The result is a copy with the answers applied (default: the zero value of T)
+	Initial *T
+	// The optional validation across fields before the form is submitted, the message of the error is displayed below the fields
+	Validate func(result *T) error
+	// Whether or not the answers are reviewed before the form is submitted (default: true)
+	Review *bool
+	// The optional hint text that displays below the fields (default: "Tab to move, Shift+Tab to go back, Enter to continue")
+	Hint string
+}
+
+// Form groups fields on a single canvas so the user can move between the fields and fix earlier answers before the
+// answers are submitted. Tab and Enter move to the next field once the current field is valid, Shift+Tab moves back.
+// Fields are only displayed while their Visible function returns true for the answers so far.
+type Form[T any] struct {
+	input       *internal.Input
+	inputConfig *internal.InputConfig
+	canvas      Canvas
+
+	options        *FormOptions[T]
+	result         *T
+	focused        int
+	reviewing      bool
+	showHelp       bool
+	complete       bool
+	cancelled      bool
+	fieldError     string
+	formError      string
+	cursorPosition *CursorPosition
+}
+
+func NewForm[T any](options *FormOptions[T]) *Form[T] {
+	mergedOptions := FormOptions[T]{}
+	if options != nil {
+		mergedOptions = *options
+	}
+
+	// Generic options can't be merged with a package level default
+	if mergedOptions.Writer == nil {
+		mergedOptions.Writer = DefaultFormWriter
+	}
+
+	if mergedOptions.Reader == nil {
+		mergedOptions.Reader = os.Stdin
+	}
+
+	if mergedOptions.Review == nil {
+		mergedOptions.Review = Ptr(true)
+	}
+
+	if mergedOptions.Hint == "" {
+		mergedOptions.Hint = "Tab to move, Shift+Tab to go back, Enter to continue"
+	}
+
+	result := new(T)
+	if mergedOptions.Initial != nil {
+		*result = *mergedOptions.Initial
+	}
+
+	return &Form[T]{
+		input:       internal.NewInput(resolveInputSource(mergedOptions.Input, mergedOptions.Reader)),
+		inputConfig: &internal.InputConfig{},
+		options:     &mergedOptions,
+		result:      result,
+		focused:     -1,
+	}
+}
+
+func (f *Form[T]) WithCanvas(canvas Canvas) Visual {
+	f.canvas = canvas
+	return f
+}
+
+// Ask displays the form and returns the answers once the form is submitted.
+func (f *Form[T]) Ask() (*T, error) {
+	if f.canvas == nil {
+		f.canvas = NewCanvas(f).WithWriter(f.options.Writer)
+	}
+
+	for _, field := range f.options.Fields {
+		field.load(f.result)
+	}
+
+	if !canReadInput(f.options.Input, f.options.Reader) {
+		if index := f.firstInvalidField(); index >= 0 {
+			return nil, noPromptValueError(f.options.Fields[index].Message())
+		}
+
+		if err := f.validateForm(); err != nil {
+			return nil, err
+		}
+
+		f.complete = true
+		return f.result, f.canvas.Run()
+	}
+
+	next, done, err := f.input.ReadInput(f.inputConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if !f.focusNext(-1) {
+		f.finish()
+	}
+
+	if err := showPrompt(f.canvas, f); err != nil {
+		done()
+		return nil, err
+	}
+
+	for {
+		msg, err := next()
+		if err != nil {
+			done()
+
+			if errors.Is(err, internal.ErrInterrupted) {
+				f.cancelled = true
+				updatePrompt(f.canvas, f, true)
+				return nil, ErrCancelled
+			}
+
+			return nil, err
+		}
+
+		f.showHelp = msg.Hint
+
+		if f.reviewing {
+			f.handleReviewKey(msg)
+		} else {
+			f.handleKey(msg)
+		}
+
+		updatePrompt(f.canvas, f, f.complete)
+
+		if f.complete {
+			done()
+			return f.result, nil
+		}
+	}
+}
+
+// handleKey edits the focused field or moves between the fields.
+func (f *Form[T]) handleKey(msg internal.InputEventArgs) {
+	if f.focused < 0 {
+		return
+	}
+
+	field := f.options.Fields[f.focused]
+
+	switch msg.Key {
+	case KeyShiftTab:
+		f.focusPrevious()
+		return
+	case keyboard.KeyTab, keyboard.KeyEnter:
+		f.advance()
+		return
+	}
+
+	// ? shows the help message of the field and Esc hides it
+	if msg.Hint || msg.Key == keyboard.KeyEsc {
+		return
+	}
+
+	if !field.handleKey(msg) {
+		if msg.Key == keyboard.KeyArrowUp {
+			f.focusPrevious()
+		} else if msg.Key == keyboard.KeyArrowDown {
+			f.advance()
+		}
+
+		return
+	}
+
+	field.store(f.result)
+	f.input.SetValue(field.focus())
+	f.fieldError = ""
+	f.formError = ""
+}
+
+// handleReviewKey submits the form or returns to the fields.
+func (f *Form[T]) handleReviewKey(msg internal.InputEventArgs) {
+	f.input.ResetValue()
+
+	switch {
+	case msg.Key == keyboard.KeyEnter || msg.Char == 'y' || msg.Char == 'Y':
+		f.complete = true
+	case msg.Key == KeyShiftTab || msg.Key == keyboard.KeyEsc || msg.Char == 'n' || msg.Char == 'N':
+		if f.focused >= 0 {
+			f.reviewing = false
+			f.input.SetValue(f.options.Fields[f.focused].focus())
+		}
+	}
+}
+
+// advance validates the focused field and moves to the next field, or finishes the form after the last field.
+func (f *Form[T]) advance() {
+	field := f.options.Fields[f.focused]
+
+	f.fieldError = field.validate()
+	if f.fieldError != "" {
+		return
+	}
+
+	field.store(f.result)
+
+	if !f.focusNext(f.focused) {
+		f.finish()
+	}
+}
+
+// finish validates all fields and the form and then moves to the review or submits the form.
+func (f *Form[T]) finish() {
+	if index := f.firstInvalidField(); index >= 0 {
+		f.focus(index)
+		f.fieldError = f.options.Fields[index].validate()
+		return
+	}
+
+	if err := f.validateForm(); err != nil {
+		f.formError = err.Error()
+		return
+	}
+
+	f.formError = ""
+
+	if *f.options.Review {
+		f.reviewing = true
+		f.input.ResetValue()
+		return
+	}
+
+	f.complete = true
+}
+
+func (f *Form[T]) validateForm() error {
+	if f.options.Validate == nil {
+		return nil
+	}
+
+	return f.options.Validate(f.result)
+}
+
+// firstInvalidField returns the index of the first visible field with an invalid value or -1 when all are valid.
+func (f *Form[T]) firstInvalidField() int {
+	for index, field := range f.options.Fields {
+		if field.visible(f.result) && field.validate() != "" {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// focusNext focuses the next visible field after the index and returns false when there is none.
+func (f *Form[T]) focusNext(index int) bool {
+	for next := index + 1; next < len(f.options.Fields); next++ {
+		if f.options.Fields[next].visible(f.result) {
+			f.focus(next)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *Form[T]) focusPrevious() {
+	for previous := f.focused - 1; previous >= 0; previous-- {
+		if f.options.Fields[previous].visible(f.result) {
+			f.focus(previous)
+			return
+		}
+	}
+}
+
+func (f *Form[T]) focus(index int) {
+	f.focused = index
+	f.fieldError = ""
+	f.inputConfig.IgnoreHintKeys = !f.options.Fields[index].capturesHintKeys()
+	f.input.SetValue(f.options.Fields[index].focus())
+}
+
+func (f *Form[T]) Render(printer Printer) error {
+	if f.options.Title != "" {
+		printer.Fprintf("%s%s\n", color.CyanString("? "), BoldString("%s", f.options.Title))
+	}
+
+	if f.cancelled {
+		printer.Fprintf("%s\n", color.HiRedString("(Cancelled)"))
+		return nil
+	}
+
+	if f.options.Title != "" && !f.complete {
+		printer.Fprintf("\n")
+	}
+
+	labelWidth := 0
+	for _, field := range f.options.Fields {
+		labelWidth = max(labelWidth, VisibleWidth(field.Message())+1)
+	}
+
+	editing := !f.complete && !f.reviewing
+	f.cursorPosition = nil
+
+	for index, field := range f.options.Fields {
+		if !field.visible(f.result) {
+			continue
+		}
+
+		focused := editing && index == f.focused
+		label := AlignText(field.Message()+":", labelWidth, AlignLeft)
+		value := field.display(focused)
+
+		if f.complete {
+			value = color.CyanString(value)
+		}
+
+		if focused {
+			printer.Fprintf("%s%s %s", color.CyanString("> "), BoldString("%s", label), value)
+			f.cursorPosition = Ptr(printer.CursorPosition())
+			printer.Fprintf("\n")
+
+			field.renderOptions(printer, "    ")
+
+			if f.fieldError != "" && !f.showHelp {
+				printer.Fprintf("  %s\n", color.YellowString(f.fieldError))
+			}
+
+			if f.showHelp && field.helpMessage() != "" {
+				printer.Fprintf("  %s\n", color.HiMagentaString("%s %s", BoldString("Hint:"), field.helpMessage()))
+			}
+
+			continue
+		}
+
+		printer.Fprintf("  %s %s\n", label, value)
+	}
+
+	if f.complete {
+		return nil
+	}
+
+	printer.Fprintf("\n")
+
+	if f.formError != "" {
+		printer.Fprintf("%s\n", color.YellowString(f.formError))
+	}
+
+	if f.reviewing {
+		printer.Fprintf("%s%s %s ", color.CyanString("? "), BoldString("Submit?"), color.CyanString("[Y/n]"))
+		f.cursorPosition = Ptr(printer.CursorPosition())
+		printer.Fprintf("\n%s\n", color.HiBlackString("Enter to submit, Shift+Tab to edit the answers"))
+	} else {
+		printer.Fprintf("%s\n", color.HiBlackString(f.options.Hint))
+	}
+
+	if f.cursorPosition != nil {
+		printer.SetCursorPosition(*f.cursorPosition)
+	}
+
+	return nil
+}
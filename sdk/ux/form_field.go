@@ -0,0 +1,513 @@
+package ux
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/eiannone/keyboard"
+	"github.com/fatih/color"
+	"github.com/wbreza/azd-extensions/sdk/ux/internal"
+)
+
+// FormField is a field of a form that reads its initial value from a field of the result T and writes the answer back.
+// Fields are created with NewTextField, NewSecretField, NewConfirmField, NewSelectField and NewMultiSelectField.
+type FormField[T any] interface {
+	// Message returns the label displayed before the value.
+	Message() string
+
+	visible(result *T) bool
+	load(result *T)
+	store(result *T)
+	// focus prepares the field to be edited and returns the text value of the input
+	focus() string
+	// handleKey applies the key event and returns false when the form should handle the key instead
+	handleKey(args internal.InputEventArgs) bool
+	// validate returns the message displayed below the field when the value is invalid
+	validate() string
+	// display returns the value displayed after the label
+	display(focused bool) string
+	// renderOptions renders the lines displayed below the label while the field is focused
+	renderOptions(printer Printer, indent string)
+	helpMessage() string
+	// capturesHintKeys returns false when ? is part of the value, for example in secrets
+	capturesHintKeys() bool
+}
+
+type TextFieldOptions[T any] struct {
+	// The label displayed before the value
+	Message string
+	// Returns the field of the result the value is read from and written to
+	Value func(result *T) *string
+	// The optional message to display when the user types ? (default: "")
+	HelpMessage string
+	// The optional placeholder text to display when the value is empty (default: "")
+	PlaceHolder string
+	// Whether or not a value is required (default: false)
+	Required bool
+	// The optional validation message to display when the value is empty and required (default: "This field is required")
+	RequiredMessage string
+	// The optional validation function, the message of the error is displayed below the field
+	Validate func(value string) error
+	// Whether the value is masked while typing and in the review. Hint keys aren't captured so any character can be
+	// typed (default: false)
+	Secret bool
+	// Whether the field is displayed based on the other answers (default: always)
+	Visible func(result *T) bool
+}
+
+// NewTextField creates a field that reads a line of text.
+func NewTextField[T any](options *TextFieldOptions[T]) FormField[T] {
+	return &textField[T]{options: options}
+}
+
+// NewSecretField creates a text field that masks the value, for example for keys and passwords.
+func NewSecretField[T any](options *TextFieldOptions[T]) FormField[T] {
+	secretOptions := *options
+	secretOptions.Secret = true
+
+	return &textField[T]{options: &secretOptions}
+}
+
+type textField[T any] struct {
+	options *TextFieldOptions[T]
+	value   string
+}
+
+func (f *textField[T]) Message() string {
+	return f.options.Message
+}
+
+func (f *textField[T]) visible(result *T) bool {
+	return f.options.Visible == nil || f.options.Visible(result)
+}
+
+func (f *textField[T]) load(result *T) {
+	if f.options.Value != nil {
+		f.value = *f.options.Value(result)
+	}
+}
+
+func (f *textField[T]) store(result *T) {
+	if f.options.Value != nil {
+		*f.options.Value(result) = f.value
+	}
+}
+
+func (f *textField[T]) focus() string {
+	return f.value
+}
+
+func (f *textField[T]) handleKey(args internal.InputEventArgs) bool {
+	if args.Key == keyboard.KeyArrowUp || args.Key == keyboard.KeyArrowDown {
+		return false
+	}
+
+	f.value = args.Value
+	return true
+}
+
+func (f *textField[T]) validate() string {
+	if f.options.Required && f.value == "" {
+		if f.options.RequiredMessage != "" {
+			return f.options.RequiredMessage
+		}
+
+		return "This field is required"
+	}
+
+	if f.options.Validate != nil {
+		if err := f.options.Validate(f.value); err != nil {
+			return err.Error()
+		}
+	}
+
+	return ""
+}
+
+func (f *textField[T]) display(focused bool) string {
+	if f.value == "" {
+		if focused && f.options.PlaceHolder != "" {
+			return color.HiBlackString(f.options.PlaceHolder)
+		}
+
+		return ""
+	}
+
+	if f.options.Secret {
+		return maskSecret(f.value)
+	}
+
+	return f.value
+}
+
+func (f *textField[T]) renderOptions(printer Printer, indent string) {
+}
+
+func (f *textField[T]) helpMessage() string {
+	return f.options.HelpMessage
+}
+
+func (f *textField[T]) capturesHintKeys() bool {
+	return !f.options.Secret
+}
+
+type ConfirmFieldOptions[T any] struct {
+	// The label displayed before the value
+	Message string
+	// Returns the field of the result the value is read from and written to
+	Value func(result *T) *bool
+	// The optional message to display when the user types ? (default: "")
+	HelpMessage string
+	// Whether the field is displayed based on the other answers (default: always)
+	Visible func(result *T) bool
+}
+
+// NewConfirmField creates a yes or no field. Type y or n, or press space to toggle the value.
+func NewConfirmField[T any](options *ConfirmFieldOptions[T]) FormField[T] {
+	return &confirmField[T]{options: options}
+}
+
+type confirmField[T any] struct {
+	options *ConfirmFieldOptions[T]
+	value   bool
+}
+
+func (f *confirmField[T]) Message() string {
+	return f.options.Message
+}
+
+func (f *confirmField[T]) visible(result *T) bool {
+	return f.options.Visible == nil || f.options.Visible(result)
+}
+
+func (f *confirmField[T]) load(result *T) {
+	if f.options.Value != nil {
+		f.value = *f.options.Value(result)
+	}
+}
+
+func (f *confirmField[T]) store(result *T) {
+	if f.options.Value != nil {
+		*f.options.Value(result) = f.value
+	}
+}
+
+func (f *confirmField[T]) focus() string {
+	return ""
+}
+
+func (f *confirmField[T]) handleKey(args internal.InputEventArgs) bool {
+	switch {
+	case args.Char == 'y' || args.Char == 'Y':
+		f.value = true
+	case args.Char == 'n' || args.Char == 'N':
+		f.value = false
+	case args.Key == keyboard.KeySpace || args.Key == keyboard.KeyArrowLeft || args.Key == keyboard.KeyArrowRight:
+		f.value = !f.value
+	case args.Key == keyboard.KeyArrowUp || args.Key == keyboard.KeyArrowDown:
+		return false
+	}
+
+	return true
+}
+
+func (f *confirmField[T]) validate() string {
+	return ""
+}
+
+func (f *confirmField[T]) display(focused bool) string {
+	value := getBooleanString(f.value)
+	if focused {
+		return fmt.Sprintf("%s %s", value, color.HiBlackString("[y/n]"))
+	}
+
+	return value
+}
+
+func (f *confirmField[T]) renderOptions(printer Printer, indent string) {
+}
+
+func (f *confirmField[T]) helpMessage() string {
+	return f.options.HelpMessage
+}
+
+func (f *confirmField[T]) capturesHintKeys() bool {
+	return true
+}
+
+type SelectFieldOptions[T any] struct {
+	// The label displayed before the value
+	Message string
+	// Returns the field of the result the selected option is read from and written to
+	Value func(result *T) *string
+	// The options to choose from
+	Allowed []string
+	// The optional message to display when the user types ? (default: "")
+	HelpMessage string
+	// The maximum number of options to display at one time (default: 6)
+	DisplayCount int
+	// Whether the field is displayed based on the other answers (default: always)
+	Visible func(result *T) bool
+}
+
+// NewSelectField creates a field that selects one of the allowed options with the arrow keys.
+func NewSelectField[T any](options *SelectFieldOptions[T]) FormField[T] {
+	return &selectField[T]{options: options, selected: -1}
+}
+
+type selectField[T any] struct {
+	options  *SelectFieldOptions[T]
+	selected int
+}
+
+func (f *selectField[T]) Message() string {
+	return f.options.Message
+}
+
+func (f *selectField[T]) visible(result *T) bool {
+	return f.options.Visible == nil || f.options.Visible(result)
+}
+
+func (f *selectField[T]) load(result *T) {
+	if f.options.Value != nil {
+		f.selected = slices.Index(f.options.Allowed, *f.options.Value(result))
+	}
+}
+
+func (f *selectField[T]) store(result *T) {
+	if f.options.Value != nil && f.selected >= 0 {
+		*f.options.Value(result) = f.options.Allowed[f.selected]
+	}
+}
+
+func (f *selectField[T]) focus() string {
+	if f.selected < 0 && len(f.options.Allowed) > 0 {
+		f.selected = 0
+	}
+
+	return ""
+}
+
+func (f *selectField[T]) handleKey(args internal.InputEventArgs) bool {
+	count := len(f.options.Allowed)
+	if count == 0 {
+		return args.Key != keyboard.KeyArrowUp && args.Key != keyboard.KeyArrowDown
+	}
+
+	switch args.Key {
+	case keyboard.KeyArrowUp:
+		f.selected = (f.selected - 1 + count) % count
+	case keyboard.KeyArrowDown:
+		f.selected = (f.selected + 1) % count
+	}
+
+	return true
+}
+
+func (f *selectField[T]) validate() string {
+	if f.selected < 0 {
+		return "Select an option"
+	}
+
+	return ""
+}
+
+func (f *selectField[T]) display(focused bool) string {
+	if f.selected < 0 {
+		return ""
+	}
+
+	return f.options.Allowed[f.selected]
+}
+
+func (f *selectField[T]) renderOptions(printer Printer, indent string) {
+	choices := make([]*selectChoice, len(f.options.Allowed))
+	for index, value := range f.options.Allowed {
+		choices[index] = &selectChoice{Index: index, Value: value}
+	}
+
+	start, end := displayRange(max(0, f.selected), len(choices), formDisplayCount(f.options.DisplayCount))
+
+	renderChoices(printer, indent, choices, start, end, func(index int, choice *selectChoice) string {
+		if index == f.selected {
+			return color.CyanString("> %s", choice.Value)
+		}
+
+		return fmt.Sprintf("  %s", choice.Value)
+	})
+}
+
+func (f *selectField[T]) helpMessage() string {
+	return f.options.HelpMessage
+}
+
+func (f *selectField[T]) capturesHintKeys() bool {
+	return true
+}
+
+type MultiSelectFieldOptions[T any] struct {
+	// The label displayed before the value
+	Message string
+	// Returns the field of the result the selected options are read from and written to
+	Value func(result *T) *[]string
+	// The options to choose from
+	Allowed []string
+	// The optional message to display when the user types ? (default: "")
+	HelpMessage string
+	// The maximum number of options to display at one time (default: 6)
+	DisplayCount int
+	// The minimum number of options that must be selected (default: 0)
+	MinSelections int
+	// The maximum number of options that can be selected, 0 for no limit (default: 0)
+	MaxSelections int
+	// Whether the field is displayed based on the other answers (default: always)
+	Visible func(result *T) bool
+}
+
+// NewMultiSelectField creates a field that selects any number of the allowed options.
+// Space toggles the current option, the right arrow selects all options and the left arrow clears them.
+func NewMultiSelectField[T any](options *MultiSelectFieldOptions[T]) FormField[T] {
+	return &multiSelectField[T]{options: options, selected: map[int]bool{}}
+}
+
+type multiSelectField[T any] struct {
+	options      *MultiSelectFieldOptions[T]
+	currentIndex int
+	selected     map[int]bool
+}
+
+func (f *multiSelectField[T]) Message() string {
+	return f.options.Message
+}
+
+func (f *multiSelectField[T]) visible(result *T) bool {
+	return f.options.Visible == nil || f.options.Visible(result)
+}
+
+func (f *multiSelectField[T]) load(result *T) {
+	if f.options.Value == nil {
+		return
+	}
+
+	f.selected = map[int]bool{}
+	for _, value := range *f.options.Value(result) {
+		if index := slices.Index(f.options.Allowed, value); index >= 0 {
+			f.selected[index] = true
+		}
+	}
+}
+
+func (f *multiSelectField[T]) store(result *T) {
+	if f.options.Value != nil {
+		*f.options.Value(result) = f.values()
+	}
+}
+
+func (f *multiSelectField[T]) focus() string {
+	return ""
+}
+
+func (f *multiSelectField[T]) handleKey(args internal.InputEventArgs) bool {
+	count := len(f.options.Allowed)
+	if count == 0 {
+		return args.Key != keyboard.KeyArrowUp && args.Key != keyboard.KeyArrowDown
+	}
+
+	switch args.Key {
+	case keyboard.KeyArrowUp:
+		f.currentIndex = (f.currentIndex - 1 + count) % count
+	case keyboard.KeyArrowDown:
+		f.currentIndex = (f.currentIndex + 1) % count
+	case keyboard.KeySpace:
+		if f.selected[f.currentIndex] {
+			delete(f.selected, f.currentIndex)
+		} else if f.options.MaxSelections == 0 || len(f.selected) < f.options.MaxSelections {
+			f.selected[f.currentIndex] = true
+		}
+	case keyboard.KeyArrowRight:
+		for index := range f.options.Allowed {
+			if f.options.MaxSelections > 0 && len(f.selected) >= f.options.MaxSelections {
+				break
+			}
+
+			f.selected[index] = true
+		}
+	case keyboard.KeyArrowLeft:
+		f.selected = map[int]bool{}
+	}
+
+	return true
+}
+
+func (f *multiSelectField[T]) validate() string {
+	count := len(f.selected)
+
+	if count < f.options.MinSelections {
+		return fmt.Sprintf("Select at least %d %s", f.options.MinSelections, pluralize("option", f.options.MinSelections))
+	}
+
+	if f.options.MaxSelections > 0 && count > f.options.MaxSelections {
+		return fmt.Sprintf("Select at most %d %s", f.options.MaxSelections, pluralize("option", f.options.MaxSelections))
+	}
+
+	return ""
+}
+
+func (f *multiSelectField[T]) display(focused bool) string {
+	if focused {
+		return color.HiBlackString("%d of %d selected", len(f.selected), len(f.options.Allowed))
+	}
+
+	return strings.Join(f.values(), ", ")
+}
+
+func (f *multiSelectField[T]) renderOptions(printer Printer, indent string) {
+	choices := make([]*selectChoice, len(f.options.Allowed))
+	for index, value := range f.options.Allowed {
+		choices[index] = &selectChoice{Index: index, Value: value}
+	}
+
+	start, end := displayRange(f.currentIndex, len(choices), formDisplayCount(f.options.DisplayCount))
+
+	renderChoices(printer, indent, choices, start, end, func(index int, choice *selectChoice) string {
+		checkbox := "[ ]"
+		if f.selected[index] {
+			checkbox = color.GreenString("[✔]")
+		}
+
+		if index == f.currentIndex {
+			return fmt.Sprintf("%s %s %s", color.CyanString(">"), checkbox, color.CyanString(choice.Value))
+		}
+
+		return fmt.Sprintf("  %s %s", checkbox, choice.Value)
+	})
+}
+
+func (f *multiSelectField[T]) helpMessage() string {
+	return f.options.HelpMessage
+}
+
+func (f *multiSelectField[T]) capturesHintKeys() bool {
+	return true
+}
+
+// values returns the selected options in their original order.
+func (f *multiSelectField[T]) values() []string {
+	values := []string{}
+	for index, value := range f.options.Allowed {
+		if f.selected[index] {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+func formDisplayCount(displayCount int) int {
+	if displayCount <= 0 {
+		return 6
+	}
+
+	return displayCount
+}
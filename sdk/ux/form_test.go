@@ -0,0 +1,201 @@
+package ux
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eiannone/keyboard"
+	"github.com/stretchr/testify/require"
+)
+
+type deploymentAnswers struct {
+	Name      string
+	ApiKey    string
+	Advanced  bool
+	Region    string
+	Models    []string
+	Capacity  string
+	Confirmed bool
+}
+
+func deploymentFields() []FormField[deploymentAnswers] {
+	return []FormField[deploymentAnswers]{
+		NewTextField(&TextFieldOptions[deploymentAnswers]{
+			Message:  "Name",
+			Required: true,
+			Value:    func(answers *deploymentAnswers) *string { return &answers.Name },
+		}),
+		NewSecretField(&TextFieldOptions[deploymentAnswers]{
+			Message: "API key",
+			Value:   func(answers *deploymentAnswers) *string { return &answers.ApiKey },
+		}),
+		NewSelectField(&SelectFieldOptions[deploymentAnswers]{
+			Message: "Region",
+			Allowed: []string{"eastus", "westus"},
+			Value:   func(answers *deploymentAnswers) *string { return &answers.Region },
+		}),
+		NewMultiSelectField(&MultiSelectFieldOptions[deploymentAnswers]{
+			Message:       "Models",
+			Allowed:       []string{"gpt-4o", "gpt-4o-mini", "text-embedding-3-small"},
+			MinSelections: 1,
+			Value:         func(answers *deploymentAnswers) *[]string { return &answers.Models },
+		}),
+		NewConfirmField(&ConfirmFieldOptions[deploymentAnswers]{
+			Message: "Advanced",
+			Value:   func(answers *deploymentAnswers) *bool { return &answers.Advanced },
+		}),
+		NewTextField(&TextFieldOptions[deploymentAnswers]{
+			Message:  "Capacity",
+			Required: true,
+			Value:    func(answers *deploymentAnswers) *string { return &answers.Capacity },
+			Visible:  func(answers *deploymentAnswers) bool { return answers.Advanced },
+		}),
+	}
+}
+
+func Test_Form(t *testing.T) {
+	t.Run("Submit", func(t *testing.T) {
+		var buffer bytes.Buffer
+		input := NewScriptedInput().
+			Press(keyboard.KeyTab). // Name is required
+			Type("my-ap").
+			Press(keyboard.KeyTab).
+			Type("sec?ret"). // Secrets don't capture the hint key
+			Press(keyboard.KeyTab).
+			Press(keyboard.KeyArrowDown, keyboard.KeyEnter). // westus
+			Press(keyboard.KeySpace, keyboard.KeyArrowDown, keyboard.KeySpace, keyboard.KeyEnter).
+			Press(KeyShiftTab, KeyShiftTab, KeyShiftTab, KeyShiftTab). // Back to fix the name
+			Type("p").
+			Press(keyboard.KeyEnter, keyboard.KeyEnter, keyboard.KeyEnter, keyboard.KeyEnter, keyboard.KeyEnter).
+			Press(keyboard.KeyEnter) // Submit the review
+
+		form := NewForm(&FormOptions[deploymentAnswers]{
+			Writer: &buffer,
+			Input:  input,
+			Title:  "Deploy models",
+			Fields: deploymentFields(),
+		})
+
+		answers, err := form.Ask()
+		require.NoError(t, err)
+		require.Equal(t, &deploymentAnswers{
+			Name:   "my-app",
+			ApiKey: "sec?ret",
+			Region: "westus",
+			Models: []string{"gpt-4o", "gpt-4o-mini"},
+		}, answers)
+
+		output := buffer.String()
+		require.Contains(t, output, "This field is required")
+		require.Contains(t, output, "Submit?")
+		require.NotContains(t, output, "sec?ret")
+		require.NotContains(t, output, "Capacity")
+	})
+
+	t.Run("ConditionalField", func(t *testing.T) {
+		input := NewScriptedInput().
+			Press(keyboard.KeyEnter, keyboard.KeyEnter, keyboard.KeyEnter, keyboard.KeyEnter).
+			Type("y").
+			Press(keyboard.KeyEnter).
+			Type("10").
+			Press(keyboard.KeyEnter).
+			Type("n"). // Return to the fields from the review
+			Press(keyboard.KeyBackspace2).
+			Type("20").
+			Press(keyboard.KeyEnter, keyboard.KeyEnter)
+
+		form := NewForm(&FormOptions[deploymentAnswers]{
+			Writer:  &bytes.Buffer{},
+			Input:   input,
+			Fields:  deploymentFields(),
+			Initial: &deploymentAnswers{Name: "my-app", Region: "eastus", Models: []string{"gpt-4o"}},
+		})
+
+		answers, err := form.Ask()
+		require.NoError(t, err)
+		require.True(t, answers.Advanced)
+		require.Equal(t, "120", answers.Capacity)
+	})
+
+	t.Run("FormValidation", func(t *testing.T) {
+		var buffer bytes.Buffer
+		input := NewScriptedInput().
+			Press(keyboard.KeyEnter, keyboard.KeyEnter, keyboard.KeyEnter, keyboard.KeyEnter, keyboard.KeyEnter).
+			Press(KeyShiftTab). // Back to the models
+			Press(keyboard.KeyArrowDown, keyboard.KeySpace).
+			Press(keyboard.KeyEnter, keyboard.KeyEnter)
+
+		form := NewForm(&FormOptions[deploymentAnswers]{
+			Writer:  &buffer,
+			Input:   input,
+			Fields:  deploymentFields(),
+			Review:  Ptr(false),
+			Initial: &deploymentAnswers{Name: "my-app", Region: "westus", Models: []string{"gpt-4o"}},
+			Validate: func(answers *deploymentAnswers) error {
+				if answers.Region == "westus" && len(answers.Models) < 2 {
+					return errors.New("westus requires at least 2 models")
+				}
+
+				return nil
+			},
+		})
+
+		answers, err := form.Ask()
+		require.NoError(t, err)
+		require.Equal(t, []string{"gpt-4o", "gpt-4o-mini"}, answers.Models)
+		require.Contains(t, buffer.String(), "westus requires at least 2 models")
+		require.NotContains(t, buffer.String(), "Submit?")
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		form := NewForm(&FormOptions[deploymentAnswers]{
+			Writer: &bytes.Buffer{},
+			Input:  NewScriptedInput().Type("my").Press(keyboard.KeyCtrlC),
+			Fields: deploymentFields(),
+		})
+
+		answers, err := form.Ask()
+		require.ErrorIs(t, err, ErrCancelled)
+		require.Nil(t, answers)
+	})
+
+	t.Run("AppendOnly", func(t *testing.T) {
+		SetRenderMode(AppendOnlyRenderMode)
+		t.Cleanup(func() {
+			SetRenderMode(InteractiveRenderMode)
+		})
+
+		_, err := NewForm(&FormOptions[deploymentAnswers]{Fields: deploymentFields()}).Ask()
+		require.EqualError(t, err, "requires --no-prompt value for 'Name'")
+
+		var buffer bytes.Buffer
+		answers, err := NewForm(&FormOptions[deploymentAnswers]{
+			Writer:  &buffer,
+			Title:   "Deploy models",
+			Fields:  deploymentFields(),
+			Initial: &deploymentAnswers{Name: "my-app", Region: "eastus", Models: []string{"gpt-4o"}},
+		}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, "my-app", answers.Name)
+
+		lines := strings.Split(strings.TrimSpace(StripEscapeSequences(buffer.String())), "\n")
+		require.Equal(t, []string{
+			"? Deploy models",
+			"  Name:     my-app",
+			"  API key:",
+			"  Region:   eastus",
+			"  Models:   gpt-4o",
+			"  Advanced: No",
+		}, lines)
+	})
+
+	t.Run("ShiftTabSequence", func(t *testing.T) {
+		events, err := NewReaderInput(strings.NewReader("\x1b[Za")).Open()
+		require.NoError(t, err)
+
+		require.Equal(t, KeyEvent{Key: KeyShiftTab}, <-events)
+		require.Equal(t, KeyEvent{Char: 'a'}, <-events)
+	})
+}
@@ -6,10 +6,8 @@ require (
 	dario.cat/mergo v1.0.1
 	github.com/eiannone/keyboard v0.0.0-20220611211555-0d226195f203
 	github.com/fatih/color v1.17.0
+	github.com/mattn/go-isatty v0.0.20
+	golang.org/x/sys v0.18.0
 )
 
-require (
-	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sys v0.18.0 // indirect
-)
+require github.com/mattn/go-colorable v0.1.13 // indirect
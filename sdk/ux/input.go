@@ -0,0 +1,116 @@
+package ux
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/eiannone/keyboard"
+	"github.com/mattn/go-isatty"
+	"github.com/wbreza/azd-extensions/sdk/ux/internal"
+)
+
+// ErrInputClosed is returned by prompts when the input ends before the prompt completes.
+var ErrInputClosed = internal.ErrInputClosed
+
+// KeyShiftTab is shift+tab, which isn't defined by the keyboard package.
+const KeyShiftTab = internal.KeyShiftTab
+
+// KeyEvent is a single key press. Printable keys set Char, all other keys set Key.
+type KeyEvent = internal.KeyEvent
+
+// InputSource provides the key events read by prompts.
+type InputSource = internal.InputSource
+
+// NewTerminalInput reads key events from the terminal in raw mode.
+func NewTerminalInput() InputSource {
+	return internal.NewTerminalInputSource()
+}
+
+// NewReaderInput decodes key events from a reader such as piped stdin, including the ANSI escape sequences of arrow
+// and navigation keys. Enter is read from "\r", "\n" or "\r\n".
+func NewReaderInput(reader io.Reader) InputSource {
+	return internal.NewReaderInputSource(reader)
+}
+
+// ScriptedInput is an input source for tests that delivers a fixed sequence of key events.
+type ScriptedInput struct {
+	events []KeyEvent
+	source InputSource
+	once   sync.Once
+}
+
+// NewScriptedInput creates an empty script. Add key events with Type and Press before the first prompt reads them.
+func NewScriptedInput() *ScriptedInput {
+	return &ScriptedInput{}
+}
+
+// Type adds a key event for each character of the text.
+func (s *ScriptedInput) Type(text string) *ScriptedInput {
+	for _, char := range text {
+		if char == ' ' {
+			s.events = append(s.events, KeyEvent{Key: keyboard.KeySpace})
+		} else {
+			s.events = append(s.events, KeyEvent{Char: char})
+		}
+	}
+
+	return s
+}
+
+// Press adds a key event for each key.
+func (s *ScriptedInput) Press(keys ...keyboard.Key) *ScriptedInput {
+	for _, key := range keys {
+		s.events = append(s.events, KeyEvent{Key: key})
+	}
+
+	return s
+}
+
+func (s *ScriptedInput) Open() (<-chan KeyEvent, error) {
+	s.once.Do(func() {
+		s.source = internal.NewScriptedInputSource(s.events)
+	})
+
+	return s.source.Open()
+}
+
+func (s *ScriptedInput) Close() error {
+	return nil
+}
+
+// readerInputs shares the input source of each reader across prompts since the decoder buffers the input.
+var readerInputs sync.Map
+
+// resolveInputSource returns the configured input source. Otherwise the terminal is used when the reader is a
+// terminal and key events are decoded from the reader when it is redirected.
+func resolveInputSource(source InputSource, reader io.Reader) InputSource {
+	if source != nil {
+		return source
+	}
+
+	if reader == nil {
+		reader = os.Stdin
+	}
+
+	if file, ok := reader.(*os.File); ok && isTerminal(file) {
+		return NewTerminalInput()
+	}
+
+	// Readers that can't be used as map keys aren't shared
+	if !reflect.TypeOf(reader).Comparable() {
+		return NewReaderInput(reader)
+	}
+
+	if input, has := readerInputs.Load(reader); has {
+		return input.(InputSource)
+	}
+
+	input, _ := readerInputs.LoadOrStore(reader, NewReaderInput(reader))
+	return input.(InputSource)
+}
+
+func isTerminal(file *os.File) bool {
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
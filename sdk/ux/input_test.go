@@ -0,0 +1,91 @@
+package ux
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/eiannone/keyboard"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Input(t *testing.T) {
+	t.Run("ReaderInput", func(t *testing.T) {
+		source := NewReaderInput(strings.NewReader("ab c\x7f\x1b[A\x1b[B\x1bOC\x1b[3~\r\nd\n\x1b"))
+		events, err := source.Open()
+		require.NoError(t, err)
+
+		received := []KeyEvent{}
+		for event := range events {
+			received = append(received, event)
+		}
+
+		require.Equal(t, []KeyEvent{
+			{Char: 'a'},
+			{Char: 'b'},
+			{Key: keyboard.KeySpace},
+			{Char: 'c'},
+			{Key: keyboard.KeyBackspace2},
+			{Key: keyboard.KeyArrowUp},
+			{Key: keyboard.KeyArrowDown},
+			{Key: keyboard.KeyArrowRight},
+			{Key: keyboard.KeyDelete},
+			{Key: keyboard.KeyEnter},
+			{Char: 'd'},
+			{Key: keyboard.KeyEnter},
+			{Key: keyboard.KeyEsc},
+		}, received)
+	})
+
+	t.Run("PipedPrompts", func(t *testing.T) {
+		reader := strings.NewReader("my app\ny\n\x1b[B\n")
+		writer := &bytes.Buffer{}
+
+		name, err := NewPrompt(&PromptOptions{Message: "Name", Reader: reader, Writer: writer}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, "my app", name)
+
+		confirmed, err := NewConfirm(&ConfirmOptions{Message: "Continue", Reader: reader, Writer: writer}).Ask()
+		require.NoError(t, err)
+		require.True(t, *confirmed)
+
+		selected, err := NewSelect(&SelectOptions{
+			Message: "Region",
+			Allowed: []string{"eastus", "westus"},
+			Reader:  reader,
+			Writer:  writer,
+		}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, 1, *selected)
+
+		_, err = NewPrompt(&PromptOptions{Message: "Name", Reader: reader, Writer: writer}).Ask()
+		require.ErrorIs(t, err, ErrInputClosed)
+	})
+
+	t.Run("ScriptedInput", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		input := NewScriptedInput().
+			Type("").
+			Press(keyboard.KeyEnter).
+			Type("hello").
+			Press(keyboard.KeyEnter)
+
+		value, err := NewPrompt(&PromptOptions{
+			Message:  "Greeting",
+			Required: true,
+			Input:    input,
+			Writer:   writer,
+		}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, "hello", value)
+		require.Contains(t, writer.String(), "This field is required")
+
+		_, err = NewPrompt(&PromptOptions{
+			Message: "Cancelled",
+			Input:   NewScriptedInput().Type("abc").Press(keyboard.KeyCtrlC),
+			Writer:  writer,
+		}).Ask()
+		require.ErrorIs(t, err, ErrCancelled)
+		require.Contains(t, writer.String(), "(Cancelled)")
+	})
+}
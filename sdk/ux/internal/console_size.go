@@ -0,0 +1,13 @@
+//go:build !windows && !unix
+
+package internal
+
+import (
+	"errors"
+	"os"
+)
+
+// ConsoleSize returns the number of rows and columns of the terminal the file is attached to.
+func ConsoleSize(file *os.File) (int, int, error) {
+	return 0, 0, errors.New("unsupported OS")
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package internal
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// ConsoleSize returns the number of rows and columns of the terminal the file is attached to.
+func ConsoleSize(file *os.File) (int, int, error) {
+	size, err := unix.IoctlGetWinsize(int(file.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(size.Row), int(size.Col), nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package internal
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// ConsoleSize returns the number of rows and columns of the console the file is attached to.
+func ConsoleSize(file *os.File) (int, int, error) {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(file.Fd()), &info); err != nil {
+		return 0, 0, err
+	}
+
+	rows := int(info.Window.Bottom-info.Window.Top) + 1
+	cols := int(info.Window.Right-info.Window.Left) + 1
+
+	return rows, cols, nil
+}
@@ -21,34 +21,64 @@ func NewCursor(writer io.Writer) Cursor {
 	}
 }
 
+// cursor writes ANSI cursor movement. Nothing is written outside the interactive render mode so the
+// escape codes don't end up in logs.
 type cursor struct {
 	writer io.Writer
 }
 
 func (c *cursor) MoveCursorUp(lines int) {
+	if !IsInteractive() {
+		return
+	}
+
 	fmt.Fprintf(c.writer, "\033[%dA", lines)
 }
 
 func (c *cursor) MoveCursorDown(lines int) {
+	if !IsInteractive() {
+		return
+	}
+
 	fmt.Fprintf(c.writer, "\033[%dB", lines)
 }
 
 func (c *cursor) MoveCursorLeft(columns int) {
+	if !IsInteractive() {
+		return
+	}
+
 	fmt.Fprintf(c.writer, "\033[%dD", columns)
 }
 
 func (c *cursor) MoveCursorRight(columns int) {
+	if !IsInteractive() {
+		return
+	}
+
 	fmt.Fprintf(c.writer, "\033[%dC", columns)
 }
 
 func (c *cursor) MoveCursorToStartOfLine() {
+	if !IsInteractive() {
+		return
+	}
+
 	fmt.Fprint(c.writer, "\r")
 }
 
 func (c *cursor) HideCursor() {
+	if !IsInteractive() {
+		return
+	}
+
 	fmt.Fprint(c.writer, "\033[?25l")
 }
 
 func (c *cursor) ShowCursor() {
+	if !IsInteractive() {
+		return
+	}
+
 	fmt.Fprint(c.writer, "\033[?25h")
 }
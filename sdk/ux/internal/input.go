@@ -1,19 +1,22 @@
 package internal
 
 import (
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 	"unicode"
 
 	"github.com/eiannone/keyboard"
 )
 
+// ErrInterrupted is returned when the user presses Ctrl+C or Ctrl+X or the process receives an interrupt signal.
+var ErrInterrupted = errors.New("interrupted")
+
 type Input struct {
-	cursor  Cursor
-	value   []rune
-	SigChan chan os.Signal
+	cursor Cursor
+	source InputSource
+	value  []rune
 }
 
 type InputEventArgs struct {
@@ -26,12 +29,14 @@ type InputEventArgs struct {
 type InputConfig struct {
 	InitialValue   string
 	IgnoreHintKeys bool
+	// IgnoreSpaceKey prevents the space key from being added to the value, for example when it toggles a selection
+	IgnoreSpaceKey bool
 }
 
-func NewInput() *Input {
+func NewInput(source InputSource) *Input {
 	return &Input{
-		cursor:  NewCursor(os.Stdout),
-		SigChan: make(chan os.Signal),
+		cursor: NewCursor(os.Stdout),
+		source: source,
 	}
 }
 
@@ -39,73 +44,79 @@ func (i *Input) ResetValue() {
 	i.value = []rune{}
 }
 
-func (i *Input) ReadInput(config *InputConfig) (<-chan InputEventArgs, func(), error) {
+// SetValue replaces the current value, for example when the focus moves to another field.
+func (i *Input) SetValue(value string) {
+	i.value = []rune(value)
+}
+
+// ReadInput opens the input source. The returned next function blocks until the next key event and returns
+// ErrInterrupted when the prompt is cancelled or ErrInputClosed when the input ended.
+// The returned done function must be called once the prompt completes.
+func (i *Input) ReadInput(config *InputConfig) (func() (InputEventArgs, error), func(), error) {
 	if config == nil {
 		config = &InputConfig{}
 	}
 
-	inputChan := make(chan InputEventArgs)
-
-	if !keyboard.IsStarted(200 * time.Millisecond) {
-		if err := keyboard.Open(); err != nil {
-			return nil, nil, err
-		}
+	events, err := i.source.Open()
+	if err != nil {
+		return nil, nil, err
 	}
 
+	// Register for SIGINT (Ctrl+C) signal
 	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	done := func() {
 		signal.Stop(sigChan)
-		close(sigChan)
 
-		if err := keyboard.Close(); err != nil {
+		if err := i.source.Close(); err != nil {
 			panic(err)
 		}
 	}
 
-	// Register for SIGINT (Ctrl+C) signal
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		_, ok := <-sigChan
-		if ok {
-			i.SigChan <- os.Interrupt
-		}
-	}()
-
 	i.cursor.ShowCursor()
 	i.value = []rune(config.InitialValue)
 
-	go func() {
-		defer keyboard.Close()
-
-		for {
-			eventArgs := InputEventArgs{}
-			char, key, err := keyboard.GetKey()
-			if err != nil {
-				break
+	next := func() (InputEventArgs, error) {
+		select {
+		case <-sigChan:
+			return InputEventArgs{}, ErrInterrupted
+		case event, ok := <-events:
+			if !ok {
+				return InputEventArgs{}, ErrInputClosed
 			}
 
-			eventArgs.Char = char
-			eventArgs.Key = key
-
-			if len(i.value) > 0 && (key == keyboard.KeyBackspace || key == keyboard.KeyBackspace2) {
-				i.value = i.value[:len(i.value)-1]
-			} else if !config.IgnoreHintKeys && char == '?' {
-				eventArgs.Hint = true
-			} else if !config.IgnoreHintKeys && key == keyboard.KeyEsc {
-				eventArgs.Hint = false
-			} else if key == keyboard.KeySpace {
-				i.value = append(i.value, ' ')
-			} else if unicode.IsPrint(char) {
-				i.value = append(i.value, char)
-			} else if key == keyboard.KeyCtrlC || key == keyboard.KeyCtrlX {
-				i.SigChan <- os.Interrupt
+			if event.Key == keyboard.KeyCtrlC || event.Key == keyboard.KeyCtrlX {
+				return InputEventArgs{}, ErrInterrupted
 			}
 
-			eventArgs.Value = string(i.value)
-			inputChan <- eventArgs
+			return i.handleKey(config, event), nil
 		}
-	}()
+	}
+
+	return next, done, nil
+}
+
+// handleKey applies the key event to the current value.
+func (i *Input) handleKey(config *InputConfig, event KeyEvent) InputEventArgs {
+	eventArgs := InputEventArgs{
+		Char: event.Char,
+		Key:  event.Key,
+	}
+
+	if len(i.value) > 0 && (event.Key == keyboard.KeyBackspace || event.Key == keyboard.KeyBackspace2) {
+		i.value = i.value[:len(i.value)-1]
+	} else if !config.IgnoreHintKeys && event.Char == '?' {
+		eventArgs.Hint = true
+	} else if !config.IgnoreHintKeys && event.Key == keyboard.KeyEsc {
+		eventArgs.Hint = false
+	} else if event.Key == keyboard.KeySpace && !config.IgnoreSpaceKey {
+		i.value = append(i.value, ' ')
+	} else if unicode.IsPrint(event.Char) {
+		i.value = append(i.value, event.Char)
+	}
+
+	eventArgs.Value = string(i.value)
 
-	return inputChan, done, nil
+	return eventArgs
 }
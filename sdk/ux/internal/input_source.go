@@ -0,0 +1,247 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/eiannone/keyboard"
+)
+
+// ErrInputClosed is returned when the input ends before the prompt completes, for example at the end of piped input.
+var ErrInputClosed = errors.New("input closed")
+
+// KeyShiftTab is shift+tab, which isn't defined by the keyboard package. Terminals send it as the ESC [ Z sequence.
+const KeyShiftTab keyboard.Key = 0xFFFF - 64
+
+// KeyEvent is a single key press. Printable keys set Char, all other keys set Key.
+type KeyEvent struct {
+	Char rune
+	Key  keyboard.Key
+}
+
+// InputSource provides the key events read by prompts.
+type InputSource interface {
+	// Open starts reading key events and returns the channel they are delivered on.
+	// The channel is closed when the input ends.
+	Open() (<-chan KeyEvent, error)
+	// Close stops reading key events.
+	Close() error
+}
+
+// NewTerminalInputSource reads key events from the terminal in raw mode.
+func NewTerminalInputSource() InputSource {
+	return &terminalInputSource{}
+}
+
+type terminalInputSource struct {
+	stop chan struct{}
+}
+
+func (s *terminalInputSource) Open() (<-chan KeyEvent, error) {
+	if !keyboard.IsStarted(200 * time.Millisecond) {
+		if err := keyboard.Open(); err != nil {
+			return nil, err
+		}
+	}
+
+	events := make(chan KeyEvent)
+	stop := make(chan struct{})
+	s.stop = stop
+
+	go func() {
+		defer close(events)
+
+		for {
+			char, key, err := keyboard.GetKey()
+			if err != nil {
+				return
+			}
+
+			// The keyboard package reports unknown sequences as ESC followed by the first character of the sequence
+			if key == keyboard.KeyEsc && char == '[' {
+				char, key = 0, KeyShiftTab
+			}
+
+			select {
+			case events <- KeyEvent{Char: char, Key: key}:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (s *terminalInputSource) Close() error {
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+
+	return keyboard.Close()
+}
+
+// NewReaderInputSource decodes key events from the reader, including the ANSI escape sequences of arrow and
+// navigation keys. Keys that haven't been received by a prompt remain available to the next prompt.
+func NewReaderInputSource(reader io.Reader) InputSource {
+	return &readerInputSource{
+		decoder: &keyDecoder{reader: bufio.NewReader(reader)},
+		events:  make(chan KeyEvent),
+	}
+}
+
+type readerInputSource struct {
+	decoder *keyDecoder
+	events  chan KeyEvent
+	once    sync.Once
+}
+
+func (s *readerInputSource) Open() (<-chan KeyEvent, error) {
+	s.once.Do(func() {
+		go func() {
+			defer close(s.events)
+
+			for {
+				event, err := s.decoder.Next()
+				if err != nil {
+					return
+				}
+
+				s.events <- event
+			}
+		}()
+	})
+
+	return s.events, nil
+}
+
+func (s *readerInputSource) Close() error {
+	return nil
+}
+
+// NewScriptedInputSource delivers the key events in order and then ends the input.
+func NewScriptedInputSource(events []KeyEvent) InputSource {
+	channel := make(chan KeyEvent, len(events))
+	for _, event := range events {
+		channel <- event
+	}
+
+	close(channel)
+
+	return &scriptedInputSource{events: channel}
+}
+
+type scriptedInputSource struct {
+	events chan KeyEvent
+}
+
+func (s *scriptedInputSource) Open() (<-chan KeyEvent, error) {
+	return s.events, nil
+}
+
+func (s *scriptedInputSource) Close() error {
+	return nil
+}
+
+// escapeSequences maps the ANSI escape sequences (without the leading ESC) sent by terminals to keys.
+var escapeSequences = map[string]keyboard.Key{
+	"[A":  keyboard.KeyArrowUp,
+	"[B":  keyboard.KeyArrowDown,
+	"[C":  keyboard.KeyArrowRight,
+	"[D":  keyboard.KeyArrowLeft,
+	"OA":  keyboard.KeyArrowUp,
+	"OB":  keyboard.KeyArrowDown,
+	"OC":  keyboard.KeyArrowRight,
+	"OD":  keyboard.KeyArrowLeft,
+	"[H":  keyboard.KeyHome,
+	"[F":  keyboard.KeyEnd,
+	"OH":  keyboard.KeyHome,
+	"OF":  keyboard.KeyEnd,
+	"[1~": keyboard.KeyHome,
+	"[2~": keyboard.KeyInsert,
+	"[3~": keyboard.KeyDelete,
+	"[4~": keyboard.KeyEnd,
+	"[5~": keyboard.KeyPgup,
+	"[6~": keyboard.KeyPgdn,
+	"OP":  keyboard.KeyF1,
+	"OQ":  keyboard.KeyF2,
+	"OR":  keyboard.KeyF3,
+	"OS":  keyboard.KeyF4,
+	"[Z":  KeyShiftTab,
+}
+
+// keyDecoder decodes the bytes written by a terminal into key events.
+type keyDecoder struct {
+	reader  *bufio.Reader
+	afterCR bool
+}
+
+// Next returns the next key event or the error of the reader at the end of the input.
+func (d *keyDecoder) Next() (KeyEvent, error) {
+	for {
+		char, _, err := d.reader.ReadRune()
+		if err != nil {
+			return KeyEvent{}, err
+		}
+
+		afterCR := d.afterCR
+		d.afterCR = char == '\r'
+
+		switch {
+		case char == '\n':
+			// Windows line endings result in a single enter key
+			if afterCR {
+				continue
+			}
+
+			return KeyEvent{Key: keyboard.KeyEnter}, nil
+		case char == '\x1b':
+			return d.escape(), nil
+		case char == ' ':
+			return KeyEvent{Key: keyboard.KeySpace}, nil
+		case char < ' ' || char == '\x7f':
+			return KeyEvent{Key: keyboard.Key(char)}, nil
+		default:
+			return KeyEvent{Char: char}, nil
+		}
+	}
+}
+
+// escape decodes the escape sequence following an ESC byte. Escape sequences are written at once by terminals,
+// so an ESC that isn't followed by buffered input is the escape key.
+func (d *keyDecoder) escape() KeyEvent {
+	if d.reader.Buffered() == 0 {
+		return KeyEvent{Key: keyboard.KeyEsc}
+	}
+
+	prefix, err := d.reader.Peek(1)
+	if err != nil || (prefix[0] != '[' && prefix[0] != 'O') {
+		return KeyEvent{Key: keyboard.KeyEsc}
+	}
+
+	sequence := []byte{}
+	for d.reader.Buffered() > 0 {
+		b, err := d.reader.ReadByte()
+		if err != nil {
+			break
+		}
+
+		sequence = append(sequence, b)
+
+		// The final byte of a control sequence is in the range @ to ~
+		if len(sequence) > 1 && b >= '@' && b <= '~' {
+			break
+		}
+	}
+
+	if key, has := escapeSequences[string(sequence)]; has {
+		return KeyEvent{Key: key}
+	}
+
+	// Unsupported sequences are ignored as a single escape key
+	return KeyEvent{Key: keyboard.KeyEsc}
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mattn/go-isatty"
+	"github.com/wbreza/azd-extensions/sdk/core/ci"
+)
+
+// RenderMode controls whether visuals repaint the terminal or write append-only lines.
+type RenderMode int32
+
+const (
+	// AutoRenderMode is interactive when stdout is a terminal and the process isn't running on CI.
+	AutoRenderMode RenderMode = iota
+	// InteractiveRenderMode repaints visuals in place with cursor movement and prompts for input.
+	InteractiveRenderMode
+	// AppendOnlyRenderMode writes each new line of a visual once without cursor movement and doesn't prompt.
+	AppendOnlyRenderMode
+	// ScreenReaderRenderMode writes the lines that changed since the last update without cursor movement or animation
+	// and prompts for input.
+	ScreenReaderRenderMode
+)
+
+var (
+	renderMode atomic.Int32
+
+	// detectInteractive checks the stdout of the process before it may be redirected by the output format.
+	detectInteractive = sync.OnceValue(func() bool {
+		fd := os.Stdout.Fd()
+		return (isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)) && !ci.IsRunningOnCI()
+	})
+)
+
+// SetRenderMode overrides the render mode of all visuals.
+func SetRenderMode(mode RenderMode) {
+	renderMode.Store(int32(mode))
+}
+
+// CurrentRenderMode returns the render mode, resolving the auto mode from the terminal and CI environment.
+func CurrentRenderMode() RenderMode {
+	mode := RenderMode(renderMode.Load())
+	if mode != AutoRenderMode {
+		return mode
+	}
+
+	if detectInteractive() {
+		return InteractiveRenderMode
+	}
+
+	return AppendOnlyRenderMode
+}
+
+// IsInteractive returns true when visuals repaint the terminal and prompts read input.
+func IsInteractive() bool {
+	return CurrentRenderMode() == InteractiveRenderMode
+}
+
+// CanPrompt returns true when prompts read input from the console.
+func CanPrompt() bool {
+	mode := CurrentRenderMode()
+	return mode == InteractiveRenderMode || mode == ScreenReaderRenderMode
+}
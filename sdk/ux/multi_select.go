@@ -0,0 +1,409 @@
+package ux
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/wbreza/azd-extensions/sdk/ux/internal"
+
+	"dario.cat/mergo"
+	"github.com/eiannone/keyboard"
+)
+
+type MultiSelectOptions struct {
+	// The writer to use for output (default: os.Stdout)
+	Writer io.Writer
+	// The reader to use for input (default: os.Stdin)
+	Reader io.Reader
+	// The source of key events (default: the terminal when Reader is a terminal, otherwise decoded from Reader)
+	Input InputSource
+	// The indexes of the options that are selected by default, also the answer when the render mode can't prompt (default: nil)
+	SelectedIndexes []int
+	// The message to display before the prompt
+	Message string
+	// The available options to display
+	Allowed []string
+	// The optional message to display when the user types ? (default: "")
+	HelpMessage string
+	// The optional hint text that displays below the options (default: "Space to select, → all, ← none, type ? for hint")
+	Hint string
+	// The maximum number of options to display at one time (default: 6)
+	DisplayCount int
+	// Whether or not to display the number prefix before each option (default: false)
+	DisplayNumbers *bool
+	// Whether or not to disable filtering (default: true)
+	EnableFiltering *bool
+	// The minimum number of options that must be selected (default: 0)
+	MinSelections int
+	// The maximum number of options that can be selected, 0 for no limit (default: 0)
+	MaxSelections int
+}
+
+var DefaultMultiSelectOptions MultiSelectOptions = MultiSelectOptions{
+	Writer:          os.Stdout,
+	Reader:          os.Stdin,
+	DisplayCount:    6,
+	EnableFiltering: Ptr(true),
+	DisplayNumbers:  Ptr(false),
+}
+
+// MultiSelect prompts the user to select any number of options.
+// Space toggles the current option, the right arrow selects all visible options and the left arrow clears them.
+type MultiSelect struct {
+	input  *internal.Input
+	cursor internal.Cursor
+	canvas Canvas
+
+	options            *MultiSelectOptions
+	currentIndex       int
+	selected           map[int]bool
+	showHelp           bool
+	complete           bool
+	filter             string
+	choices            []*selectChoice
+	filteredChoices    []*selectChoice
+	hasValidationError bool
+	validationMessage  string
+	cancelled          bool
+	cursorPosition     *CursorPosition
+}
+
+func NewMultiSelect(options *MultiSelectOptions) *MultiSelect {
+	mergedOptions := MultiSelectOptions{}
+	if err := mergo.Merge(&mergedOptions, options, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	if err := mergo.Merge(&mergedOptions, DefaultMultiSelectOptions, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	choices := make([]*selectChoice, len(mergedOptions.Allowed))
+	for index, value := range mergedOptions.Allowed {
+		choices[index] = &selectChoice{
+			Index: index,
+			Value: value,
+		}
+	}
+
+	selected := map[int]bool{}
+	for _, index := range mergedOptions.SelectedIndexes {
+		if index >= 0 && index < len(choices) {
+			selected[index] = true
+		}
+	}
+
+	// Define default hint message
+	if mergedOptions.Hint == "" {
+		mergedOptions.Hint = "Space to select, → all, ← none, type ? for hint"
+	}
+
+	return &MultiSelect{
+		input:           internal.NewInput(resolveInputSource(mergedOptions.Input, mergedOptions.Reader)),
+		cursor:          internal.NewCursor(mergedOptions.Writer),
+		options:         &mergedOptions,
+		selected:        selected,
+		filteredChoices: choices,
+		choices:         choices,
+	}
+}
+
+func (p *MultiSelect) WithCanvas(canvas Canvas) Visual {
+	p.canvas = canvas
+	return p
+}
+
+// Ask displays the prompt and returns the indexes of the selected options in their original order.
+func (p *MultiSelect) Ask() ([]int, error) {
+	if p.canvas == nil {
+		p.canvas = NewCanvas(p).WithWriter(p.options.Writer)
+	}
+
+	if !canReadInput(p.options.Input, p.options.Reader) {
+		p.validate()
+		if p.options.SelectedIndexes == nil || p.hasValidationError {
+			return nil, noPromptValueError(p.options.Message)
+		}
+
+		p.complete = true
+		return p.selectedIndexes(), p.canvas.Run()
+	}
+
+	if err := showPrompt(p.canvas, p); err != nil {
+		return nil, err
+	}
+
+	next, done, err := p.input.ReadInput(&internal.InputConfig{
+		IgnoreSpaceKey: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !*p.options.EnableFiltering {
+		p.cursor.HideCursor()
+	}
+
+	for {
+		msg, err := next()
+		if err != nil {
+			done()
+
+			if errors.Is(err, internal.ErrInterrupted) {
+				p.cancelled = true
+				updatePrompt(p.canvas, p, true)
+				return nil, ErrCancelled
+			}
+
+			return nil, err
+		}
+
+		p.showHelp = msg.Hint
+		p.hasValidationError = false
+
+		if *p.options.EnableFiltering && p.filter != msg.Value {
+			p.filter = msg.Value
+			p.applyFilter()
+		}
+
+		optionCount := len(p.filteredChoices)
+
+		switch msg.Key {
+		case keyboard.KeyArrowUp:
+			if optionCount > 0 {
+				p.currentIndex = (p.currentIndex - 1 + optionCount) % optionCount
+			}
+		case keyboard.KeyArrowDown:
+			if optionCount > 0 {
+				p.currentIndex = (p.currentIndex + 1) % optionCount
+			}
+		case keyboard.KeySpace:
+			if optionCount > 0 {
+				p.toggle(p.filteredChoices[p.currentIndex])
+			}
+		case keyboard.KeyArrowRight:
+			p.selectAll()
+		case keyboard.KeyArrowLeft:
+			for _, option := range p.filteredChoices {
+				delete(p.selected, option.Index)
+			}
+		case keyboard.KeyEnter:
+			p.validate()
+			p.complete = !p.hasValidationError
+		}
+
+		updatePrompt(p.canvas, p, p.complete)
+
+		if p.complete {
+			done()
+			return p.selectedIndexes(), nil
+		}
+	}
+}
+
+// toggle selects or deselects the option unless the maximum number of selections is reached.
+func (p *MultiSelect) toggle(option *selectChoice) {
+	if p.selected[option.Index] {
+		delete(p.selected, option.Index)
+		return
+	}
+
+	if p.options.MaxSelections > 0 && len(p.selected) >= p.options.MaxSelections {
+		p.setValidationError(fmt.Sprintf("Select at most %d %s", p.options.MaxSelections, pluralize("option", p.options.MaxSelections)))
+		return
+	}
+
+	p.selected[option.Index] = true
+}
+
+// selectAll selects the visible options up to the maximum number of selections.
+func (p *MultiSelect) selectAll() {
+	for _, option := range p.filteredChoices {
+		if p.selected[option.Index] {
+			continue
+		}
+
+		p.toggle(option)
+
+		if p.hasValidationError {
+			return
+		}
+	}
+}
+
+func (p *MultiSelect) validate() {
+	p.hasValidationError = false
+	p.validationMessage = ""
+
+	count := len(p.selected)
+
+	if count < p.options.MinSelections {
+		p.setValidationError(fmt.Sprintf("Select at least %d %s", p.options.MinSelections, pluralize("option", p.options.MinSelections)))
+	} else if p.options.MaxSelections > 0 && count > p.options.MaxSelections {
+		p.setValidationError(fmt.Sprintf("Select at most %d %s", p.options.MaxSelections, pluralize("option", p.options.MaxSelections)))
+	}
+}
+
+func (p *MultiSelect) setValidationError(message string) {
+	p.hasValidationError = true
+	p.validationMessage = message
+}
+
+func (p *MultiSelect) selectedIndexes() []int {
+	indexes := []int{}
+	for index := range p.selected {
+		indexes = append(indexes, index)
+	}
+
+	slices.Sort(indexes)
+
+	return indexes
+}
+
+func (p *MultiSelect) applyFilter() {
+	if p.filter == "" {
+		p.filteredChoices = p.choices
+	} else {
+		p.filteredChoices = filterChoices(p.choices, p.filter, *p.options.DisplayNumbers)
+	}
+
+	if p.currentIndex > len(p.filteredChoices)-1 {
+		p.currentIndex = 0
+	}
+}
+
+func (p *MultiSelect) Render(printer Printer) error {
+	indent := "  "
+
+	p.renderMessage(printer)
+
+	if p.complete || p.cancelled {
+		return nil
+	}
+
+	p.renderOptions(printer, indent)
+	p.renderValidation(printer)
+	p.renderFooter(printer)
+
+	if p.cursorPosition != nil {
+		printer.SetCursorPosition(*p.cursorPosition)
+	}
+
+	return nil
+}
+
+func (p *MultiSelect) renderMessage(printer Printer) {
+	theme := activeTheme()
+
+	printer.Fprintf(theme.Palette.Accent.Sprintf("%s ", theme.Glyphs.Question))
+
+	// Message
+	printer.Fprintf(BoldString("%s: ", p.options.Message))
+
+	// Cancelled
+	if p.cancelled {
+		printer.Fprintf(theme.Palette.Error.Sprint("(Cancelled)"))
+	}
+
+	// Selected Values
+	if !p.cancelled && p.complete {
+		values := []string{}
+		for _, index := range p.selectedIndexes() {
+			values = append(values, p.choices[index].Value)
+		}
+
+		if len(values) == 0 {
+			printer.Fprintf(theme.Palette.Muted.Sprint("(None)"))
+		} else {
+			printer.Fprintf(theme.Palette.Accent.Sprint(strings.Join(values, ", ")))
+		}
+	}
+
+	printer.Fprintln()
+
+	// Filter
+	if !p.cancelled && !p.complete && *p.options.EnableFiltering {
+		printer.Fprintln()
+		printer.Fprintf("  Filter: ")
+
+		if p.filter == "" {
+			p.cursorPosition = Ptr(printer.CursorPosition())
+			printer.Fprintf(theme.Palette.Muted.Sprint("Type to filter list"))
+		} else {
+			printer.Fprintf(p.filter)
+			p.cursorPosition = Ptr(printer.CursorPosition())
+		}
+
+		printer.Fprintln()
+		printer.Fprintln()
+	}
+}
+
+func (p *MultiSelect) renderOptions(printer Printer, indent string) {
+	theme := activeTheme()
+
+	start, end := displayRange(p.currentIndex, len(p.filteredChoices), p.options.DisplayCount)
+	digitWidth := len(fmt.Sprintf("%d", len(p.choices))) // Calculate the width of the digit prefix
+
+	renderChoices(printer, indent, p.filteredChoices, start, end, func(index int, option *selectChoice) string {
+		displayValue := formatChoice(option, p.filter, *p.options.DisplayNumbers, digitWidth)
+
+		checkbox := theme.Glyphs.Unchecked
+		if p.selected[option.Index] {
+			checkbox = theme.Palette.Accent.Sprint(theme.Glyphs.Checked)
+		}
+
+		if index == p.currentIndex {
+			return fmt.Sprintf("%s %s %s", theme.Palette.Accent.Sprint(theme.Glyphs.Pointer), checkbox, theme.Palette.Accent.Sprint(displayValue))
+		}
+
+		return fmt.Sprintf("  %s %s", checkbox, displayValue)
+	})
+}
+
+func (p *MultiSelect) renderValidation(printer Printer) {
+	theme := activeTheme()
+
+	if len(p.filteredChoices) == 0 {
+		printer.Fprintln(theme.Palette.Warning.Sprint("No options found matching the filter"))
+	}
+
+	// Validation error
+	if !p.showHelp && p.hasValidationError {
+		printer.Fprintln(theme.Palette.Warning.Sprint(p.validationMessage))
+	}
+
+	// Hint
+	if p.showHelp && p.options.HelpMessage != "" {
+		printer.Fprintln()
+		printer.Fprintf(
+			theme.Palette.Hint.Sprintf("%s %s\n",
+				BoldString("Hint:"),
+				p.options.HelpMessage,
+			),
+		)
+	}
+}
+
+func (p *MultiSelect) renderFooter(printer Printer) {
+	theme := activeTheme()
+
+	count := len(p.selected)
+
+	printer.Fprintln()
+	printer.Fprintln(theme.Palette.Muted.Sprint(theme.Glyphs.Separator))
+	printer.Fprintln(theme.Palette.Muted.Sprintf("%d of %d selected", count, len(p.choices)))
+	printer.Fprintln(theme.Palette.Muted.Sprint(p.options.Hint))
+}
+
+func pluralize(word string, count int) string {
+	if count == 1 {
+		return word
+	}
+
+	return word + "s"
+}
@@ -0,0 +1,97 @@
+package ux
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eiannone/keyboard"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MultiSelect(t *testing.T) {
+	allowed := []string{"eastus", "eastus2", "westus", "westus2", "centralus"}
+
+	t.Run("Toggle", func(t *testing.T) {
+		selected, err := NewMultiSelect(&MultiSelectOptions{
+			Message:         "Select regions",
+			Allowed:         allowed,
+			SelectedIndexes: []int{4},
+			Writer:          &bytes.Buffer{},
+			Input: NewScriptedInput().
+				Press(keyboard.KeyArrowDown, keyboard.KeySpace).
+				Press(keyboard.KeyArrowDown, keyboard.KeySpace, keyboard.KeySpace).
+				Press(keyboard.KeyArrowUp, keyboard.KeyArrowUp, keyboard.KeySpace, keyboard.KeyEnter),
+		}).Ask()
+
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 4}, selected)
+	})
+
+	t.Run("SelectAllFiltered", func(t *testing.T) {
+		selected, err := NewMultiSelect(&MultiSelectOptions{
+			Message: "Select regions",
+			Allowed: allowed,
+			Writer:  &bytes.Buffer{},
+			Input: NewScriptedInput().
+				Type("west").
+				Press(keyboard.KeyArrowRight, keyboard.KeyEnter),
+		}).Ask()
+
+		require.NoError(t, err)
+		require.Equal(t, []int{2, 3}, selected)
+	})
+
+	t.Run("SelectNone", func(t *testing.T) {
+		selected, err := NewMultiSelect(&MultiSelectOptions{
+			Message:         "Select regions",
+			Allowed:         allowed,
+			SelectedIndexes: []int{0, 1, 2},
+			Writer:          &bytes.Buffer{},
+			Input:           NewScriptedInput().Press(keyboard.KeyArrowLeft, keyboard.KeyEnter),
+		}).Ask()
+
+		require.NoError(t, err)
+		require.Empty(t, selected)
+	})
+
+	t.Run("MinSelections", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		selected, err := NewMultiSelect(&MultiSelectOptions{
+			Message:       "Select regions",
+			Allowed:       allowed,
+			MinSelections: 1,
+			Writer:        writer,
+			Input:         NewScriptedInput().Press(keyboard.KeyEnter, keyboard.KeySpace, keyboard.KeyEnter),
+		}).Ask()
+
+		require.NoError(t, err)
+		require.Equal(t, []int{0}, selected)
+		require.Contains(t, writer.String(), "Select at least 1 option")
+	})
+
+	t.Run("MaxSelections", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		selected, err := NewMultiSelect(&MultiSelectOptions{
+			Message:       "Select regions",
+			Allowed:       allowed,
+			MaxSelections: 2,
+			Writer:        writer,
+			Input:         NewScriptedInput().Press(keyboard.KeyArrowRight, keyboard.KeyEnter),
+		}).Ask()
+
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1}, selected)
+		require.Contains(t, writer.String(), "Select at most 2 options")
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		_, err := NewMultiSelect(&MultiSelectOptions{
+			Message: "Select regions",
+			Allowed: allowed,
+			Writer:  &bytes.Buffer{},
+			Input:   NewScriptedInput().Press(keyboard.KeySpace, keyboard.KeyCtrlC),
+		}).Ask()
+
+		require.ErrorIs(t, err, ErrCancelled)
+	})
+}
@@ -29,6 +29,13 @@ type Printer interface {
 	CursorPosition() CursorPosition
 	SetCursorPosition(position CursorPosition)
 	Size() CanvasSize
+	ConsoleSize() CanvasSize
+}
+
+// SizedWriter is a writer that reports the size of the console it writes to, such as a virtual terminal in tests.
+type SizedWriter interface {
+	io.Writer
+	Size() CanvasSize
 }
 
 func NewPrinter(writer io.Writer) Printer {
@@ -63,6 +70,12 @@ func (p *printer) Size() CanvasSize {
 	return *p.size
 }
 
+// ConsoleSize returns the size of the console the printer writes to.
+// Rows and Cols are 0 when the size is unknown, for example when the output is redirected to a file.
+func (p *printer) ConsoleSize() CanvasSize {
+	return consoleSize(p.writer)
+}
+
 func (p *printer) CursorPosition() CursorPosition {
 	cursorPosition := CursorPosition{
 		Row: p.size.Rows,
@@ -169,3 +182,18 @@ func (p *printer) ClearCanvas() {
 func (p *printer) ClearLine() {
 	fmt.Fprint(p.writer, "\033[2K\r")
 }
+
+func consoleSize(writer io.Writer) CanvasSize {
+	if sizedWriter, ok := writer.(SizedWriter); ok {
+		return sizedWriter.Size()
+	}
+
+	if file, ok := writer.(*os.File); ok && isTerminal(file) {
+		rows, cols, err := internal.ConsoleSize(file)
+		if err == nil {
+			return CanvasSize{Rows: rows, Cols: cols}
+		}
+	}
+
+	return CanvasSize{}
+}
@@ -0,0 +1,520 @@
+package ux
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dario.cat/mergo"
+	"github.com/fatih/color"
+	"github.com/wbreza/azd-extensions/sdk/ux/internal"
+)
+
+// ProgressUnit is the unit of the work tracked by a progress bar.
+type ProgressUnit int
+
+const (
+	// ItemsUnit tracks a number of items such as documents or chunks.
+	ItemsUnit ProgressUnit = iota
+	// BytesUnit tracks a number of bytes and displays sizes such as 1.5 MB.
+	BytesUnit
+)
+
+// appendPercentStep is the step in percent between the lines written by progress bars in the append only render mode.
+const appendPercentStep = 10
+
+type ProgressBarOptions struct {
+	// The writer to use for output (default: os.Stdout)
+	Writer io.Writer
+	// The text displayed before the bar (default: "")
+	Title string
+	// The total amount of work, 0 when the total isn't known yet (default: 0)
+	Total int64
+	// The unit of the work (default: ItemsUnit)
+	Unit ProgressUnit
+	// The name of the items displayed after the counts such as "chunks" (default: "")
+	ItemName string
+	// The width of the bar in columns (default: 30)
+	Width int
+	// The interval between updates when the progress bar is started (default: 250ms)
+	Interval time.Duration
+	// Whether the progress bar is removed when stopped (default: false)
+	ClearOnStop bool
+}
+
+var DefaultProgressBarOptions ProgressBarOptions = ProgressBarOptions{
+	Writer:   os.Stdout,
+	Width:    30,
+	Interval: 250 * time.Millisecond,
+}
+
+// ProgressBar displays determinate progress with the throughput and the estimated time remaining.
+// Progress bars are safe to update from multiple goroutines.
+type ProgressBar struct {
+	canvas Canvas
+
+	cursor      internal.Cursor
+	options     *ProgressBarOptions
+	current     int64
+	total       int64
+	running     int32
+	clear       bool
+	title       string
+	startTime   *time.Time
+	endTime     *time.Time
+	now         func() time.Time
+	mutex       sync.Mutex
+	canvasMutex sync.Mutex
+}
+
+func NewProgressBar(options *ProgressBarOptions) *ProgressBar {
+	mergedOptions := ProgressBarOptions{}
+
+	if options == nil {
+		options = &ProgressBarOptions{}
+	}
+
+	if err := mergo.Merge(&mergedOptions, options, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	if err := mergo.Merge(&mergedOptions, DefaultProgressBarOptions, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	return &ProgressBar{
+		options: &mergedOptions,
+		total:   mergedOptions.Total,
+		title:   mergedOptions.Title,
+		cursor:  internal.NewCursor(mergedOptions.Writer),
+		now:     time.Now,
+	}
+}
+
+func (p *ProgressBar) WithCanvas(canvas Canvas) Visual {
+	p.canvasMutex.Lock()
+	defer p.canvasMutex.Unlock()
+
+	if canvas != nil {
+		p.canvas = canvas
+	}
+
+	return p
+}
+
+// Add increases the completed amount of work.
+func (p *ProgressBar) Add(delta int64) {
+	p.ensureStartTime()
+	atomic.AddInt64(&p.current, delta)
+}
+
+// SetCurrent sets the completed amount of work.
+func (p *ProgressBar) SetCurrent(current int64) {
+	p.ensureStartTime()
+	atomic.StoreInt64(&p.current, current)
+}
+
+// SetTotal sets the total amount of work once it's known.
+func (p *ProgressBar) SetTotal(total int64) {
+	atomic.StoreInt64(&p.total, total)
+}
+
+// SetTitle changes the text displayed before the bar.
+func (p *ProgressBar) SetTitle(title string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.title = title
+}
+
+// Current returns the completed amount of work.
+func (p *ProgressBar) Current() int64 {
+	return atomic.LoadInt64(&p.current)
+}
+
+// Total returns the total amount of work.
+func (p *ProgressBar) Total() int64 {
+	return atomic.LoadInt64(&p.total)
+}
+
+// Reader returns a reader that adds the bytes read from the reader to the progress.
+func (p *ProgressBar) Reader(reader io.Reader) io.Reader {
+	return &progressReader{reader: reader, progressBar: p}
+}
+
+func (p *ProgressBar) Start(ctx context.Context) error {
+	p.ensureCanvas()
+	p.ensureStartTime()
+
+	p.clear = false
+	atomic.StoreInt32(&p.running, 1)
+	p.cursor.HideCursor()
+
+	go refresh(&p.running, p.options.Interval, p.update)
+
+	return p.run()
+}
+
+func (p *ProgressBar) Stop(ctx context.Context) error {
+	p.ensureCanvas()
+
+	atomic.StoreInt32(&p.running, 0)
+	p.cursor.ShowCursor()
+
+	p.mutex.Lock()
+	p.endTime = Ptr(p.now())
+	p.mutex.Unlock()
+
+	p.clear = p.options.ClearOnStop
+
+	return p.update()
+}
+
+// Run displays the progress bar while the task runs.
+func (p *ProgressBar) Run(ctx context.Context, task func(context.Context, *ProgressBar) error) error {
+	if err := p.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = p.Stop(ctx)
+	}()
+
+	return task(ctx, p)
+}
+
+func (p *ProgressBar) Render(printer Printer) error {
+	if p.clear {
+		return nil
+	}
+
+	printer.Fprintf("%s\n", p.line(0))
+
+	return nil
+}
+
+// line formats the progress bar with the title padded to the title width.
+func (p *ProgressBar) line(titleWidth int) string {
+	p.mutex.Lock()
+	title := p.title
+	startTime := p.startTime
+	endTime := p.endTime
+	now := p.now()
+	p.mutex.Unlock()
+
+	current := p.Current()
+	total := p.Total()
+	parts := []string{}
+
+	if !IsInteractive() {
+		return p.appendLine(title, titleWidth, current, total, endTime != nil)
+	}
+
+	if title != "" || titleWidth > 0 {
+		parts = append(parts, AlignText(title, titleWidth, AlignLeft))
+	}
+
+	if total > 0 {
+		ratio := min(1, max(0, float64(current)/float64(total)))
+		filled := int(ratio * float64(p.options.Width))
+
+		parts = append(parts,
+			color.CyanString(strings.Repeat("█", filled))+
+				color.HiBlackString(strings.Repeat("░", p.options.Width-filled)),
+			fmt.Sprintf("%3d%%", int(ratio*100)),
+		)
+	}
+
+	parts = append(parts, p.formatCounts(current, total))
+
+	if startTime != nil {
+		if endTime != nil {
+			now = *endTime
+		}
+
+		elapsed := now.Sub(*startTime)
+		if elapsed > 0 && current > 0 {
+			rate := float64(current) / elapsed.Seconds()
+			parts = append(parts, color.HiBlackString("%s/s", p.formatAmount(rate)))
+
+			if total > 0 && current < total {
+				remaining := time.Duration(float64(total-current) / rate * float64(time.Second))
+				parts = append(parts, color.HiBlackString("ETA %s", durationAsText(remaining)))
+			}
+		}
+	}
+
+	return strings.Join(parts, "  ")
+}
+
+// appendLine formats the progress bar for the append only render mode. The percentage is rounded down to a multiple of
+// appendPercentStep so a new line is only written as the progress passes each step. Counts are only written once stopped
+// when the total is unknown.
+func (p *ProgressBar) appendLine(title string, titleWidth int, current int64, total int64, stopped bool) string {
+	parts := []string{}
+
+	if title != "" || titleWidth > 0 {
+		parts = append(parts, AlignText(title, titleWidth, AlignLeft))
+	}
+
+	if total > 0 {
+		percent := int(min(1, max(0, float64(current)/float64(total))) * 100)
+		parts = append(parts, fmt.Sprintf("%d%%", percent-percent%appendPercentStep))
+	} else if stopped {
+		parts = append(parts, p.formatCounts(current, total))
+	}
+
+	return strings.Join(parts, "  ")
+}
+
+func (p *ProgressBar) formatCounts(current int64, total int64) string {
+	if p.options.Unit == BytesUnit {
+		if total > 0 {
+			return fmt.Sprintf("%s / %s", formatBytes(float64(current)), formatBytes(float64(total)))
+		}
+
+		return formatBytes(float64(current))
+	}
+
+	counts := fmt.Sprintf("%d", current)
+	if total > 0 {
+		counts = fmt.Sprintf("%d/%d", current, total)
+	}
+
+	if p.options.ItemName != "" {
+		counts = fmt.Sprintf("%s %s", counts, p.options.ItemName)
+	}
+
+	return counts
+}
+
+func (p *ProgressBar) formatAmount(amount float64) string {
+	if p.options.Unit == BytesUnit {
+		return formatBytes(amount)
+	}
+
+	if p.options.ItemName != "" {
+		return fmt.Sprintf("%.1f %s", amount, p.options.ItemName)
+	}
+
+	return fmt.Sprintf("%.1f", amount)
+}
+
+func (p *ProgressBar) ensureStartTime() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.startTime == nil {
+		p.startTime = Ptr(p.now())
+	}
+}
+
+func (p *ProgressBar) ensureCanvas() {
+	p.canvasMutex.Lock()
+	defer p.canvasMutex.Unlock()
+
+	if p.canvas == nil {
+		p.canvas = NewCanvas(p).WithWriter(p.options.Writer)
+	}
+}
+
+func (p *ProgressBar) update() error {
+	p.canvasMutex.Lock()
+	defer p.canvasMutex.Unlock()
+
+	if p.canvas == nil {
+		return nil
+	}
+
+	return p.canvas.Update()
+}
+
+func (p *ProgressBar) run() error {
+	p.canvasMutex.Lock()
+	defer p.canvasMutex.Unlock()
+
+	if p.canvas == nil {
+		return nil
+	}
+
+	return p.canvas.Run()
+}
+
+type ProgressGroupOptions struct {
+	// The writer to use for output (default: os.Stdout)
+	Writer io.Writer
+	// The interval between updates when the group is started (default: 250ms)
+	Interval time.Duration
+	// Whether the progress bars are removed when stopped (default: false)
+	ClearOnStop bool
+}
+
+var DefaultProgressGroupOptions ProgressGroupOptions = ProgressGroupOptions{
+	Writer:   os.Stdout,
+	Interval: 250 * time.Millisecond,
+}
+
+// ProgressGroup stacks multiple progress bars in a single canvas with their titles aligned.
+type ProgressGroup struct {
+	canvas Canvas
+
+	cursor  internal.Cursor
+	options *ProgressGroupOptions
+	bars    []*ProgressBar
+	running int32
+	clear   bool
+	mutex   sync.Mutex
+}
+
+func NewProgressGroup(options *ProgressGroupOptions) *ProgressGroup {
+	mergedOptions := ProgressGroupOptions{}
+
+	if options == nil {
+		options = &ProgressGroupOptions{}
+	}
+
+	if err := mergo.Merge(&mergedOptions, options, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	if err := mergo.Merge(&mergedOptions, DefaultProgressGroupOptions, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	return &ProgressGroup{
+		options: &mergedOptions,
+		cursor:  internal.NewCursor(mergedOptions.Writer),
+	}
+}
+
+// AddBar adds a progress bar to the bottom of the group. The bar is displayed by the group and isn't started itself.
+func (g *ProgressGroup) AddBar(options *ProgressBarOptions) *ProgressBar {
+	progressBar := NewProgressBar(options)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.bars = append(g.bars, progressBar)
+
+	return progressBar
+}
+
+func (g *ProgressGroup) WithCanvas(canvas Canvas) Visual {
+	g.canvas = canvas
+	return g
+}
+
+func (g *ProgressGroup) Start(ctx context.Context) error {
+	if g.canvas == nil {
+		g.canvas = NewCanvas(g).WithWriter(g.options.Writer)
+	}
+
+	g.clear = false
+	atomic.StoreInt32(&g.running, 1)
+	g.cursor.HideCursor()
+
+	go refresh(&g.running, g.options.Interval, g.canvas.Update)
+
+	return g.canvas.Run()
+}
+
+func (g *ProgressGroup) Stop(ctx context.Context) error {
+	if g.canvas == nil {
+		return nil
+	}
+
+	atomic.StoreInt32(&g.running, 0)
+	g.cursor.ShowCursor()
+
+	g.mutex.Lock()
+	for _, progressBar := range g.bars {
+		progressBar.mutex.Lock()
+		if progressBar.endTime == nil {
+			progressBar.endTime = Ptr(progressBar.now())
+		}
+		progressBar.mutex.Unlock()
+	}
+	g.mutex.Unlock()
+
+	g.clear = g.options.ClearOnStop
+
+	return g.canvas.Update()
+}
+
+// Run displays the progress bars while the task runs.
+func (g *ProgressGroup) Run(ctx context.Context, task func(context.Context, *ProgressGroup) error) error {
+	if err := g.Start(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = g.Stop(ctx)
+	}()
+
+	return task(ctx, g)
+}
+
+func (g *ProgressGroup) Render(printer Printer) error {
+	if g.clear {
+		return nil
+	}
+
+	g.mutex.Lock()
+	bars := append([]*ProgressBar{}, g.bars...)
+	g.mutex.Unlock()
+
+	titleWidth := 0
+	for _, progressBar := range bars {
+		progressBar.mutex.Lock()
+		titleWidth = max(titleWidth, VisibleWidth(progressBar.title))
+		progressBar.mutex.Unlock()
+	}
+
+	for _, progressBar := range bars {
+		printer.Fprintf("%s\n", progressBar.line(titleWidth))
+	}
+
+	return nil
+}
+
+// refresh calls update at the interval while running is set.
+func refresh(running *int32, interval time.Duration, update func() error) {
+	for atomic.LoadInt32(running) == 1 {
+		_ = update()
+		time.Sleep(interval)
+	}
+}
+
+type progressReader struct {
+	reader      io.Reader
+	progressBar *ProgressBar
+}
+
+func (r *progressReader) Read(buffer []byte) (int, error) {
+	count, err := r.reader.Read(buffer)
+	r.progressBar.Add(int64(count))
+
+	return count, err
+}
+
+// formatBytes formats the size with a binary unit such as 1.5 MB.
+func formatBytes(size float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	unit := 0
+
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%.0f %s", size, units[unit])
+	}
+
+	return fmt.Sprintf("%.1f %s", size, units[unit])
+}
@@ -0,0 +1,118 @@
+package ux
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ProgressBar(t *testing.T) {
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newClock := func() (func() time.Time, func(time.Duration)) {
+		now := startTime
+		return func() time.Time { return now }, func(duration time.Duration) { now = now.Add(duration) }
+	}
+
+	t.Run("Items", func(t *testing.T) {
+		clock, advance := newClock()
+		progressBar := NewProgressBar(&ProgressBarOptions{
+			Title:    "Embedding",
+			Total:    10,
+			ItemName: "chunks",
+			Width:    10,
+		})
+		progressBar.now = clock
+
+		progressBar.Add(0)
+		advance(2 * time.Second)
+		progressBar.Add(4)
+
+		require.Equal(t,
+			"Embedding  ████░░░░░░   40%  4/10 chunks  2.0 chunks/s  ETA 3 seconds",
+			StripEscapeSequences(progressBar.line(0)),
+		)
+
+		advance(3 * time.Second)
+		progressBar.SetCurrent(10)
+
+		require.Equal(t,
+			"Embedding  ██████████  100%  10/10 chunks  2.0 chunks/s",
+			StripEscapeSequences(progressBar.line(0)),
+		)
+	})
+
+	t.Run("Bytes", func(t *testing.T) {
+		clock, advance := newClock()
+		progressBar := NewProgressBar(&ProgressBarOptions{
+			Total: 4 * 1024 * 1024,
+			Unit:  BytesUnit,
+			Width: 4,
+		})
+		progressBar.now = clock
+
+		reader := progressBar.Reader(strings.NewReader(strings.Repeat("x", 1024*1024)))
+		progressBar.Add(0)
+		advance(time.Second)
+
+		_, err := io.Copy(io.Discard, reader)
+		require.NoError(t, err)
+
+		require.Equal(t,
+			"█░░░   25%  1.0 MB / 4.0 MB  1.0 MB/s  ETA 3 seconds",
+			StripEscapeSequences(progressBar.line(0)),
+		)
+	})
+
+	t.Run("UnknownTotal", func(t *testing.T) {
+		progressBar := NewProgressBar(&ProgressBarOptions{Title: "Scanning"})
+		progressBar.Add(12)
+
+		require.True(t, strings.HasPrefix(StripEscapeSequences(progressBar.line(0)), "Scanning  12"))
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		clock, _ := newClock()
+		group := NewProgressGroup(nil)
+
+		first := group.AddBar(&ProgressBarOptions{Title: "a.pdf", Total: 2, Width: 2})
+		second := group.AddBar(&ProgressBarOptions{Title: "report.docx", Total: 4, Width: 2})
+		first.now = clock
+		second.now = clock
+
+		first.SetCurrent(1)
+
+		writer := &bytes.Buffer{}
+		require.NoError(t, group.Render(NewPrinter(writer)))
+
+		require.Equal(t, ""+
+			"a.pdf        █░   50%  1/2\n"+
+			"report.docx  ░░    0%  0/4\n",
+			StripEscapeSequences(writer.String()),
+		)
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		progressBar := NewProgressBar(&ProgressBarOptions{
+			Writer:   writer,
+			Total:    3,
+			Interval: time.Millisecond,
+		})
+
+		err := progressBar.Run(context.Background(), func(ctx context.Context, progressBar *ProgressBar) error {
+			for i := 0; i < 3; i++ {
+				progressBar.Add(1)
+			}
+
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.Contains(t, StripEscapeSequences(writer.String()), "100%  3/3")
+	})
+}
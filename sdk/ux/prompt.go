@@ -1,14 +1,16 @@
 package ux
 
 import (
+	"errors"
 	"io"
 	"os"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/wbreza/azd-extensions/sdk/ux/internal"
 
 	"dario.cat/mergo"
 	"github.com/eiannone/keyboard"
-	"github.com/fatih/color"
 )
 
 type PromptOptions struct {
@@ -16,7 +18,9 @@ type PromptOptions struct {
 	Writer io.Writer
 	// The reader to use for input (default: os.Stdin)
 	Reader io.Reader
-	// The default value to use for the prompt (default: "")
+	// The source of key events (default: the terminal when Reader is a terminal, otherwise decoded from Reader)
+	Input InputSource
+	// The default value to use for the prompt, also the answer when the render mode can't prompt (default: "")
 	DefaultValue string
 	// The message to display before the prompt
 	Message string
@@ -38,6 +42,9 @@ type PromptOptions struct {
 	ClearOnCompletion bool
 	// Whether or not to capture hint keys (default: true)
 	IgnoreHintKeys bool
+	// Whether or not the value is a secret such as a key or password. Secrets are masked while typing and the value is
+	// never rendered. Hint keys aren't captured so any character can be typed (default: false)
+	Secret bool
 }
 
 var DefaultPromptOptions PromptOptions = PromptOptions{
@@ -71,16 +78,16 @@ type Prompt struct {
 
 func NewPrompt(options *PromptOptions) *Prompt {
 	mergedOptions := PromptOptions{}
-	if err := mergo.Merge(&mergedOptions, DefaultPromptOptions, mergo.WithoutDereference); err != nil {
+	if err := mergo.Merge(&mergedOptions, options, mergo.WithoutDereference); err != nil {
 		panic(err)
 	}
 
-	if err := mergo.Merge(&mergedOptions, options, mergo.WithoutDereference); err != nil {
+	if err := mergo.Merge(&mergedOptions, DefaultPromptOptions, mergo.WithoutDereference); err != nil {
 		panic(err)
 	}
 
 	return &Prompt{
-		input:   internal.NewInput(),
+		input:   internal.NewInput(resolveInputSource(mergedOptions.Input, mergedOptions.Reader)),
 		options: &mergedOptions,
 		value:   mergedOptions.DefaultValue,
 	}
@@ -117,84 +124,102 @@ func (p *Prompt) Ask() (string, error) {
 		p.canvas = NewCanvas(p).WithWriter(p.options.Writer)
 	}
 
-	if err := p.canvas.Run(); err != nil {
+	if !canReadInput(p.options.Input, p.options.Reader) {
+		if p.options.DefaultValue == "" {
+			return "", noPromptValueError(p.options.Message)
+		}
+
+		p.complete = true
+		return p.value, p.canvas.Run()
+	}
+
+	if err := showPrompt(p.canvas, p); err != nil {
 		return "", err
 	}
 
 	inputOptions := &internal.InputConfig{
 		InitialValue:   p.options.DefaultValue,
-		IgnoreHintKeys: p.options.IgnoreHintKeys,
+		IgnoreHintKeys: p.options.IgnoreHintKeys || p.options.Secret,
 	}
-	input, done, err := p.input.ReadInput(inputOptions)
+	next, done, err := p.input.ReadInput(inputOptions)
 	if err != nil {
 		return "", err
 	}
 
 	for {
-		select {
-		case <-p.input.SigChan:
-			p.cancelled = true
+		msg, err := next()
+		if err != nil {
 			done()
-			p.canvas.Update()
-			return "", ErrCancelled
 
-		case msg := <-input:
-			p.showHelp = msg.Hint
-			p.value = msg.Value
+			if errors.Is(err, internal.ErrInterrupted) {
+				p.cancelled = true
+				updatePrompt(p.canvas, p, true)
+				return "", ErrCancelled
+			}
 
-			p.validate()
+			return "", err
+		}
 
-			if msg.Key == keyboard.KeyEnter {
-				p.submitted = true
+		p.showHelp = msg.Hint
+		p.value = msg.Value
 
-				if !p.hasValidationError {
-					p.complete = true
-				}
-			}
+		p.validate()
 
-			p.canvas.Update()
+		if msg.Key == keyboard.KeyEnter {
+			p.submitted = true
 
-			if p.complete {
-				done()
-				return p.value, nil
+			if !p.hasValidationError {
+				p.complete = true
 			}
 		}
+
+		updatePrompt(p.canvas, p, p.complete)
+
+		if p.complete {
+			done()
+			return p.value, nil
+		}
 	}
 }
 
 func (p *Prompt) Render(printer Printer) error {
+	theme := activeTheme()
+
 	if p.options.ClearOnCompletion && p.complete {
 		return nil
 	}
 
-	printer.Fprintf(color.CyanString("? "))
+	printer.Fprintf(theme.Palette.Accent.Sprintf("%s ", theme.Glyphs.Question))
 
 	// Message
 	printer.Fprintf(BoldString("%s: ", p.options.Message))
 
 	// Cancelled
 	if p.cancelled {
-		printer.Fprintln(color.HiRedString("(Cancelled)"))
+		printer.Fprintln(theme.Palette.Error.Sprint("(Cancelled)"))
 		return nil
 	}
 
 	// Hint (Only show when a help message has been defined)
 	if !p.complete && p.options.Hint != "" && p.options.HelpMessage != "" {
-		printer.Fprintf("%s ", color.CyanString(p.options.Hint))
+		printer.Fprintf("%s ", theme.Palette.Accent.Sprint(p.options.Hint))
 	}
 
 	// Placeholder
 	if p.value == "" && p.options.PlaceHolder != "" {
 		p.cursorPosition = Ptr(printer.CursorPosition())
-		printer.Fprintf(color.HiBlackString(p.options.PlaceHolder))
+		printer.Fprintf(theme.Palette.Muted.Sprint(p.options.PlaceHolder))
 	}
 
 	// Value
 	if p.value != "" {
 		valueOutput := p.value
+		if p.options.Secret {
+			valueOutput = maskSecret(p.value)
+		}
 
 		if p.complete || p.value == p.options.DefaultValue {
-			valueOutput = color.CyanString(p.value)
+			valueOutput = theme.Palette.Accent.Sprint(valueOutput)
 		}
 
 		printer.Fprintf(valueOutput)
@@ -210,14 +235,14 @@ func (p *Prompt) Render(printer Printer) error {
 	// Validation error
 	if !p.showHelp && p.submitted && p.hasValidationError {
 		printer.Fprintln()
-		printer.Fprintln(color.YellowString(p.validationMessage))
+		printer.Fprintln(theme.Palette.Warning.Sprint(p.validationMessage))
 	}
 
 	// Hint
 	if p.showHelp && p.options.HelpMessage != "" {
 		printer.Fprintln()
 		printer.Fprintf(
-			color.HiMagentaString("%s %s\n",
+			theme.Palette.Hint.Sprintf("%s %s\n",
 				BoldString("Hint:"),
 				p.options.HelpMessage,
 			),
@@ -231,3 +256,8 @@ func (p *Prompt) Render(printer Printer) error {
 
 	return nil
 }
+
+// maskSecret returns a mask character for each character of the secret.
+func maskSecret(value string) string {
+	return strings.Repeat("*", utf8.RuneCountInString(value))
+}
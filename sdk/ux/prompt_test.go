@@ -0,0 +1,47 @@
+package ux
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eiannone/keyboard"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Prompt(t *testing.T) {
+	t.Run("Secret", func(t *testing.T) {
+		var buffer bytes.Buffer
+		input := NewScriptedInput().Type("s3cr?t-kéy").Press(keyboard.KeyBackspace2).Type("y").Press(keyboard.KeyEnter)
+
+		value, err := NewPrompt(&PromptOptions{
+			Writer:      &buffer,
+			Input:       input,
+			Message:     "API key",
+			HelpMessage: "The key of the service",
+			Secret:      true,
+		}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, "s3cr?t-kéy", value)
+
+		output := StripEscapeSequences(buffer.String())
+		require.NotContains(t, output, "s3cr")
+		require.NotContains(t, output, "Hint:")
+		require.Contains(t, output, "? API key: **********\n")
+	})
+
+	t.Run("SecretDefaultValue", func(t *testing.T) {
+		var buffer bytes.Buffer
+		input := NewScriptedInput().Press(keyboard.KeyEnter)
+
+		value, err := NewPrompt(&PromptOptions{
+			Writer:       &buffer,
+			Input:        input,
+			Message:      "API key",
+			DefaultValue: "existing-key",
+			Secret:       true,
+		}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, "existing-key", value)
+		require.NotContains(t, buffer.String(), "existing-key")
+	})
+}
@@ -0,0 +1,94 @@
+package ux
+
+import (
+	"io"
+	"os"
+
+	"dario.cat/mergo"
+	"github.com/fatih/color"
+)
+
+type Property struct {
+	// The name displayed before the value
+	Name string
+	// The value of the property. Values may contain colors and hyperlinks created with Hyperlink.
+	Value string
+}
+
+type PropertiesOptions struct {
+	// The writer to use for output (default: os.Stdout)
+	Writer io.Writer
+	// The properties to display
+	Properties []Property
+	// The maximum width of each line, 0 to fit the console (default: 0)
+	Width int
+}
+
+var DefaultPropertiesOptions PropertiesOptions = PropertiesOptions{
+	Writer: os.Stdout,
+}
+
+// Properties renders a list of names and values with the values aligned.
+type Properties struct {
+	canvas  Canvas
+	options *PropertiesOptions
+}
+
+func NewProperties(options *PropertiesOptions) *Properties {
+	mergedOptions := PropertiesOptions{}
+
+	if options == nil {
+		options = &PropertiesOptions{}
+	}
+
+	if err := mergo.Merge(&mergedOptions, options, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	if err := mergo.Merge(&mergedOptions, DefaultPropertiesOptions, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	return &Properties{
+		options: &mergedOptions,
+	}
+}
+
+// Add adds a property.
+func (p *Properties) Add(name string, value string) *Properties {
+	p.options.Properties = append(p.options.Properties, Property{Name: name, Value: value})
+	return p
+}
+
+func (p *Properties) WithCanvas(canvas Canvas) Visual {
+	p.canvas = canvas
+	return p
+}
+
+// Print writes the properties to the writer.
+func (p *Properties) Print() error {
+	return p.Render(NewPrinter(p.options.Writer))
+}
+
+func (p *Properties) Render(printer Printer) error {
+	nameWidth := 0
+	for _, property := range p.options.Properties {
+		nameWidth = max(nameWidth, VisibleWidth(property.Name)+1)
+	}
+
+	maxWidth := p.options.Width
+	if maxWidth == 0 {
+		maxWidth = printer.ConsoleSize().Cols
+	}
+
+	for _, property := range p.options.Properties {
+		value := property.Value
+		if maxWidth > 0 {
+			value = TruncateText(value, max(1, maxWidth-nameWidth-1))
+		}
+
+		printer.Fprintf("%s %s\n", color.HiBlackString(AlignText(property.Name+":", nameWidth, AlignLeft)), value)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package ux
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wbreza/azd-extensions/sdk/ux/internal"
+)
+
+// RenderMode controls whether visuals repaint the terminal or write append-only lines.
+type RenderMode = internal.RenderMode
+
+const (
+	// AutoRenderMode is interactive when stdout is a terminal and the process isn't running on CI.
+	AutoRenderMode = internal.AutoRenderMode
+	// InteractiveRenderMode repaints visuals in place with cursor movement and prompts for input.
+	InteractiveRenderMode = internal.InteractiveRenderMode
+	// AppendOnlyRenderMode writes each new line of a visual once without cursor movement, for example to CI logs.
+	// Prompts answer with their default value or fail with ErrNoPromptValue instead of waiting for input.
+	AppendOnlyRenderMode = internal.AppendOnlyRenderMode
+	// ScreenReaderRenderMode announces state changes as plain lines for screen readers. Visuals aren't animated or
+	// repainted, only the lines that changed since the last update are written and glyphs are ASCII only.
+	// Prompts still read input from the console.
+	ScreenReaderRenderMode = internal.ScreenReaderRenderMode
+)
+
+// ErrNoPromptValue is returned by prompts without a default value that can't prompt for input in the render mode.
+var ErrNoPromptValue = errors.New("requires --no-prompt value")
+
+// SetRenderMode overrides the render mode of all visuals (default: AutoRenderMode).
+func SetRenderMode(mode RenderMode) {
+	internal.SetRenderMode(mode)
+}
+
+// CurrentRenderMode returns the render mode of the visuals, resolving the auto mode from the terminal and CI environment.
+func CurrentRenderMode() RenderMode {
+	return internal.CurrentRenderMode()
+}
+
+// IsInteractive returns true when visuals repaint the terminal and prompts read input from the console.
+func IsInteractive() bool {
+	return internal.IsInteractive()
+}
+
+// canReadInput returns true when a prompt can read its answer. Outside the interactive mode the console isn't read since
+// there may be nobody to answer, but explicit input sources and readers such as scripted answers are still read.
+func canReadInput(source InputSource, reader io.Reader) bool {
+	return internal.CanPrompt() || source != nil || (reader != nil && reader != os.Stdin)
+}
+
+func noPromptValueError(message string) error {
+	return fmt.Errorf("%w for '%s'", ErrNoPromptValue, message)
+}
+
+// showPrompt displays a prompt before reading input. In the append only mode prompts are written once answered
+// and are rendered off screen until then, since rendering also applies filters and validation.
+func showPrompt(canvas Canvas, prompt Visual) error {
+	if !internal.CanPrompt() {
+		return prompt.Render(NewPrinter(io.Discard))
+	}
+
+	return canvas.Run()
+}
+
+// updatePrompt repaints a prompt after input. In the append only mode only the answered or cancelled prompt is written.
+func updatePrompt(canvas Canvas, prompt Visual, done bool) error {
+	if internal.CanPrompt() {
+		return canvas.Update()
+	}
+
+	if done {
+		return canvas.Run()
+	}
+
+	return prompt.Render(NewPrinter(io.Discard))
+}
@@ -0,0 +1,184 @@
+package ux
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/eiannone/keyboard"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain renders the visuals interactively since the output of go test isn't a terminal.
+func TestMain(m *testing.M) {
+	SetRenderMode(InteractiveRenderMode)
+	os.Exit(m.Run())
+}
+
+func Test_RenderMode(t *testing.T) {
+	appendOnly := func(t *testing.T) {
+		SetRenderMode(AppendOnlyRenderMode)
+		t.Cleanup(func() {
+			SetRenderMode(InteractiveRenderMode)
+		})
+	}
+
+	t.Run("CanvasWritesNewLines", func(t *testing.T) {
+		appendOnly(t)
+
+		var buffer bytes.Buffer
+		spinner := NewSpinner(&SpinnerOptions{Writer: &buffer, Text: "Loading models"})
+		canvas := NewCanvas(spinner).WithWriter(&buffer)
+
+		require.NoError(t, canvas.Run())
+		require.NoError(t, canvas.Update())
+
+		spinner.UpdateText("Loading deployments")
+		require.NoError(t, canvas.Update())
+		require.NoError(t, canvas.Update())
+
+		require.Equal(t, "Loading models\nLoading deployments\n", buffer.String())
+	})
+
+	t.Run("TaskList", func(t *testing.T) {
+		appendOnly(t)
+
+		var buffer bytes.Buffer
+		taskList := NewTaskList(&TaskListConfig{Writer: &buffer})
+		progressBar := NewProgressBar(&ProgressBarOptions{Total: 4})
+
+		taskList.
+			AddTask(TaskOptions{
+				Title:       "Upload documents",
+				ProgressBar: progressBar,
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					for range 4 {
+						progressBar.Add(1)
+						require.NoError(t, taskList.canvas.Update())
+					}
+
+					return Success, nil
+				},
+			}).
+			AddTask(TaskOptions{
+				Title: "Index documents",
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Success, nil
+				},
+			})
+
+		require.NoError(t, taskList.Run())
+
+		output := buffer.String()
+		require.NotContains(t, output, "\x1b[")
+		require.NotContains(t, output, "Pending")
+		require.Contains(t, output, "Upload documents 20%\n")
+		require.Contains(t, output, "Upload documents 100%\n")
+		require.Equal(t, 1, strings.Count(output, "Upload documents 50%"))
+		require.Contains(t, output, "Index documents")
+	})
+
+	t.Run("ProgressBar", func(t *testing.T) {
+		appendOnly(t)
+
+		progressBar := NewProgressBar(&ProgressBarOptions{Title: "Uploading", Total: 200})
+		progressBar.SetCurrent(37)
+		require.Equal(t, "Uploading  10%", progressBar.line(0))
+
+		progressBar = NewProgressBar(&ProgressBarOptions{Title: "Embedding", ItemName: "chunks"})
+		progressBar.SetCurrent(12)
+		require.Equal(t, "Embedding", progressBar.line(0))
+
+		require.NoError(t, progressBar.Stop(context.Background()))
+		require.Equal(t, "Embedding  12 chunks", progressBar.line(0))
+	})
+
+	t.Run("PromptWithoutDefault", func(t *testing.T) {
+		appendOnly(t)
+
+		var buffer bytes.Buffer
+		prompt := NewPrompt(&PromptOptions{Writer: &buffer, Message: "Name"})
+
+		value, err := prompt.Ask()
+		require.ErrorIs(t, err, ErrNoPromptValue)
+		require.EqualError(t, err, "requires --no-prompt value for 'Name'")
+		require.Empty(t, value)
+		require.Empty(t, buffer.String())
+	})
+
+	t.Run("PromptsUseDefaults", func(t *testing.T) {
+		appendOnly(t)
+
+		var buffer bytes.Buffer
+
+		value, err := NewPrompt(&PromptOptions{Writer: &buffer, Message: "Name", DefaultValue: "my-app"}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, "my-app", value)
+
+		confirmed, err := NewConfirm(&ConfirmOptions{Writer: &buffer, Message: "Continue", DefaultValue: Ptr(true)}).Ask()
+		require.NoError(t, err)
+		require.True(t, *confirmed)
+
+		selected, err := NewSelect(&SelectOptions{
+			Writer:        &buffer,
+			Message:       "Region",
+			Allowed:       []string{"eastus", "westus"},
+			SelectedIndex: Ptr(1),
+		}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, 1, *selected)
+
+		selectedIndexes, err := NewMultiSelect(&MultiSelectOptions{
+			Writer:          &buffer,
+			Message:         "Models",
+			Allowed:         []string{"gpt-4o", "gpt-4o-mini"},
+			SelectedIndexes: []int{0, 1},
+		}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1}, selectedIndexes)
+
+		require.NotContains(t, buffer.String(), "\x1b[")
+		require.Contains(t, buffer.String(), "? Name: my-app\n")
+		require.Contains(t, buffer.String(), "? Region: westus\n")
+	})
+
+	t.Run("SelectsRequireExplicitDefault", func(t *testing.T) {
+		appendOnly(t)
+
+		_, err := NewSelect(&SelectOptions{Message: "Region", Allowed: []string{"eastus"}}).Ask()
+		require.ErrorIs(t, err, ErrNoPromptValue)
+
+		_, err = NewMultiSelect(&MultiSelectOptions{
+			Message:         "Models",
+			Allowed:         []string{"gpt-4o", "gpt-4o-mini"},
+			SelectedIndexes: []int{0},
+			MinSelections:   2,
+		}).Ask()
+		require.ErrorIs(t, err, ErrNoPromptValue)
+	})
+
+	t.Run("ExplicitInputWritesAnswer", func(t *testing.T) {
+		appendOnly(t)
+
+		var buffer bytes.Buffer
+		input := NewScriptedInput().Press(keyboard.KeyEnter).Type("my app").Press(keyboard.KeyEnter)
+
+		value, err := NewPrompt(&PromptOptions{Writer: &buffer, Input: input, Message: "Name", Required: true}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, "my app", value)
+		require.Equal(t, "? Name: my app\n", StripEscapeSequences(buffer.String()))
+	})
+
+	t.Run("AutoDetect", func(t *testing.T) {
+		SetRenderMode(AutoRenderMode)
+		t.Cleanup(func() {
+			SetRenderMode(InteractiveRenderMode)
+		})
+
+		// The output of go test isn't a terminal
+		require.Equal(t, AppendOnlyRenderMode, CurrentRenderMode())
+		require.False(t, IsInteractive())
+	})
+}
@@ -1,6 +1,7 @@
 package ux
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -19,7 +20,9 @@ type SelectOptions struct {
 	Writer io.Writer
 	// The reader to use for input (default: os.Stdin)
 	Reader io.Reader
-	// The default value to use for the prompt (default: nil)
+	// The source of key events (default: the terminal when Reader is a terminal, otherwise decoded from Reader)
+	Input InputSource
+	// The default value to use for the prompt, also the answer when the render mode can't prompt (default: nil)
 	SelectedIndex *int
 	// The message to display before the prompt
 	Message string
@@ -52,6 +55,7 @@ type Select struct {
 	canvas Canvas
 
 	options            *SelectOptions
+	hasDefault         bool
 	selectedIndex      *int
 	showHelp           bool
 	complete           bool
@@ -98,9 +102,10 @@ func NewSelect(options *SelectOptions) *Select {
 	}
 
 	return &Select{
-		input:           internal.NewInput(),
+		input:           internal.NewInput(resolveInputSource(mergedOptions.Input, mergedOptions.Reader)),
 		cursor:          internal.NewCursor(mergedOptions.Writer),
 		options:         &mergedOptions,
+		hasDefault:      options != nil && options.SelectedIndex != nil,
 		filteredChoices: selectOptions,
 		choices:         selectOptions,
 	}
@@ -116,11 +121,21 @@ func (p *Select) Ask() (*int, error) {
 		p.canvas = NewCanvas(p).WithWriter(p.options.Writer)
 	}
 
-	if err := p.canvas.Run(); err != nil {
+	if !canReadInput(p.options.Input, p.options.Reader) {
+		if !p.hasDefault || *p.options.SelectedIndex < 0 || *p.options.SelectedIndex >= len(p.choices) {
+			return nil, noPromptValueError(p.options.Message)
+		}
+
+		p.selectedIndex = p.options.SelectedIndex
+		p.complete = true
+		return &p.choices[*p.selectedIndex].Index, p.canvas.Run()
+	}
+
+	if err := showPrompt(p.canvas, p); err != nil {
 		return nil, err
 	}
 
-	input, done, err := p.input.ReadInput(nil)
+	next, done, err := p.input.ReadInput(nil)
 	if err != nil {
 		return nil, err
 	}
@@ -130,37 +145,41 @@ func (p *Select) Ask() (*int, error) {
 	}
 
 	for {
-		select {
-		case <-p.input.SigChan:
-			p.cancelled = true
+		msg, err := next()
+		if err != nil {
 			done()
-			p.canvas.Update()
-			return nil, ErrCancelled
 
-		case msg := <-input:
-			p.showHelp = msg.Hint
-
-			if *p.options.EnableFiltering {
-				p.filter = msg.Value
+			if errors.Is(err, internal.ErrInterrupted) {
+				p.cancelled = true
+				updatePrompt(p.canvas, p, true)
+				return nil, ErrCancelled
 			}
 
-			optionCount := len(p.filteredChoices)
-			if msg.Key == keyboard.KeyArrowUp {
-				p.selectedIndex = Ptr(((*p.selectedIndex - 1 + optionCount) % optionCount))
-			} else if msg.Key == keyboard.KeyArrowDown {
-				p.selectedIndex = Ptr(((*p.selectedIndex + 1) % optionCount))
-			}
+			return nil, err
+		}
 
-			if msg.Key == keyboard.KeyEnter && p.selectedIndex != nil {
-				p.complete = true
-			}
+		p.showHelp = msg.Hint
 
-			p.canvas.Update()
+		if *p.options.EnableFiltering {
+			p.filter = msg.Value
+		}
 
-			if p.complete {
-				done()
-				return &p.filteredChoices[*p.selectedIndex].Index, nil
-			}
+		optionCount := len(p.filteredChoices)
+		if msg.Key == keyboard.KeyArrowUp {
+			p.selectedIndex = Ptr(((*p.selectedIndex - 1 + optionCount) % optionCount))
+		} else if msg.Key == keyboard.KeyArrowDown {
+			p.selectedIndex = Ptr(((*p.selectedIndex + 1) % optionCount))
+		}
+
+		if msg.Key == keyboard.KeyEnter && p.selectedIndex != nil {
+			p.complete = true
+		}
+
+		updatePrompt(p.canvas, p, p.complete)
+
+		if p.complete {
+			done()
+			return &p.filteredChoices[*p.selectedIndex].Index, nil
 		}
 	}
 }
@@ -175,23 +194,7 @@ func (p *Select) applyFilter() {
 		return
 	}
 
-	p.filteredChoices = []*selectChoice{}
-	for _, option := range p.choices {
-		// Attempt to parse the filter as an index
-		if p.options.DisplayNumbers != nil && *p.options.DisplayNumbers {
-			index, err := strconv.Atoi(p.filter)
-			if err == nil {
-				if index == option.Index+1 {
-					p.filteredChoices = append(p.filteredChoices, option)
-					continue
-				}
-			}
-		}
-
-		if strings.Contains(strings.ToLower(option.Value), strings.ToLower(p.filter)) {
-			p.filteredChoices = append(p.filteredChoices, option)
-		}
-	}
+	p.filteredChoices = filterChoices(p.choices, p.filter, *p.options.DisplayNumbers)
 
 	if *p.selectedIndex > len(p.filteredChoices)-1 {
 		p.selectedIndex = Ptr(0)
@@ -199,66 +202,116 @@ func (p *Select) applyFilter() {
 }
 
 func (p *Select) renderOptions(printer Printer, indent string) {
+	theme := activeTheme()
+
 	// Options
 	if p.cancelled || p.complete {
 		return
 	}
 
-	totalOptionsCount := len(p.choices)
-	filteredOptionsCount := len(p.filteredChoices)
 	selected := *p.selectedIndex
+	start, end := displayRange(selected, len(p.filteredChoices), p.options.DisplayCount)
+	digitWidth := len(fmt.Sprintf("%d", len(p.choices))) // Calculate the width of the digit prefix
+
+	renderChoices(printer, indent, p.filteredChoices, start, end, func(index int, option *selectChoice) string {
+		displayValue := formatChoice(option, p.filter, *p.options.DisplayNumbers, digitWidth)
 
-	start := selected - p.options.DisplayCount/2
-	end := start + p.options.DisplayCount
+		if index == selected {
+			return theme.Palette.Accent.Sprintf("%s %s", theme.Glyphs.Pointer, displayValue)
+		}
+
+		return fmt.Sprintf("  %s", displayValue)
+	})
+}
+
+// filterChoices returns the choices that contain the filter or, when numbers are displayed, match the filter number.
+func filterChoices(choices []*selectChoice, filter string, displayNumbers bool) []*selectChoice {
+	filteredChoices := []*selectChoice{}
+
+	for _, option := range choices {
+		// Attempt to parse the filter as an index
+		if displayNumbers {
+			index, err := strconv.Atoi(filter)
+			if err == nil {
+				if index == option.Index+1 {
+					filteredChoices = append(filteredChoices, option)
+					continue
+				}
+			}
+		}
+
+		if strings.Contains(strings.ToLower(option.Value), strings.ToLower(filter)) {
+			filteredChoices = append(filteredChoices, option)
+		}
+	}
+
+	return filteredChoices
+}
+
+// displayRange returns the range of choices to display so the selected choice stays near the middle of the page.
+func displayRange(selected int, count int, displayCount int) (int, int) {
+	start := selected - displayCount/2
+	end := start + displayCount
 
 	if start < 0 {
 		start = 0
-		end = min(filteredOptionsCount, p.options.DisplayCount)
-	} else if end > filteredOptionsCount {
-		end = filteredOptionsCount
-		start = max(0, filteredOptionsCount-p.options.DisplayCount)
+		end = min(count, displayCount)
+	} else if end > count {
+		end = count
+		start = max(0, count-displayCount)
 	}
 
-	if start > 0 {
-		if start >= 9 {
-			printer.Fprintf("%s  ...\n", indent)
-		} else {
-			printer.Fprintf("%s   ...\n", indent)
-		}
-	}
+	return start, end
+}
 
-	digitWidth := len(fmt.Sprintf("%d", totalOptionsCount)) // Calculate the width of the digit prefix
+// formatChoice underlines the portion of the choice matching the filter and adds the number prefix.
+func formatChoice(option *selectChoice, filter string, displayNumbers bool, digitWidth int) string {
+	displayValue := option.Value
 	underline := color.New(color.Underline).SprintfFunc()
 
-	for index, option := range p.filteredChoices[start:end] {
-		displayValue := option.Value
-
-		// Underline the matching portion of the string
-		if p.filter != "" {
-			matchIndex := strings.Index(strings.ToLower(displayValue), strings.ToLower(p.filter))
-			if matchIndex > -1 {
-				displayValue = fmt.Sprintf("%s%s%s",
-					displayValue[:matchIndex],                                    // Start of the string
-					underline(displayValue[matchIndex:matchIndex+len(p.filter)]), // Highlighted filter
-					displayValue[matchIndex+len(p.filter):],                      // End of the string
-				)
-			}
+	// Underline the matching portion of the string
+	if filter != "" {
+		matchIndex := strings.Index(strings.ToLower(displayValue), strings.ToLower(filter))
+		if matchIndex > -1 {
+			displayValue = fmt.Sprintf("%s%s%s",
+				displayValue[:matchIndex],                                  // Start of the string
+				underline(displayValue[matchIndex:matchIndex+len(filter)]), // Highlighted filter
+				displayValue[matchIndex+len(filter):],                      // End of the string
+			)
 		}
+	}
 
-		// Show item digit prefixes
-		if *p.options.DisplayNumbers {
-			digitPrefix := fmt.Sprintf("%*d.", digitWidth, option.Index+1) // Padded digit prefix
-			displayValue = fmt.Sprintf("%s %s", digitPrefix, displayValue)
-		}
+	// Show item digit prefixes
+	if displayNumbers {
+		digitPrefix := fmt.Sprintf("%*d.", digitWidth, option.Index+1) // Padded digit prefix
+		displayValue = fmt.Sprintf("%s %s", digitPrefix, displayValue)
+	}
 
-		if start+index == selected {
-			printer.Fprintf("%s%s\n", indent, color.CyanString("> %s", displayValue))
+	return displayValue
+}
+
+// renderChoices renders the page of choices between start and end, with ellipses when more choices are available.
+func renderChoices(
+	printer Printer,
+	indent string,
+	choices []*selectChoice,
+	start int,
+	end int,
+	renderFn func(index int, option *selectChoice) string,
+) {
+	if start > 0 {
+		if start >= 9 {
+			printer.Fprintf("%s  ...\n", indent)
 		} else {
-			printer.Fprintf("%s  %s\n", indent, displayValue)
+			printer.Fprintf("%s   ...\n", indent)
 		}
 	}
 
-	if end < filteredOptionsCount {
+	for index, option := range choices[start:end] {
+		printer.Fprintf("%s%s\n", indent, renderFn(start+index, option))
+	}
+
+	if end < len(choices) {
 		if end >= 10 {
 			printer.Fprintf("%s  ...\n", indent)
 		} else {
@@ -268,6 +321,8 @@ func (p *Select) renderOptions(printer Printer, indent string) {
 }
 
 func (p *Select) renderValidation(printer Printer) {
+	theme := activeTheme()
+
 	p.hasValidationError = false
 	p.validationMessage = ""
 
@@ -279,14 +334,14 @@ func (p *Select) renderValidation(printer Printer) {
 
 	// Validation error
 	if !p.showHelp && p.hasValidationError {
-		printer.Fprintln(color.YellowString(p.validationMessage))
+		printer.Fprintln(theme.Palette.Warning.Sprint(p.validationMessage))
 	}
 
 	// Hint
 	if p.showHelp && p.options.HelpMessage != "" {
 		printer.Fprintln()
 		printer.Fprintf(
-			color.HiMagentaString("%s %s\n",
+			theme.Palette.Hint.Sprintf("%s %s\n",
 				BoldString("Hint:"),
 				p.options.HelpMessage,
 			),
@@ -295,18 +350,20 @@ func (p *Select) renderValidation(printer Printer) {
 }
 
 func (p *Select) renderMessage1(printer Printer) {
+	theme := activeTheme()
+
 	if p.selectedIndex == nil && p.options.SelectedIndex != nil {
 		p.selectedIndex = p.options.SelectedIndex
 	}
 
-	printer.Fprintf(color.CyanString("? "))
+	printer.Fprintf(theme.Palette.Accent.Sprintf("%s ", theme.Glyphs.Question))
 
 	// Message
 	printer.Fprintf(BoldString("%s: ", p.options.Message))
 
 	// Hint
 	if !p.cancelled && !p.complete && p.options.Hint != "" {
-		printer.Fprintf("%s ", color.CyanString(p.options.Hint))
+		printer.Fprintf("%s ", theme.Palette.Accent.Sprint(p.options.Hint))
 	}
 
 	// Filter
@@ -318,20 +375,22 @@ func (p *Select) renderMessage1(printer Printer) {
 
 	// Cancelled
 	if p.cancelled {
-		printer.Fprintf(color.RedString("(Cancelled)"))
+		printer.Fprintf(theme.Palette.Error.Sprint("(Cancelled)"))
 	}
 
 	// Selected Value
 	if !p.cancelled && p.complete {
 		rawValue := p.filteredChoices[*p.selectedIndex].Value
-		printer.Fprintf(color.CyanString(rawValue))
+		printer.Fprintf(theme.Palette.Accent.Sprint(rawValue))
 	}
 
 	printer.Fprintln()
 }
 
 func (p *Select) renderMessage2(printer Printer) {
-	printer.Fprintf(color.CyanString("? "))
+	theme := activeTheme()
+
+	printer.Fprintf(theme.Palette.Accent.Sprintf("%s ", theme.Glyphs.Question))
 
 	if p.selectedIndex == nil && p.options.SelectedIndex != nil {
 		p.selectedIndex = p.options.SelectedIndex
@@ -342,13 +401,13 @@ func (p *Select) renderMessage2(printer Printer) {
 
 	// Cancelled
 	if p.cancelled {
-		printer.Fprintf(color.RedString("(Cancelled)"))
+		printer.Fprintf(theme.Palette.Error.Sprint("(Cancelled)"))
 	}
 
 	// Selected Value
 	if !p.cancelled && p.complete {
 		rawValue := p.filteredChoices[*p.selectedIndex].Value
-		printer.Fprintf(color.CyanString(rawValue))
+		printer.Fprintf(theme.Palette.Accent.Sprint(rawValue))
 	}
 
 	printer.Fprintln()
@@ -360,7 +419,7 @@ func (p *Select) renderMessage2(printer Printer) {
 
 		if p.filter == "" {
 			p.cursorPosition = Ptr(printer.CursorPosition())
-			printer.Fprintf(color.HiBlackString("Type to filter list"))
+			printer.Fprintf(theme.Palette.Muted.Sprint("Type to filter list"))
 		} else {
 			printer.Fprintf(p.filter)
 			p.cursorPosition = Ptr(printer.CursorPosition())
@@ -402,11 +461,13 @@ func (p *Select) Render(printer Printer) error {
 }
 
 func (p *Select) renderFooter(printer Printer) {
+	theme := activeTheme()
+
 	if p.cancelled || p.complete {
 		return
 	}
 
 	printer.Fprintln()
-	printer.Fprintln(color.HiBlackString("───────────────────────────────────"))
-	printer.Fprintln(color.HiBlackString("Use arrows to move, type ? for hint"))
+	printer.Fprintln(theme.Palette.Muted.Sprint(theme.Glyphs.Separator))
+	printer.Fprintln(theme.Palette.Muted.Sprint("Use arrows to move, type ? for hint"))
 }
@@ -0,0 +1,126 @@
+package ux_test
+
+import (
+	"testing"
+
+	"github.com/eiannone/keyboard"
+	"github.com/stretchr/testify/require"
+	"github.com/wbreza/azd-extensions/sdk/ux"
+	"github.com/wbreza/azd-extensions/sdk/ux/uxtest"
+)
+
+func Test_Snapshots(t *testing.T) {
+	t.Run("Select", func(t *testing.T) {
+		selectPrompt := ux.NewSelect(&ux.SelectOptions{
+			Message: "Select a region",
+			Allowed: []string{"eastus", "eastus2", "westus", "westus2", "centralus"},
+			Input: ux.NewScriptedInput().
+				Press(keyboard.KeyArrowDown).
+				Type("west").
+				Press(keyboard.KeyArrowDown, keyboard.KeyEnter),
+		})
+
+		recorder := uxtest.NewRecorder(uxtest.NewTerminal(uxtest.DefaultSize), selectPrompt)
+		selected, err := selectPrompt.Ask()
+		require.NoError(t, err)
+		require.Equal(t, 2, *selected)
+
+		uxtest.AssertSnapshot(t, uxtest.FormatFrames(recorder.Frames()))
+	})
+
+	t.Run("MultiSelect", func(t *testing.T) {
+		multiSelect := ux.NewMultiSelect(&ux.MultiSelectOptions{
+			Message:         "Select deployments to delete",
+			Allowed:         []string{"gpt-4o", "gpt-4o-mini", "text-embedding-3-small"},
+			SelectedIndexes: []int{1},
+			Input: ux.NewScriptedInput().
+				Press(keyboard.KeySpace, keyboard.KeyArrowDown, keyboard.KeyArrowDown, keyboard.KeySpace).
+				Press(keyboard.KeyEnter),
+		})
+
+		recorder := uxtest.NewRecorder(uxtest.NewTerminal(uxtest.DefaultSize), multiSelect)
+		selected, err := multiSelect.Ask()
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 2}, selected)
+
+		uxtest.AssertSnapshot(t, uxtest.FormatFrames(recorder.Frames()))
+	})
+
+	t.Run("Table", func(t *testing.T) {
+		table := ux.NewTable(&ux.TableOptions{
+			Columns: []ux.TableColumn{
+				{Heading: "Name"},
+				{Heading: "Endpoint"},
+				{Heading: "Region"},
+			},
+		}).
+			AddRow("my-ai-service", ux.Hyperlink("https://my-ai-service.openai.azure.com/"), "eastus2").
+			AddRow("my-search-service", ux.Hyperlink("https://my-search-service.search.windows.net/"), "westus")
+
+		recorder := uxtest.NewRecorder(uxtest.NewTerminal(ux.CanvasSize{Rows: 10, Cols: 50}), table)
+		require.NoError(t, recorder.Run())
+
+		uxtest.AssertSnapshot(t, recorder.LastFrame())
+	})
+
+	t.Run("Prompt", func(t *testing.T) {
+		prompt := ux.NewPrompt(&ux.PromptOptions{
+			Message:  "Enter a name",
+			Required: true,
+			Input: ux.NewScriptedInput().
+				Press(keyboard.KeyEnter).
+				Type("my-apx").
+				Press(keyboard.KeyBackspace2).
+				Type("p").
+				Press(keyboard.KeyEnter),
+		})
+
+		recorder := uxtest.NewRecorder(uxtest.NewTerminal(uxtest.DefaultSize), prompt)
+		value, err := prompt.Ask()
+		require.NoError(t, err)
+		require.Equal(t, "my-app", value)
+
+		uxtest.AssertSnapshot(t, recorder.LastFrame())
+	})
+
+	t.Run("Form", func(t *testing.T) {
+		type serviceAnswers struct {
+			Name   string
+			Region string
+			Public bool
+		}
+
+		form := ux.NewForm(&ux.FormOptions[serviceAnswers]{
+			Title: "Configure the service",
+			Fields: []ux.FormField[serviceAnswers]{
+				ux.NewTextField(&ux.TextFieldOptions[serviceAnswers]{
+					Message:  "Name",
+					Required: true,
+					Value:    func(answers *serviceAnswers) *string { return &answers.Name },
+				}),
+				ux.NewSelectField(&ux.SelectFieldOptions[serviceAnswers]{
+					Message: "Region",
+					Allowed: []string{"eastus", "westus"},
+					Value:   func(answers *serviceAnswers) *string { return &answers.Region },
+				}),
+				ux.NewConfirmField(&ux.ConfirmFieldOptions[serviceAnswers]{
+					Message: "Public access",
+					Value:   func(answers *serviceAnswers) *bool { return &answers.Public },
+				}),
+			},
+			Input: ux.NewScriptedInput().
+				Press(keyboard.KeyTab).
+				Type("my-app").
+				Press(keyboard.KeyTab, keyboard.KeyArrowDown, keyboard.KeyTab).
+				Type("y").
+				Press(keyboard.KeyEnter, keyboard.KeyEnter),
+		})
+
+		recorder := uxtest.NewRecorder(uxtest.NewTerminal(uxtest.DefaultSize), form)
+		answers, err := form.Ask()
+		require.NoError(t, err)
+		require.Equal(t, &serviceAnswers{Name: "my-app", Region: "westus", Public: true}, answers)
+
+		uxtest.AssertSnapshot(t, uxtest.FormatFrames(recorder.Frames()))
+	})
+}
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"dario.cat/mergo"
-	"github.com/fatih/color"
 	"github.com/wbreza/azd-extensions/sdk/ux/internal"
 )
 
@@ -26,6 +25,7 @@ type Spinner struct {
 }
 
 type SpinnerOptions struct {
+	// The frames of the animation (default: the spinner glyphs of the theme)
 	Animation   []string
 	Text        string
 	Interval    time.Duration
@@ -34,10 +34,9 @@ type SpinnerOptions struct {
 }
 
 var DefaultSpinnerOptions SpinnerOptions = SpinnerOptions{
-	Animation: []string{"|", "/", "-", "\\"},
-	Text:      "Loading...",
-	Interval:  250 * time.Millisecond,
-	Writer:    os.Stdout,
+	Text:     "Loading...",
+	Interval: 250 * time.Millisecond,
+	Writer:   os.Stdout,
 }
 
 func NewSpinner(options *SpinnerOptions) *Spinner {
@@ -128,10 +127,28 @@ func (s *Spinner) Render(printer Printer) error {
 		return nil
 	}
 
-	printer.Fprintf(color.HiMagentaString(s.options.Animation[s.animationIndex]))
+	// The animation would write a new line on every frame in the append only mode
+	if !IsInteractive() {
+		printer.Fprintf("%s\n", s.text)
+		return nil
+	}
+
+	theme := activeTheme()
+
+	animation := s.options.Animation
+	if len(animation) == 0 {
+		animation = theme.Glyphs.Spinner
+	}
+
+	// The glyphs of the theme can change between frames
+	if s.animationIndex >= len(animation) {
+		s.animationIndex = 0
+	}
+
+	printer.Fprintf(theme.Palette.Hint.Sprint(animation[s.animationIndex]))
 	printer.Fprintf(" %s", s.text)
 
-	if s.animationIndex == len(s.options.Animation)-1 {
+	if s.animationIndex == len(animation)-1 {
 		s.animationIndex = 0
 	} else {
 		s.animationIndex++
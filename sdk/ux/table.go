@@ -0,0 +1,180 @@
+package ux
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"dario.cat/mergo"
+	"github.com/fatih/color"
+)
+
+// columnGap is the number of spaces between table columns.
+const columnGap = 3
+
+type TableColumn struct {
+	// The heading displayed above the column
+	Heading string
+	// The horizontal alignment of the values (default: AlignLeft)
+	Alignment Alignment
+	// The minimum width the column is shrunk to when the table doesn't fit (default: the width of the heading)
+	MinWidth int
+	// The maximum width of the column, 0 for no limit (default: 0)
+	MaxWidth int
+}
+
+type TableOptions struct {
+	// The writer to use for output (default: os.Stdout)
+	Writer io.Writer
+	// The columns of the table
+	Columns []TableColumn
+	// The values of each row. Values may contain colors and hyperlinks created with Hyperlink.
+	Rows [][]string
+	// The maximum width of the table, 0 to fit the console (default: 0)
+	Width int
+}
+
+var DefaultTableOptions TableOptions = TableOptions{
+	Writer: os.Stdout,
+}
+
+// Table renders rows of values in aligned columns sized to fit the console.
+// Values that don't fit are truncated with an ellipsis.
+type Table struct {
+	canvas  Canvas
+	options *TableOptions
+}
+
+func NewTable(options *TableOptions) *Table {
+	mergedOptions := TableOptions{}
+
+	if options == nil {
+		options = &TableOptions{}
+	}
+
+	if err := mergo.Merge(&mergedOptions, options, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	if err := mergo.Merge(&mergedOptions, DefaultTableOptions, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	return &Table{
+		options: &mergedOptions,
+	}
+}
+
+// AddRow adds a row with a value for each column.
+func (t *Table) AddRow(values ...string) *Table {
+	t.options.Rows = append(t.options.Rows, values)
+	return t
+}
+
+func (t *Table) WithCanvas(canvas Canvas) Visual {
+	t.canvas = canvas
+	return t
+}
+
+// Print writes the table to the writer.
+func (t *Table) Print() error {
+	return t.Render(NewPrinter(t.options.Writer))
+}
+
+func (t *Table) Render(printer Printer) error {
+	if len(t.options.Columns) == 0 {
+		return nil
+	}
+
+	maxWidth := t.options.Width
+	if maxWidth == 0 {
+		maxWidth = printer.ConsoleSize().Cols
+	}
+
+	widths := t.columnWidths(maxWidth)
+
+	headings := make([]string, len(t.options.Columns))
+	separators := make([]string, len(t.options.Columns))
+
+	for i, column := range t.options.Columns {
+		headings[i] = BoldString("%s", TruncateText(column.Heading, widths[i]))
+		separators[i] = color.HiBlackString(strings.Repeat("─", widths[i]))
+	}
+
+	t.renderRow(printer, headings, widths)
+	t.renderRow(printer, separators, widths)
+
+	for _, row := range t.options.Rows {
+		values := make([]string, len(t.options.Columns))
+		for i := range values {
+			if i < len(row) {
+				values[i] = TruncateText(row[i], widths[i])
+			}
+		}
+
+		t.renderRow(printer, values, widths)
+	}
+
+	return nil
+}
+
+func (t *Table) renderRow(printer Printer, values []string, widths []int) {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		cells[i] = AlignText(value, widths[i], t.options.Columns[i].Alignment)
+	}
+
+	printer.Fprintf("%s\n", strings.TrimRight(strings.Join(cells, strings.Repeat(" ", columnGap)), " "))
+}
+
+// columnWidths returns the width of each column. When the table is wider than the max width the widest columns
+// are shrunk until the table fits or every column is at its minimum width.
+func (t *Table) columnWidths(maxWidth int) []int {
+	columns := t.options.Columns
+	widths := make([]int, len(columns))
+	minWidths := make([]int, len(columns))
+
+	for i, column := range columns {
+		widths[i] = VisibleWidth(column.Heading)
+		for _, row := range t.options.Rows {
+			if i < len(row) {
+				widths[i] = max(widths[i], VisibleWidth(row[i]))
+			}
+		}
+
+		if column.MaxWidth > 0 {
+			widths[i] = min(widths[i], column.MaxWidth)
+		}
+
+		minWidths[i] = column.MinWidth
+		if minWidths[i] == 0 {
+			minWidths[i] = VisibleWidth(column.Heading)
+		}
+
+		minWidths[i] = min(minWidths[i], widths[i])
+	}
+
+	if maxWidth <= 0 {
+		return widths
+	}
+
+	available := maxWidth - columnGap*(len(columns)-1)
+
+	for {
+		total := 0
+		widest := -1
+
+		for i, width := range widths {
+			total += width
+			if width > minWidths[i] && (widest == -1 || width > widths[widest]) {
+				widest = i
+			}
+		}
+
+		if total <= available || widest == -1 {
+			return widths
+		}
+
+		widths[widest]--
+	}
+}
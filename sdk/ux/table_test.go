@@ -0,0 +1,106 @@
+package ux
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Text(t *testing.T) {
+	link := Hyperlink("https://example.com", "example")
+	colored := color.New(color.FgCyan).Sprint("cyan text")
+
+	t.Run("VisibleWidth", func(t *testing.T) {
+		require.Equal(t, 7, VisibleWidth(link))
+		require.Equal(t, 9, VisibleWidth("\x1b[36mcyan text\x1b[0m"))
+		require.Equal(t, 3, VisibleWidth("✔ ✖"))
+	})
+
+	t.Run("TruncateText", func(t *testing.T) {
+		require.Equal(t, "hello", TruncateText("hello", 5))
+		require.Equal(t, "hel…", TruncateText("hello", 4))
+		require.Equal(t, "…", TruncateText("hello", 1))
+		require.Equal(t, "", TruncateText("hello", 0))
+
+		truncated := TruncateText(link, 4)
+		require.Equal(t, "exa…", StripEscapeSequences(truncated))
+		require.Equal(t, "\033]8;;https://example.com\007exa…\033]8;;\007", truncated)
+
+		require.Equal(t, "cyan…", StripEscapeSequences(TruncateText(colored, 5)))
+	})
+
+	t.Run("AlignText", func(t *testing.T) {
+		require.Equal(t, "ab  ", AlignText("ab", 4, AlignLeft))
+		require.Equal(t, "  ab", AlignText("ab", 4, AlignRight))
+		require.Equal(t, " ab ", AlignText("ab", 4, AlignCenter))
+		require.Equal(t, "abcde", AlignText("abcde", 4, AlignLeft))
+	})
+}
+
+func Test_Table(t *testing.T) {
+	newTable := func(writer *bytes.Buffer, width int) *Table {
+		return NewTable(&TableOptions{
+			Writer: writer,
+			Width:  width,
+			Columns: []TableColumn{
+				{Heading: "Name"},
+				{Heading: "Model Version"},
+				{Heading: "Capacity", Alignment: AlignRight},
+			},
+		}).
+			AddRow("gpt-4o", Hyperlink("https://example.com", "2024-08-06"), "10").
+			AddRow("text-embedding-3-small", "1", "120")
+	}
+
+	t.Run("Text", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		require.NoError(t, newTable(writer, 0).Print())
+
+		require.Equal(t, ""+
+			"Name                     Model Version   Capacity\n"+
+			"──────────────────────   ─────────────   ────────\n"+
+			"gpt-4o                   2024-08-06            10\n"+
+			"text-embedding-3-small   1                    120\n",
+			StripEscapeSequences(writer.String()),
+		)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		require.NoError(t, newTable(writer, 40).Print())
+
+		require.Equal(t, ""+
+			"Name            Model Version   Capacity\n"+
+			"─────────────   ─────────────   ────────\n"+
+			"gpt-4o          2024-08-06            10\n"+
+			"text-embeddi…   1                    120\n",
+			StripEscapeSequences(writer.String()),
+		)
+	})
+}
+
+func Test_Properties(t *testing.T) {
+	newProperties := func(writer *bytes.Buffer) *Properties {
+		return NewProperties(&PropertiesOptions{
+			Writer: writer,
+			Width:  40,
+		}).
+			Add("Service", "my-ai-service").
+			Add("Endpoint", Hyperlink("https://my-ai-service.openai.azure.com/")).
+			Add("Subscription ID", "00000000-0000-0000-0000-000000000000")
+	}
+
+	t.Run("Text", func(t *testing.T) {
+		writer := &bytes.Buffer{}
+		require.NoError(t, newProperties(writer).Print())
+
+		require.Equal(t, ""+
+			"Service:         my-ai-service\n"+
+			"Endpoint:        https://my-ai-service.…\n"+
+			"Subscription ID: 00000000-0000-0000-000…\n",
+			StripEscapeSequences(writer.String()),
+		)
+	})
+}
@@ -1,10 +1,12 @@
 package ux
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -19,24 +21,30 @@ type TaskListConfig struct {
 	// The writer to use for output (default: os.Stdout)
 	Writer             io.Writer
 	MaxConcurrentAsync int
-	SuccessStyle       string
-	ErrorStyle         string
-	WarningStyle       string
-	RunningStyle       string
-	SkippedStyle       string
-	PendingStyle       string
+	// Whether the remaining tasks keep running after a task fails (default: ContinueOnError)
+	FailurePolicy FailurePolicy
+	// The prefixes of the task states (default: the glyph and color of the state in the theme followed by the state)
+	SuccessStyle string
+	ErrorStyle   string
+	WarningStyle string
+	RunningStyle string
+	SkippedStyle string
+	PendingStyle string
 }
 
+// FailurePolicy controls what happens to the other tasks of a task list when a task fails.
+type FailurePolicy int
+
+const (
+	// ContinueOnError keeps running the other tasks, only the tasks that depend on the failed task are skipped.
+	ContinueOnError FailurePolicy = iota
+	// FailFast cancels the context of the running tasks and skips the tasks that haven't started.
+	FailFast
+)
+
 var DefaultTaskListConfig TaskListConfig = TaskListConfig{
 	Writer:             os.Stdout,
 	MaxConcurrentAsync: 5,
-
-	SuccessStyle: color.GreenString("(✔) Done "),
-	ErrorStyle:   color.RedString("(x) Error "),
-	WarningStyle: color.YellowString("(!) Warning "),
-	RunningStyle: color.CyanString("(-) Running "),
-	SkippedStyle: color.HiBlackString("(-) Skipped "),
-	PendingStyle: color.HiBlackString("(o) Pending "),
 }
 
 type TaskList struct {
@@ -44,33 +52,70 @@ type TaskList struct {
 	waitGroup sync.WaitGroup
 	config    *TaskListConfig
 	allTasks  []*Task
-	syncTasks []*Task // Queue for synchronous tasks
 
 	completed      int32
-	syncMutex      sync.Mutex // Mutex to handle sync task queue safely
+	ran            atomic.Bool
+	taskMutex      sync.Mutex // Mutex to handle the state of the tasks safely while they run and render
 	errorMuxtex    sync.Mutex // Mutex to handle errors slice safely
 	asyncSemaphore chan struct{}
 	errors         []error
+	cancel         context.CancelCauseFunc
 }
 
 type TaskOptions struct {
 	Title  string
 	Action func(SetProgressFunc) (TaskState, error)
-	Async  bool
+	// An optional action that receives the context of the run instead of Action. The context is cancelled when the run
+	// is cancelled, when another task fails with the FailFast policy or when the timeout of the task expires.
+	ActionWithContext func(context.Context, SetProgressFunc) (TaskState, error)
+	Async             bool
+	// An optional progress bar displayed below the task while it's running. The action updates the progress bar.
+	ProgressBar *ProgressBar
+	// The name other tasks use to depend on this task (default: Title)
+	Name string
+	// The names of the tasks that must succeed before this task starts. The task is skipped when a dependency fails or
+	// is skipped. Tasks with dependencies start as soon as the dependencies are done instead of in the order they were
+	// added, async tasks still share the MaxConcurrentAsync slots.
+	DependsOn []string
+	// The optional time limit of each attempt, the context of ActionWithContext is cancelled once it expires.
+	// Only applies to ActionWithContext, tasks that set Action can't be cancelled and are rejected (default: none)
+	Timeout time.Duration
+	// The number of times the action is run again after it fails (default: 0)
+	Retries int
+	// The delay before the action is run again after it fails (default: 0)
+	RetryDelay time.Duration
 }
 
 type SetProgressFunc func(string)
 
 type Task struct {
-	Title     string
-	Action    func(SetProgressFunc) (TaskState, error)
-	State     TaskState
-	Error     error
-	progress  string
-	startTime *time.Time
-	endTime   *time.Time
+	Title       string
+	Action      func(SetProgressFunc) (TaskState, error)
+	State       TaskState
+	Error       error
+	ProgressBar *ProgressBar
+	progress    string
+	startTime   *time.Time
+	endTime     *time.Time
+	options     TaskOptions
+	attempts    int
+	done        chan struct{}
 }
 
+// TaskResult is the outcome of a task after the task list ran.
+type TaskResult struct {
+	Name     string
+	Title    string
+	State    TaskState
+	Error    error
+	Duration time.Duration
+	// The number of times the action ran, 0 when the task was skipped before it started
+	Attempts int
+}
+
+// ErrTaskListRan is returned when a task list runs again, create a new task list to run the tasks again.
+var ErrTaskListRan = errors.New("task list has already run")
+
 type TaskState int
 
 const (
@@ -82,6 +127,32 @@ const (
 	Success
 )
 
+func (s TaskState) String() string {
+	switch s {
+	case Pending:
+		return "Pending"
+	case Running:
+		return "Running"
+	case Skipped:
+		return "Skipped"
+	case Warning:
+		return "Warning"
+	case Error:
+		return "Error"
+	case Success:
+		return "Success"
+	default:
+		return fmt.Sprintf("TaskState(%d)", int(s))
+	}
+}
+
+// taskDependency is a task that must be done before another task starts.
+type taskDependency struct {
+	task *Task
+	// Whether the task must succeed, the implicit order of tasks without dependencies only waits for the task
+	required bool
+}
+
 func NewTaskList(config *TaskListConfig) *TaskList {
 	mergedConfig := TaskListConfig{}
 
@@ -101,8 +172,6 @@ func NewTaskList(config *TaskListConfig) *TaskList {
 		config:         &mergedConfig,
 		waitGroup:      sync.WaitGroup{},
 		allTasks:       []*Task{},
-		syncTasks:      []*Task{},
-		syncMutex:      sync.Mutex{},
 		errorMuxtex:    sync.Mutex{},
 		completed:      0,
 		asyncSemaphore: make(chan struct{}, mergedConfig.MaxConcurrentAsync),
@@ -117,6 +186,21 @@ func (t *TaskList) WithCanvas(canvas Canvas) Visual {
 
 // Run executes all async tasks first and then runs queued sync tasks sequentially.
 func (t *TaskList) Run() error {
+	return t.RunWithContext(context.Background())
+}
+
+// RunWithContext executes the tasks like Run. Cancelling the context cancels the context of the running actions and
+// skips the tasks that haven't started. A task list can only run once.
+func (t *TaskList) RunWithContext(ctx context.Context) error {
+	if !t.ran.CompareAndSwap(false, true) {
+		return ErrTaskListRan
+	}
+
+	dependencies, err := t.resolveDependencies()
+	if err != nil {
+		return err
+	}
+
 	if t.canvas == nil {
 		t.canvas = NewCanvas(t).WithWriter(t.config.Writer)
 	}
@@ -125,6 +209,10 @@ func (t *TaskList) Run() error {
 		return err
 	}
 
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	t.cancel = cancel
+
 	go func() {
 		for {
 			if t.isCompleted() {
@@ -136,12 +224,20 @@ func (t *TaskList) Run() error {
 		}
 	}()
 
-	// Wait for all async tasks to complete
+	for _, task := range t.allTasks {
+		t.waitGroup.Add(1)
+		go t.runTask(runCtx, task, dependencies[task])
+	}
+
 	t.waitGroup.Wait()
-	// Run sync tasks after async tasks are completed
-	t.runSyncTasks()
 	t.canvas.Update()
 
+	if err := ctx.Err(); err != nil && !slices.ContainsFunc(t.errors, func(taskErr error) bool {
+		return errors.Is(taskErr, err)
+	}) {
+		t.errors = append(t.errors, err)
+	}
+
 	if len(t.errors) > 0 {
 		return errors.Join(t.errors...)
 	}
@@ -149,19 +245,16 @@ func (t *TaskList) Run() error {
 	return nil
 }
 
-// AddTask adds a task to the task list and manages async/sync execution.
+// AddTask adds a task to the task list. Async tasks start right away when the task list runs and sync tasks run one at
+// a time in the order they were added once the async tasks are done, unless the task has dependencies.
 func (t *TaskList) AddTask(options TaskOptions) *TaskList {
 	task := &Task{
-		Title:  options.Title,
-		Action: options.Action,
-		State:  Pending,
-	}
-
-	// Differentiate between async and sync tasks
-	if options.Async {
-		t.addAsyncTask(task)
-	} else {
-		t.addSyncTask(task)
+		Title:       options.Title,
+		Action:      options.Action,
+		State:       Pending,
+		ProgressBar: options.ProgressBar,
+		options:     options,
+		done:        make(chan struct{}),
 	}
 
 	t.allTasks = append(t.allTasks, task)
@@ -169,13 +262,38 @@ func (t *TaskList) AddTask(options TaskOptions) *TaskList {
 	return t
 }
 
+// Results returns the state, duration and error of each task in the order the tasks were added.
+func (t *TaskList) Results() []TaskResult {
+	tasks := t.snapshot()
+	results := make([]TaskResult, len(tasks))
+
+	for i, task := range tasks {
+		var duration time.Duration
+		if task.startTime != nil && task.endTime != nil {
+			duration = task.endTime.Sub(*task.startTime)
+		}
+
+		results[i] = TaskResult{
+			Name:     task.name(),
+			Title:    task.Title,
+			State:    task.State,
+			Error:    task.Error,
+			Duration: duration,
+			Attempts: task.attempts,
+		}
+	}
+
+	return results
+}
+
 func (t *TaskList) Render(printer Printer) error {
-	otherTasks := []*Task{}
-	runningTasks := []*Task{}
-	pendingTasks := []*Task{}
+	theme := activeTheme()
+	otherTasks := []Task{}
+	runningTasks := []Task{}
+	pendingTasks := []Task{}
 
 	// Sort tasks for proper rendering order
-	for _, task := range t.allTasks {
+	for _, task := range t.snapshot() {
 		switch task.State {
 		case Running:
 			runningTasks = append(runningTasks, task)
@@ -186,7 +304,7 @@ func (t *TaskList) Render(printer Printer) error {
 		}
 	}
 
-	renderTasks := []*Task{}
+	renderTasks := []Task{}
 	renderTasks = append(renderTasks, otherTasks...)
 	renderTasks = append(renderTasks, runningTasks...)
 	renderTasks = append(renderTasks, pendingTasks...)
@@ -194,6 +312,10 @@ func (t *TaskList) Render(printer Printer) error {
 	printer.Fprintln()
 
 	for _, task := range renderTasks {
+		if !IsInteractive() && task.State == Pending {
+			continue
+		}
+
 		endTime := time.Now()
 		if task.endTime != nil {
 			endTime = *task.endTime
@@ -202,7 +324,7 @@ func (t *TaskList) Render(printer Printer) error {
 		var elapsedText string
 		if task.startTime != nil {
 			elapsed := endTime.Sub(*task.startTime)
-			elapsedText = color.HiBlackString("(%s)", durationAsText(elapsed))
+			elapsedText = theme.Palette.Muted.Sprintf("(%s)", durationAsText(elapsed))
 		}
 
 		var errorDescription string
@@ -222,17 +344,32 @@ func (t *TaskList) Render(printer Printer) error {
 
 		switch task.State {
 		case Pending:
-			printer.Fprintf("%s %s\n", color.HiBlackString(t.config.PendingStyle), task.Title)
+			printer.Fprintf("%s %s\n", taskStyle(t.config.PendingStyle, theme.Palette.Muted, theme.Glyphs.Pending, "Pending"), task.Title)
 		case Running:
-			printer.Fprintf("%s %s%s %s\n", color.CyanString(t.config.RunningStyle), task.Title, progressText, elapsedText)
+			// The elapsed time and progress bar change on every update, so the append only mode writes the progress
+			// bar as a percentage on the line of the task and a new line is only written when it changes.
+			if !IsInteractive() {
+				if task.ProgressBar != nil {
+					progressText += " " + task.ProgressBar.line(0)
+				}
+
+				printer.Fprintf("%s %s%s\n", taskStyle(t.config.RunningStyle, theme.Palette.Accent, theme.Glyphs.Running, "Running"), task.Title, progressText)
+				break
+			}
+
+			printer.Fprintf("%s %s%s %s\n", taskStyle(t.config.RunningStyle, theme.Palette.Accent, theme.Glyphs.Running, "Running"), task.Title, progressText, elapsedText)
+
+			if task.ProgressBar != nil {
+				printer.Fprintf("    %s\n", task.ProgressBar.line(0))
+			}
 		case Warning:
-			printer.Fprintf("%s %s %s %s\n", color.YellowString(t.config.WarningStyle), task.Title, elapsedText, color.RedString("(%s)", errorDescription))
+			printer.Fprintf("%s %s %s %s\n", taskStyle(t.config.WarningStyle, theme.Palette.Warning, theme.Glyphs.Warning, "Warning"), task.Title, elapsedText, theme.Palette.Error.Sprintf("(%s)", errorDescription))
 		case Error:
-			printer.Fprintf("%s %s %s %s\n", color.RedString(t.config.ErrorStyle), task.Title, elapsedText, color.RedString("(%s)", errorDescription))
+			printer.Fprintf("%s %s %s %s\n", taskStyle(t.config.ErrorStyle, theme.Palette.Error, theme.Glyphs.Error, "Error"), task.Title, elapsedText, theme.Palette.Error.Sprintf("(%s)", errorDescription))
 		case Success:
-			printer.Fprintf("%s %s  %s\n", color.GreenString(t.config.SuccessStyle), task.Title, elapsedText)
+			printer.Fprintf("%s %s  %s\n", taskStyle(t.config.SuccessStyle, theme.Palette.Success, theme.Glyphs.Success, "Done"), task.Title, elapsedText)
 		case Skipped:
-			printer.Fprintf("%s %s %s\n", color.HiBlackString(t.config.SkippedStyle), task.Title, color.RedString("(%s)", errorDescription))
+			printer.Fprintf("%s %s %s\n", taskStyle(t.config.SkippedStyle, theme.Palette.Muted, theme.Glyphs.Skipped, "Skipped"), task.Title, theme.Palette.Error.Sprintf("(%s)", errorDescription))
 		}
 	}
 
@@ -241,77 +378,281 @@ func (t *TaskList) Render(printer Printer) error {
 	return nil
 }
 
-// isCompleted checks if all async tasks are complete.
+// snapshot copies the tasks while they aren't updated, since the tasks run while the task list renders.
+func (t *TaskList) snapshot() []Task {
+	t.taskMutex.Lock()
+	defer t.taskMutex.Unlock()
+
+	tasks := make([]Task, len(t.allTasks))
+	for i, task := range t.allTasks {
+		tasks[i] = *task
+	}
+
+	return tasks
+}
+
+// updateTask changes the state of a task while the task list doesn't render.
+func (t *TaskList) updateTask(task *Task, update func(task *Task)) {
+	t.taskMutex.Lock()
+	defer t.taskMutex.Unlock()
+
+	update(task)
+}
+
+// taskStyle returns the prefix of a task state, the configured style or the glyph and name of the state in the theme.
+func taskStyle(style string, stateColor *color.Color, glyph string, name string) string {
+	if style == "" {
+		style = fmt.Sprintf("%s %s ", glyph, name)
+	}
+
+	return stateColor.Sprint(style)
+}
+
+// isCompleted checks if all tasks are complete.
 func (t *TaskList) isCompleted() bool {
-	return int(t.completed) == len(t.allTasks)
+	return int(atomic.LoadInt32(&t.completed)) == len(t.allTasks)
 }
 
-// runSyncTasks executes all synchronous tasks in order after async tasks are completed.
-func (t *TaskList) runSyncTasks() {
-	t.syncMutex.Lock()
-	defer t.syncMutex.Unlock()
+// resolveDependencies returns the tasks each task waits for. Tasks without dependencies keep the order of the task list:
+// sync tasks wait for the previous sync task and for the async tasks without dependencies.
+func (t *TaskList) resolveDependencies() (map[*Task][]taskDependency, error) {
+	tasksByName := map[string]*Task{}
+	duplicateNames := map[string]bool{}
 
-	for _, task := range t.syncTasks {
-		task.startTime = Ptr(time.Now())
-		task.State = Running
+	for _, task := range t.allTasks {
+		if _, has := tasksByName[task.name()]; has {
+			duplicateNames[task.name()] = true
+		}
 
-		setProgress := func(progress string) {
-			task.progress = progress
+		tasksByName[task.name()] = task
+	}
+
+	dependencies := map[*Task][]taskDependency{}
+	asyncTasks := []*Task{}
+
+	for _, task := range t.allTasks {
+		if task.options.Timeout > 0 && task.options.ActionWithContext == nil {
+			return nil, fmt.Errorf("task '%s' has a timeout but no ActionWithContext to cancel", task.name())
 		}
 
-		state, err := task.Action(setProgress)
-		if err != nil {
-			t.errorMuxtex.Lock()
-			t.errors = append(t.errors, err)
-			t.errorMuxtex.Unlock()
+		for _, name := range task.options.DependsOn {
+			dependency, has := tasksByName[name]
+			if !has {
+				return nil, fmt.Errorf("task '%s' depends on unknown task '%s'", task.name(), name)
+			}
+
+			if duplicateNames[name] {
+				return nil, fmt.Errorf("task '%s' depends on '%s' which is the name of more than one task", task.name(), name)
+			}
+
+			dependencies[task] = append(dependencies[task], taskDependency{task: dependency, required: true})
 		}
 
-		task.endTime = Ptr(time.Now())
-		task.Error = err
-		task.State = state
+		if len(task.options.DependsOn) == 0 && task.options.Async {
+			asyncTasks = append(asyncTasks, task)
+		}
+	}
+
+	// The first sync task waits for the async tasks, including the async tasks added after it
+	var previousSyncTask *Task
+	for _, task := range t.allTasks {
+		if len(task.options.DependsOn) > 0 || task.options.Async {
+			continue
+		}
+
+		if previousSyncTask == nil {
+			for _, asyncTask := range asyncTasks {
+				dependencies[task] = append(dependencies[task], taskDependency{task: asyncTask})
+			}
+		} else {
+			dependencies[task] = append(dependencies[task], taskDependency{task: previousSyncTask})
+		}
+
+		previousSyncTask = task
+	}
 
-		atomic.AddInt32(&t.completed, 1)
+	if err := checkDependencyCycles(t.allTasks, dependencies); err != nil {
+		return nil, err
 	}
+
+	return dependencies, nil
 }
 
-// addAsyncTask adds an asynchronous task and starts its execution in a goroutine.
-func (t *TaskList) addAsyncTask(task *Task) {
-	t.waitGroup.Add(1)
-	go func() {
-		defer t.waitGroup.Done()
+// checkDependencyCycles returns an error when tasks depend on each other, since none of them could start.
+func checkDependencyCycles(tasks []*Task, dependencies map[*Task][]taskDependency) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	visits := map[*Task]int{}
+
+	var visit func(task *Task) error
+	visit = func(task *Task) error {
+		switch visits[task] {
+		case visiting:
+			return fmt.Errorf("task '%s' depends on itself through its dependencies", task.name())
+		case visited:
+			return nil
+		}
+
+		visits[task] = visiting
+
+		for _, dependency := range dependencies[task] {
+			if err := visit(dependency.task); err != nil {
+				return err
+			}
+		}
+
+		visits[task] = visited
+
+		return nil
+	}
+
+	for _, task := range tasks {
+		if err := visit(task); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runTask waits for the dependencies of the task and then runs the action unless the task is skipped.
+func (t *TaskList) runTask(ctx context.Context, task *Task, dependencies []taskDependency) {
+	defer t.waitGroup.Done()
+	defer close(task.done)
+	defer atomic.AddInt32(&t.completed, 1)
+
+	for _, dependency := range dependencies {
+		select {
+		case <-dependency.task.done:
+		case <-ctx.Done():
+			t.skipTask(task, context.Cause(ctx))
+			return
+		}
+
+		if dependency.required && !t.succeeded(dependency.task) {
+			t.skipTask(task, fmt.Errorf("dependency '%s' didn't succeed", dependency.task.name()))
+			return
+		}
+	}
+
+	if ctx.Err() != nil {
+		t.skipTask(task, context.Cause(ctx))
+		return
+	}
 
+	if task.options.Async {
 		// Acquire a slot in the semaphore
-		t.asyncSemaphore <- struct{}{}
+		select {
+		case t.asyncSemaphore <- struct{}{}:
+		case <-ctx.Done():
+			t.skipTask(task, context.Cause(ctx))
+			return
+		}
+
 		defer func() { <-t.asyncSemaphore }()
+	}
 
+	t.updateTask(task, func(task *Task) {
 		task.startTime = Ptr(time.Now())
 		task.State = Running
+	})
 
-		setProgress := func(progress string) {
-			task.progress = progress
+	state, err := t.runAction(ctx, task)
+	if err != nil {
+		t.errorMuxtex.Lock()
+		t.errors = append(t.errors, err)
+		t.errorMuxtex.Unlock()
+	}
+
+	t.updateTask(task, func(task *Task) {
+		task.endTime = Ptr(time.Now())
+		task.Error = err
+		task.State = state
+	})
+
+	if state == Error && t.config.FailurePolicy == FailFast {
+		t.cancel(fmt.Errorf("cancelled after '%s' failed", task.name()))
+	}
+}
+
+// runAction runs the action of the task again after it fails until it runs out of retries.
+func (t *TaskList) runAction(ctx context.Context, task *Task) (TaskState, error) {
+	for attempt := 1; ; attempt++ {
+		t.updateTask(task, func(task *Task) {
+			task.attempts = attempt
+		})
+
+		state, err := t.runAttempt(ctx, task)
+		if state != Error || attempt > task.options.Retries || ctx.Err() != nil {
+			return state, err
 		}
 
-		state, err := task.Action(setProgress)
-		if err != nil {
-			t.errorMuxtex.Lock()
-			t.errors = append(t.errors, err)
-			t.errorMuxtex.Unlock()
+		t.updateTask(task, func(task *Task) {
+			task.progress = fmt.Sprintf("retry %d of %d", attempt, task.options.Retries)
+		})
+
+		select {
+		case <-time.After(task.options.RetryDelay):
+		case <-ctx.Done():
+			return state, err
 		}
+	}
+}
 
-		task.endTime = Ptr(time.Now())
+func (t *TaskList) skipTask(task *Task, err error) {
+	t.updateTask(task, func(task *Task) {
 		task.Error = err
-		task.State = state
+		task.State = Skipped
+	})
+}
 
-		atomic.AddInt32(&t.completed, 1)
-	}()
+// succeeded returns true when the task ran without an error, a warning still allows dependent tasks to run.
+func (t *TaskList) succeeded(task *Task) bool {
+	t.taskMutex.Lock()
+	defer t.taskMutex.Unlock()
+
+	return task.State == Success || task.State == Warning
 }
 
-// addSyncTask queues a synchronous task for execution after async completion.
-func (t *TaskList) addSyncTask(task *Task) {
-	t.syncMutex.Lock()
-	defer t.syncMutex.Unlock()
+// runAttempt runs the action once within the timeout of the task.
+func (t *TaskList) runAttempt(ctx context.Context, task *Task) (TaskState, error) {
+	if task.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.options.Timeout)
+		defer cancel()
+	}
+
+	setProgress := func(progress string) {
+		t.updateTask(task, func(task *Task) {
+			task.progress = progress
+		})
+	}
+
+	var state TaskState
+	var err error
+	if task.options.ActionWithContext != nil {
+		state, err = task.options.ActionWithContext(ctx, setProgress)
+	} else {
+		state, err = task.Action(setProgress)
+	}
+
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s: %w", durationAsText(task.options.Timeout), err)
+	}
+
+	return state, err
+}
+
+func (task *Task) name() string {
+	if task.options.Name != "" {
+		return task.options.Name
+	}
 
-	t.syncTasks = append(t.syncTasks, task)
+	return task.Title
 }
 
 // DurationAsText provides a slightly nicer string representation of a duration
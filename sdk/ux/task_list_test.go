@@ -0,0 +1,218 @@
+package ux
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TaskList(t *testing.T) {
+	// recordTask returns a task that appends its name to the order when it runs
+	recordTask := func(order *[]string, mutex *sync.Mutex, options TaskOptions) TaskOptions {
+		options.Action = func(setProgress SetProgressFunc) (TaskState, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			*order = append(*order, options.Name)
+			return Success, nil
+		}
+
+		return options
+	}
+
+	t.Run("ImplicitOrder", func(t *testing.T) {
+		order := []string{}
+		mutex := sync.Mutex{}
+
+		err := NewTaskList(&TaskListConfig{Writer: io.Discard}).
+			AddTask(recordTask(&order, &mutex, TaskOptions{Name: "sync 1"})).
+			AddTask(recordTask(&order, &mutex, TaskOptions{Name: "sync 2"})).
+			AddTask(recordTask(&order, &mutex, TaskOptions{Name: "async", Async: true})).
+			Run()
+		require.NoError(t, err)
+		require.Equal(t, []string{"async", "sync 1", "sync 2"}, order)
+	})
+
+	t.Run("Dependencies", func(t *testing.T) {
+		order := []string{}
+		mutex := sync.Mutex{}
+
+		err := NewTaskList(&TaskListConfig{Writer: io.Discard}).
+			AddTask(recordTask(&order, &mutex, TaskOptions{Name: "index", DependsOn: []string{"upload", "embed"}, Async: true})).
+			AddTask(recordTask(&order, &mutex, TaskOptions{Name: "embed", DependsOn: []string{"upload"}, Async: true})).
+			AddTask(recordTask(&order, &mutex, TaskOptions{Name: "upload", Async: true})).
+			Run()
+		require.NoError(t, err)
+		require.Equal(t, []string{"upload", "embed", "index"}, order)
+	})
+
+	t.Run("FailedDependency", func(t *testing.T) {
+		uploadErr := errors.New("upload failed")
+
+		taskList := NewTaskList(&TaskListConfig{Writer: io.Discard}).
+			AddTask(TaskOptions{
+				Title: "Upload",
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Error, uploadErr
+				},
+			}).
+			AddTask(TaskOptions{
+				Title:     "Index",
+				DependsOn: []string{"Upload"},
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Success, nil
+				},
+			}).
+			AddTask(TaskOptions{
+				Title: "Report",
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Success, nil
+				},
+			})
+
+		err := taskList.Run()
+		require.ErrorIs(t, err, uploadErr)
+
+		results := taskList.Results()
+		require.Equal(t, Error, results[0].State)
+		require.Equal(t, 1, results[0].Attempts)
+		require.Equal(t, Skipped, results[1].State)
+		require.EqualError(t, results[1].Error, "dependency 'Upload' didn't succeed")
+		require.Equal(t, 0, results[1].Attempts)
+		require.Equal(t, Success, results[2].State)
+	})
+
+	t.Run("FailFast", func(t *testing.T) {
+		taskList := NewTaskList(&TaskListConfig{Writer: io.Discard, FailurePolicy: FailFast}).
+			AddTask(TaskOptions{
+				Title: "Create account",
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Error, errors.New("quota exceeded")
+				},
+			}).
+			AddTask(TaskOptions{
+				Title: "Create deployment",
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Success, nil
+				},
+			})
+
+		require.EqualError(t, taskList.Run(), "quota exceeded")
+
+		results := taskList.Results()
+		require.Equal(t, Skipped, results[1].State)
+		require.EqualError(t, results[1].Error, "cancelled after 'Create account' failed")
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+
+		go func() {
+			<-started
+			cancel()
+		}()
+
+		taskList := NewTaskList(&TaskListConfig{Writer: io.Discard}).
+			AddTask(TaskOptions{
+				Title: "Wait",
+				ActionWithContext: func(ctx context.Context, setProgress SetProgressFunc) (TaskState, error) {
+					close(started)
+					<-ctx.Done()
+					return Error, ctx.Err()
+				},
+			}).
+			AddTask(TaskOptions{
+				Title: "Next",
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Success, nil
+				},
+			})
+
+		err := taskList.RunWithContext(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		require.EqualError(t, err, "context canceled")
+
+		results := taskList.Results()
+		require.Equal(t, Error, results[0].State)
+		require.Equal(t, Skipped, results[1].State)
+	})
+
+	t.Run("TimeoutAndRetries", func(t *testing.T) {
+		attempts := 0
+
+		taskList := NewTaskList(&TaskListConfig{Writer: io.Discard}).
+			AddTask(TaskOptions{
+				Title:   "Flaky",
+				Retries: 2,
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					attempts++
+					if attempts < 3 {
+						return Error, errors.New("throttled")
+					}
+
+					return Success, nil
+				},
+			}).
+			AddTask(TaskOptions{
+				Title:   "Slow",
+				Timeout: 10 * time.Millisecond,
+				ActionWithContext: func(ctx context.Context, setProgress SetProgressFunc) (TaskState, error) {
+					<-ctx.Done()
+					return Error, ctx.Err()
+				},
+			})
+
+		err := taskList.Run()
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.EqualError(t, err, "timed out after less than a second: context deadline exceeded")
+
+		results := taskList.Results()
+		require.Equal(t, Success, results[0].State)
+		require.Equal(t, 3, results[0].Attempts)
+		require.Equal(t, Error, results[1].State)
+		require.GreaterOrEqual(t, results[1].Duration, 10*time.Millisecond)
+	})
+
+	t.Run("RunTwice", func(t *testing.T) {
+		taskList := NewTaskList(&TaskListConfig{Writer: io.Discard}).
+			AddTask(TaskOptions{
+				Title: "Deploy",
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Success, nil
+				},
+			})
+
+		require.NoError(t, taskList.Run())
+		require.ErrorIs(t, taskList.Run(), ErrTaskListRan)
+		require.Equal(t, Success, taskList.Results()[0].State)
+	})
+
+	t.Run("InvalidDependencies", func(t *testing.T) {
+		action := func(setProgress SetProgressFunc) (TaskState, error) {
+			return Success, nil
+		}
+
+		err := NewTaskList(&TaskListConfig{Writer: io.Discard}).
+			AddTask(TaskOptions{Title: "Index", DependsOn: []string{"Upload"}, Action: action}).
+			Run()
+		require.EqualError(t, err, "task 'Index' depends on unknown task 'Upload'")
+
+		err = NewTaskList(&TaskListConfig{Writer: io.Discard}).
+			AddTask(TaskOptions{Title: "Upload", DependsOn: []string{"Index"}, Action: action}).
+			AddTask(TaskOptions{Title: "Index", DependsOn: []string{"Upload"}, Action: action}).
+			Run()
+		require.EqualError(t, err, "task 'Upload' depends on itself through its dependencies")
+
+		// The timeout cancels the context of ActionWithContext, Action can't be cancelled
+		err = NewTaskList(&TaskListConfig{Writer: io.Discard}).
+			AddTask(TaskOptions{Title: "Upload", Timeout: time.Second, Action: action}).
+			Run()
+		require.EqualError(t, err, "task 'Upload' has a timeout but no ActionWithContext to cancel")
+	})
+}
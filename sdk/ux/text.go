@@ -0,0 +1,84 @@
+package ux
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// escapeSequenceRegex matches color and style sequences as well as hyperlinks and other operating system commands.
+var escapeSequenceRegex = regexp.MustCompile("\x1b\\[[0-9;?]*[@-~]|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)")
+
+// Alignment is the horizontal alignment of text within a column.
+type Alignment int
+
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+	AlignCenter
+)
+
+// StripEscapeSequences removes colors, styles and hyperlink targets from the text, leaving the visible text.
+func StripEscapeSequences(text string) string {
+	return escapeSequenceRegex.ReplaceAllString(text, "")
+}
+
+// VisibleWidth returns the number of columns the text occupies in the terminal, ignoring escape sequences.
+func VisibleWidth(text string) int {
+	return utf8.RuneCountInString(StripEscapeSequences(text))
+}
+
+// TruncateText shortens the text to the width, ending with an ellipsis when it doesn't fit.
+// Escape sequences are preserved so colors and hyperlinks are still closed after the truncated text.
+func TruncateText(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	if VisibleWidth(text) <= width {
+		return text
+	}
+
+	builder := strings.Builder{}
+	visible := 0
+	truncated := false
+
+	for len(text) > 0 {
+		if location := escapeSequenceRegex.FindStringIndex(text); location != nil && location[0] == 0 {
+			builder.WriteString(text[:location[1]])
+			text = text[location[1]:]
+			continue
+		}
+
+		char, size := utf8.DecodeRuneInString(text)
+		text = text[size:]
+
+		if visible < width-1 {
+			builder.WriteRune(char)
+			visible++
+		} else if !truncated {
+			builder.WriteRune('…')
+			truncated = true
+		}
+	}
+
+	return builder.String()
+}
+
+// AlignText pads the text with spaces to the width.
+func AlignText(text string, width int, alignment Alignment) string {
+	padding := width - VisibleWidth(text)
+	if padding <= 0 {
+		return text
+	}
+
+	switch alignment {
+	case AlignRight:
+		return strings.Repeat(" ", padding) + text
+	case AlignCenter:
+		left := padding / 2
+		return strings.Repeat(" ", left) + text + strings.Repeat(" ", padding-left)
+	default:
+		return text + strings.Repeat(" ", padding)
+	}
+}
@@ -0,0 +1,140 @@
+package ux
+
+import (
+	"sync/atomic"
+
+	"dario.cat/mergo"
+	"github.com/fatih/color"
+)
+
+// Theme is the palette and glyphs used by Prompt, Confirm, Select, MultiSelect, Spinner and TaskList.
+// Colors are disabled when NO_COLOR is set or the output isn't a terminal, so the glyphs and text of a theme should
+// convey the state without colors.
+type Theme struct {
+	Palette Palette
+	Glyphs  Glyphs
+	// Whether the glyphs are replaced by AsciiGlyphs for terminals and fonts without unicode symbols (default: false)
+	AsciiOnly bool
+}
+
+// Palette are the colors of a theme.
+type Palette struct {
+	// The color of questions, answers and the focused choice
+	Accent *color.Color
+	// The color of placeholders, footers and pending or skipped tasks
+	Muted *color.Color
+	// The color of hints
+	Hint *color.Color
+	// The color of completed tasks
+	Success *color.Color
+	// The color of validation messages and warnings
+	Warning *color.Color
+	// The color of errors and cancelled prompts
+	Error *color.Color
+}
+
+// Glyphs are the symbols of a theme.
+type Glyphs struct {
+	// The prefix of questions
+	Question string
+	// The marker of the focused choice
+	Pointer string
+	// The checkbox of selected and unselected choices
+	Checked   string
+	Unchecked string
+	// The separator line above the footer of selects
+	Separator string
+	// The frames of the spinner animation
+	Spinner []string
+	// The markers of the task states
+	Success string
+	Error   string
+	Warning string
+	Running string
+	Skipped string
+	Pending string
+}
+
+var UnicodeGlyphs = Glyphs{
+	Question:  "?",
+	Pointer:   ">",
+	Checked:   "[✔]",
+	Unchecked: "[ ]",
+	Separator: "───────────────────────────────────",
+	Spinner:   []string{"|", "/", "-", "\\"},
+	Success:   "(✔)",
+	Error:     "(x)",
+	Warning:   "(!)",
+	Running:   "(-)",
+	Skipped:   "(-)",
+	Pending:   "(o)",
+}
+
+var AsciiGlyphs = Glyphs{
+	Question:  "?",
+	Pointer:   ">",
+	Checked:   "[x]",
+	Unchecked: "[ ]",
+	Separator: "-----------------------------------",
+	Spinner:   []string{"|", "/", "-", "\\"},
+	Success:   "(+)",
+	Error:     "(x)",
+	Warning:   "(!)",
+	Running:   "(-)",
+	Skipped:   "(-)",
+	Pending:   "(o)",
+}
+
+var DefaultTheme Theme = Theme{
+	Palette: Palette{
+		Accent:  color.New(color.FgCyan),
+		Muted:   color.New(color.FgHiBlack),
+		Hint:    color.New(color.FgHiMagenta),
+		Success: color.New(color.FgGreen),
+		Warning: color.New(color.FgYellow),
+		Error:   color.New(color.FgRed),
+	},
+	Glyphs: UnicodeGlyphs,
+}
+
+var currentTheme atomic.Pointer[Theme]
+
+// SetTheme changes the theme of all visuals, the colors and glyphs that aren't set come from the DefaultTheme.
+// Nil restores the DefaultTheme.
+func SetTheme(theme *Theme) {
+	if theme == nil {
+		currentTheme.Store(nil)
+		return
+	}
+
+	mergedTheme := Theme{}
+	if err := mergo.Merge(&mergedTheme, theme, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	if err := mergo.Merge(&mergedTheme, DefaultTheme, mergo.WithoutDereference); err != nil {
+		panic(err)
+	}
+
+	currentTheme.Store(&mergedTheme)
+}
+
+// CurrentTheme returns the theme of the visuals.
+func CurrentTheme() Theme {
+	if theme := currentTheme.Load(); theme != nil {
+		return *theme
+	}
+
+	return DefaultTheme
+}
+
+// activeTheme returns the current theme with the glyphs of the render mode, screen readers read ASCII glyphs more
+// predictably than unicode symbols.
+func activeTheme() Theme {
+	theme := CurrentTheme()
+	if theme.AsciiOnly || CurrentRenderMode() == ScreenReaderRenderMode {
+		theme.Glyphs = AsciiGlyphs
+	}
+
+	return theme
+}
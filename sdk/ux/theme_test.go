@@ -0,0 +1,109 @@
+package ux
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/eiannone/keyboard"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Theme(t *testing.T) {
+	useTheme := func(t *testing.T, theme *Theme) {
+		SetTheme(theme)
+		t.Cleanup(func() {
+			SetTheme(nil)
+		})
+	}
+
+	t.Run("MergesDefaults", func(t *testing.T) {
+		success := color.New(color.FgBlue)
+		useTheme(t, &Theme{Palette: Palette{Success: success}, Glyphs: Glyphs{Pointer: "→"}})
+
+		theme := CurrentTheme()
+		require.Same(t, success, theme.Palette.Success)
+		require.Same(t, DefaultTheme.Palette.Error, theme.Palette.Error)
+		require.Equal(t, "→", theme.Glyphs.Pointer)
+		require.Equal(t, UnicodeGlyphs.Checked, theme.Glyphs.Checked)
+		require.Equal(t, UnicodeGlyphs.Spinner, theme.Glyphs.Spinner)
+	})
+
+	t.Run("AsciiOnly", func(t *testing.T) {
+		useTheme(t, &Theme{AsciiOnly: true})
+
+		var buffer bytes.Buffer
+		_, err := NewMultiSelect(&MultiSelectOptions{
+			Writer:  &buffer,
+			Message: "Models",
+			Allowed: []string{"gpt-4o", "gpt-4o-mini"},
+			Input:   NewScriptedInput().Press(keyboard.KeySpace, keyboard.KeyEnter),
+		}).Ask()
+		require.NoError(t, err)
+		require.Contains(t, buffer.String(), "[x] gpt-4o")
+		require.Contains(t, buffer.String(), AsciiGlyphs.Separator)
+
+		buffer.Reset()
+		err = NewTaskList(&TaskListConfig{Writer: &buffer}).
+			AddTask(TaskOptions{
+				Title: "Deploy",
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Success, nil
+				},
+			}).
+			Run()
+		require.NoError(t, err)
+		require.Contains(t, StripEscapeSequences(buffer.String()), "(+) Done  Deploy")
+
+		for _, r := range StripEscapeSequences(buffer.String()) {
+			require.Less(t, r, rune(128))
+		}
+	})
+
+	t.Run("TaskListStyles", func(t *testing.T) {
+		var buffer bytes.Buffer
+		err := NewTaskList(&TaskListConfig{Writer: &buffer, SuccessStyle: "OK"}).
+			AddTask(TaskOptions{
+				Title: "Deploy",
+				Action: func(setProgress SetProgressFunc) (TaskState, error) {
+					return Success, nil
+				},
+			}).
+			Run()
+		require.NoError(t, err)
+		require.Contains(t, StripEscapeSequences(buffer.String()), "OK Deploy")
+	})
+
+	t.Run("ScreenReader", func(t *testing.T) {
+		SetRenderMode(ScreenReaderRenderMode)
+		t.Cleanup(func() {
+			SetRenderMode(InteractiveRenderMode)
+		})
+
+		var buffer bytes.Buffer
+		selected, err := NewSelect(&SelectOptions{
+			Writer:          &buffer,
+			Message:         "Region",
+			Allowed:         []string{"eastus", "westus"},
+			EnableFiltering: Ptr(false),
+			Input:           NewScriptedInput().Press(keyboard.KeyArrowDown, keyboard.KeyArrowUp, keyboard.KeyArrowDown, keyboard.KeyEnter),
+		}).Ask()
+		require.NoError(t, err)
+		require.Equal(t, 1, *selected)
+
+		output := buffer.String()
+		require.NotContains(t, output, "\x1b[")
+		require.True(t, strings.HasPrefix(output, "? Region:\n"))
+		require.Equal(t, 4, strings.Count(output, "> "))
+		require.Equal(t, 2, strings.Count(output, "> westus"))
+		require.True(t, strings.HasSuffix(output, "? Region: westus\n"))
+
+		buffer.Reset()
+		spinner := NewSpinner(&SpinnerOptions{Writer: &buffer, Text: "Loading models"})
+		canvas := NewCanvas(spinner).WithWriter(&buffer)
+		require.NoError(t, canvas.Run())
+		require.NoError(t, canvas.Update())
+		require.Equal(t, "Loading models\n", buffer.String())
+	})
+}
@@ -11,6 +11,12 @@ import (
 var ErrCancelled = errors.New("cancelled by user")
 
 func init() {
+	// NO_COLOR takes precedence over FORCE_COLOR, see https://no-color.org
+	if os.Getenv("NO_COLOR") != "" {
+		color.NoColor = true
+		return
+	}
+
 	forceColorVal, has := os.LookupEnv("FORCE_COLOR")
 	if has && forceColorVal == "1" {
 		color.NoColor = false
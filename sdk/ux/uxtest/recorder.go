@@ -0,0 +1,86 @@
+package uxtest
+
+import (
+	"io"
+	"sync"
+
+	"github.com/wbreza/azd-extensions/sdk/ux"
+)
+
+// Recorder is a canvas that renders visuals to a virtual terminal and captures the visible screen after each
+// Run and Update.
+type Recorder struct {
+	terminal *Terminal
+	canvas   ux.Canvas
+	frames   []string
+	mutex    sync.Mutex
+}
+
+// NewRecorder creates a canvas for the visuals that renders to the terminal. The visuals are attached to the
+// recorder so the updates they trigger while running are captured.
+//
+// Visuals render in the current render mode, which is append only under go test since its output isn't a terminal.
+// Call ux.SetRenderMode(ux.InteractiveRenderMode) to record the interactive screens.
+func NewRecorder(terminal *Terminal, visuals ...ux.Visual) *Recorder {
+	if terminal == nil {
+		terminal = NewTerminal(DefaultSize)
+	}
+
+	recorder := &Recorder{
+		terminal: terminal,
+		canvas:   ux.NewCanvas(visuals...).WithWriter(terminal),
+	}
+
+	for _, visual := range visuals {
+		visual.WithCanvas(recorder)
+	}
+
+	return recorder
+}
+
+// Terminal returns the virtual terminal the visuals are rendered to.
+func (r *Recorder) Terminal() *Terminal {
+	return r.terminal
+}
+
+func (r *Recorder) Run() error {
+	return r.capture(r.canvas.Run())
+}
+
+func (r *Recorder) Update() error {
+	return r.capture(r.canvas.Update())
+}
+
+// WithWriter is ignored since the recorder always renders to its terminal.
+func (r *Recorder) WithWriter(writer io.Writer) ux.Canvas {
+	return r
+}
+
+// Frames returns the screens captured so far.
+func (r *Recorder) Frames() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]string{}, r.frames...)
+}
+
+// LastFrame returns the most recently captured screen.
+func (r *Recorder) LastFrame() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.frames) == 0 {
+		return ""
+	}
+
+	return r.frames[len(r.frames)-1]
+}
+
+func (r *Recorder) capture(err error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.frames = append(r.frames, r.terminal.Screen())
+
+	return err
+}
@@ -0,0 +1,73 @@
+package uxtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// UpdateSnapshotsEnv is the environment variable that rewrites existing snapshots when set to "1" or "true".
+const UpdateSnapshotsEnv = "UXTEST_UPDATE"
+
+// SnapshotDir is the directory, relative to the package under test, where snapshots are stored.
+var SnapshotDir = filepath.Join("testdata", "snapshots")
+
+var snapshotNameRegex = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// AssertSnapshot compares the actual output with the snapshot named after the current test.
+// Missing snapshots are created and all snapshots are rewritten when UXTEST_UPDATE is set.
+func AssertSnapshot(t testing.TB, actual string) {
+	t.Helper()
+
+	name := snapshotNameRegex.ReplaceAllString(t.Name(), "_")
+	path := filepath.Join(SnapshotDir, name+".snap")
+	actual = strings.TrimRight(actual, "\n") + "\n"
+
+	update := os.Getenv(UpdateSnapshotsEnv)
+	expected, err := os.ReadFile(path)
+
+	if os.IsNotExist(err) || update == "1" || update == "true" {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("failed creating snapshot directory: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte(actual), 0600); err != nil {
+			t.Fatalf("failed writing snapshot: %v", err)
+		}
+
+		t.Logf("updated snapshot %s", path)
+		return
+	}
+
+	if err != nil {
+		t.Fatalf("failed reading snapshot: %v", err)
+	}
+
+	if string(expected) != actual {
+		t.Errorf(
+			"output does not match snapshot %s (set %s=1 to update)\n--- expected ---\n%s--- actual ---\n%s",
+			path,
+			UpdateSnapshotsEnv,
+			expected,
+			actual,
+		)
+	}
+}
+
+// FormatFrames joins the frames captured by a recorder into a single snapshot separated by numbered dividers.
+func FormatFrames(frames []string) string {
+	builder := strings.Builder{}
+
+	for i, frame := range frames {
+		builder.WriteString(fmt.Sprintf("--- frame %d ---\n", i+1))
+		if frame != "" {
+			builder.WriteString(frame)
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,293 @@
+// Package uxtest provides an in-memory virtual terminal and snapshot assertions to test the output of ux visuals.
+package uxtest
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/wbreza/azd-extensions/sdk/ux"
+)
+
+// DefaultSize is the size of terminals created without an explicit size.
+var DefaultSize = ux.CanvasSize{Rows: 24, Cols: 80}
+
+// Terminal is a fixed-size virtual terminal. It interprets the text, cursor movements and clears written by
+// ux.Printer so tests can assert on the visible screen. Colors and other text styles are ignored.
+type Terminal struct {
+	size          ux.CanvasSize
+	lines         [][]rune
+	row           int
+	col           int
+	cursorVisible bool
+	pending       []byte
+	mutex         sync.Mutex
+}
+
+// NewTerminal creates a blank terminal with the specified size.
+func NewTerminal(size ux.CanvasSize) *Terminal {
+	if size.Rows <= 0 || size.Cols <= 0 {
+		size = DefaultSize
+	}
+
+	terminal := &Terminal{
+		size:          size,
+		cursorVisible: true,
+	}
+
+	terminal.lines = make([][]rune, size.Rows)
+	for i := range terminal.lines {
+		terminal.lines[i] = terminal.blankLine()
+	}
+
+	return terminal
+}
+
+// Size returns the fixed size of the terminal.
+func (t *Terminal) Size() ux.CanvasSize {
+	return t.size
+}
+
+// Write interprets the written bytes. Escape sequences and characters split across writes are supported.
+func (t *Terminal) Write(data []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	buffer := append(t.pending, data...)
+	t.pending = nil
+
+	for i := 0; i < len(buffer); {
+		consumed := t.process(buffer[i:])
+		if consumed == 0 {
+			// Incomplete escape sequence or character, wait for the next write
+			t.pending = append([]byte{}, buffer[i:]...)
+			break
+		}
+
+		i += consumed
+	}
+
+	return len(data), nil
+}
+
+// Screen returns the visible lines of the terminal. Trailing spaces and trailing blank lines are removed.
+func (t *Terminal) Screen() string {
+	return strings.Join(t.Lines(), "\n")
+}
+
+// Lines returns the visible lines of the terminal up to the last non-blank line.
+func (t *Terminal) Lines() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	lines := make([]string, len(t.lines))
+	last := -1
+
+	for i, line := range t.lines {
+		lines[i] = strings.TrimRight(string(line), " ")
+		if lines[i] != "" {
+			last = i
+		}
+	}
+
+	return lines[:last+1]
+}
+
+// Cursor returns the zero-based position of the cursor.
+func (t *Terminal) Cursor() ux.CursorPosition {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return ux.CursorPosition{Row: t.row, Col: min(t.col, t.size.Cols-1)}
+}
+
+// CursorVisible returns false while the cursor is hidden.
+func (t *Terminal) CursorVisible() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.cursorVisible
+}
+
+// process interprets the control sequence or character at the start of the data and returns the number of bytes
+// consumed, or zero when the data is incomplete.
+func (t *Terminal) process(data []byte) int {
+	switch data[0] {
+	case '\x1b':
+		return t.escape(data)
+	case '\r':
+		t.col = 0
+	case '\n':
+		// Output written to terminals translates line feeds to carriage return and line feed
+		t.col = 0
+		t.lineFeed()
+	case '\b':
+		t.col = max(0, min(t.col, t.size.Cols-1)-1)
+	case '\t':
+		t.col = min(t.size.Cols-1, (t.col/8+1)*8)
+	case '\a':
+	default:
+		if !utf8.FullRune(data) {
+			return 0
+		}
+
+		char, size := utf8.DecodeRune(data)
+		if char >= ' ' {
+			t.put(char)
+		}
+
+		return size
+	}
+
+	return 1
+}
+
+// put writes the character at the cursor position. Lines wrap when the cursor is past the last column.
+func (t *Terminal) put(char rune) {
+	if t.col >= t.size.Cols {
+		t.col = 0
+		t.lineFeed()
+	}
+
+	t.lines[t.row][t.col] = char
+	t.col++
+}
+
+// lineFeed moves the cursor to the next line and scrolls the screen at the bottom.
+func (t *Terminal) lineFeed() {
+	if t.row < t.size.Rows-1 {
+		t.row++
+		return
+	}
+
+	t.lines = append(t.lines[1:], t.blankLine())
+}
+
+func (t *Terminal) escape(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+
+	switch data[1] {
+	case '[':
+		// Control sequence: parameter bytes followed by a final byte in the range @ to ~
+		for i := 2; i < len(data); i++ {
+			if data[i] >= '@' && data[i] <= '~' {
+				t.control(string(data[2:i]), data[i])
+				return i + 1
+			}
+		}
+
+		return 0
+	case ']':
+		// Operating system command such as hyperlinks, terminated by BEL or ESC \
+		for i := 2; i < len(data); i++ {
+			if data[i] == '\a' {
+				return i + 1
+			}
+
+			if data[i] == '\x1b' && i+1 < len(data) && data[i+1] == '\\' {
+				return i + 2
+			}
+		}
+
+		return 0
+	default:
+		return 2
+	}
+}
+
+func (t *Terminal) control(parameters string, command byte) {
+	if parameters == "?25" {
+		switch command {
+		case 'h':
+			t.cursorVisible = true
+		case 'l':
+			t.cursorVisible = false
+		}
+
+		return
+	}
+
+	values := []int{}
+	for _, parameter := range strings.Split(parameters, ";") {
+		value, _ := strconv.Atoi(parameter)
+		values = append(values, value)
+	}
+
+	count := max(1, values[0])
+	col := min(t.col, t.size.Cols-1)
+
+	switch command {
+	case 'A':
+		t.row = max(0, t.row-count)
+		t.col = col
+	case 'B':
+		t.row = min(t.size.Rows-1, t.row+count)
+		t.col = col
+	case 'C':
+		t.col = min(t.size.Cols-1, col+count)
+	case 'D':
+		t.col = max(0, col-count)
+	case 'G':
+		t.col = min(t.size.Cols-1, count-1)
+	case 'H', 'f':
+		t.row = min(t.size.Rows-1, count-1)
+		t.col = 0
+		if len(values) > 1 {
+			t.col = min(t.size.Cols-1, max(1, values[1])-1)
+		}
+	case 'K':
+		t.clearLine(values[0], col)
+	case 'J':
+		t.clearScreen(values[0], col)
+	}
+}
+
+// clearLine clears to the end of the line (0), to the start of the line (1) or the whole line (2).
+func (t *Terminal) clearLine(mode int, col int) {
+	line := t.lines[t.row]
+
+	switch mode {
+	case 0:
+		clearRange(line, col, len(line))
+	case 1:
+		clearRange(line, 0, col+1)
+	case 2:
+		clearRange(line, 0, len(line))
+	}
+}
+
+// clearScreen clears to the end of the screen (0), to the start of the screen (1) or the whole screen (2).
+func (t *Terminal) clearScreen(mode int, col int) {
+	switch mode {
+	case 0:
+		t.clearLine(0, col)
+		for row := t.row + 1; row < len(t.lines); row++ {
+			clearRange(t.lines[row], 0, t.size.Cols)
+		}
+	case 1:
+		t.clearLine(1, col)
+		for row := 0; row < t.row; row++ {
+			clearRange(t.lines[row], 0, t.size.Cols)
+		}
+	case 2, 3:
+		for _, line := range t.lines {
+			clearRange(line, 0, t.size.Cols)
+		}
+	}
+}
+
+func (t *Terminal) blankLine() []rune {
+	line := make([]rune, t.size.Cols)
+	clearRange(line, 0, len(line))
+
+	return line
+}
+
+func clearRange(line []rune, start int, end int) {
+	for i := start; i < end; i++ {
+		line[i] = ' '
+	}
+}
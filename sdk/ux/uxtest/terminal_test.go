@@ -0,0 +1,107 @@
+package uxtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/wbreza/azd-extensions/sdk/ux"
+)
+
+func Test_Terminal(t *testing.T) {
+	t.Run("Text", func(t *testing.T) {
+		terminal := NewTerminal(ux.CanvasSize{Rows: 5, Cols: 20})
+		fmt.Fprint(terminal, "hello\nworld   \n\033[32mgreen\033[0m")
+
+		require.Equal(t, []string{"hello", "world", "green"}, terminal.Lines())
+		require.Equal(t, ux.CursorPosition{Row: 2, Col: 5}, terminal.Cursor())
+	})
+
+	t.Run("CursorMovement", func(t *testing.T) {
+		terminal := NewTerminal(ux.CanvasSize{Rows: 5, Cols: 20})
+		fmt.Fprint(terminal, "one\ntwo\nthree")
+		fmt.Fprint(terminal, "\033[2A\rONE\033[B\033[2CX\033[10D>")
+
+		require.Equal(t, "ONE\n>wo  X\nthree", terminal.Screen())
+	})
+
+	t.Run("ClearLine", func(t *testing.T) {
+		terminal := NewTerminal(ux.CanvasSize{Rows: 5, Cols: 20})
+		fmt.Fprint(terminal, "one\ntwo\nthree")
+		fmt.Fprint(terminal, "\033[2K\r\033[1A\033[2K\rTWO")
+
+		require.Equal(t, "one\nTWO", terminal.Screen())
+		require.Equal(t, ux.CursorPosition{Row: 1, Col: 3}, terminal.Cursor())
+
+		fmt.Fprint(terminal, "\033[2D\033[K")
+		require.Equal(t, "one\nT", terminal.Screen())
+	})
+
+	t.Run("ClearScreen", func(t *testing.T) {
+		terminal := NewTerminal(ux.CanvasSize{Rows: 5, Cols: 20})
+		fmt.Fprint(terminal, "one\ntwo\nthree\033[1;2H\033[J")
+
+		require.Equal(t, "o", terminal.Screen())
+
+		fmt.Fprint(terminal, "\033[2J")
+		require.Equal(t, "", terminal.Screen())
+	})
+
+	t.Run("WrapAndScroll", func(t *testing.T) {
+		terminal := NewTerminal(ux.CanvasSize{Rows: 3, Cols: 4})
+		fmt.Fprint(terminal, "abcd")
+		require.Equal(t, ux.CursorPosition{Row: 0, Col: 3}, terminal.Cursor())
+
+		fmt.Fprint(terminal, "efgh\n1\n2")
+		require.Equal(t, []string{"efgh", "1", "2"}, terminal.Lines())
+	})
+
+	t.Run("SplitSequences", func(t *testing.T) {
+		terminal := NewTerminal(ux.CanvasSize{Rows: 5, Cols: 20})
+		fmt.Fprint(terminal, "ab\033[")
+		fmt.Fprint(terminal, "1D\xe2\x9c")
+		fmt.Fprint(terminal, "\x94")
+
+		require.Equal(t, "a✔", terminal.Screen())
+	})
+
+	t.Run("CursorVisibilityAndHyperlinks", func(t *testing.T) {
+		terminal := NewTerminal(ux.CanvasSize{Rows: 5, Cols: 40})
+		fmt.Fprint(terminal, "\033[?25l\033]8;;https://example.com\007link\033]8;;\007")
+
+		require.False(t, terminal.CursorVisible())
+		require.Equal(t, "link", terminal.Screen())
+
+		fmt.Fprint(terminal, "\033[?25h")
+		require.True(t, terminal.CursorVisible())
+	})
+}
+
+func Test_Recorder(t *testing.T) {
+	ux.SetRenderMode(ux.InteractiveRenderMode)
+	t.Cleanup(func() {
+		ux.SetRenderMode(ux.AutoRenderMode)
+	})
+
+	count := 0
+	visual := ux.NewVisualElement(func(printer ux.Printer) error {
+		count++
+		for i := 0; i < count; i++ {
+			printer.Fprintf("line %d\n", i+1)
+		}
+
+		return nil
+	})
+
+	recorder := NewRecorder(NewTerminal(ux.CanvasSize{Rows: 10, Cols: 20}), visual)
+	require.NoError(t, recorder.Run())
+	require.NoError(t, recorder.Update())
+	require.NoError(t, recorder.Update())
+
+	require.Equal(t, []string{
+		"line 1",
+		"line 1\nline 2",
+		"line 1\nline 2\nline 3",
+	}, recorder.Frames())
+	require.Equal(t, "--- frame 1 ---\nline 1\n--- frame 2 ---\nline 1\nline 2\n", FormatFrames(recorder.Frames()[:2]))
+}